@@ -2,4 +2,9 @@ module github.com/SHIMA0111/kanjo
 
 go 1.24.3
 
-require github.com/go-gota/gota v0.12.0 // indirect
+require github.com/go-gota/gota v0.12.0
+
+require (
+	golang.org/x/net v0.0.0-20210423184538-5f58ad60dda6 // indirect
+	gonum.org/v1/gonum v0.9.1 // indirect
+)
@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"slices"
+)
+
+// outputColumnOrder returns the deterministic column order of the result of the validated config:
+// - With aggregations, the grouping columns of the last aggregation in the config order
+// followed by its passthrough columns and its aggregation results in the config order
+// - Otherwise, the source columns in the fetched order followed by the merged columns
+// and the computed columns in the config order
+func outputColumnOrder(config *entities.Config, sourceColumns []string) []string {
+	if len(config.Aggregations) > 0 {
+		last := config.Aggregations[len(config.Aggregations)-1]
+		order := slices.Concat(last.GroupingColumns, last.PassthroughColumns)
+		for _, aggregation := range last.Aggregations {
+			order = append(order, aggregation.ResultName)
+		}
+
+		return order
+	}
+
+	order := slices.Clone(sourceColumns)
+	for _, merge := range config.MergeColumns {
+		order = append(order, merge.ResultColumnName)
+	}
+	for _, computed := range config.Computed {
+		order = append(order, computed.Name)
+	}
+
+	return order
+}
+
+// reorderColumns returns the DataFrame with the columns in the order. The columns missing in the DataFrame are skipped,
+// and the columns missing in the order follow the ordered columns in their current order, so no column is dropped.
+func reorderColumns(df *dataframe.DataFrame, order []string) (*dataframe.DataFrame, error) {
+	names := df.Names()
+	columns := make([]string, 0, len(names))
+	for _, name := range order {
+		if slices.Contains(names, name) && !slices.Contains(columns, name) {
+			columns = append(columns, name)
+		}
+	}
+	for _, name := range names {
+		if !slices.Contains(columns, name) {
+			columns = append(columns, name)
+		}
+	}
+
+	if slices.Equal(columns, names) {
+		return df, nil
+	}
+
+	reordered := df.Select(columns)
+	if reordered.Err != nil {
+		return nil, domainerrors.NewDataProcessError("reorder", "failed to reorder the columns", reordered.Err)
+	}
+
+	return &reordered, nil
+}
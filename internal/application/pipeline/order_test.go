@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReorderColumns(t *testing.T) {
+	df := dataframe.New(
+		series.New([]string{"a"}, series.String, "c"),
+		series.New([]string{"b"}, series.String, "a"),
+		series.New([]string{"c"}, series.String, "b"),
+	)
+
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{name: "full order", order: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "partial order keeps the rest", order: []string{"b"}, want: []string{"b", "c", "a"}},
+		{name: "missing and duplicated columns are skipped", order: []string{"x", "b", "b", "a"}, want: []string{"b", "a", "c"}},
+		{name: "no order", order: nil, want: []string{"c", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reorderColumns(&df, tt.order)
+			if err != nil {
+				t.Fatalf("reorderColumns() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Names(), tt.want) {
+				t.Errorf("reorderColumns() names = %v, want %v", got.Names(), tt.want)
+			}
+			if got.Nrow() != 1 {
+				t.Errorf("reorderColumns() rows = %d, want 1", got.Nrow())
+			}
+		})
+	}
+}
+
+func TestPipelineOutputColumnOrder(t *testing.T) {
+	const content = "region,product,price,quantity,cost\neast,apple,100,2,60\nwest,banana,200,1,150\neast,cherry,50,4,20\n"
+
+	tests := []struct {
+		name      string
+		configure func(config *entities.Config)
+		want      []string
+	}{
+		{
+			name:      "source order",
+			configure: func(config *entities.Config) {},
+			want:      []string{"region", "product", "price", "quantity", "cost"},
+		},
+		{
+			name: "merged then computed columns after the source columns",
+			configure: func(config *entities.Config) {
+				config.Computed = []entities.ComputedColumn{{Name: "total", Expression: "price * quantity"}}
+				config.MergeColumns = []entities.MergeConfig{
+					{FirstColumn: "region", SecondColumn: "product", Strategy: "concat", Separator: "-", ResultColumnName: "key"},
+					{FirstColumn: "price", SecondColumn: "cost", Strategy: "sum", ResultColumnName: "gross"},
+				}
+			},
+			want: []string{"region", "product", "price", "quantity", "cost", "key", "gross", "total"},
+		},
+		{
+			name: "grouping columns then aggregation results",
+			configure: func(config *entities.Config) {
+				config.Aggregations = []entities.AggregationConfig{{
+					GroupingColumns: []string{"region"},
+					Aggregations: []entities.Aggregation{
+						{Column: "quantity", AggregateMethod: "sum"},
+						{Column: "price", AggregateMethod: "max", ResultName: "top"},
+						{Column: "cost", AggregateMethod: "avg"},
+					},
+				}}
+			},
+			want: []string{"region", "quantity_sum", "top", "cost_avg"},
+		},
+		{
+			name: "grouping columns in the config order",
+			configure: func(config *entities.Config) {
+				config.Aggregations = []entities.AggregationConfig{{
+					GroupingColumns: []string{"product", "region"},
+					Aggregations:    []entities.Aggregation{{Column: "price", AggregateMethod: "sum"}},
+				}}
+			},
+			want: []string{"product", "region", "price_sum"},
+		},
+		{
+			name: "merged and computed columns aggregated",
+			configure: func(config *entities.Config) {
+				config.Computed = []entities.ComputedColumn{{Name: "total", Expression: "price * quantity"}}
+				config.MergeColumns = []entities.MergeConfig{{FirstColumn: "region", SecondColumn: "product", Strategy: "first", ResultColumnName: "label"}}
+				config.Aggregations = []entities.AggregationConfig{{
+					GroupingColumns:    []string{"region"},
+					PassthroughColumns: []string{"label"},
+					Aggregations:       []entities.Aggregation{{Column: "total", AggregateMethod: "sum"}, {Column: "quantity", AggregateMethod: "count"}},
+				}}
+			},
+			want: []string{"region", "label", "total_sum", "quantity_count"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvRunConfig(t, content)
+			tt.configure(config)
+
+			processing, err := NewPipeline(nil).Run(context.Background(), config)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := processing.Data.Names(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() columns = %v, want %v", got, tt.want)
+			}
+
+			content, err := os.ReadFile(config.Destination)
+			if err != nil {
+				t.Fatalf("read %s: %v", config.Destination, err)
+			}
+			if header, _, _ := strings.Cut(string(content), "\n"); header != strings.Join(tt.want, ",") {
+				t.Errorf("result header = %q, want %q", header, strings.Join(tt.want, ","))
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/processor"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/registry"
+	"github.com/go-gota/gota/dataframe"
+	"maps"
+	"time"
+)
+
+// RecoverFunc performs the RecoveryAction of the recoverable error the run continues past.
+// Returning an error aborts the run.
+type RecoverFunc func(ctx context.Context, err *domainerrors.DataProcessError) error
+
+// Options holds the options of the Pipeline.
+// ContinueOnRecoverable represents the policy of the recoverable DataProcessError returned by a step.
+// When true, the error is logged as a warning, recorded in the Warnings of the ProcessingMetadata,
+// passed to Recover if it is set, and the run proceeds skipping the step (the input of the step is kept).
+// When false (default), the run aborts with the error as any other error.
+// ForceGCOnComplete forces a garbage collection before the final memory stats are read (see Processing.CompleteProcessWithGC),
+// so they show the retained memory instead of the garbage not collected yet, at the cost of a stop-the-world pause per run.
+// MemorySampleInterval samples the peak memory of each step at the interval (see Processing.SetMemorySampleInterval),
+// 0 for no sampling (default) to avoid the overhead on the short steps.
+type Options struct {
+	ContinueOnRecoverable bool
+	Recover               RecoverFunc
+	ForceGCOnComplete     bool
+	MemorySampleInterval  time.Duration
+}
+
+// Pipeline runs a Config end to end: it fetches the data from the data source of the Type,
+// applies the stages in the order documented on the Config, and writes the result to the output of the OutputFormat.
+type Pipeline struct {
+	registry  *registry.Registry
+	processor interfaces.Processor
+	logger    interfaces.Logger
+	observer  interfaces.Observer
+	options   Options
+}
+
+// NewPipeline creates a new Pipeline resolving the data sources and the outputs from the default registry
+// and processing with a GotaProcessor. The logger may be nil to discard the logs.
+func NewPipeline(logger interfaces.Logger) *Pipeline {
+	return NewPipelineWithOptions(registry.Default(), processor.NewGotaProcessor(), logger, Options{})
+}
+
+// NewPipelineWithOptions creates a new Pipeline with the registry, the processor, the logger, and the options.
+// The nil registry and processor are replaced with the default ones, and the nil logger discards the logs.
+// If the processor is an interfaces.ObservableProcessor, which observes the stages itself (see GotaProcessorOptions.Observer),
+// the fetch and the output of every run are forwarded to its Observer too.
+func NewPipelineWithOptions(implementations *registry.Registry, dataProcessor interfaces.Processor, logger interfaces.Logger, options Options) *Pipeline {
+	if implementations == nil {
+		implementations = registry.Default()
+	}
+	if dataProcessor == nil {
+		dataProcessor = processor.NewGotaProcessor()
+	}
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	var observer interfaces.Observer = nopObserver{}
+	if observable, ok := dataProcessor.(interfaces.ObservableProcessor); ok {
+		observer = observable.Observer()
+	}
+
+	return &Pipeline{
+		registry:  implementations,
+		processor: dataProcessor,
+		logger:    logger,
+		observer:  observer,
+		options:   options,
+	}
+}
+
+// Run validates the config, fetches the data, applies the stages, and writes the result.
+// Each stage is recorded in the StepPerformance of the metadata, and the data is checked against
+// the row budget (MaxRows) and the column references of the config before any stage.
+// The type warnings of the fetched data are logged and recorded in the Warnings of the metadata.
+// The columns of the result are ordered deterministically regardless of the stages (see outputColumnOrder).
+// The rows rejected by the processor collecting the bad rows are recorded in the RejectedRows of the processing.
+// Returns the processing holding the result data and its metadata, or the error of the failed step.
+func (p *Pipeline) Run(ctx context.Context, config *entities.Config) (*entities.Processing, error) {
+	return p.run(ctx, config, 0)
+}
+
+// RunWithTimeout runs the config like Run but bounds the whole run including the fetch and the output by the timeout.
+// The timeout is recorded in the metadata. When the deadline is hit, returns a DataProcessError tagged with the step
+// in progress, even if the step doesn't watch the context, because every step checks the deadline when it returns.
+func (p *Pipeline) RunWithTimeout(ctx context.Context, config *entities.Config, timeout time.Duration) (*entities.Processing, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	processing, err := p.run(timeoutCtx, config, timeout)
+	if err == nil {
+		return processing, nil
+	}
+
+	// The deadline of the parent context is not the timeout of this run
+	var processErr *domainerrors.DataProcessError
+	if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) && errors.As(err, &processErr) {
+		return nil, domainerrors.NewDataProcessError(processErr.Step, fmt.Sprintf("run timed out after %s", timeout), err)
+	}
+
+	return nil, err
+}
+
+// run runs the config recording the timeout of the context in the metadata.
+func (p *Pipeline) run(ctx context.Context, config *entities.Config, timeout time.Duration) (*entities.Processing, error) {
+	if err := p.registry.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	dataSource, err := p.registry.GetDataSource(config.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := p.registry.GetOutput(config.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := output.Close(); err != nil {
+			p.logger.Error("failed to close the output", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	processing := entities.NewProcessing(nil, config.Name)
+	processing.Metadata.Timeout = timeout
+	processing.SetMemorySampleInterval(p.options.MemorySampleInterval)
+	processing.SetEffectiveConfig(config)
+
+	// The processor collecting the bad rows reports them here
+	ctx = interfaces.WithRejectFunc(ctx, func(step string, row []interface{}, reason string) {
+		p.logger.Warn("row is rejected", map[string]interface{}{"step": step, "reason": reason})
+		processing.AddRejectedRow(row, reason)
+	})
+	// The data source cleaning or renaming the headers reports them here
+	ctx = interfaces.WithHeaderRenameFunc(ctx, func(position int, header, name string) {
+		p.logger.Warn("header is renamed", map[string]interface{}{"position": position, "header": header, "name": name})
+		processing.AddRenamedHeader(position, header, name)
+	})
+	if err := p.fetch(ctx, processing, dataSource, config); err != nil {
+		return nil, err
+	}
+
+	if err := p.process(ctx, processing, config); err != nil {
+		return nil, err
+	}
+	if p.options.ForceGCOnComplete {
+		processing.CompleteProcessWithGC()
+	} else {
+		processing.CompleteProcess()
+	}
+
+	outputConfig := interfaces.OutputConfig{
+		Format:      config.OutputFormat,
+		Destination: config.Destination,
+		Options:     maps.Clone(config.OutputOptions),
+		Metadata:    &processing.Metadata,
+	}
+	if _, ok := output.GetFormatOptions(config.OutputFormat)["nullValue"]; ok && config.NullValue != nil {
+		if outputConfig.Options == nil {
+			outputConfig.Options = make(map[string]interface{}, 1)
+		}
+		outputConfig.Options["nullValue"] = *config.NullValue
+	}
+	// The output is observed but not measured in the metadata, which the output writes itself
+	start := utils.Now()
+	p.observer.OnStepStart("output", processing.GetRowCount())
+	err = interrupted(ctx, "output", output.Write(ctx, processing.Data, outputConfig))
+	if err == nil {
+		// The output may buffer the written data until Close, so the run succeeds only when it is flushed
+		err = output.Close()
+	}
+	p.observer.OnStepEnd("output", processing.GetRowCount(), utils.Now().Sub(start), err)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.Info("pipeline completed", map[string]interface{}{"config": config.Name, "summary": processing.Summary()})
+
+	return processing, nil
+}
+
+// fetch fetches the data of the config and checks it against the row budget and the column references.
+func (p *Pipeline) fetch(ctx context.Context, processing *entities.Processing, dataSource interfaces.DataSource, config *entities.Config) error {
+	sourceConfig := interfaces.DataSourceConfig{
+		Type:          config.Type,
+		Source:        config.Source,
+		ColumnMapping: config.ColumnMapping,
+		Options:       config.SourceOptions,
+	}
+	processing.SetDataSourceInfo(dataSource.GetSourceInfo(sourceConfig))
+
+	// The source over the row budget is rejected before it is fetched when the source can estimate its rows.
+	// The estimate can be larger than the actual rows (e.g. the blank rows of a Sheets grid), which a range narrows.
+	if config.MaxRows > 0 && dataSource.GetCapabilities(sourceConfig).SupportsRowCountEstimation {
+		estimate, err := dataSource.EstimateRowCount(ctx, sourceConfig)
+		if err != nil {
+			// The fetch reports the failure of the source itself, and the fetched rows are still checked
+			p.logger.Warn("failed to estimate the rows of the source", map[string]interface{}{"error": err.Error()})
+		} else if err := processor.CheckEstimatedRowBudget(estimate, config.MaxRows); err != nil {
+			return err
+		}
+	}
+
+	// The stages are observed by the processor, and the fetch is forwarded to its Observer
+	p.observer.OnStepStart("fetch", 0)
+	entry := processing.StartStep("fetch", 0)
+	data, err := dataSource.Fetch(ctx, sourceConfig)
+	if err := interrupted(ctx, "fetch", err); err != nil {
+		processing.EndStep(entry, 0)
+		p.observer.OnStepEnd("fetch", 0, entry.Duration, err)
+		return err
+	}
+	processing.SetSourceData(data)
+	processing.EndStep(entry, data.Nrow())
+	p.observer.OnStepEnd("fetch", data.Nrow(), entry.Duration, nil)
+
+	// The budget protects the process, so exceeding it stops the pipeline even with ContinueOnRecoverable
+	if err := processor.CheckRowBudget(data, config.MaxRows); err != nil {
+		return err
+	}
+
+	return config.ValidateAgainstSchema(data.Names())
+}
+
+// process applies the stages of the config to the fetched data in order. The empty stages are skipped.
+func (p *Pipeline) process(ctx context.Context, processing *entities.Processing, config *entities.Config) error {
+	sourceColumns := processing.GetColumnNames()
+
+	if len(config.Normalize) > 0 {
+		if err := p.runStep(ctx, processing, "normalize", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.Normalize(ctx, data, config.Normalize)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(config.Casts) > 0 {
+		if err := p.runStep(ctx, processing, "cast", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.Cast(ctx, data, config.Casts)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// The casts may resolve the mistyped columns, so the types are inspected after them
+	for _, warning := range p.processor.InspectTypes(processing.Data) {
+		p.logger.Warn("column looks mistyped", map[string]interface{}{"column": warning.Column, "warning": warning.String()})
+		processing.AddWarning(warning.String())
+	}
+
+	if config.Watermark != nil {
+		if err := p.runStep(ctx, processing, "watermark", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			result, watermark, err := p.processor.Watermark(ctx, data, *config.Watermark)
+			if err == nil {
+				processing.SetWatermark(watermark)
+			}
+			return result, err
+		}); err != nil {
+			return err
+		}
+	}
+
+	if config.Dedup != nil {
+		inputRows := processing.GetRowCount()
+		if err := p.runStep(ctx, processing, "dedup", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.Dedup(ctx, data, *config.Dedup)
+		}); err != nil {
+			return err
+		}
+		processing.SetRemovedDuplicateRows(inputRows - processing.GetRowCount())
+	}
+
+	if len(config.FillNull) > 0 {
+		if err := p.runStep(ctx, processing, "fillNull", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.FillNull(ctx, data, config.FillNull)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := p.filter(ctx, processing, "filter", config.Filters); err != nil {
+		return err
+	}
+
+	if len(config.MergeColumns) > 0 {
+		if err := p.runStep(ctx, processing, "merge", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.Merge(ctx, data, config.MergeColumns)
+		}); err != nil {
+			return err
+		}
+		for _, merge := range config.MergeColumns {
+			processing.AddMergeOf(merge.SourceColumns(), merge.Strategy)
+		}
+	}
+
+	if len(config.Computed) > 0 {
+		if err := p.runStep(ctx, processing, "compute", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.Compute(ctx, data, config.Computed)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := p.filter(ctx, processing, "postFilter", config.PostFilters); err != nil {
+		return err
+	}
+
+	if len(config.Aggregations) > 0 {
+		if err := p.runStep(ctx, processing, "aggregate", func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+			return p.processor.Aggregate(ctx, data, config.Aggregations)
+		}); err != nil {
+			return err
+		}
+		for _, aggregation := range config.Aggregations {
+			for _, a := range aggregation.Aggregations {
+				processing.AddAggregation(a.AggregateMethod, a.Column)
+			}
+		}
+	}
+
+	data, err := reorderColumns(processing.Data, outputColumnOrder(config, sourceColumns))
+	if err != nil {
+		return err
+	}
+	processing.Data = data
+
+	return nil
+}
+
+// filter applies the filters as the step of the name and records them and the filtered rows in the metadata.
+func (p *Pipeline) filter(ctx context.Context, processing *entities.Processing, step string, filters []entities.FilterConfig) error {
+	if len(filters) == 0 {
+		return nil
+	}
+
+	emptyResult := false
+	if err := p.runStep(ctx, processing, step, func(data *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+		result, err := p.processor.Filter(ctx, data, filters)
+		emptyResult = errors.Is(err, processor.ErrEmptyResult)
+		return result, err
+	}); err != nil {
+		return err
+	}
+	// The tolerated empty result continues with no rows rather than skipping the filters
+	if emptyResult {
+		empty := processing.Data.Subset([]int{})
+		processing.Data = &empty
+	}
+
+	for _, filter := range filters {
+		processing.AddFilter(processor.DescribeFilter(filter))
+	}
+	processing.UpdateRows(processing.Metadata.SourceTotalRows, processing.GetRowCount())
+
+	return nil
+}
+
+// runStep runs the stage over the current data of the processing as the step of the name, measuring it in the metadata.
+// The result of the stage replaces the data of the processing. If the stage fails with a tolerated recoverable error,
+// the data is kept as it is and the run continues (see Options.ContinueOnRecoverable).
+func (p *Pipeline) runStep(ctx context.Context, processing *entities.Processing, step string, stage func(*dataframe.DataFrame) (*dataframe.DataFrame, error)) error {
+	p.logger.Debug("step started", map[string]interface{}{"step": step, "rows": processing.GetRowCount()})
+
+	entry := processing.StartStep(step, processing.GetRowCount())
+	data, err := stage(processing.Data)
+	if ctx.Err() != nil {
+		processing.EndStep(entry, processing.GetRowCount())
+		return interrupted(ctx, step, err)
+	}
+	if err != nil {
+		processing.EndStep(entry, processing.GetRowCount())
+		return p.tolerate(ctx, processing, step, err)
+	}
+	processing.Data = data
+	processing.EndStep(entry, data.Nrow())
+
+	return nil
+}
+
+// tolerate decides whether the run continues past the error of the step. Returns nil to continue, or the error to abort.
+// Only the recoverable DataProcessError is tolerated, and only when ContinueOnRecoverable is set.
+func (p *Pipeline) tolerate(ctx context.Context, processing *entities.Processing, step string, err error) error {
+	var processErr *domainerrors.DataProcessError
+	if !p.options.ContinueOnRecoverable || !errors.As(err, &processErr) || !processErr.IsRecoverable() {
+		return err
+	}
+
+	p.logger.Warn("continuing past the recoverable error", map[string]interface{}{
+		"step":           step,
+		"error":          err.Error(),
+		"recoveryAction": processErr.GetRecoveryAction(),
+	})
+	processing.AddWarning(err.Error())
+
+	if p.options.Recover != nil {
+		if recoverErr := p.options.Recover(ctx, processErr); recoverErr != nil {
+			return domainerrors.NewDataProcessError(step, fmt.Sprintf("failed to recover from: %s", err.Error()), recoverErr)
+		}
+	}
+
+	return nil
+}
+
+// interrupted returns the error of the step if the context is done when the step returns, tagging it with the step.
+// err is the error the step returned, if any; nil is returned if the context is not done and err is nil.
+func interrupted(ctx context.Context, step string, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil {
+		return err
+	}
+
+	if err == nil || !errors.Is(err, ctxErr) {
+		err = errors.Join(err, ctxErr)
+	}
+
+	return domainerrors.NewDataProcessError(step, fmt.Sprintf("%s is interrupted", step), err)
+}
+
+// nopLogger discards all logs
+type nopLogger struct{}
+
+// Ensure nopLogger implements the Logger interface
+var _ interfaces.Logger = nopLogger{}
+
+func (nopLogger) Debug(string, map[string]interface{}) {}
+func (nopLogger) Info(string, map[string]interface{})  {}
+func (nopLogger) Warn(string, map[string]interface{})  {}
+func (nopLogger) Error(string, map[string]interface{}) {}
+
+// nopObserver observes nothing
+type nopObserver struct{}
+
+// Ensure nopObserver implements the Observer interface
+var _ interfaces.Observer = nopObserver{}
+
+func (nopObserver) OnStepStart(string, int)                     {}
+func (nopObserver) OnStepEnd(string, int, time.Duration, error) {}
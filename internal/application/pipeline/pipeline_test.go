@@ -0,0 +1,688 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/datasource"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/processor"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/registry"
+	"github.com/go-gota/gota/dataframe"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// csvRunConfig returns the config reading the CSV content from a temporary file and writing the CSV result next to it.
+func csvRunConfig(t *testing.T, content string) *entities.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.csv")
+	if err := os.WriteFile(source, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", source, err)
+	}
+
+	return &entities.Config{
+		Name:         "pipeline",
+		Type:         "csv",
+		Source:       source,
+		OutputFormat: "csv",
+		Destination:  filepath.Join(dir, "result.csv"),
+	}
+}
+
+func TestPipelinePostFiltersOnComputedColumn(t *testing.T) {
+	config := csvRunConfig(t, "product,revenue,cost\napple,100,60\nbanana,200,120\ncherry,50,20\ndurian,80,80\n")
+	config.Filters = []entities.FilterConfig{{Column: "revenue", Operator: "gte", Value: "80", LogicalOperator: "and"}}
+	config.Computed = []entities.ComputedColumn{{Name: "margin", Expression: "(revenue - cost) / revenue"}}
+	config.PostFilters = []entities.FilterConfig{{Column: "margin", Operator: "gte", Value: "0.4", LogicalOperator: "and"}}
+
+	processing, err := NewPipeline(nil).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// cherry is removed by the filter before the compute, and durian (margin 0) by the post filter after it
+	want := [][]string{
+		{"product", "revenue", "cost", "margin"},
+		{"apple", "100", "60", "0.400000"},
+		{"banana", "200", "120", "0.400000"},
+	}
+	if got := processing.Data.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() records = %v, want %v", got, want)
+	}
+	if got, want := processing.Metadata.AppliedFilters, []string{"revenue gte 80", "margin gte 0.4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppliedFilters = %v, want %v", got, want)
+	}
+	if got := processing.Metadata.FilteredTotalRows; got != 2 {
+		t.Errorf("FilteredTotalRows = %d, want 2", got)
+	}
+}
+
+func TestPipelineFilterOnComputedColumnBeforeCompute(t *testing.T) {
+	config := csvRunConfig(t, "product,revenue,cost\napple,100,60\n")
+	config.Filters = []entities.FilterConfig{{Column: "margin", Operator: "gte", Value: "0.4", LogicalOperator: "and"}}
+	config.Computed = []entities.ComputedColumn{{Name: "margin", Expression: "(revenue - cost) / revenue"}}
+
+	// The filters run before the compute stage, so the computed column is reported missing up front
+	_, err := NewPipeline(nil).Run(context.Background(), config)
+	if !domainerrors.IsConfigurationError(err) || !strings.Contains(err.Error(), "'margin' (filters[0].column)") {
+		t.Errorf("Run() error = %v, want a ConfigurationError of the filter column missing before the compute stage", err)
+	}
+}
+
+func TestPipelineClosesOutputBeforeReturning(t *testing.T) {
+	config := csvRunConfig(t, "product,revenue\napple,100\nbanana,200\n")
+
+	if _, err := NewPipeline(nil).Run(context.Background(), config); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The CSV output buffers the rows until Close, so the file is complete only if Run closed the output
+	content, err := os.ReadFile(config.Destination)
+	if err != nil {
+		t.Fatalf("read %s: %v", config.Destination, err)
+	}
+	if got, want := string(content), "product,revenue\napple,100\nbanana,200\n"; got != want {
+		t.Errorf("result = %q, want %q", got, want)
+	}
+}
+
+// recordingLogger records the messages of the warnings
+type recordingLogger struct {
+	nopLogger
+	warnings []string
+}
+
+func (l *recordingLogger) Warn(msg string, _ map[string]interface{}) {
+	l.warnings = append(l.warnings, msg)
+}
+
+func TestPipelineRecoverablePolicies(t *testing.T) {
+	const content = "product,amount\napple,100\nbanana,abc\n"
+	recoverErr := errors.New("cannot recover")
+
+	tests := []struct {
+		name       string
+		options    Options
+		wantErr    bool
+		wantStep   string
+		wantRecord [][]string
+	}{
+		{
+			name:     "abort by default",
+			options:  Options{},
+			wantErr:  true,
+			wantStep: "cast",
+		},
+		{
+			name:       "continue",
+			options:    Options{ContinueOnRecoverable: true},
+			wantRecord: [][]string{{"product", "amount"}, {"apple", "100"}, {"banana", "abc"}},
+		},
+		{
+			name: "continue and recover",
+			options: Options{ContinueOnRecoverable: true, Recover: func(context.Context, *domainerrors.DataProcessError) error {
+				return nil
+			}},
+			wantRecord: [][]string{{"product", "amount"}, {"apple", "100"}, {"banana", "abc"}},
+		},
+		{
+			name: "continue but fail to recover",
+			options: Options{ContinueOnRecoverable: true, Recover: func(context.Context, *domainerrors.DataProcessError) error {
+				return recoverErr
+			}},
+			wantErr:  true,
+			wantStep: "cast",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvRunConfig(t, content)
+			config.Casts = []entities.CastConfig{{Column: "amount", To: "int"}}
+
+			var recovered []*domainerrors.DataProcessError
+			options := tt.options
+			if next := options.Recover; next != nil {
+				options.Recover = func(ctx context.Context, err *domainerrors.DataProcessError) error {
+					recovered = append(recovered, err)
+					return next(ctx, err)
+				}
+			}
+			logger := &recordingLogger{}
+
+			processing, err := NewPipelineWithOptions(nil, nil, logger, options).Run(context.Background(), config)
+			if tt.wantErr {
+				var processErr *domainerrors.DataProcessError
+				if !errors.As(err, &processErr) || processErr.Step != tt.wantStep {
+					t.Fatalf("Run() error = %v, want a DataProcessError of step %s", err, tt.wantStep)
+				}
+				if tt.options.Recover == nil && !processErr.IsRecoverable() {
+					t.Errorf("Run() error = %v, want the recoverable cast error", err)
+				}
+				if tt.options.Recover != nil && !errors.Is(err, recoverErr) {
+					t.Errorf("Run() error = %v, want the error of Recover", err)
+				}
+				if _, statErr := os.Stat(config.Destination); !os.IsNotExist(statErr) {
+					t.Errorf("output exists after the aborted run: %v", statErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			// The failed cast keeps the column as it is
+			if got := processing.Data.Records(); !reflect.DeepEqual(got, tt.wantRecord) {
+				t.Errorf("Run() records = %v, want %v", got, tt.wantRecord)
+			}
+			if !slices.ContainsFunc(processing.Metadata.Warnings, func(w string) bool { return strings.Contains(w, "cannot cast 'abc'") }) {
+				t.Errorf("Warnings = %q, want the cast error", processing.Metadata.Warnings)
+			}
+			if !slices.Contains(logger.warnings, "continuing past the recoverable error") {
+				t.Errorf("logged warnings = %q, want the continued error", logger.warnings)
+			}
+			if tt.options.Recover != nil && (len(recovered) != 1 || recovered[0].Step != "cast" || recovered[0].GetRecoveryAction() == "") {
+				t.Errorf("Recover() called with %v, want the cast error with the recovery action once", recovered)
+			}
+		})
+	}
+}
+
+// slowDataSource fetches the CSV after the delay, ignoring the context like a blocking client
+type slowDataSource struct {
+	*datasource.CSVDataSource
+	delay time.Duration
+}
+
+func (s slowDataSource) Fetch(ctx context.Context, config interfaces.DataSourceConfig) (*dataframe.DataFrame, error) {
+	time.Sleep(s.delay)
+	return s.CSVDataSource.Fetch(ctx, config)
+}
+
+// slowProcessor casts after the delay, ignoring the context like a blocking step
+type slowProcessor struct {
+	*processor.GotaProcessor
+	delay time.Duration
+}
+
+func (s slowProcessor) Cast(ctx context.Context, data *dataframe.DataFrame, config []entities.CastConfig) (*dataframe.DataFrame, error) {
+	time.Sleep(s.delay)
+	return s.GotaProcessor.Cast(ctx, data, config)
+}
+
+func TestPipelineRunWithTimeout(t *testing.T) {
+	const (
+		content = "product,amount\napple,100\nbanana,200\n"
+		timeout = 50 * time.Millisecond
+		delay   = 4 * timeout
+	)
+
+	slowFetch := registry.NewDefaultRegistry()
+	slowFetch.RegisterDataSource("csv", func() interfaces.DataSource {
+		return slowDataSource{CSVDataSource: datasource.NewCSVDataSource(), delay: delay}
+	})
+
+	tests := []struct {
+		name     string
+		pipeline *Pipeline
+		wantStep string
+	}{
+		{name: "completes", pipeline: NewPipeline(nil)},
+		{name: "slow fetch", pipeline: NewPipelineWithOptions(slowFetch, nil, nil, Options{}), wantStep: "fetch"},
+		{
+			name:     "slow cast",
+			pipeline: NewPipelineWithOptions(nil, slowProcessor{GotaProcessor: processor.NewGotaProcessor(), delay: delay}, nil, Options{}),
+			wantStep: "cast",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvRunConfig(t, content)
+			config.Casts = []entities.CastConfig{{Column: "amount", To: "float"}}
+
+			processing, err := tt.pipeline.RunWithTimeout(context.Background(), config, timeout)
+			if tt.wantStep == "" {
+				if err != nil {
+					t.Fatalf("RunWithTimeout() error = %v", err)
+				}
+				if processing.Metadata.Timeout != timeout {
+					t.Errorf("Timeout = %v, want %v", processing.Metadata.Timeout, timeout)
+				}
+				return
+			}
+
+			var processErr *domainerrors.DataProcessError
+			if !errors.As(err, &processErr) || processErr.Step != tt.wantStep {
+				t.Fatalf("RunWithTimeout() error = %v, want a DataProcessError of step %s", err, tt.wantStep)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "run timed out after "+timeout.String()) {
+				t.Errorf("RunWithTimeout() error = %v, want the timeout of %s", err, timeout)
+			}
+			if _, statErr := os.Stat(config.Destination); !os.IsNotExist(statErr) {
+				t.Errorf("output exists after the timed out run: %v", statErr)
+			}
+		})
+	}
+
+	// The canceled parent context is reported as it is rather than as the timeout of the run
+	t.Run("parent canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewPipeline(nil).RunWithTimeout(ctx, csvRunConfig(t, content), timeout)
+		if !errors.Is(err, context.Canceled) || strings.Contains(fmt.Sprint(err), "timed out") {
+			t.Errorf("RunWithTimeout() error = %v, want the cancellation of the parent context", err)
+		}
+	})
+}
+
+// countingDataSource counts the fetches of the CSV, optionally hiding its row count estimation
+type countingDataSource struct {
+	*datasource.CSVDataSource
+	fetches    *int
+	noEstimate bool
+}
+
+func (c countingDataSource) Fetch(ctx context.Context, config interfaces.DataSourceConfig) (*dataframe.DataFrame, error) {
+	*c.fetches++
+	return c.CSVDataSource.Fetch(ctx, config)
+}
+
+func (c countingDataSource) GetCapabilities(config interfaces.DataSourceConfig) interfaces.Capabilities {
+	capabilities := c.CSVDataSource.GetCapabilities(config)
+	capabilities.SupportsRowCountEstimation = !c.noEstimate
+	return capabilities
+}
+
+func TestPipelineRowBudget(t *testing.T) {
+	const content = "product,amount\napple,100\nbanana,200\ncherry,300\n"
+
+	tests := []struct {
+		name        string
+		noEstimate  bool
+		maxRows     int
+		wantErr     bool
+		wantFetches int
+	}{
+		{name: "estimate over budget", maxRows: 2, wantErr: true, wantFetches: 0},
+		{name: "fetched rows over budget", noEstimate: true, maxRows: 2, wantErr: true, wantFetches: 1},
+		{name: "within budget", maxRows: 3, wantFetches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			counting := registry.NewDefaultRegistry()
+			counting.RegisterDataSource("csv", func() interfaces.DataSource {
+				return countingDataSource{CSVDataSource: datasource.NewCSVDataSource(), fetches: &fetches, noEstimate: tt.noEstimate}
+			})
+			config := csvRunConfig(t, content)
+			config.MaxRows = tt.maxRows
+
+			// The budget is never continued past, even with ContinueOnRecoverable
+			_, err := NewPipelineWithOptions(counting, nil, nil, Options{ContinueOnRecoverable: true}).Run(context.Background(), config)
+			if tt.wantErr {
+				var processErr *domainerrors.DataProcessError
+				if !errors.As(err, &processErr) || processErr.Step != "fetch" || !strings.Contains(err.Error(), "exceeding maxRows 2") {
+					t.Fatalf("Run() error = %v, want the row budget error of the fetch", err)
+				}
+				if _, statErr := os.Stat(config.Destination); !os.IsNotExist(statErr) {
+					t.Errorf("output exists after the run over the budget: %v", statErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if fetches != tt.wantFetches {
+				t.Errorf("fetches = %d, want %d", fetches, tt.wantFetches)
+			}
+		})
+	}
+}
+
+func TestPipelineCollectsRejectedRows(t *testing.T) {
+	config := csvRunConfig(t, "product,amount\napple,100\nbanana,abc\ncherry,50\n")
+	config.Casts = []entities.CastConfig{{Column: "amount", To: "float"}}
+
+	dataProcessor := processor.NewGotaProcessorWithOptions(processor.GotaProcessorOptions{CollectBadRows: true})
+	processing, err := NewPipelineWithOptions(nil, dataProcessor, nil, Options{}).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := [][]string{{"product", "amount"}, {"apple", "100.000000"}, {"cherry", "50.000000"}}
+	if got := processing.Data.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() records = %v, want %v", got, want)
+	}
+	wantRejected := [][]interface{}{{"banana", "abc", "cannot cast 'abc' of column 'amount' at row 2 to float"}}
+	if !reflect.DeepEqual(processing.RejectedRows, wantRejected) {
+		t.Errorf("RejectedRows = %v, want %v", processing.RejectedRows, wantRejected)
+	}
+	if got := processing.Metadata.RejectedRowCount; got != 1 {
+		t.Errorf("RejectedRowCount = %d, want 1", got)
+	}
+}
+
+func TestPipelineWatermarkIncrementalRuns(t *testing.T) {
+	config := csvRunConfig(t, "id,updated\n1,2024-01-05\n2,2024-01-06\n")
+	config.Watermark = &entities.WatermarkConfig{Column: "updated"}
+
+	first, err := NewPipeline(nil).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	if got := first.Metadata.Watermark; got != "2024-01-06" {
+		t.Fatalf("first Watermark = %q, want 2024-01-06", got)
+	}
+
+	// The next run continues from the reported watermark, processing only the rows added since
+	if err := os.WriteFile(config.Source, []byte("id,updated\n1,2024-01-05\n2,2024-01-06\n3,2024-01-07\n4,2024-01-09\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", config.Source, err)
+	}
+	config.Watermark = &entities.WatermarkConfig{Column: "updated", Since: first.Metadata.Watermark}
+
+	second, err := NewPipeline(nil).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	want := [][]string{{"id", "updated"}, {"3", "2024-01-07"}, {"4", "2024-01-09"}}
+	if got := second.Data.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("second Run() records = %v, want %v", got, want)
+	}
+	if got := second.Metadata.Watermark; got != "2024-01-09" {
+		t.Errorf("second Watermark = %q, want 2024-01-09", got)
+	}
+}
+
+func TestPipelineEmptyResult(t *testing.T) {
+	const content = "product,amount\napple,100\nbanana,200\n"
+	disallow := false
+
+	tests := []struct {
+		name     string
+		pipeline *Pipeline
+		wantErr  bool
+	}{
+		{name: "allowed", pipeline: NewPipeline(nil)},
+		{
+			name:     "disallowed",
+			pipeline: NewPipelineWithOptions(nil, processor.NewGotaProcessorWithOptions(processor.GotaProcessorOptions{AllowEmptyResult: &disallow}), nil, Options{}),
+			wantErr:  true,
+		},
+		{
+			name:     "disallowed but continued",
+			pipeline: NewPipelineWithOptions(nil, processor.NewGotaProcessorWithOptions(processor.GotaProcessorOptions{AllowEmptyResult: &disallow}), nil, Options{ContinueOnRecoverable: true}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvRunConfig(t, content)
+			config.Filters = []entities.FilterConfig{{Column: "amount", Operator: "gt", Value: "1000", LogicalOperator: "and"}}
+
+			processing, err := tt.pipeline.Run(context.Background(), config)
+			if tt.wantErr {
+				if !errors.Is(err, processor.ErrEmptyResult) {
+					t.Errorf("Run() error = %v, want ErrEmptyResult", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			// The empty result is written with the header only
+			if got := processing.Metadata.FilteredTotalRows; got != 0 {
+				t.Errorf("FilteredTotalRows = %d, want 0", got)
+			}
+			content, err := os.ReadFile(config.Destination)
+			if err != nil {
+				t.Fatalf("read %s: %v", config.Destination, err)
+			}
+			if got, want := string(content), "product,amount\n"; got != want {
+				t.Errorf("result = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPipelineNormalizeBeforeFilters(t *testing.T) {
+	const content = "product,region\napple,east \nbanana, East\ncherry,west\n"
+	filters := []entities.FilterConfig{{Column: "region", Operator: "eq", Value: "east", LogicalOperator: "and"}}
+
+	tests := []struct {
+		name      string
+		normalize []entities.NormalizeConfig
+		want      [][]string
+	}{
+		{name: "as fetched", normalize: nil, want: [][]string{{"product", "region"}}},
+		{
+			name:      "trimmed",
+			normalize: []entities.NormalizeConfig{{Column: "region"}},
+			want:      [][]string{{"product", "region"}, {"apple", "east"}},
+		},
+		{
+			name:      "trimmed and lowercased",
+			normalize: []entities.NormalizeConfig{{Column: "region", Lowercase: true}},
+			want:      [][]string{{"product", "region"}, {"apple", "east"}, {"banana", "east"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvRunConfig(t, content)
+			config.Normalize = tt.normalize
+			config.Filters = filters
+
+			processing, err := NewPipeline(nil).Run(context.Background(), config)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := processing.Data.Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() records = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineRecordsEffectiveConfig(t *testing.T) {
+	config := csvRunConfig(t, "first,last\nAlice,Smith\nBob,Jones\n")
+	config.MergeColumns = []entities.MergeConfig{{FirstColumn: "first", SecondColumn: "last", Separator: " "}}
+
+	processing, err := NewPipeline(nil).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	effective := processing.Metadata.EffectiveConfig
+	if effective == nil {
+		t.Fatal("EffectiveConfig = nil, want the validated config")
+	}
+	got := effective.MergeColumns[0]
+	if got.Strategy != "concat" || got.ResultColumnName != "first_last" {
+		t.Errorf("EffectiveConfig merge = %+v, want the concat strategy and the result name first_last", got)
+	}
+	if !slices.Contains(processing.Data.Names(), got.ResultColumnName) {
+		t.Errorf("Run() columns = %v, want the result column %s of the effective config", processing.Data.Names(), got.ResultColumnName)
+	}
+}
+
+func TestPipelineNullValue(t *testing.T) {
+	config := csvRunConfig(t, "product,amount\napple,100\nbanana,\n")
+	nullValue := "NULL"
+	config.NullValue = &nullValue
+
+	if _, err := NewPipeline(nil).Run(context.Background(), config); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	content, err := os.ReadFile(config.Destination)
+	if err != nil {
+		t.Fatalf("read %s: %v", config.Destination, err)
+	}
+	if got, want := string(content), "product,amount\napple,100\nbanana,NULL\n"; got != want {
+		t.Errorf("Run() wrote %q, want %q", got, want)
+	}
+
+	// The JSON output ignores the NullValue of the config and writes the real null
+	config.OutputFormat = "json"
+	config.Destination = filepath.Join(filepath.Dir(config.Destination), "result.json")
+	if _, err := NewPipeline(nil).Run(context.Background(), config); err != nil {
+		t.Fatalf("Run(json) error = %v", err)
+	}
+	content, err = os.ReadFile(config.Destination)
+	if err != nil {
+		t.Fatalf("read %s: %v", config.Destination, err)
+	}
+	if !strings.Contains(string(content), `"amount": null`) {
+		t.Errorf("Run(json) wrote %s, want the amount of banana as null", content)
+	}
+}
+
+func TestPipelineRecordsRenamedHeaders(t *testing.T) {
+	config := csvRunConfig(t, "name,,name\nAlice,1,Smith\n")
+	config.Filters = []entities.FilterConfig{{Column: "name_2", Operator: "eq", Value: "Smith", LogicalOperator: "and"}}
+
+	processing, err := NewPipeline(nil).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []entities.HeaderRename{{Position: 2, Header: "", Name: "col_2"}, {Position: 3, Header: "name", Name: "name_2"}}
+	if got := processing.Metadata.RenamedHeaders; !reflect.DeepEqual(got, want) {
+		t.Errorf("RenamedHeaders = %+v, want %+v", got, want)
+	}
+	if got := processing.Data.Nrow(); got != 1 {
+		t.Errorf("Run() rows filtered by the renamed header = %d, want 1", got)
+	}
+}
+
+func TestPipelineForceGCOnComplete(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		config := csvRunConfig(t, "product,amount\napple,100\n")
+		processing, err := NewPipelineWithOptions(nil, nil, nil, Options{ForceGCOnComplete: force}).Run(context.Background(), config)
+		if err != nil {
+			t.Fatalf("Run(ForceGCOnComplete: %v) error = %v", force, err)
+		}
+
+		// The allocation before the collection is recorded only when the collection is forced
+		if got := processing.Metadata.MemoryStats.PreGCAllocBytes; (got > 0) != force {
+			t.Errorf("Run(ForceGCOnComplete: %v) PreGCAllocBytes = %d", force, got)
+		}
+	}
+}
+
+func TestPipelineFiltersOnCleanedHeader(t *testing.T) {
+	config := csvRunConfig(t, "\ufeffid,amount\n1,10\n2,20\n")
+	config.Filters = []entities.FilterConfig{{Column: "id", Operator: "eq", Value: "2", LogicalOperator: "and"}}
+
+	processing, err := NewPipeline(nil).Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []entities.HeaderRename{{Position: 1, Header: "\ufeffid", Name: "id"}}
+	if got := processing.Metadata.RenamedHeaders; !reflect.DeepEqual(got, want) {
+		t.Errorf("RenamedHeaders = %+v, want %+v", got, want)
+	}
+	if got := processing.Data.Nrow(); got != 1 {
+		t.Errorf("Run() rows filtered by the cleaned header = %d, want 1", got)
+	}
+}
+
+// recordingObserver records the callbacks as "start <name> <inputRows>" and "end <name> <outputRows> <err>",
+// and the durations of the steps
+type recordingObserver struct {
+	events    []string
+	durations []time.Duration
+}
+
+func (o *recordingObserver) OnStepStart(name string, inputRows int) {
+	o.events = append(o.events, fmt.Sprintf("start %s %d", name, inputRows))
+}
+
+func (o *recordingObserver) OnStepEnd(name string, outputRows int, d time.Duration, err error) {
+	o.events = append(o.events, fmt.Sprintf("end %s %d %v", name, outputRows, err))
+	o.durations = append(o.durations, d)
+}
+
+func TestPipelineObserver(t *testing.T) {
+	config := csvRunConfig(t, "region,amount\neast,10\nwest,5\neast,3\nwest,0\n")
+	config.Filters = []entities.FilterConfig{{Column: "amount", Operator: "gt", Value: "0", LogicalOperator: "and"}}
+	config.Aggregations = []entities.AggregationConfig{{
+		GroupingColumns: []string{"region"},
+		Aggregations:    []entities.Aggregation{{Column: "amount", AggregateMethod: "sum", ResultName: "total"}},
+	}}
+
+	observer := &recordingObserver{}
+	dataProcessor := processor.NewGotaProcessorWithOptions(processor.GotaProcessorOptions{Observer: observer})
+	if _, err := NewPipelineWithOptions(nil, dataProcessor, nil, Options{}).Run(context.Background(), config); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The fetch and the output are forwarded to the Observer of the processor around its stages
+	want := []string{
+		"start fetch 0",
+		"end fetch 4 <nil>",
+		"start filter 4",
+		"end filter 3 <nil>",
+		"start aggregate 3",
+		"end aggregate 2 <nil>",
+		"start output 2",
+		"end output 2 <nil>",
+	}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("events = %q, want %q", observer.events, want)
+	}
+	for i, d := range observer.durations {
+		if d < 0 {
+			t.Errorf("duration of step %d = %v, want non-negative", i+1, d)
+		}
+	}
+}
+
+func TestPipelineObserverFailedFetch(t *testing.T) {
+	config := csvRunConfig(t, "region,amount\neast,10\n")
+	config.Source = filepath.Join(t.TempDir(), "missing.csv")
+
+	observer := &recordingObserver{}
+	dataProcessor := processor.NewGotaProcessorWithOptions(processor.GotaProcessorOptions{Observer: observer})
+	_, err := NewPipelineWithOptions(nil, dataProcessor, nil, Options{}).Run(context.Background(), config)
+	if err == nil {
+		t.Fatalf("Run() error = nil, want the missing source")
+	}
+
+	if len(observer.events) != 2 || observer.events[0] != "start fetch 0" || !strings.HasPrefix(observer.events[1], "end fetch 0 ") || strings.HasSuffix(observer.events[1], "<nil>") {
+		t.Errorf("events = %q, want the fetch ending with the error", observer.events)
+	}
+}
+
+func TestPipelineMemorySampleInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, time.Millisecond} {
+		config := csvRunConfig(t, "product,amount\napple,100\nbanana,200\n")
+		config.Filters = []entities.FilterConfig{{Column: "amount", Operator: "gt", Value: "100", LogicalOperator: "and"}}
+		processing, err := NewPipelineWithOptions(nil, nil, nil, Options{MemorySampleInterval: interval}).Run(context.Background(), config)
+		if err != nil {
+			t.Fatalf("Run(MemorySampleInterval: %v) error = %v", interval, err)
+		}
+
+		if len(processing.Metadata.StepPerformance) == 0 {
+			t.Fatalf("Run(MemorySampleInterval: %v) recorded no steps", interval)
+		}
+		// The peak of a sampled step is at least its end-of-step allocation
+		for _, step := range processing.Metadata.StepPerformance {
+			if sampled := step.PeakMemoryBytes > 0; sampled != (interval > 0) || (sampled && step.PeakMemoryBytes < step.MemoryAfterBytes) {
+				t.Errorf("Run(MemorySampleInterval: %v) %s PeakMemoryBytes = %d, MemoryAfterBytes = %d", interval, step.StepName, step.PeakMemoryBytes, step.MemoryAfterBytes)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Canonicalize converts the Config into the canonical form to stabilize diffs and hashing.
+// It applies the default values via Validate and sorts the order-insensitive slices:
+// - Filters and PostFilters are sorted by column, operator, and value only when all filters are combined with "and",
+// because the order matters for the chain including "or"
+// - Aggregations are sorted by their grouping columns
+func (c *Config) Canonicalize() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	c.sortCanonical()
+
+	return nil
+}
+
+// Hash returns a stable SHA-256 hex digest over the canonical JSON of the Config without modifying it.
+// Descriptive fields (Name, Description, and Creator) are excluded because they don't affect the processing.
+// If the Config is invalid, the digest is computed over the content canonicalized as far as possible.
+func (c *Config) Hash() string {
+	canonical := c.clone()
+
+	// The validation error is ignored to hash the invalid configs as well
+	_ = canonical.Validate()
+	canonical.sortCanonical()
+
+	canonical.Name = ""
+	canonical.Description = ""
+	canonical.Creator = ""
+
+	canonicalData, err := json.Marshal(canonical)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal Config: %v", err))
+	}
+
+	sum := sha256.Sum256(canonicalData)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// clone returns a deep copy of the Config via JSON.
+func (c *Config) clone() *Config {
+	data, err := json.Marshal(c)
+	if err != nil {
+		// Config consists of JSON-compatible types only, so this never happens
+		panic(fmt.Sprintf("failed to marshal Config: %v", err))
+	}
+
+	cloned := &Config{}
+	if err := json.Unmarshal(data, cloned); err != nil {
+		panic(fmt.Sprintf("failed to unmarshal Config: %v", err))
+	}
+
+	return cloned
+}
+
+// sortCanonical sorts the order-insensitive slices of the Config in place.
+func (c *Config) sortCanonical() {
+	sortCanonicalFilters(c.Filters)
+	sortCanonicalFilters(c.PostFilters)
+
+	slices.SortStableFunc(c.Aggregations, func(a, b AggregationConfig) int {
+		return strings.Compare(strings.Join(a.GroupingColumns, "\x00"), strings.Join(b.GroupingColumns, "\x00"))
+	})
+}
+
+// sortCanonicalFilters sorts the filters in place if all of them are combined with "and".
+func sortCanonicalFilters(filters []FilterConfig) {
+	allAnd := !slices.ContainsFunc(filters, func(f FilterConfig) bool {
+		return f.LogicalOperator != "and"
+	})
+	if !allAnd {
+		return
+	}
+
+	slices.SortStableFunc(filters, func(a, b FilterConfig) int {
+		if cmp := strings.Compare(a.Column, b.Column); cmp != 0 {
+			return cmp
+		}
+		if cmp := strings.Compare(a.Operator, b.Operator); cmp != 0 {
+			return cmp
+		}
+
+		if cmp := strings.Compare(a.Value, b.Value); cmp != 0 {
+			return cmp
+		}
+		if cmp := strings.Compare(a.ValueType, b.ValueType); cmp != 0 {
+			return cmp
+		}
+		if cmp := strings.Compare(a.ValuesFile, b.ValuesFile); cmp != 0 {
+			return cmp
+		}
+
+		if cmp := slices.Compare(a.Values, b.Values); cmp != 0 {
+			return cmp
+		}
+
+		return strings.Compare(a.Delimiter, b.Delimiter)
+	})
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"testing"
+)
+
+// salesConfig returns a valid config of the CSV sales data filtering and aggregating the amounts by region.
+func salesConfig() *Config {
+	return &Config{
+		Name:   "sales",
+		Type:   "csv",
+		Source: "sales.csv",
+		Filters: []FilterConfig{
+			{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: "and"},
+			{Column: "amount", Operator: "gt", Value: "0", LogicalOperator: "and"},
+		},
+		Aggregations: []AggregationConfig{{
+			GroupingColumns: []string{"region"},
+			Aggregations:    []Aggregation{{Column: "amount", AggregateMethod: "sum", ResultName: "total"}},
+		}},
+		OutputFormat: "csv",
+	}
+}
+
+func TestHashEquivalentConfigs(t *testing.T) {
+	base := salesConfig()
+
+	equivalent := salesConfig()
+	equivalent.Name = "renamed"
+	equivalent.Description = "same processing"
+	equivalent.OutputFormat = ""
+	equivalent.Filters[0], equivalent.Filters[1] = equivalent.Filters[1], equivalent.Filters[0]
+
+	if base.Hash() != equivalent.Hash() {
+		t.Errorf("Hash() differs for the equivalent configs:\n%+v\n%+v", base, equivalent)
+	}
+}
+
+func TestHashMeaningfulChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+	}{
+		{name: "filter value", change: func(c *Config) { c.Filters[0].Value = "open" }},
+		{name: "aggregate method", change: func(c *Config) { c.Aggregations[0].Aggregations[0].AggregateMethod = "avg" }},
+		{name: "output format", change: func(c *Config) { c.OutputFormat = "json" }},
+		{name: "source", change: func(c *Config) { c.Source = "other.csv" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := salesConfig()
+			tt.change(changed)
+			if salesConfig().Hash() == changed.Hash() {
+				t.Error("Hash() is unchanged by the change")
+			}
+		})
+	}
+}
+
+func TestHashOrderedOrFilters(t *testing.T) {
+	// The order of the filters combined with "or" matters, so it is kept
+	base := salesConfig()
+	base.Filters = []FilterConfig{
+		{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: "and"},
+		{Column: "amount", Operator: "gt", Value: "0", LogicalOperator: "or"},
+		{Column: "region", Operator: "eq", Value: "east", LogicalOperator: "and"},
+	}
+	reordered := salesConfig()
+	reordered.Filters = []FilterConfig{base.Filters[2], base.Filters[1], base.Filters[0]}
+
+	if base.Hash() == reordered.Hash() {
+		t.Error("Hash() is the same for the reordered filters combined with or")
+	}
+}
+
+func TestHashDoesNotModifyConfig(t *testing.T) {
+	config := salesConfig()
+	config.OutputFormat = ""
+	config.Hash()
+
+	if config.OutputFormat != "" || config.Filters[0].Column != "status" {
+		t.Errorf("Hash() modified the config: %+v", config)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	config := salesConfig()
+	config.OutputFormat = ""
+	if err := config.Canonicalize(); err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+
+	if config.OutputFormat != "csv" {
+		t.Errorf("OutputFormat = %q, want the default csv", config.OutputFormat)
+	}
+	if config.Filters[0].Column != "amount" || config.Filters[1].Column != "status" {
+		t.Errorf("Filters = %+v, want them sorted by column", config.Filters)
+	}
+}
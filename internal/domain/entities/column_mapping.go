@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ValidateColumnMapping checks the mapping from the source headers to the canonical column names
+// has no empty names and no two headers renamed to the same column.
+func ValidateColumnMapping(mapping map[string]string) error {
+	// The headers are checked in order to report the same error for the same mapping
+	headers := slices.Sorted(maps.Keys(mapping))
+	renamedFrom := make(map[string]string, len(mapping))
+	for _, header := range headers {
+		target := mapping[header]
+		if header == "" {
+			return newValidationError(MessageCannotBeEmpty, "source header")
+		}
+		if target == "" {
+			return newValidationError(MessageCannotBeEmpty, fmt.Sprintf("target of '%s'", header))
+		}
+		if other, exists := renamedFrom[target]; exists {
+			return newValidationError(MessageDuplicateTarget, other, header, target)
+		}
+		renamedFrom[target] = header
+	}
+
+	return nil
+}
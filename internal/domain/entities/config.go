@@ -2,93 +2,320 @@ package entities
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
 	"github.com/SHIMA0111/kanjo/internal/domain/utils"
 	"slices"
+	"strings"
 )
 
 // Config represents the configuration for a calculation
 // Type represents the DataSource type; csv, googlesheets, etc.
 // Source represents the identifier for the data source, such as the sheet ID for a Google Sheets source, filepath for csv.
+// Destination represents the output destination, such as the filepath for the csv output.
+// MaxRows represents the row budget of the fetched data to protect the process from a huge source (0 for unlimited).
+// The source estimating its rows is rejected before the fetch, and exceeding the budget always stops the run.
+// ColumnMapping renames the fetched headers (keys) to the canonical column names (values) right after fetch,
+// so every other field refers to the canonical names (e.g. {"売上 金額": "sales_amount"}).
+// SourceOptions represents the options specific to the DataSource type, e.g. {"delimiter": ";"} for csv.
+// OutputOptions represents the options specific to the output format, e.g. {"delimiter": ";", "includeMetadata": "sidecar"}
+// for csv (see Output.GetFormatOptions). The missing options keep the defaults of the output.
+// NullValue represents the text of the null values of the outputs supporting the nullValue option, e.g. "" or "NULL".
+// It is ignored by the outputs writing the null values as they are, e.g. JSON null, and nil keeps the default of the output.
+// It cannot be set with the nullValue of the OutputOptions.
+//
+// The stages are applied in the following order:
+// Normalize -> Casts -> Watermark -> Dedup -> FillNull -> Filters -> MergeColumns -> Computed -> PostFilters -> Aggregations
+// Normalize comes first, so the filters, the dedup, and the grouping compare the normalized values.
+// PostFilters have the same semantics as Filters but can refer to the merged and computed columns.
+//
+// The columns of the result are ordered as follows:
+// - With Aggregations, the grouping columns of the last aggregation followed by its passthrough columns and its results, all in the config order
+// - Otherwise, the source columns in the fetched order followed by the merged and the computed columns in the config order
 type Config struct {
-	Name         string              `json:"name"`
-	Description  string              `json:"description"`
-	Creator      string              `json:"creator"`
-	Type         string              `json:"type"`
-	Source       string              `json:"source"`
-	Filters      []FilterConfig      `json:"filters,omitempty"`
-	MergeColumns []MergeConfig       `json:"mergeColumns,omitempty"`
-	Aggregations []AggregationConfig `json:"aggregations,omitempty"`
-	OutputFormat string              `json:"outputFormat"`
+	SchemaVersion int                    `json:"schemaVersion"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Creator       string                 `json:"creator"`
+	Type          string                 `json:"type"`
+	Source        string                 `json:"source"`
+	ColumnMapping map[string]string      `json:"columnMapping,omitempty"`
+	SourceOptions map[string]interface{} `json:"sourceOptions,omitempty"`
+	MaxRows       int                    `json:"maxRows,omitempty"`
+	Normalize     []NormalizeConfig      `json:"normalize,omitempty"`
+	Casts         []CastConfig           `json:"casts,omitempty"`
+	Watermark     *WatermarkConfig       `json:"watermark,omitempty"`
+	Dedup         *DedupConfig           `json:"dedup,omitempty"`
+	FillNull      []FillConfig           `json:"fillNull,omitempty"`
+	Filters       []FilterConfig         `json:"filters,omitempty"`
+	MergeColumns  []MergeConfig          `json:"mergeColumns,omitempty"`
+	Computed      []ComputedColumn       `json:"computed,omitempty"`
+	PostFilters   []FilterConfig         `json:"postFilters,omitempty"`
+	Aggregations  []AggregationConfig    `json:"aggregations,omitempty"`
+	OutputFormat  string                 `json:"outputFormat"`
+	Destination   string                 `json:"destination,omitempty"`
+	OutputOptions map[string]interface{} `json:"outputOptions,omitempty"`
+	NullValue     *string                `json:"nullValue,omitempty"`
+}
+
+// NormalizeConfig defines how to normalize the whitespace and the case of a string column right after fetch
+// The leading and trailing whitespace is always trimmed.
+// CollapseSpaces replaces each run of the internal whitespace with a single space.
+// Lowercase converts the values to lower case.
+// The column stays a string column even if the normalized values are numbers, so cast it to change the type.
+type NormalizeConfig struct {
+	Column         string `json:"column"`
+	CollapseSpaces bool   `json:"collapseSpaces,omitempty"`
+	Lowercase      bool   `json:"lowercase,omitempty"`
+}
+
+// CastConfig defines the type a column is forced to right after fetch
+// To represents the target type:
+// - int: Integers (integral decimals like "3.0" are accepted)
+// - float: Decimal numbers
+// - string: The text of the values as they are
+// - date: Dates normalized to "2006-01-02" (or RFC 3339 when the value has the time of day)
+type CastConfig struct {
+	Column string `json:"column"`
+	To     string `json:"to"`
+}
+
+// WatermarkConfig defines the incremental processing keeping only the rows newer than the previous run
+// Column represents the column compared with the watermark; an int, float, or string column.
+// Since represents the watermark, usually the Watermark of the metadata of the previous run (empty for the first run).
+// The rows whose value is strictly greater than Since are kept, and the new maximum of the kept rows
+// is reported as the Watermark of the metadata for the next run.
+// The string columns are compared as dates if all their values and Since are dates, otherwise as strings.
+type WatermarkConfig struct {
+	Column string `json:"column"`
+	Since  string `json:"since,omitempty"`
+}
+
+// DedupConfig defines how to remove duplicate rows
+// Columns represents the subset of columns to compare (empty means the whole row).
+// Keep represents which row to keep among duplicates; first or last (default first).
+type DedupConfig struct {
+	Columns []string `json:"columns,omitempty"`
+	Keep    string   `json:"keep,omitempty"`
+}
+
+// FillConfig defines how to fill null (missing or blank) values of a column
+// Method represents the way how to fill values:
+// - literal: Fill with the Value (default when Method is empty)
+// - mean: Fill with the mean of the non-null values in the numeric column
+// - zero: Fill with 0
+// - forward: Fill with the last non-null value above (values before the first non-null value remain null)
+type FillConfig struct {
+	Column string `json:"column"`
+	Value  string `json:"value,omitempty"`
+	Method string `json:"method,omitempty"`
 }
 
 // FilterConfig defines the structure for filtering operations based on a column, its value, and a specified operator.
+// Validate pre-parses Value into the typed values (see NumberValue and BoolValue), while JSON keeps the original string.
+//
+// The "in" and "notIn" operators match the rows whose value is (not) one of the list instead of the single Value.
+// The list is either inlined as Values or loaded from ValuesFile, a text file of one value per line
+// (surrounding spaces are trimmed and blank lines are skipped). Exactly one of them must be set for these operators,
+// and neither of them can be set for the other operators. ValuesFile is loaded by Validate (see ListValues).
+//
+// The "containsAny" and "containsAll" operators treat the cell as the values separated by the Delimiter
+// (e.g. "tag1;tag2;tag3" with ";"), and match the rows containing any or all of the list respectively.
+// The Delimiter must be a single character and is required for these operators only.
+//
+// ValueType "date" makes the filter date-typed: the values of the string column and the Value are compared as dates
+// (see the layouts of the date cast) instead of as strings. The Value of a date-typed filter can be a date or
+// a relative date token, e.g. "now-30d", "today", or "startOfMonth" (see ResolveRelativeDate), so the Value of
+// the other filters is always compared as it is, even if it reads "today". The token is checked by Validate and
+// resolved by the processor when the filter is applied, in UTC by default. ValueType cannot be set for the list operators.
 type FilterConfig struct {
-	Column          string `json:"column"`
-	Value           string `json:"value"`
-	Operator        string `json:"operator"`
-	LogicalOperator string `json:"logicalOperator"` // LogicalOperator represents the way how to combine the next filter
+	Column          string   `json:"column"`
+	Value           string   `json:"value"`
+	ValueType       string   `json:"valueType,omitempty"`
+	Values          []string `json:"values,omitempty"`
+	ValuesFile      string   `json:"valuesFile,omitempty"`
+	Delimiter       string   `json:"delimiter,omitempty"`
+	Operator        string   `json:"operator"`
+	LogicalOperator string   `json:"logicalOperator"` // LogicalOperator represents the way how to combine the next filter
+
+	parsed *parsedFilterValue  // parsed caches the typed values of Value
+	loaded *loadedFilterValues // loaded caches the values loaded from ValuesFile
 }
 
 // MergeConfig defines how to merge columns
+// DefaultValues represents the values used instead of the null values of the first and second columns respectively.
+// The divide strategy computes first / second, and the percentage strategy computes first / second * 100.
+// DecimalPlaces represents the decimal places the result of the percentage strategy
+// is rounded to (default 0, rounding to an integer). It cannot be set for the other strategies.
+// Separator represents the string put between the values of the concat strategy (default none). With a separator,
+// the values containing the separator, quotes, or newlines are quoted as the CSV fields of RFC 4180,
+// so the result can be split back into the values. It cannot be set for the other strategies.
+// Columns represents the ordered columns of the coalesce strategy, which takes the first value that is neither null
+// nor an empty string (whitespace is a value) and the DefaultValues[0] when all are missing. The coalesce strategy
+// uses Columns instead of FirstColumn and SecondColumn, and DefaultValues can have at most one value for it.
 type MergeConfig struct {
 	FirstColumn      string   `json:"firstColumn"`
 	SecondColumn     string   `json:"secondColumn"`
+	Columns          []string `json:"columns,omitempty"`
 	Strategy         string   `json:"strategy"`
 	DefaultValues    []string `json:"defaultValues,omitempty"`
 	ResultColumnName string   `json:"resultColumnName,omitempty"`
+	DecimalPlaces    int      `json:"decimalPlaces,omitempty"`
+	Separator        string   `json:"separator,omitempty"`
+}
+
+// ComputedColumn defines a numeric column computed from an arithmetic expression over the other columns
+// Expression supports "+", "-", "*", "/", parentheses, numeric literals, and column references
+// (quote the column names containing spaces or symbols, e.g. `(revenue - cost) / "unit count"`).
+// Referenced columns must be numeric and exist when the column is computed, including the preceding computed columns.
+// A null operand makes the result of the row null, and so does a division by zero by default
+// (the processor can be configured to produce 0 or fail instead).
+type ComputedColumn struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
 }
 
 // AggregationConfig defines how to aggregate data
+// PassthroughColumns represents the columns kept in the result with the value of the first row of each group,
+// e.g. a label column determined by the grouping columns. They cannot be grouping columns.
 type AggregationConfig struct {
-	GroupingColumns []string      `json:"groupingColumns"`
-	Aggregations    []Aggregation `json:"aggregations"`
+	GroupingColumns    []string      `json:"groupingColumns"`
+	PassthroughColumns []string      `json:"passthroughColumns,omitempty"`
+	Aggregations       []Aggregation `json:"aggregations"`
 }
 
 // Aggregation defines a specific aggregation operation
+// The "median" method ignores null values and returns the middle value for an odd count
+// and the average of the two middle values for an even count (null if all values are null).
+// WeightColumn is required only for the "weightedAvg" method, which computes sum(value*weight)/sum(weight) per group.
+// If the total weight of a group is zero, the result of the group is null.
+// The "sharePercent" method computes the sum of each group as a percentage of the sum of all groups,
+// e.g. the share of the revenue of each region. If the sum of all groups is zero, the results are null.
+// Approximate is supported only for the "median" method, which then estimates the median with the P² algorithm
+// in constant memory instead of buffering the values of each group (see the processor for the error bound).
+// Condition restricts the aggregation to the rows matching it within each group.
+// If no rows in a group match the Condition, the result is 0 for "count" and null for the other methods.
 type Aggregation struct {
-	Column          string `json:"column"`
-	AggregateMethod string `json:"aggregateMethod"`
-	ResultName      string `json:"resultName,omitempty"`
+	Column          string        `json:"column"`
+	AggregateMethod string        `json:"aggregateMethod"`
+	ResultName      string        `json:"resultName,omitempty"`
+	WeightColumn    string        `json:"weightColumn,omitempty"`
+	Approximate     bool          `json:"approximate,omitempty"`
+	Condition       *FilterConfig `json:"condition,omitempty"`
+}
+
+// JoinConfig defines how to join two DataFrames by key columns
+// Non-key columns existing in both DataFrames are suffixed with "_left" and "_right" respectively.
+// The result key column takes the name of LeftKey.
+type JoinConfig struct {
+	LeftKey  string `json:"leftKey"`
+	RightKey string `json:"rightKey"`
+	Type     string `json:"type"` // Type represents the join type; inner, left, right, outer
 }
 
 // Validate checks the Config object for required fields and sets default values where applicable.
 // It validates nested MergeColumns and Aggregations configurations as well. Errors are returned for invalid cases.
+// The Type and the OutputFormat are checked against SupportedSourceTypes and SupportedOutputFormats
+// by ValidateType and ValidateOutputFormat respectively (see also registry.ValidateConfig).
 func (c *Config) Validate() error {
+	if c.SchemaVersion == 0 {
+		c.SchemaVersion = CurrentSchemaVersion
+	}
+	if c.SchemaVersion != CurrentSchemaVersion {
+		return newValidationError(MessageUnsupportedSchemaVersion, c.SchemaVersion)
+	}
 	if c.Name == "" {
-		c.Name = "UntitledConfig_" + utils.RandomString(10)
+		c.Name = generateUntitledName(LocaleEnglish)
 	}
 	if c.Type == "" {
-		return fmt.Errorf("type is required, valid values are: %s", "csv, googlesheets")
+		return newValidationError(MessageTypeRequired, SupportedSourceTypes())
+	}
+	if err := c.ValidateType(SupportedSourceTypes()); err != nil {
+		return err
 	}
 	if c.Source == "" {
-		return fmt.Errorf("source is required")
+		return newValidationError(MessageRequired, "source")
+	}
+	if c.MaxRows < 0 {
+		return newValidationError(MessageNotNegative, "maxRows", c.MaxRows)
+	}
+	if err := ValidateColumnMapping(c.ColumnMapping); err != nil {
+		return fmt.Errorf("columnMapping: %w", err)
 	}
 	if c.OutputFormat == "" {
 		c.OutputFormat = "csv"
 	}
+	if err := c.ValidateOutputFormat(SupportedOutputFormats()); err != nil {
+		return err
+	}
+	if _, ok := c.OutputOptions["nullValue"]; ok && c.NullValue != nil {
+		return newValidationError(MessageCannotBeSetWith, "outputOptions.nullValue", "nullValue", *c.NullValue)
+	}
 
 	// This may be an implicit conversion and cause bugs. So commented out.
 	//if len(c.Filters) == 1 && !slices.Contains([]string{"and", "or"}, c.Filters[0].LogicalOperator) {
 	//	c.Filters[0].LogicalOperator = "and"
 	//}
 
-	for i, filter := range c.Filters {
-		if err := filter.Validate(); err != nil {
+	for i := range c.Normalize {
+		if err := c.Normalize[i].Validate(); err != nil {
+			return fmt.Errorf("normalize[%d]: %w", i, err)
+		}
+	}
+
+	for i := range c.Casts {
+		if err := c.Casts[i].Validate(); err != nil {
+			return fmt.Errorf("casts[%d]: %w", i, err)
+		}
+	}
+
+	if c.Watermark != nil {
+		if err := c.Watermark.Validate(); err != nil {
+			return fmt.Errorf("watermark: %w", err)
+		}
+	}
+
+	if c.Dedup != nil {
+		if err := c.Dedup.Validate(); err != nil {
+			return fmt.Errorf("dedup: %w", err)
+		}
+	}
+
+	for i := range c.FillNull {
+		if err := c.FillNull[i].Validate(); err != nil {
+			return fmt.Errorf("fillNull[%d]: %w", i, err)
+		}
+	}
+
+	for i := range c.Filters {
+		if err := c.Filters[i].Validate(); err != nil {
 			return fmt.Errorf("filter[%d]: %w", i, err)
 		}
 	}
 
 	// Validate all mergeColumns setting
-	for i, mergeColumn := range c.MergeColumns {
-		if err := mergeColumn.Validate(); err != nil {
+	for i := range c.MergeColumns {
+		if err := c.MergeColumns[i].Validate(); err != nil {
 			return fmt.Errorf("mergeColumn[%d]: %w", i, err)
 		}
 	}
 
+	for i := range c.Computed {
+		if err := c.Computed[i].Validate(); err != nil {
+			return fmt.Errorf("computed[%d]: %w", i, err)
+		}
+	}
+
+	for i := range c.PostFilters {
+		if err := c.PostFilters[i].Validate(); err != nil {
+			return fmt.Errorf("postFilter[%d]: %w", i, err)
+		}
+	}
+
 	// Validate all aggregations setting
-	for i, aggregation := range c.Aggregations {
-		if err := aggregation.Validate(); err != nil {
+	for i := range c.Aggregations {
+		if err := c.Aggregations[i].Validate(); err != nil {
 			return fmt.Errorf("aggregation[%d]: %w", i, err)
 		}
 	}
@@ -96,62 +323,252 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// generateUntitledName generates the name of the Config without a name in the locale.
+func generateUntitledName(locale Locale) string {
+	return MessagesFor(locale).Message(MessageUntitledConfigName) + utils.RandomString(10)
+}
+
+// Validate checks the WatermarkConfig for the required column. The column type is checked by the processor.
+func (w *WatermarkConfig) Validate() error {
+	if w.Column == "" {
+		return newValidationError(MessageRequired, "column")
+	}
+
+	return nil
+}
+
+// Validate checks the DedupConfig for the keep value and the listed columns, and sets the default keep value.
+func (d *DedupConfig) Validate() error {
+	for i, column := range d.Columns {
+		if column == "" {
+			return newValidationError(MessageCannotBeEmpty, fmt.Sprintf("columns[%d]", i))
+		}
+	}
+	if d.Keep == "" {
+		d.Keep = "first"
+	}
+
+	validateKeeps := SupportedDedupKeeps()
+	if !slices.Contains(validateKeeps, d.Keep) {
+		return newValidationError(MessageInvalidChoice, "keep", d.Keep, "keep", validateKeeps)
+	}
+
+	return nil
+}
+
+// Validate checks the FillConfig for the required column, sets the default method, and validates the method and value.
+func (f *FillConfig) Validate() error {
+	if f.Column == "" {
+		return newValidationError(MessageRequired, "column")
+	}
+	if f.Method == "" {
+		f.Method = "literal"
+	}
+
+	validateMethods := SupportedFillMethods()
+	if !slices.Contains(validateMethods, f.Method) {
+		return newValidationError(MessageInvalidChoice, "method", f.Method, "method", validateMethods)
+	}
+
+	if f.Method == "literal" && f.Value == "" {
+		return newValidationError(MessageRequiredFor, "value", "literal method")
+	}
+	if f.Method != "literal" && f.Value != "" {
+		return newValidationError(MessageCannotBeSetWith, "value", "method", f.Method)
+	}
+
+	return nil
+}
+
 func (fc *FilterConfig) Validate() error {
 	if fc.Column == "" {
-		return fmt.Errorf("column is required")
+		return newValidationError(MessageRequired, "column")
 	}
 
-	if fc.Value == "" {
-		return fmt.Errorf("value is required")
+	validateOperators := SupportedFilterOperators()
+	if IsListOperator(fc.Operator) {
+		if err := fc.validateList(); err != nil {
+			return err
+		}
+	} else {
+		if fc.Value == "" {
+			return newValidationError(MessageRequired, "value")
+		}
+		if !slices.Contains(validateOperators, fc.Operator) {
+			return newValidationError(MessageInvalidChoice, "operator", fc.Operator, "operator", validateOperators)
+		}
+		if len(fc.Values) > 0 {
+			return newValidationError(MessageCannotBeSetWith, "values", "operator", fc.Operator)
+		}
+		if fc.ValuesFile != "" {
+			return newValidationError(MessageCannotBeSetWith, "valuesFile", "operator", fc.Operator)
+		}
 	}
-
-	validateOperators := []string{"eq", "neq", "gt", "gte", "lt", "lte"}
-	if !slices.Contains(validateOperators, fc.Operator) {
-		return fmt.Errorf("invalid operator '%s', operator must be one of %v", fc.Operator, validateOperators)
+	if !IsDelimitedOperator(fc.Operator) && fc.Delimiter != "" {
+		return newValidationError(MessageCannotBeSetWith, "delimiter", "operator", fc.Operator)
 	}
 
-	validateLogicalOperators := []string{"and", "or"}
+	validateLogicalOperators := SupportedLogicalOperators()
 	if !slices.Contains(validateLogicalOperators, fc.LogicalOperator) {
-		return fmt.Errorf("invalid logical operator '%s', operator must be one of %v", fc.LogicalOperator, validateLogicalOperators)
+		return newValidationError(MessageInvalidChoice, "logical operator", fc.LogicalOperator, "operator", validateLogicalOperators)
 	}
 
+	if fc.ValueType != "" {
+		valueTypes := SupportedFilterValueTypes()
+		if !slices.Contains(valueTypes, fc.ValueType) {
+			return newValidationError(MessageInvalidChoice, "value type", fc.ValueType, "valueType", valueTypes)
+		}
+		if IsListOperator(fc.Operator) {
+			return newValidationError(MessageCannotBeSetWith, "valueType", "operator", fc.Operator)
+		}
+		if IsRelativeDate(fc.Value) {
+			if _, err := ResolveRelativeDate(fc.Value, utils.Now()); err != nil {
+				return err
+			}
+		}
+	}
+
+	fc.parsed = parseFilterValue(fc.Value)
+
 	return nil
 }
 
-// Validate checks the MergeConfig for required fields, sets appropriate defaults, and validates the strategy field.
-func (m *MergeConfig) Validate() error {
-	if m.FirstColumn == "" {
-		return fmt.Errorf("firstColumn is required")
+// Validate checks the ComputedColumn for required fields. The expression is parsed by the processor.
+func (cc *ComputedColumn) Validate() error {
+	if cc.Name == "" {
+		return newValidationError(MessageRequired, "name")
+	}
+
+	if cc.Expression == "" {
+		return newValidationError(MessageRequired, "expression")
+	}
+
+	return nil
+}
+
+// Validate checks the NormalizeConfig for the required column.
+func (nc *NormalizeConfig) Validate() error {
+	if nc.Column == "" {
+		return newValidationError(MessageRequired, "column")
 	}
-	if m.SecondColumn == "" {
-		return fmt.Errorf("secondColumn is required")
+
+	return nil
+}
+
+// Validate checks the CastConfig for the required column and the supported target type.
+func (cc *CastConfig) Validate() error {
+	if cc.Column == "" {
+		return newValidationError(MessageRequired, "column")
 	}
+
+	castTypes := SupportedCastTypes()
+	if !slices.Contains(castTypes, cc.To) {
+		return newValidationError(MessageInvalidChoice, "cast type", cc.To, "to", castTypes)
+	}
+
+	return nil
+}
+
+// Validate checks the MergeConfig for required fields, sets appropriate defaults, and validates the strategy field.
+func (m *MergeConfig) Validate() error {
 	if m.Strategy == "" {
 		m.Strategy = "concat"
 	}
+	if m.Strategy == "coalesce" {
+		if err := m.validateCoalesce(); err != nil {
+			return err
+		}
+	} else {
+		if m.FirstColumn == "" {
+			return newValidationError(MessageRequired, "firstColumn")
+		}
+		if m.SecondColumn == "" {
+			return newValidationError(MessageRequired, "secondColumn")
+		}
+		if len(m.Columns) > 0 {
+			return newValidationError(MessageOnlySupportedFor, "columns", "strategy 'coalesce'")
+		}
+	}
 	if m.ResultColumnName == "" {
-		m.ResultColumnName = m.FirstColumn + "_" + m.SecondColumn
+		m.ResultColumnName = strings.Join(m.SourceColumns(), "_")
 	}
 
-	validateStrategies := []string{"concat", "sum", "first", "second"}
+	validateStrategies := SupportedMergeStrategies()
 	if !slices.Contains(validateStrategies, m.Strategy) {
-		return fmt.Errorf("invalid strategy '%s', strategy must be one of %v", m.Strategy, validateStrategies)
+		return newValidationError(MessageInvalidChoice, "strategy", m.Strategy, "strategy", validateStrategies)
+	}
+	if m.DecimalPlaces < 0 {
+		return newValidationError(MessageNotNegative, "decimalPlaces", m.DecimalPlaces)
+	}
+	if m.DecimalPlaces != 0 && m.Strategy != "percentage" {
+		return newValidationError(MessageOnlySupportedFor, "decimalPlaces", "strategy 'percentage'")
+	}
+	if m.Separator != "" && m.Strategy != "concat" {
+		return newValidationError(MessageOnlySupportedFor, "separator", "strategy 'concat'")
+	}
+
+	return nil
+}
+
+// validateCoalesce checks the Columns of the coalesce strategy are set instead of FirstColumn and SecondColumn
+// and the DefaultValues has at most one value.
+func (m *MergeConfig) validateCoalesce() error {
+	if len(m.Columns) == 0 {
+		return newValidationError(MessageRequiredFor, "columns", "strategy 'coalesce'")
+	}
+	for i, column := range m.Columns {
+		if column == "" {
+			return newValidationError(MessageCannotBeEmpty, fmt.Sprintf("columns[%d]", i))
+		}
+		if slices.Contains(m.Columns[:i], column) {
+			return newValidationError(MessageAlreadyIn, "columns", column, "columns")
+		}
+	}
+	if m.FirstColumn != "" {
+		return newValidationError(MessageCannotBeSetWith, "firstColumn", "strategy", m.Strategy)
+	}
+	if m.SecondColumn != "" {
+		return newValidationError(MessageCannotBeSetWith, "secondColumn", "strategy", m.Strategy)
+	}
+	if len(m.DefaultValues) > 1 {
+		return newValidationError(MessageAtMost, "defaultValues", 1, len(m.DefaultValues))
 	}
 
 	return nil
 }
 
-// Validate checks if the AggregationConfig instance has valid GroupingColumns and Aggregations and validates each aggregation.
+// SourceColumns returns the columns the merge reads: the Columns for the coalesce strategy,
+// otherwise the FirstColumn and the SecondColumn.
+func (m *MergeConfig) SourceColumns() []string {
+	if m.Strategy == "coalesce" {
+		return m.Columns
+	}
+
+	return []string{m.FirstColumn, m.SecondColumn}
+}
+
+// Validate checks if the AggregationConfig instance has valid GroupingColumns, PassthroughColumns, and Aggregations and validates each aggregation.
 func (ac *AggregationConfig) Validate() error {
 	if len(ac.GroupingColumns) == 0 {
-		return fmt.Errorf("groupingColumns cannot be empty")
+		return newValidationError(MessageCannotBeEmpty, "groupingColumns")
 	}
 	if len(ac.Aggregations) == 0 {
-		return fmt.Errorf("aggregations cannot be empty")
+		return newValidationError(MessageCannotBeEmpty, "aggregations")
+	}
+	for i, column := range ac.PassthroughColumns {
+		if column == "" {
+			return newValidationError(MessageCannotBeEmpty, fmt.Sprintf("passthroughColumns[%d]", i))
+		}
+		if slices.Contains(ac.GroupingColumns, column) {
+			return newValidationError(MessageAlreadyIn, "passthroughColumns", column, "groupingColumns")
+		}
+		if slices.Contains(ac.PassthroughColumns[:i], column) {
+			return newValidationError(MessageAlreadyIn, "passthroughColumns", column, "passthroughColumns")
+		}
 	}
 
-	for i, aggregation := range ac.Aggregations {
-		if err := aggregation.Validate(); err != nil {
+	for i := range ac.Aggregations {
+		if err := ac.Aggregations[i].Validate(); err != nil {
 			return fmt.Errorf("aggregation[%d]: %w", i, err)
 		}
 	}
@@ -162,24 +579,64 @@ func (ac *AggregationConfig) Validate() error {
 // Validate ensures that the Aggregation instance has valid values and performs the necessary validations on its fields.
 func (a *Aggregation) Validate() error {
 	if a.Column == "" {
-		return fmt.Errorf("column is required")
+		return newValidationError(MessageRequired, "column")
 	}
 	if a.AggregateMethod == "" {
-		return fmt.Errorf("aggregateMethod is required")
+		return newValidationError(MessageRequired, "aggregateMethod")
 	}
 	if a.ResultName == "" {
 		a.ResultName = a.Column + "_" + a.AggregateMethod
 	}
 
-	validateAggregateMethods := []string{"sum", "avg", "min", "max", "count", "median"}
+	validateAggregateMethods := SupportedAggregateMethods()
 	if !slices.Contains(validateAggregateMethods, a.AggregateMethod) {
-		return fmt.Errorf("invalid aggregateMethod '%s', aggregateMethod must be one of %v", a.AggregateMethod, validateAggregateMethods)
+		return newValidationError(MessageInvalidChoice, "aggregateMethod", a.AggregateMethod, "aggregateMethod", validateAggregateMethods)
+	}
+
+	if a.AggregateMethod == "weightedAvg" {
+		if a.WeightColumn == "" {
+			return newValidationError(MessageRequiredFor, "weightColumn", "weightedAvg")
+		}
+		if a.WeightColumn == a.Column {
+			return newValidationError(MessageMustDiffer, "weightColumn", "column", a.Column)
+		}
+	}
+
+	if a.Approximate && a.AggregateMethod != "median" {
+		return newValidationError(MessageOnlySupportedFor, "approximate", "median")
+	}
+
+	if a.Condition != nil {
+		if err := a.Condition.Validate(); err != nil {
+			return fmt.Errorf("condition: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the JoinConfig for required keys, sets the default join type, and validates the join type.
+func (j *JoinConfig) Validate() error {
+	if j.LeftKey == "" {
+		return newValidationError(MessageRequired, "leftKey")
+	}
+	if j.RightKey == "" {
+		return newValidationError(MessageRequired, "rightKey")
+	}
+	if j.Type == "" {
+		j.Type = "inner"
+	}
+
+	validateJoinTypes := []string{"inner", "left", "right", "outer"}
+	if !slices.Contains(validateJoinTypes, j.Type) {
+		return newValidationError(MessageInvalidChoice, "join type", j.Type, "type", validateJoinTypes)
 	}
 
 	return nil
 }
 
 // ToJSON converts the Config object into a formatted JSON string. Returns an error if marshaling fails.
+// The JSON of a validated Config is parsed back by FromJSON into a Config Equal to it.
 func (c *Config) ToJSON() (string, error) {
 	data, err := json.MarshalIndent(c, "", "    ")
 	if err != nil {
@@ -190,10 +647,76 @@ func (c *Config) ToJSON() (string, error) {
 }
 
 // FromJSON parses a JSON string and populates the Config struct. Returns an error if unmarshalling or validation fails.
+// Older schema versions are migrated to the CurrentSchemaVersion before validation.
 func (c *Config) FromJSON(jsonString string) error {
-	if err := json.Unmarshal([]byte(jsonString), c); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON to Config: %w", err)
+	migrated, err := MigrateConfig([]byte(jsonString))
+	if err != nil {
+		return err
 	}
+	*c = *migrated
 
 	return c.Validate()
 }
+
+// FromJSONStrict is the same as FromJSON except that the unknown fields are rejected instead of being ignored,
+// which catches the misspelled keys. Returns a ConfigurationError naming the unknown field.
+func (c *Config) FromJSONStrict(jsonString string) error {
+	migrated, err := MigrateConfigStrict([]byte(jsonString))
+	if err != nil {
+		return err
+	}
+	*c = *migrated
+
+	return c.Validate()
+}
+
+// ConfigsFromJSON parses a JSON array of configs, migrating and validating each element as FromJSON does.
+// An invalid element doesn't abort the others: the returned slice is aligned with the array and has nil
+// for the invalid elements, and the returned error joins their errors prefixed with the index, e.g. "config[2]: ...".
+// Returns only the error if the data is not a JSON array.
+func ConfigsFromJSON(data []byte) ([]*Config, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON to the array of Config: %w", err)
+	}
+
+	configs := make([]*Config, len(elements))
+	errs := make([]error, 0)
+	for i, element := range elements {
+		config := &Config{}
+		if err := config.FromJSON(string(element)); err != nil {
+			errs = append(errs, fmt.Errorf("config[%d]: %w", i, err))
+			continue
+		}
+		configs[i] = config
+	}
+
+	return configs, errors.Join(errs...)
+}
+
+// ValidateOutputFormat checks the OutputFormat is one of the supported formats (e.g. the formats of the output registry).
+// The empty OutputFormat is treated as its default "csv". Returns a ConfigurationError listing the supported formats.
+func (c *Config) ValidateOutputFormat(supportedFormats []string) error {
+	format := c.OutputFormat
+	if format == "" {
+		format = "csv"
+	}
+
+	if !slices.Contains(supportedFormats, format) {
+		err := newValidationError(MessageInvalidChoice, "outputFormat", format, "outputFormat", supportedFormats)
+		return domainerrors.NewConfigurationError("outputFormat", err.Error(), err)
+	}
+
+	return nil
+}
+
+// ValidateType checks the Type is one of the supported source types (e.g. the types of the data source registry).
+// Returns a ConfigurationError listing the supported types.
+func (c *Config) ValidateType(supportedTypes []string) error {
+	if !slices.Contains(supportedTypes, c.Type) {
+		err := newValidationError(MessageInvalidChoice, "type", c.Type, "type", supportedTypes)
+		return domainerrors.NewConfigurationError("type", err.Error(), err)
+	}
+
+	return nil
+}
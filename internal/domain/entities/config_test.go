@@ -0,0 +1,311 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// assertConfigurationError fails the test unless the error is a ConfigurationError of the field
+// whose message contains every wanted text.
+func assertConfigurationError(t *testing.T, err error, field string, contains ...string) {
+	t.Helper()
+
+	var configurationError *domainerrors.ConfigurationError
+	if !errors.As(err, &configurationError) {
+		t.Fatalf("error = %v, want a ConfigurationError", err)
+	}
+	if configurationError.Field != field {
+		t.Errorf("Field = %q, want %q", configurationError.Field, field)
+	}
+	for _, text := range contains {
+		if !strings.Contains(configurationError.Message, text) {
+			t.Errorf("Message = %q, want it to contain %q", configurationError.Message, text)
+		}
+	}
+}
+
+func TestConfigValidateOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		want    string // the OutputFormat after Validate
+		wantErr bool
+	}{
+		{name: "valid", format: "json", want: "json"},
+		{name: "default", format: "", want: "csv"},
+		{name: "typo", format: "cvs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := salesConfig()
+			config.OutputFormat = tt.format
+
+			err := config.Validate()
+			if tt.wantErr {
+				assertConfigurationError(t, err, "outputFormat", "'cvs'", "[console csv json report]")
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if config.OutputFormat != tt.want {
+				t.Errorf("OutputFormat = %q, want %q", config.OutputFormat, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidateOutputFormatAgainstRegistry(t *testing.T) {
+	config := salesConfig()
+	config.OutputFormat = "xml"
+
+	if err := config.ValidateOutputFormat([]string{"csv", "xml"}); err != nil {
+		t.Errorf("ValidateOutputFormat() error = %v, want nil for the registered format", err)
+	}
+	assertConfigurationError(t, config.ValidateOutputFormat([]string{"csv"}), "outputFormat", "'xml'", "[csv]")
+
+	// The empty format is validated as its default
+	config.OutputFormat = ""
+	assertConfigurationError(t, config.ValidateOutputFormat([]string{"json"}), "outputFormat", "'csv'")
+}
+
+func TestConfigValidateType(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, sourceType := range []string{"csv", "googlesheets"} {
+			config := salesConfig()
+			config.Type = sourceType
+			if err := config.Validate(); err != nil {
+				t.Errorf("Validate() with the type %q error = %v, want nil", sourceType, err)
+			}
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		config := salesConfig()
+		config.Type = "gsheets"
+		assertConfigurationError(t, config.Validate(), "type", "'gsheets'", "[csv googlesheets]")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		config := salesConfig()
+		config.Type = ""
+
+		err := config.Validate()
+		var validationError *ValidationError
+		if !errors.As(err, &validationError) || validationError.Key != MessageTypeRequired {
+			t.Fatalf("Validate() error = %v, want the %s ValidationError", err, MessageTypeRequired)
+		}
+		if !strings.Contains(err.Error(), "[csv googlesheets]") {
+			t.Errorf("Validate() error = %q, want the accepted types", err)
+		}
+	})
+}
+
+func TestConfigValidateTypeAgainstRegistry(t *testing.T) {
+	config := salesConfig()
+	config.Type = "postgres"
+
+	if err := config.ValidateType([]string{"csv", "postgres"}); err != nil {
+		t.Errorf("ValidateType() error = %v, want nil for the registered type", err)
+	}
+	assertConfigurationError(t, config.ValidateType([]string{"csv"}), "type", "'postgres'", "[csv]")
+}
+
+func TestConfigFromJSONStrict(t *testing.T) {
+	const typo = `{
+		"type": "csv",
+		"source": "sales.csv",
+		"filtres": [{"column": "amount", "operator": "gt", "value": "100", "logicalOperator": "and"}]
+	}`
+
+	// The lenient loader ignores the misspelled key for the forward compatibility
+	lenient := &Config{}
+	if err := lenient.FromJSON(typo); err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if len(lenient.Filters) != 0 {
+		t.Errorf("FromJSON() filters = %v, want none", lenient.Filters)
+	}
+
+	strict := &Config{}
+	assertConfigurationError(t, strict.FromJSONStrict(typo), "filtres", "unknown field 'filtres'")
+
+	// The unknown field of a nested object is named as the decoder reports it
+	nested := `{"type": "csv", "source": "sales.csv", "filters": [{"column": "amount", "operater": "gt", "value": "1", "logicalOperator": "and"}]}`
+	assertConfigurationError(t, strict.FromJSONStrict(nested), "operater", "unknown field 'operater'")
+
+	// The known fields are loaded and validated as FromJSON does
+	valid := `{"type": "csv", "source": "sales.csv", "filters": [{"column": "amount", "operator": "gt", "value": "100", "logicalOperator": "and"}]}`
+	if err := strict.FromJSONStrict(valid); err != nil {
+		t.Fatalf("FromJSONStrict() error = %v", err)
+	}
+	if len(strict.Filters) != 1 || strict.Filters[0].Column != "amount" {
+		t.Errorf("FromJSONStrict() filters = %v, want the amount filter", strict.Filters)
+	}
+	if err := strict.FromJSONStrict(`{"type": "csv"}`); err == nil {
+		t.Error("FromJSONStrict() error = nil, want the error of the missing source")
+	}
+}
+
+func TestConfigValidateColumnMapping(t *testing.T) {
+	config := &Config{Type: "csv", Source: "sales.csv", ColumnMapping: map[string]string{"Order Date": "order_date", "売上 金額": "sales_amount"}}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	config.ColumnMapping["Ship Date"] = "order_date"
+	var validationErr *ValidationError
+	if err := config.Validate(); !errors.As(err, &validationErr) || validationErr.Key != MessageDuplicateTarget {
+		t.Fatalf("Validate() error = %v, want the ValidationError of %s", err, MessageDuplicateTarget)
+	}
+	// The headers are checked in the sorted order, so the same collision is always reported the same way
+	if got, want := validationErr.Args, []interface{}{"Order Date", "Ship Date", "order_date"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Args = %v, want %v", got, want)
+	}
+}
+
+func TestAggregationConfigValidatePassthroughColumns(t *testing.T) {
+	aggregations := []Aggregation{{Column: "amount", AggregateMethod: "sum"}}
+
+	tests := []struct {
+		name        string
+		passthrough []string
+		wantKey     MessageKey
+		wantArgs    []interface{}
+	}{
+		{name: "valid", passthrough: []string{"label", "manager"}},
+		{name: "grouping column", passthrough: []string{"label", "region"}, wantKey: MessageAlreadyIn, wantArgs: []interface{}{"passthroughColumns", "region", "groupingColumns"}},
+		{name: "duplicated", passthrough: []string{"label", "label"}, wantKey: MessageAlreadyIn, wantArgs: []interface{}{"passthroughColumns", "label", "passthroughColumns"}},
+		{name: "empty", passthrough: []string{""}, wantKey: MessageCannotBeEmpty, wantArgs: []interface{}{"passthroughColumns[0]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := AggregationConfig{GroupingColumns: []string{"region"}, PassthroughColumns: tt.passthrough, Aggregations: aggregations}
+			err := config.Validate()
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Key != tt.wantKey {
+				t.Fatalf("Validate() error = %v, want the ValidationError of %s", err, tt.wantKey)
+			}
+			if !reflect.DeepEqual(validationErr.Args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", validationErr.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConfigsFromJSON(t *testing.T) {
+	data := []byte(`[
+		{"name": "sales", "type": "csv", "source": "sales.csv"},
+		{"name": "no source", "type": "csv"},
+		{"name": "orders", "type": "csv", "source": "orders.csv", "filters": [{"column": "amount", "operator": "gt", "value": "5", "logicalOperator": "and"}]},
+		{"name": "bad operator", "type": "csv", "source": "bad.csv", "filters": [{"column": "amount", "operator": "like", "value": "5", "logicalOperator": "and"}]},
+		"not an object"
+	]`)
+
+	configs, err := ConfigsFromJSON(data)
+	if len(configs) != 5 {
+		t.Fatalf("ConfigsFromJSON() = %d configs, want 5 aligned with the array", len(configs))
+	}
+
+	// The valid elements are loaded regardless of the invalid ones
+	for i, want := range map[int]string{0: "sales", 2: "orders"} {
+		if configs[i] == nil || configs[i].Name != want {
+			t.Errorf("configs[%d] = %+v, want the config %s", i, configs[i], want)
+		}
+	}
+	if configs[2] != nil && configs[2].Filters[0].Operator != "gt" {
+		t.Errorf("configs[2] filters = %+v, want the filter of the element", configs[2].Filters)
+	}
+
+	// The error identifies every invalid element by its index
+	if err == nil {
+		t.Fatal("ConfigsFromJSON() error = nil, want the errors of the invalid elements")
+	}
+	for _, i := range []int{1, 3, 4} {
+		if configs[i] != nil {
+			t.Errorf("configs[%d] = %+v, want nil for the invalid element", i, configs[i])
+		}
+		if prefix := fmt.Sprintf("config[%d]: ", i); !strings.Contains(err.Error(), prefix) {
+			t.Errorf("ConfigsFromJSON() error = %v, want the error of %s", err, prefix)
+		}
+	}
+	for _, i := range []int{0, 2} {
+		if prefix := fmt.Sprintf("config[%d]: ", i); strings.Contains(err.Error(), prefix) {
+			t.Errorf("ConfigsFromJSON() error = %v, want no error of the valid %s", err, prefix)
+		}
+	}
+
+	// The error of each element is kept to inspect it
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("ConfigsFromJSON() error = %v, want the ValidationError of the element without a source", err)
+	}
+}
+
+func TestConfigsFromJSONInvalidArray(t *testing.T) {
+	for _, data := range []string{`{"name": "sales", "type": "csv", "source": "sales.csv"}`, `[`, ``} {
+		configs, err := ConfigsFromJSON([]byte(data))
+		if err == nil || configs != nil {
+			t.Errorf("ConfigsFromJSON(%q) = %v, %v, want only the error", data, configs, err)
+		}
+	}
+
+	configs, err := ConfigsFromJSON([]byte(`[]`))
+	if err != nil || len(configs) != 0 {
+		t.Errorf("ConfigsFromJSON([]) = %v, %v, want no configs and no error", configs, err)
+	}
+}
+
+func TestMergeConfigValidateCoalesce(t *testing.T) {
+	tests := []struct {
+		name     string
+		merge    MergeConfig
+		wantKey  MessageKey
+		wantArgs []interface{}
+	}{
+		{name: "valid", merge: MergeConfig{Columns: []string{"work", "mobile", "home"}, Strategy: "coalesce", DefaultValues: []string{"none"}}},
+		{name: "no columns", merge: MergeConfig{Strategy: "coalesce"}, wantKey: MessageRequiredFor, wantArgs: []interface{}{"columns", "strategy 'coalesce'"}},
+		{name: "empty column", merge: MergeConfig{Columns: []string{"work", ""}, Strategy: "coalesce"}, wantKey: MessageCannotBeEmpty, wantArgs: []interface{}{"columns[1]"}},
+		{name: "duplicated column", merge: MergeConfig{Columns: []string{"work", "work"}, Strategy: "coalesce"}, wantKey: MessageAlreadyIn, wantArgs: []interface{}{"columns", "work", "columns"}},
+		{name: "first column", merge: MergeConfig{FirstColumn: "work", Columns: []string{"mobile"}, Strategy: "coalesce"}, wantKey: MessageCannotBeSetWith, wantArgs: []interface{}{"firstColumn", "strategy", "coalesce"}},
+		{name: "two defaults", merge: MergeConfig{Columns: []string{"work", "mobile"}, Strategy: "coalesce", DefaultValues: []string{"a", "b"}}, wantKey: MessageAtMost, wantArgs: []interface{}{"defaultValues", 1, 2}},
+		{name: "columns of another strategy", merge: MergeConfig{FirstColumn: "a", SecondColumn: "b", Columns: []string{"c"}, Strategy: "sum"}, wantKey: MessageOnlySupportedFor, wantArgs: []interface{}{"columns", "strategy 'coalesce'"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.merge.Validate()
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				// The result is named after all the columns by default
+				if want := "work_mobile_home"; tt.merge.ResultColumnName != want {
+					t.Errorf("ResultColumnName = %q, want %q", tt.merge.ResultColumnName, want)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Key != tt.wantKey {
+				t.Fatalf("Validate() error = %v, want the ValidationError of %s", err, tt.wantKey)
+			}
+			if !reflect.DeepEqual(validationErr.Args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", validationErr.Args, tt.wantArgs)
+			}
+		})
+	}
+}
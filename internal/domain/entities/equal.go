@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// Equal reports whether the Config has the same fields as the other, comparing the slices in order.
+// The nil and the empty slices and maps are equal because the JSON of the Config omits both,
+// and the SourceOptions and the OutputOptions values are compared as JSON, e.g. the int 3 equals the float64 3 decoded by FromJSON.
+// The values cached by Validate (see FilterConfig) are not compared, so a validated Config equals its copy
+// before the validation. Two nil Configs are equal.
+func (c *Config) Equal(other *Config) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+
+	return c.SchemaVersion == other.SchemaVersion &&
+		c.Name == other.Name &&
+		c.Description == other.Description &&
+		c.Creator == other.Creator &&
+		c.Type == other.Type &&
+		c.Source == other.Source &&
+		maps.Equal(c.ColumnMapping, other.ColumnMapping) &&
+		equalJSONValues(c.SourceOptions, other.SourceOptions) &&
+		c.MaxRows == other.MaxRows &&
+		slices.Equal(c.Normalize, other.Normalize) &&
+		slices.Equal(c.Casts, other.Casts) &&
+		equalPointers(c.Watermark, other.Watermark, func(a, b *WatermarkConfig) bool { return *a == *b }) &&
+		equalPointers(c.Dedup, other.Dedup, (*DedupConfig).equal) &&
+		slices.Equal(c.FillNull, other.FillNull) &&
+		slices.EqualFunc(c.Filters, other.Filters, FilterConfig.equal) &&
+		slices.EqualFunc(c.MergeColumns, other.MergeColumns, MergeConfig.equal) &&
+		slices.Equal(c.Computed, other.Computed) &&
+		slices.EqualFunc(c.PostFilters, other.PostFilters, FilterConfig.equal) &&
+		slices.EqualFunc(c.Aggregations, other.Aggregations, AggregationConfig.equal) &&
+		c.OutputFormat == other.OutputFormat &&
+		c.Destination == other.Destination &&
+		equalJSONValues(c.OutputOptions, other.OutputOptions) &&
+		equalPointers(c.NullValue, other.NullValue, func(a, b *string) bool { return *a == *b })
+}
+
+// equal reports whether the DedupConfig has the same fields as the other.
+func (d *DedupConfig) equal(other *DedupConfig) bool {
+	return slices.Equal(d.Columns, other.Columns) && d.Keep == other.Keep
+}
+
+// equal reports whether the FilterConfig has the same fields as the other, ignoring the values cached by Validate.
+func (f FilterConfig) equal(other FilterConfig) bool {
+	return f.Column == other.Column &&
+		f.Value == other.Value &&
+		f.ValueType == other.ValueType &&
+		slices.Equal(f.Values, other.Values) &&
+		f.ValuesFile == other.ValuesFile &&
+		f.Delimiter == other.Delimiter &&
+		f.Operator == other.Operator &&
+		f.LogicalOperator == other.LogicalOperator
+}
+
+// equal reports whether the MergeConfig has the same fields as the other.
+func (m MergeConfig) equal(other MergeConfig) bool {
+	return m.FirstColumn == other.FirstColumn &&
+		m.SecondColumn == other.SecondColumn &&
+		slices.Equal(m.Columns, other.Columns) &&
+		m.Strategy == other.Strategy &&
+		slices.Equal(m.DefaultValues, other.DefaultValues) &&
+		m.ResultColumnName == other.ResultColumnName &&
+		m.DecimalPlaces == other.DecimalPlaces &&
+		m.Separator == other.Separator
+}
+
+// equal reports whether the AggregationConfig has the same fields as the other.
+func (ac AggregationConfig) equal(other AggregationConfig) bool {
+	return slices.Equal(ac.GroupingColumns, other.GroupingColumns) &&
+		slices.Equal(ac.PassthroughColumns, other.PassthroughColumns) &&
+		slices.EqualFunc(ac.Aggregations, other.Aggregations, Aggregation.equal)
+}
+
+// equal reports whether the Aggregation has the same fields as the other.
+func (a Aggregation) equal(other Aggregation) bool {
+	return a.Column == other.Column &&
+		a.AggregateMethod == other.AggregateMethod &&
+		a.ResultName == other.ResultName &&
+		a.WeightColumn == other.WeightColumn &&
+		a.Approximate == other.Approximate &&
+		equalPointers(a.Condition, other.Condition, func(x, y *FilterConfig) bool { return x.equal(*y) })
+}
+
+// equalPointers reports whether both pointers are nil or both are non-nil and equal by equal.
+func equalPointers[T any](a, b *T, equal func(a, b *T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return equal(a, b)
+}
+
+// equalJSONValues reports whether the maps encode to the same JSON, treating the nil and the empty maps as equal.
+func equalJSONValues(a, b map[string]interface{}) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	// encoding/json sorts the map keys, so the same values encode to the same bytes
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+
+	return aErr == nil && bErr == nil && string(aData) == string(bData)
+}
@@ -0,0 +1,138 @@
+package entities
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+// pick returns one of the choices at random, where nil choices leave the field unset.
+func pick[T any](r *rand.Rand, choices ...T) T {
+	return choices[r.IntN(len(choices))]
+}
+
+// randomConfig returns a valid Config with a random subset of the fields set to one of their varied values,
+// including the optional fields, the zero values omitted by the JSON, and the fields defaulted by Validate.
+func randomConfig(r *rand.Rand, i int) *Config {
+	nullValue := "NULL"
+	emptyValue := ""
+
+	return &Config{
+		Name:          pick(r, "", fmt.Sprintf("config %d", i)),
+		Description:   pick(r, "", "monthly sales"),
+		Creator:       pick(r, "", "analytics"),
+		Type:          pick(r, "csv", "googlesheets"),
+		Source:        fmt.Sprintf("source-%d.csv", i),
+		ColumnMapping: pick(r, nil, map[string]string{}, map[string]string{"Amount": "amount", "Region": "region"}),
+		SourceOptions: pick(r, nil, map[string]interface{}{}, map[string]interface{}{"delimiter": ";", "maxAttempts": 3, "header": true}),
+		MaxRows:       pick(r, 0, 1000),
+		Normalize:     pick(r, nil, []NormalizeConfig{}, []NormalizeConfig{{Column: "region", CollapseSpaces: true}, {Column: "name", Lowercase: true}}),
+		Casts:         pick(r, nil, []CastConfig{{Column: "amount", To: "float"}, {Column: "ordered", To: "date"}}),
+		Watermark:     pick(r, nil, &WatermarkConfig{Column: "updated"}, &WatermarkConfig{Column: "updated", Since: "2024-03-01"}),
+		Dedup:         pick(r, nil, &DedupConfig{}, &DedupConfig{Columns: []string{"id", "region"}, Keep: "last"}),
+		FillNull:      pick(r, nil, []FillConfig{{Column: "amount", Method: "zero"}, {Column: "region", Value: "unknown"}}),
+		Filters: pick(r, nil, []FilterConfig{
+			{Column: "amount", Operator: "gt", Value: "0", LogicalOperator: "and"},
+			{Column: "region", Operator: "in", Values: []string{"east", "west"}, LogicalOperator: "or"},
+			{Column: "ordered", Operator: "gte", Value: "now-30d", ValueType: "date", LogicalOperator: "and"},
+		}),
+		MergeColumns: pick(r, nil, []MergeConfig{
+			{FirstColumn: "first", SecondColumn: "last", Separator: " "},
+			{FirstColumn: "sales", SecondColumn: "target", Strategy: "percentage", DecimalPlaces: 2, ResultColumnName: "achieved"},
+			{Columns: []string{"work", "mobile", "home"}, Strategy: "coalesce", DefaultValues: []string{"none"}},
+		}),
+		Computed:    pick(r, nil, []ComputedColumn{{Name: "margin", Expression: "(revenue - cost) / revenue"}}),
+		PostFilters: pick(r, nil, []FilterConfig{{Column: "margin", Operator: "gte", Value: "0.4", LogicalOperator: "and"}}),
+		Aggregations: pick(r, nil, []AggregationConfig{{
+			GroupingColumns:    []string{"region"},
+			PassthroughColumns: pick(r, nil, []string{"manager"}),
+			Aggregations: []Aggregation{
+				{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+				{Column: "price", AggregateMethod: "weightedAvg", WeightColumn: "units", Approximate: false},
+				{Column: "amount", AggregateMethod: "median", Approximate: true,
+					Condition: &FilterConfig{Column: "amount", Operator: "gt", Value: "100", LogicalOperator: "and"}},
+			},
+		}}),
+		OutputFormat:  pick(r, "", "csv", "json"),
+		Destination:   pick(r, "", "result.out"),
+		OutputOptions: pick(r, nil, map[string]interface{}{"indent": "  ", "fields": []interface{}{"region", "total"}}),
+		NullValue:     pick(r, nil, &nullValue, &emptyValue),
+	}
+}
+
+func TestConfigJSONRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewPCG(20241016, 932))
+
+	for i := range 500 {
+		config := randomConfig(r, i)
+		if err := config.Validate(); err != nil {
+			t.Fatalf("config %d: Validate() error = %v", i, err)
+		}
+
+		data, err := config.ToJSON()
+		if err != nil {
+			t.Fatalf("config %d: ToJSON() error = %v", i, err)
+		}
+		reloaded := &Config{}
+		if err := reloaded.FromJSON(data); err != nil {
+			t.Fatalf("config %d: FromJSON() error = %v\n%s", i, err, data)
+		}
+
+		if !config.Equal(reloaded) {
+			t.Fatalf("config %d: FromJSON(ToJSON()) = %+v, want %+v\n%s", i, reloaded, config, data)
+		}
+		// The reloaded config serializes to the same JSON, so the round trip is stable
+		again, err := reloaded.ToJSON()
+		if err != nil || again != data {
+			t.Fatalf("config %d: ToJSON() of the reloaded config = %s, %v, want %s", i, again, err, data)
+		}
+	}
+}
+
+func TestConfigEqual(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Name:          "sales",
+			Type:          "csv",
+			Source:        "sales.csv",
+			SourceOptions: map[string]interface{}{"maxAttempts": 3},
+			Filters:       []FilterConfig{{Column: "amount", Operator: "gt", Value: "0", LogicalOperator: "and"}},
+			MergeColumns:  []MergeConfig{{Columns: []string{"work", "home"}, Strategy: "coalesce"}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   bool
+	}{
+		{name: "same", modify: func(c *Config) {}, want: true},
+		{name: "empty and nil slices", modify: func(c *Config) { c.Casts = []CastConfig{} }, want: true},
+		// The decoded JSON numbers are float64
+		{name: "option decoded from JSON", modify: func(c *Config) { c.SourceOptions["maxAttempts"] = 3.0 }, want: true},
+		{name: "cached by Validate", modify: func(c *Config) { _ = c.Filters[0].Validate() }, want: true},
+		{name: "other name", modify: func(c *Config) { c.Name = "orders" }, want: false},
+		{name: "other option", modify: func(c *Config) { c.SourceOptions["maxAttempts"] = 4 }, want: false},
+		{name: "column order", modify: func(c *Config) { c.MergeColumns[0].Columns = []string{"home", "work"} }, want: false},
+		{name: "extra filter", modify: func(c *Config) { c.Filters = append(c.Filters, c.Filters[0]) }, want: false},
+		{name: "null value", modify: func(c *Config) { c.NullValue = new(string) }, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base()
+			tt.modify(other)
+			if got := base().Equal(other); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := other.Equal(base()); got != tt.want {
+				t.Errorf("Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var none *Config
+	if !none.Equal(nil) || none.Equal(base()) || base().Equal(nil) {
+		t.Errorf("Equal() of nil Configs is not true only for two nil Configs")
+	}
+}
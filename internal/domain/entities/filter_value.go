@@ -0,0 +1,152 @@
+package entities
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parsedFilterValue holds the typed values parsed from the Value of a FilterConfig.
+// The column type is unknown until the data is fetched, so the value is parsed as every comparable type once.
+type parsedFilterValue struct {
+	source   string // source is the Value the cache was parsed from, to detect the Value changed after Validate
+	number   float64
+	isNumber bool
+	boolean  bool
+	isBool   bool
+}
+
+// parseFilterValue parses the value as every comparable type.
+func parseFilterValue(value string) *parsedFilterValue {
+	parsed := &parsedFilterValue{source: value}
+	trimmed := strings.TrimSpace(value)
+
+	if number, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		parsed.number = number
+		parsed.isNumber = true
+	}
+	if boolean, err := strconv.ParseBool(trimmed); err == nil {
+		parsed.boolean = boolean
+		parsed.isBool = true
+	}
+
+	return parsed
+}
+
+// parsedValue returns the cached typed values, parsing Value if Validate hasn't been called or Value has changed since.
+func (fc *FilterConfig) parsedValue() *parsedFilterValue {
+	if fc.parsed == nil || fc.parsed.source != fc.Value {
+		return parseFilterValue(fc.Value)
+	}
+
+	return fc.parsed
+}
+
+// IsValidated reports whether Validate has succeeded for the current Value and ValuesFile, so their parsed and loaded
+// values are cached. The other fields changed after Validate are not detected.
+func (fc *FilterConfig) IsValidated() bool {
+	if fc.parsed == nil || fc.parsed.source != fc.Value {
+		return false
+	}
+
+	return fc.ValuesFile == "" || (fc.loaded != nil && fc.loaded.source == fc.ValuesFile)
+}
+
+// NumberValue returns the Value parsed as a number compared with int and float columns.
+// Returns false if the Value is not a number.
+func (fc *FilterConfig) NumberValue() (float64, bool) {
+	parsed := fc.parsedValue()
+	return parsed.number, parsed.isNumber
+}
+
+// BoolValue returns the Value parsed as a bool compared with bool columns.
+// Returns false if the Value is not a bool.
+func (fc *FilterConfig) BoolValue() (bool, bool) {
+	parsed := fc.parsedValue()
+	return parsed.boolean, parsed.isBool
+}
+
+// loadedFilterValues holds the values loaded from the ValuesFile of a FilterConfig.
+type loadedFilterValues struct {
+	source string // source is the ValuesFile the values were loaded from, to detect the ValuesFile changed after Validate
+	values []string
+}
+
+// loadFilterValues reads the values of one value per line from the file, trimming the spaces and skipping blank lines.
+func loadFilterValues(path string) (*loadedFilterValues, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newValidationError(MessageCannotRead, "valuesFile", path, err)
+	}
+
+	values := make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		if value := strings.TrimSpace(line); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, newValidationError(MessageCannotBeEmpty, fmt.Sprintf("valuesFile '%s'", path))
+	}
+
+	return &loadedFilterValues{source: path, values: values}, nil
+}
+
+// validateList checks the list of the list operators and loads the ValuesFile.
+// The list must not be empty in either form: the Values must have non-blank values, and the ValuesFile must have a value
+// (see loadFilterValues). The delimited operators also require the single character Delimiter.
+func (fc *FilterConfig) validateList() error {
+	if fc.Value != "" {
+		return newValidationError(MessageCannotBeSetWith, "value", "operator", fc.Operator)
+	}
+	if len(fc.Values) > 0 && fc.ValuesFile != "" {
+		return newValidationError(MessageCannotBeSetWith, "values", "valuesFile", fc.ValuesFile)
+	}
+	if len(fc.Values) == 0 && fc.ValuesFile == "" {
+		return newValidationError(MessageRequiredFor, "values or valuesFile", fmt.Sprintf("operator '%s'", fc.Operator))
+	}
+
+	if IsDelimitedOperator(fc.Operator) {
+		if fc.Delimiter == "" {
+			return newValidationError(MessageRequiredFor, "delimiter", fmt.Sprintf("operator '%s'", fc.Operator))
+		}
+		if utf8.RuneCountInString(fc.Delimiter) != 1 {
+			return newValidationError(MessageSingleCharacter, "delimiter", fc.Delimiter)
+		}
+	}
+	for i, value := range fc.Values {
+		if strings.TrimSpace(value) == "" {
+			return newValidationError(MessageCannotBeEmpty, fmt.Sprintf("values[%d]", i))
+		}
+	}
+
+	if fc.ValuesFile != "" {
+		loaded, err := loadFilterValues(fc.ValuesFile)
+		if err != nil {
+			return err
+		}
+		fc.loaded = loaded
+	}
+
+	return nil
+}
+
+// ListValues returns the values of the list operators; the Values, or the values loaded from the ValuesFile.
+// The ValuesFile is loaded if Validate hasn't loaded it or the ValuesFile has changed since.
+func (fc *FilterConfig) ListValues() ([]string, error) {
+	if fc.ValuesFile == "" {
+		return fc.Values, nil
+	}
+
+	if fc.loaded == nil || fc.loaded.source != fc.ValuesFile {
+		loaded, err := loadFilterValues(fc.ValuesFile)
+		if err != nil {
+			return nil, err
+		}
+		return loaded.values, nil
+	}
+
+	return fc.loaded.values, nil
+}
@@ -0,0 +1,246 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilterConfigValueParsedOnce(t *testing.T) {
+	filter := FilterConfig{Column: "amount", Operator: "gte", Value: " 12.5 ", LogicalOperator: "and"}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cached := filter.parsed
+	if cached == nil {
+		t.Fatal("parsed = nil after Validate, want the cached value")
+	}
+	for i := 0; i < 3; i++ {
+		if got := filter.parsedValue(); got != cached {
+			t.Fatalf("parsedValue() = %p, want the cached %p", got, cached)
+		}
+	}
+
+	if number, ok := filter.NumberValue(); !ok || number != 12.5 {
+		t.Errorf("NumberValue() = (%v, %v), want (12.5, true)", number, ok)
+	}
+	if _, ok := filter.BoolValue(); ok {
+		t.Error("BoolValue() ok = true, want false for a number")
+	}
+
+	// The cache is ignored once the Value changes after Validate
+	filter.Value = "true"
+	if got := filter.parsedValue(); got == cached {
+		t.Error("parsedValue() returned the stale cache after the Value changed")
+	}
+	if boolean, ok := filter.BoolValue(); !ok || !boolean {
+		t.Errorf("BoolValue() = (%v, %v), want (true, true)", boolean, ok)
+	}
+	if _, ok := filter.NumberValue(); ok {
+		t.Error("NumberValue() ok = true, want false for a bool")
+	}
+}
+
+func TestFilterConfigValueNotValidated(t *testing.T) {
+	// Without Validate the Value is parsed on demand
+	filter := FilterConfig{Column: "amount", Operator: "lt", Value: "-3", LogicalOperator: "and"}
+	if number, ok := filter.NumberValue(); !ok || number != -3 {
+		t.Errorf("NumberValue() = (%v, %v), want (-3, true)", number, ok)
+	}
+	if filter.parsed != nil {
+		t.Error("parsed is set without Validate, want the cache left empty")
+	}
+}
+
+func TestFilterConfigIsValidated(t *testing.T) {
+	filter := FilterConfig{Column: "sku", Operator: "in", ValuesFile: writeValuesFile(t, "SKU-1\n"), LogicalOperator: "and"}
+	if filter.IsValidated() {
+		t.Fatal("IsValidated() = true before Validate")
+	}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !filter.IsValidated() {
+		t.Fatal("IsValidated() = false after Validate")
+	}
+
+	// Another ValuesFile isn't loaded yet
+	filter.ValuesFile = writeValuesFile(t, "SKU-2\n")
+	if filter.IsValidated() {
+		t.Error("IsValidated() = true after the ValuesFile changed")
+	}
+
+	valued := FilterConfig{Column: "amount", Operator: "gte", Value: "10", LogicalOperator: "and"}
+	if err := valued.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	valued.Value = "20"
+	if valued.IsValidated() {
+		t.Error("IsValidated() = true after the Value changed")
+	}
+}
+
+func TestFilterConfigJSONUnchangedByParsing(t *testing.T) {
+	const source = `{"column":"date","value":"2024-01-05","valueType":"date","operator":"gte","logicalOperator":"and"}`
+
+	var filter FilterConfig
+	if err := json.Unmarshal([]byte(source), &filter); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	before, err := json.Marshal(filter)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	after, err := json.Marshal(filter)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if string(before) != source || string(after) != source {
+		t.Errorf("JSON before Validate = %s, after = %s, want %s", before, after, source)
+	}
+}
+
+// writeValuesFile writes the content to a values file in a temporary directory of the test and returns its path.
+func writeValuesFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "values.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+
+	return path
+}
+
+func TestFilterConfigValuesFile(t *testing.T) {
+	path := writeValuesFile(t, "SKU-1\n  SKU-2  \n\n \nSKU-3\r\n")
+
+	filter := FilterConfig{Column: "sku", Operator: "in", ValuesFile: path, LogicalOperator: "and"}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	want := []string{"SKU-1", "SKU-2", "SKU-3"}
+	got, err := filter.ListValues()
+	if err != nil {
+		t.Fatalf("ListValues() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListValues() = %q, want %q", got, want)
+	}
+
+	// The values loaded by Validate are used even if the file changes afterwards
+	if err := os.WriteFile(path, []byte("SKU-9\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if got, _ := filter.ListValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListValues() = %q, want the values loaded by Validate %q", got, want)
+	}
+
+	// Another ValuesFile set after Validate is loaded on demand
+	filter.ValuesFile = writeValuesFile(t, "SKU-4\n")
+	if got, _ := filter.ListValues(); !reflect.DeepEqual(got, []string{"SKU-4"}) {
+		t.Errorf("ListValues() = %q, want [SKU-4]", got)
+	}
+
+	// The inline Values are returned as they are
+	inline := FilterConfig{Column: "sku", Operator: "notIn", Values: []string{"SKU-1"}, LogicalOperator: "and"}
+	if got, _ := inline.ListValues(); !reflect.DeepEqual(got, []string{"SKU-1"}) {
+		t.Errorf("ListValues() = %q, want [SKU-1]", got)
+	}
+}
+
+func TestFilterConfigValuesFileInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterConfig
+		want   MessageKey
+	}{
+		{
+			name:   "both values and values file",
+			filter: FilterConfig{Values: []string{"SKU-1"}, ValuesFile: writeValuesFile(t, "SKU-2\n")},
+			want:   MessageCannotBeSetWith,
+		},
+		{
+			name:   "neither values nor values file",
+			filter: FilterConfig{},
+			want:   MessageRequiredFor,
+		},
+		{
+			name:   "missing values file",
+			filter: FilterConfig{ValuesFile: filepath.Join(t.TempDir(), "missing.txt")},
+			want:   MessageCannotRead,
+		},
+		{
+			name:   "blank values file",
+			filter: FilterConfig{ValuesFile: writeValuesFile(t, "\n  \n")},
+			want:   MessageCannotBeEmpty,
+		},
+		{
+			name:   "blank inline value",
+			filter: FilterConfig{Values: []string{"SKU-1", " "}},
+			want:   MessageCannotBeEmpty,
+		},
+		{
+			name:   "values file of a comparison operator",
+			filter: FilterConfig{Operator: "eq", Value: "SKU-1", ValuesFile: writeValuesFile(t, "SKU-2\n")},
+			want:   MessageCannotBeSetWith,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.Column = "sku"
+			filter.LogicalOperator = "and"
+			if filter.Operator == "" {
+				filter.Operator = "in"
+			}
+
+			var validationErr *ValidationError
+			if err := filter.Validate(); !errors.As(err, &validationErr) || validationErr.Key != tt.want {
+				t.Errorf("Validate() error = %v, want the ValidationError of %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterConfigDelimiterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterConfig
+		want   MessageKey
+	}{
+		{name: "missing delimiter", filter: FilterConfig{Operator: "containsAny", Values: []string{"red"}}, want: MessageRequiredFor},
+		{name: "multi-character delimiter", filter: FilterConfig{Operator: "containsAll", Values: []string{"red"}, Delimiter: ";;"}, want: MessageSingleCharacter},
+		{name: "empty value", filter: FilterConfig{Operator: "containsAny", Values: []string{"red", ""}, Delimiter: ";"}, want: MessageCannotBeEmpty},
+		{name: "delimiter of another operator", filter: FilterConfig{Operator: "in", Values: []string{"red"}, Delimiter: ";"}, want: MessageCannotBeSetWith},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.Column = "tags"
+			filter.LogicalOperator = "and"
+
+			var validationErr *ValidationError
+			if err := filter.Validate(); !errors.As(err, &validationErr) || validationErr.Key != tt.want {
+				t.Errorf("Validate() error = %v, want the ValidationError of %s", err, tt.want)
+			}
+		})
+	}
+
+	// The multi-byte character is a single character
+	filter := FilterConfig{Column: "tags", Operator: "containsAll", Values: []string{"赤"}, Delimiter: "、", LogicalOperator: "and"}
+	if err := filter.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil for the single multi-byte delimiter", err)
+	}
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "fmt"
+
+// TypeWarning reports a column that looks mistyped, e.g. a mostly numeric column inferred as string
+// because of a few stray cells, which makes the numeric operations fail or misbehave.
+type TypeWarning struct {
+	Column        string   `json:"column"`
+	DetectedType  string   `json:"detectedType"`  // Type inferred from the data ("string")
+	SuggestedType string   `json:"suggestedType"` // Type most values conform to ("int" or "float")
+	ConformRatio  float64  `json:"conformRatio"`  // Ratio of the non-null values conforming to the suggested type
+	SampleValues  []string `json:"sampleValues"`  // Sample values not conforming to the suggested type
+}
+
+// String returns the human-readable description of the warning.
+func (w TypeWarning) String() string {
+	return fmt.Sprintf(
+		"column '%s' is %s, but %.0f%% of the values are %s (offending values: %q)",
+		w.Column, w.DetectedType, w.ConformRatio*100, w.SuggestedType, w.SampleValues,
+	)
+}
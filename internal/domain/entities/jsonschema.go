@@ -0,0 +1,172 @@
+package entities
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON Schema draft the generated schema conforms to
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// configSchemaEnums maps "<type>.<JSON field>" to the supported values of the field.
+// The values come from the same lists as the validations, so the schema never drifts from Validate.
+var configSchemaEnums = map[string]func() []string{
+	"CastConfig.to":                SupportedCastTypes,
+	"DedupConfig.keep":             SupportedDedupKeeps,
+	"FillConfig.method":            SupportedFillMethods,
+	"FilterConfig.operator":        SupportedFilterOperators,
+	"FilterConfig.valueType":       SupportedFilterValueTypes,
+	"FilterConfig.logicalOperator": SupportedLogicalOperators,
+	"MergeConfig.strategy":         SupportedMergeStrategies,
+	"Aggregation.aggregateMethod":  SupportedAggregateMethods,
+}
+
+// configSchemaRequired lists the JSON fields required by Validate for each type.
+// The fields filled with the default values by Validate are not required.
+var configSchemaRequired = map[string][]string{
+	"Config":            {"type", "source"},
+	"CastConfig":        {"column", "to"},
+	"FillConfig":        {"column"},
+	"FilterConfig":      {"column", "operator", "logicalOperator"},
+	"ComputedColumn":    {"name", "expression"},
+	"AggregationConfig": {"groupingColumns", "aggregations"},
+	"Aggregation":       {"column", "aggregateMethod"},
+}
+
+// configSchemaConditions holds the conditional requirements of each type expressed by if/then
+var configSchemaConditions = map[string]map[string]interface{}{
+	// The literal method is the default, so the value is required unless another method is set
+	"FillConfig": {
+		"if":   map[string]interface{}{"properties": map[string]interface{}{"method": map[string]interface{}{"const": "literal"}}},
+		"then": map[string]interface{}{"required": []string{"value"}},
+	},
+	// The list operators take either the values or the valuesFile, and the other operators take the value
+	"FilterConfig": {
+		"if": map[string]interface{}{
+			"properties": map[string]interface{}{"operator": map[string]interface{}{"enum": listOperators}},
+			"required":   []string{"operator"},
+		},
+		"then": map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"required": []string{"values"}},
+				map[string]interface{}{"required": []string{"valuesFile"}},
+			},
+			// The delimited operators also take the delimiter
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"operator": map[string]interface{}{"enum": delimitedOperators}},
+			},
+			"then": map[string]interface{}{
+				"required":   []string{"delimiter"},
+				"properties": map[string]interface{}{"delimiter": map[string]interface{}{"minLength": 1, "maxLength": 1}},
+			},
+		},
+		"else": map[string]interface{}{"required": []string{"value"}},
+	},
+	// The coalesce strategy takes the columns, and the other strategies (concat by default) take the two columns
+	"MergeConfig": {
+		"if": map[string]interface{}{
+			"properties": map[string]interface{}{"strategy": map[string]interface{}{"const": "coalesce"}},
+			"required":   []string{"strategy"},
+		},
+		"then": map[string]interface{}{"required": []string{"columns"}},
+		"else": map[string]interface{}{"required": []string{"firstColumn", "secondColumn"}},
+	},
+	"Aggregation": {
+		"if": map[string]interface{}{
+			"properties": map[string]interface{}{"aggregateMethod": map[string]interface{}{"const": "weightedAvg"}},
+			"required":   []string{"aggregateMethod"},
+		},
+		"then": map[string]interface{}{"required": []string{"weightColumn"}},
+	},
+}
+
+// GenerateConfigJSONSchema generates the JSON Schema (draft 2020-12) of Config for the editor completion and validation.
+// The nested types are defined in "$defs", and the enums of the fields are the values accepted by Validate.
+// The type and the outputFormat are not enumerated because the registry can add custom implementations.
+func GenerateConfigJSONSchema() ([]byte, error) {
+	defs := make(map[string]interface{})
+	root := structSchema(reflect.TypeOf(Config{}), defs)
+	root["$schema"] = jsonSchemaDialect
+	root["title"] = "Config"
+	root["$defs"] = defs
+
+	properties := root["properties"].(map[string]interface{})
+	properties["schemaVersion"].(map[string]interface{})["minimum"] = 1
+	properties["schemaVersion"].(map[string]interface{})["maximum"] = CurrentSchemaVersion
+	properties["maxRows"].(map[string]interface{})["minimum"] = 0
+
+	return json.MarshalIndent(root, "", "    ")
+}
+
+// structSchema returns the object schema of the struct type registering the nested struct types to defs.
+func structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	required := configSchemaRequired[t.Name()]
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		schema := typeSchema(field.Type, defs)
+		if enum, ok := configSchemaEnums[t.Name()+"."+name]; ok {
+			schema["enum"] = enum()
+		}
+		if slices.Contains(required, name) {
+			switch schema["type"] {
+			case "string":
+				schema["minLength"] = 1
+			case "array":
+				schema["minItems"] = 1
+			}
+		}
+		properties[name] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	for key, value := range configSchemaConditions[t.Name()] {
+		schema[key] = value
+	}
+
+	return schema
+}
+
+// typeSchema returns the schema of the field type. The struct types are referenced from defs.
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Interface:
+		// Any JSON value
+		return map[string]interface{}{}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			// Register before walking the fields to stop the recursion of the self-referencing types
+			defs[t.Name()] = nil
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64, reflect.Float32:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
@@ -0,0 +1,324 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// schemaValidator validates the JSON documents against the keywords of the generated schema.
+// It supports only the keywords GenerateConfigJSONSchema emits, which is enough to check the schema works as intended.
+type schemaValidator struct {
+	root map[string]interface{}
+}
+
+// validate returns the violations of the value against the schema, each prefixed with the JSON pointer of the value.
+func (v schemaValidator) validate(schema map[string]interface{}, value interface{}, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		return v.validate(v.root["$defs"].(map[string]interface{})[name].(map[string]interface{}), value, path)
+	}
+
+	violations := make([]string, 0)
+	fail := func(format string, args ...interface{}) {
+		violations = append(violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if kind, ok := schema["type"].(string); ok && !matchesType(kind, value) {
+		fail("want %s, got %v", kind, value)
+		return violations
+	}
+	if enum, ok := schema["enum"]; ok && !slices.ContainsFunc(toSlice(enum), func(e interface{}) bool { return e == value }) {
+		fail("%v is not one of %v", value, enum)
+	}
+	if constant, ok := schema["const"]; ok && constant != value {
+		fail("%v is not %v", value, constant)
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := float64(utf8.RuneCountInString(typed))
+		if minimum, ok := toNumber(schema["minLength"]); ok && length < minimum {
+			fail("shorter than %v", minimum)
+		}
+		if maximum, ok := toNumber(schema["maxLength"]); ok && length > maximum {
+			fail("longer than %v", maximum)
+		}
+	case float64:
+		if minimum, ok := toNumber(schema["minimum"]); ok && typed < minimum {
+			fail("%v is less than %v", typed, minimum)
+		}
+		if maximum, ok := toNumber(schema["maximum"]); ok && typed > maximum {
+			fail("%v is greater than %v", typed, maximum)
+		}
+	case []interface{}:
+		if minimum, ok := toNumber(schema["minItems"]); ok && float64(len(typed)) < minimum {
+			fail("fewer than %v items", minimum)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range typed {
+				violations = append(violations, v.validate(items, item, fmt.Sprintf("%s/%d", path, i))...)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range toSlice(schema["required"]) {
+			if _, ok := typed[name.(string)]; !ok {
+				fail("missing %s", name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range typed {
+			if propertySchema, ok := properties[name].(map[string]interface{}); ok {
+				violations = append(violations, v.validate(propertySchema, property, path+"/"+name)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("unknown property %s", name)
+				}
+			case map[string]interface{}:
+				violations = append(violations, v.validate(additional, property, path+"/"+name)...)
+			}
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"]; ok {
+		matched := 0
+		for _, option := range toSlice(oneOf) {
+			if len(v.validate(option.(map[string]interface{}), value, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("matches %d schemas of oneOf, want 1", matched)
+		}
+	}
+	if condition, ok := schema["if"].(map[string]interface{}); ok {
+		branch := "then"
+		if len(v.validate(condition, value, path)) > 0 {
+			branch = "else"
+		}
+		if next, ok := schema[branch].(map[string]interface{}); ok {
+			violations = append(violations, v.validate(next, value, path)...)
+		}
+	}
+
+	return violations
+}
+
+// matchesType reports whether the decoded JSON value is of the JSON Schema type.
+func matchesType(kind string, value interface{}) bool {
+	switch kind {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	default:
+		return false
+	}
+}
+
+// toSlice returns the elements of the schema keyword holding a list, which is a []string or
+// a []interface{} depending on whether the schema is built or decoded.
+func toSlice(value interface{}) []interface{} {
+	if value == nil {
+		return nil
+	}
+
+	list := reflect.ValueOf(value)
+	elements := make([]interface{}, list.Len())
+	for i := range elements {
+		elements[i] = list.Index(i).Interface()
+	}
+
+	return elements
+}
+
+// toNumber returns the numeric value of the schema keyword.
+func toNumber(value interface{}) (float64, bool) {
+	f, ok := value.(float64)
+	return f, ok
+}
+
+// generatedSchemaValidator generates the schema and returns the validator of it decoded from the JSON.
+func generatedSchemaValidator(t *testing.T) schemaValidator {
+	t.Helper()
+
+	data, err := GenerateConfigJSONSchema()
+	if err != nil {
+		t.Fatalf("GenerateConfigJSONSchema() error = %v", err)
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("GenerateConfigJSONSchema() is not JSON: %v", err)
+	}
+
+	return schemaValidator{root: root}
+}
+
+func TestGenerateConfigJSONSchema(t *testing.T) {
+	validator := generatedSchemaValidator(t)
+
+	if got := validator.root["$schema"]; got != jsonSchemaDialect {
+		t.Errorf("$schema = %v, want %s", got, jsonSchemaDialect)
+	}
+
+	defs := validator.root["$defs"].(map[string]interface{})
+	enums := map[string][]string{
+		"FilterConfig.operator":       SupportedFilterOperators(),
+		"MergeConfig.strategy":        SupportedMergeStrategies(),
+		"Aggregation.aggregateMethod": SupportedAggregateMethods(),
+	}
+	for key, want := range enums {
+		typeName, field, _ := strings.Cut(key, ".")
+		property := defs[typeName].(map[string]interface{})["properties"].(map[string]interface{})[field].(map[string]interface{})
+		got := make([]string, 0)
+		for _, value := range toSlice(property["enum"]) {
+			got = append(got, value.(string))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("enum of %s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestGenerateConfigJSONSchemaValidatesDocuments(t *testing.T) {
+	validator := generatedSchemaValidator(t)
+
+	tests := []struct {
+		name     string
+		document string
+		want     []string
+	}{
+		{
+			name: "good",
+			document: `{
+				"schemaVersion": 1,
+				"name": "sales",
+				"type": "csv",
+				"source": "sales.csv",
+				"maxRows": 1000,
+				"sourceOptions": {"delimiter": ";"},
+				"fillNull": [{"column": "amount", "method": "zero"}],
+				"filters": [
+					{"column": "region", "operator": "in", "values": ["east", "west"], "logicalOperator": "and"},
+					{"column": "tags", "operator": "containsAny", "values": ["vip"], "delimiter": ";", "logicalOperator": "and"},
+					{"column": "date", "operator": "gte", "value": "now-30d", "valueType": "date", "logicalOperator": "and"}
+				],
+				"mergeColumns": [
+					{"firstColumn": "first", "secondColumn": "last", "separator": " "},
+					{"columns": ["phone", "mobile"], "strategy": "coalesce"}
+				],
+				"aggregations": [{
+					"groupingColumns": ["region"],
+					"aggregations": [
+						{"column": "amount", "aggregateMethod": "sum", "resultName": "total"},
+						{"column": "price", "aggregateMethod": "weightedAvg", "weightColumn": "quantity"}
+					]
+				}],
+				"outputFormat": "json",
+				"outputOptions": {"indent": ""}
+			}`,
+			want: []string{},
+		},
+		{
+			name: "bad",
+			document: `{
+				"schemaVersion": 99,
+				"type": "csv",
+				"maxRows": -1,
+				"filters": [
+					{"column": "region", "operator": "like", "value": "east", "logicalOperator": "and"},
+					{"column": "region", "operator": "in", "values": ["east"], "valuesFile": "regions.txt", "logicalOperator": "xor"},
+					{"column": "tags", "operator": "containsAll", "values": ["a"], "logicalOperator": "and"},
+					{"column": "amount", "operator": "gt", "logicalOperator": "and"}
+				],
+				"mergeColumns": [{"firstColumn": "first", "strategy": "concat"}],
+				"aggregations": [{
+					"groupingColumns": [],
+					"aggregations": [{"column": "price", "aggregateMethod": "weightedAvg", "approx": true}]
+				}],
+				"unknown": true
+			}`,
+			want: []string{
+				": missing source",
+				": unknown property unknown",
+				"/schemaVersion: 99 is greater than 1",
+				"/maxRows: -1 is less than 0",
+				"/filters/0/operator: like is not one of " + fmt.Sprint(SupportedFilterOperators()),
+				"/filters/1/logicalOperator: xor is not one of " + fmt.Sprint(SupportedLogicalOperators()),
+				"/filters/1: matches 2 schemas of oneOf, want 1",
+				"/filters/2: missing delimiter",
+				"/filters/3: missing value",
+				"/mergeColumns/0: missing secondColumn",
+				"/aggregations/0/groupingColumns: fewer than 1 items",
+				"/aggregations/0/aggregations/0: unknown property approx",
+				"/aggregations/0/aggregations/0: missing weightColumn",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var document interface{}
+			if err := json.Unmarshal([]byte(tt.document), &document); err != nil {
+				t.Fatalf("document is not JSON: %v", err)
+			}
+
+			got := validator.validate(validator.root, document, "")
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("violations = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGenerateConfigJSONSchemaAgreesWithValidate(t *testing.T) {
+	validator := generatedSchemaValidator(t)
+
+	documents := map[string]bool{
+		`{"type": "csv", "source": "a.csv"}`: true,
+		`{"type": "csv", "source": "a.csv", "mergeColumns": [{"columns": ["a", "b"], "strategy": "coalesce"}]}`: true,
+		`{"type": "csv", "source": "a.csv", "mergeColumns": [{"firstColumn": "a", "strategy": "coalesce"}]}`:    false,
+		`{"type": "csv", "source": "a.csv", "fillNull": [{"column": "a"}]}`:                                     false,
+		`{"type": "csv", "source": "a.csv", "casts": [{"column": "a", "to": "decimal"}]}`:                       false,
+	}
+
+	for document, valid := range documents {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(document), &decoded); err != nil {
+			t.Fatalf("document is not JSON: %v", err)
+		}
+		violations := validator.validate(validator.root, decoded, "")
+
+		config := &Config{}
+		err := config.FromJSON(document)
+		if (len(violations) == 0) != valid || (err == nil) != valid {
+			t.Errorf("%s: schema violations = %q, Validate() error = %v, want valid %v", document, violations, err, valid)
+		}
+	}
+}
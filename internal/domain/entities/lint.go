@@ -0,0 +1,152 @@
+package entities
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The kinds of the LintFinding
+const (
+	LintContradictoryRange  = "contradictoryRange"
+	LintDuplicateFilter     = "duplicateFilter"
+	LintResultNameCollision = "resultNameCollision"
+)
+
+// LintFinding is an advisory finding of Config.Lint. Unlike the errors of Validate, the Config still runs.
+type LintFinding struct {
+	Field   string `json:"field"`   // Field points to the offending configuration, e.g. "filter[1]"
+	Kind    string `json:"kind"`    // Kind is one of the Lint* kinds
+	Message string `json:"message"` // Message describes the finding
+}
+
+// String returns the human-readable description of the finding.
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s", f.Field, f.Message)
+}
+
+// Lint reports the suspicious parts of the Config that are valid but likely mistakes, without modifying the Config:
+// - Contradictory numeric ranges on the same column within an AND chain of the filters, which match no rows
+// (e.g. amount gt 100 and amount lt 50, or amount eq 1 and amount eq 2)
+// - Filters exactly duplicating a preceding filter
+// - Aggregation results named after a grouping or passthrough column, which Aggregate rejects
+func (c *Config) Lint() []LintFinding {
+	findings := make([]LintFinding, 0)
+	findings = append(findings, lintFilters("filter", c.Filters)...)
+	findings = append(findings, lintFilters("postFilter", c.PostFilters)...)
+
+	for i, aggregation := range c.Aggregations {
+		for j, a := range aggregation.Aggregations {
+			resultName := a.ResultName
+			if resultName == "" {
+				resultName = a.Column + "_" + a.AggregateMethod
+			}
+			if slices.Contains(aggregation.GroupingColumns, resultName) {
+				findings = append(findings, LintFinding{
+					Field:   fmt.Sprintf("aggregation[%d].aggregations[%d]", i, j),
+					Kind:    LintResultNameCollision,
+					Message: fmt.Sprintf("result name '%s' collides with a grouping column", resultName),
+				})
+			}
+			if slices.Contains(aggregation.PassthroughColumns, resultName) {
+				findings = append(findings, LintFinding{
+					Field:   fmt.Sprintf("aggregation[%d].aggregations[%d]", i, j),
+					Kind:    LintResultNameCollision,
+					Message: fmt.Sprintf("result name '%s' collides with a passthrough column", resultName),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// numericRange is the range of the values a column can take to match an AND chain of the filters
+type numericRange struct {
+	lower, upper         float64
+	hasLower, hasUpper   bool
+	lowerOpen, upperOpen bool
+}
+
+// narrow intersects the range with the bound of the operator. Returns false if the operator doesn't bound the range.
+func (r *numericRange) narrow(operator string, value float64) bool {
+	switch operator {
+	case "gt", "gte":
+		if !r.hasLower || value > r.lower || (value == r.lower && operator == "gt") {
+			r.lower, r.hasLower, r.lowerOpen = value, true, operator == "gt"
+		}
+	case "lt", "lte":
+		if !r.hasUpper || value < r.upper || (value == r.upper && operator == "lt") {
+			r.upper, r.hasUpper, r.upperOpen = value, true, operator == "lt"
+		}
+	case "eq":
+		r.narrow("gte", value)
+		r.narrow("lte", value)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// empty reports whether no value is in the range.
+func (r *numericRange) empty() bool {
+	if !r.hasLower || !r.hasUpper {
+		return false
+	}
+
+	return r.lower > r.upper || (r.lower == r.upper && (r.lowerOpen || r.upperOpen))
+}
+
+// lintFilters reports the contradictory ranges and the duplicates of the filters whose fields are named after prefix.
+func lintFilters(prefix string, filters []FilterConfig) []LintFinding {
+	findings := make([]LintFinding, 0)
+
+	for i := range filters {
+		for j := 0; j < i; j++ {
+			if sameFilter(&filters[i], &filters[j]) {
+				findings = append(findings, LintFinding{
+					Field:   fmt.Sprintf("%s[%d]", prefix, i),
+					Kind:    LintDuplicateFilter,
+					Message: fmt.Sprintf("filter duplicates %s[%d]", prefix, j),
+				})
+				break
+			}
+		}
+	}
+
+	// The ranges of the columns in the current AND chain, and the column reported already in the chain
+	ranges := make(map[string]*numericRange)
+	reported := make(map[string]bool)
+	for i := range filters {
+		filter := &filters[i]
+		if number, ok := filter.NumberValue(); ok && !IsListOperator(filter.Operator) {
+			r, exists := ranges[filter.Column]
+			if !exists {
+				r = &numericRange{}
+				ranges[filter.Column] = r
+			}
+			if r.narrow(filter.Operator, number) && r.empty() && !reported[filter.Column] {
+				reported[filter.Column] = true
+				findings = append(findings, LintFinding{
+					Field:   fmt.Sprintf("%s[%d]", prefix, i),
+					Kind:    LintContradictoryRange,
+					Message: fmt.Sprintf("filter contradicts the preceding filters on column '%s' combined with and, so the chain matches no rows", filter.Column),
+				})
+			}
+		}
+
+		// "or" closes the AND chain (the LogicalOperator of the last filter is ignored)
+		if filter.LogicalOperator == "or" {
+			ranges = make(map[string]*numericRange)
+			reported = make(map[string]bool)
+		}
+	}
+
+	return findings
+}
+
+// sameFilter reports whether the filters compare the same column in the same way, ignoring the LogicalOperator.
+func sameFilter(a, b *FilterConfig) bool {
+	return a.Column == b.Column && a.Operator == b.Operator && a.Value == b.Value && a.ValueType == b.ValueType &&
+		a.ValuesFile == b.ValuesFile && slices.Equal(a.Values, b.Values) && a.Delimiter == b.Delimiter
+}
@@ -0,0 +1,111 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+// andFilter returns the numeric filter chained with "and".
+func andFilter(column, operator, value string) FilterConfig {
+	return FilterConfig{Column: column, Operator: operator, Value: value, LogicalOperator: "and"}
+}
+
+func TestConfigLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []LintFinding
+	}{
+		{
+			name: "clean",
+			config: Config{
+				Filters: []FilterConfig{andFilter("amount", "gt", "50"), andFilter("amount", "lt", "100"), andFilter("region", "eq", "east")},
+				Aggregations: []AggregationConfig{{
+					GroupingColumns: []string{"region"},
+					Aggregations:    []Aggregation{{Column: "amount", AggregateMethod: "sum"}},
+				}},
+			},
+			want: []LintFinding{},
+		},
+		{
+			name:   "contradictory range",
+			config: Config{Filters: []FilterConfig{andFilter("amount", "gt", "100"), andFilter("amount", "lt", "50")}},
+			want: []LintFinding{{
+				Field:   "filter[1]",
+				Kind:    LintContradictoryRange,
+				Message: "filter contradicts the preceding filters on column 'amount' combined with and, so the chain matches no rows",
+			}},
+		},
+		{
+			name:   "contradictory equalities",
+			config: Config{PostFilters: []FilterConfig{andFilter("amount", "eq", "1"), andFilter("amount", "eq", "2")}},
+			want: []LintFinding{{
+				Field:   "postFilter[1]",
+				Kind:    LintContradictoryRange,
+				Message: "filter contradicts the preceding filters on column 'amount' combined with and, so the chain matches no rows",
+			}},
+		},
+		{
+			name:   "open bounds of the same value",
+			config: Config{Filters: []FilterConfig{andFilter("amount", "gte", "10"), andFilter("amount", "lt", "10")}},
+			want: []LintFinding{{
+				Field:   "filter[1]",
+				Kind:    LintContradictoryRange,
+				Message: "filter contradicts the preceding filters on column 'amount' combined with and, so the chain matches no rows",
+			}},
+		},
+		{
+			name:   "closed bounds of the same value",
+			config: Config{Filters: []FilterConfig{andFilter("amount", "gte", "10"), andFilter("amount", "lte", "10")}},
+			want:   []LintFinding{},
+		},
+		{
+			name: "ranges in different chains",
+			config: Config{Filters: []FilterConfig{
+				{Column: "amount", Operator: "gt", Value: "100", LogicalOperator: "or"},
+				andFilter("amount", "lt", "50"),
+			}},
+			want: []LintFinding{},
+		},
+		{
+			name:   "duplicate filter",
+			config: Config{Filters: []FilterConfig{andFilter("region", "eq", "east"), andFilter("amount", "gt", "1"), andFilter("region", "eq", "east")}},
+			want: []LintFinding{{
+				Field:   "filter[2]",
+				Kind:    LintDuplicateFilter,
+				Message: "filter duplicates filter[0]",
+			}},
+		},
+		{
+			name: "result name collisions",
+			config: Config{Aggregations: []AggregationConfig{{
+				GroupingColumns:    []string{"region", "amount_sum"},
+				PassthroughColumns: []string{"label"},
+				Aggregations: []Aggregation{
+					{Column: "amount", AggregateMethod: "sum"},
+					{Column: "amount", AggregateMethod: "max", ResultName: "label"},
+				},
+			}}},
+			want: []LintFinding{
+				{
+					Field:   "aggregation[0].aggregations[0]",
+					Kind:    LintResultNameCollision,
+					Message: "result name 'amount_sum' collides with a grouping column",
+				},
+				{
+					Field:   "aggregation[0].aggregations[1]",
+					Kind:    LintResultNameCollision,
+					Message: "result name 'label' collides with a passthrough column",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Lint(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// heapObjectsMetric is the runtime metric of the bytes of the heap objects, the same as MemStats.Alloc
+// but read without stopping the world as runtime.ReadMemStats does
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// memorySampler reads the heap allocation at an interval in a goroutine and keeps the peak,
+// catching the transient allocations freed before the end of a step
+type memorySampler struct {
+	stop chan struct{}
+	done chan struct{}
+	mu   sync.Mutex
+	peak uint64
+}
+
+// startMemorySampler starts sampling the heap allocation every interval with the initial peak.
+func startMemorySampler(interval time.Duration, initial uint64) *memorySampler {
+	sampler := &memorySampler{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+		peak: initial,
+	}
+
+	go sampler.run(interval)
+
+	return sampler
+}
+
+// run samples the heap allocation until Stop is called.
+func (s *memorySampler) run(interval time.Duration) {
+	defer close(s.done)
+
+	samples := []metrics.Sample{{Name: heapObjectsMetric}}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			metrics.Read(samples)
+			if samples[0].Value.Kind() != metrics.KindUint64 {
+				continue
+			}
+
+			s.mu.Lock()
+			s.peak = max(s.peak, samples[0].Value.Uint64())
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Stop stops the sampling and returns the peak heap allocation sampled since the start.
+func (s *memorySampler) Stop() uint64 {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.peak
+}
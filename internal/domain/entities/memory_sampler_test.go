@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// transientSink keeps the transient allocation of TestStepPeakMemory reachable until it is sampled
+var transientSink []byte
+
+// sampledPeak returns the peak sampled so far by the sampler of the running step.
+func sampledPeak(entry *PerformanceEntry) uint64 {
+	entry.sampler.mu.Lock()
+	defer entry.sampler.mu.Unlock()
+
+	return entry.sampler.peak
+}
+
+func TestStepPeakMemory(t *testing.T) {
+	const transient = 64 << 20
+	processing := NewProcessing(numberFrame(3), "peak")
+	processing.SetMemorySampleInterval(time.Millisecond)
+
+	entry := processing.StartStep("transient", 3)
+	transientSink = make([]byte, transient)
+	// The allocation is kept until the sampler sees it, so the test doesn't depend on the scheduling of the sampler
+	for deadline := time.Now().Add(5 * time.Second); sampledPeak(entry) < entry.MemoryBeforeBytes+transient/2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("sampled peak = %d, want the transient allocation of %d sampled", sampledPeak(entry), transient)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	transientSink = nil
+	runtime.GC()
+	processing.EndStep(entry, 3)
+
+	step := processing.Metadata.StepPerformance[0]
+	if step.PeakMemoryBytes <= step.MemoryAfterBytes {
+		t.Errorf("PeakMemoryBytes = %d, want more than the end-of-step MemoryAfterBytes %d", step.PeakMemoryBytes, step.MemoryAfterBytes)
+	}
+	if step.PeakMemoryBytes < step.MemoryBeforeBytes+transient/2 {
+		t.Errorf("PeakMemoryBytes = %d, want the transient allocation of %d over MemoryBeforeBytes %d", step.PeakMemoryBytes, transient, step.MemoryBeforeBytes)
+	}
+	if got := processing.Metadata.MemoryStats.PeakAllocBytes; got < step.PeakMemoryBytes {
+		t.Errorf("PeakAllocBytes = %d, want at least the PeakMemoryBytes %d of the step", got, step.PeakMemoryBytes)
+	}
+}
+
+func TestStepPeakMemoryDisabled(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Millisecond} {
+		processing := NewProcessing(numberFrame(3), "peak")
+		processing.SetMemorySampleInterval(interval)
+
+		entry := processing.StartStep("step", 3)
+		if entry.sampler != nil {
+			t.Errorf("StartStep() with the interval %v started a sampler, want no sampling", interval)
+		}
+		processing.EndStep(entry, 3)
+
+		step := processing.Metadata.StepPerformance[0]
+		if step.PeakMemoryBytes != 0 {
+			t.Errorf("PeakMemoryBytes with the interval %v = %d, want 0", interval, step.PeakMemoryBytes)
+		}
+		data, err := json.Marshal(step)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		if strings.Contains(string(data), "peakMemoryBytes") {
+			t.Errorf("json.Marshal() = %s, want peakMemoryBytes omitted when not sampled", data)
+		}
+	}
+}
+
+func TestMemorySamplerKeepsInitialPeak(t *testing.T) {
+	// The initial peak above any real allocation is kept, and Stop ends the goroutine
+	sampler := startMemorySampler(time.Millisecond, 1<<62)
+	time.Sleep(5 * time.Millisecond)
+	if got := sampler.Stop(); got != 1<<62 {
+		t.Errorf("Stop() = %d, want the initial peak %d", got, uint64(1<<62))
+	}
+}
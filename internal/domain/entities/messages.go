@@ -0,0 +1,190 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Locale represents the language of the validation messages and the generated names
+type Locale string
+
+const (
+	LocaleEnglish  Locale = "en"
+	LocaleJapanese Locale = "ja"
+)
+
+// MessageKey identifies a localizable message
+type MessageKey string
+
+// The keys of the localizable messages
+const (
+	MessageUntitledConfigName       MessageKey = "untitledConfigName"
+	MessageUnsupportedSchemaVersion MessageKey = "unsupportedSchemaVersion"
+	MessageTypeRequired             MessageKey = "typeRequired"
+	MessageRequired                 MessageKey = "required"
+	MessageRequiredFor              MessageKey = "requiredFor"
+	MessageNotNegative              MessageKey = "notNegative"
+	MessageCannotBeEmpty            MessageKey = "cannotBeEmpty"
+	MessageInvalidChoice            MessageKey = "invalidChoice"
+	MessageCannotBeSetWith          MessageKey = "cannotBeSetWith"
+	MessageMustDiffer               MessageKey = "mustDiffer"
+	MessageOnlySupportedFor         MessageKey = "onlySupportedFor"
+	MessageCannotRead               MessageKey = "cannotRead"
+	MessageSingleCharacter          MessageKey = "singleCharacter"
+	MessageDuplicateTarget          MessageKey = "duplicateTarget"
+	MessageAlreadyIn                MessageKey = "alreadyIn"
+	MessageInvalidRelativeDate      MessageKey = "invalidRelativeDate"
+	MessageAtMost                   MessageKey = "atMost"
+)
+
+// messageCatalogs holds the format strings of the messages for each locale
+var messageCatalogs = map[Locale]map[MessageKey]string{
+	LocaleEnglish: {
+		MessageUntitledConfigName:       "UntitledConfig_",
+		MessageUnsupportedSchemaVersion: "unsupported schemaVersion %d, use MigrateConfig to load older versions",
+		MessageTypeRequired:             "type is required, built-in types are: %v",
+		MessageRequired:                 "%s is required",
+		MessageRequiredFor:              "%s is required for %s",
+		MessageNotNegative:              "%s must not be negative, got %d",
+		MessageCannotBeEmpty:            "%s cannot be empty",
+		MessageInvalidChoice:            "invalid %s '%s', %s must be one of %v",
+		MessageCannotBeSetWith:          "%s cannot be set with %s '%s'",
+		MessageMustDiffer:               "%s must be different from %s '%s'",
+		MessageOnlySupportedFor:         "%s is only supported for %s",
+		MessageCannotRead:               "cannot read %s '%s': %v",
+		MessageSingleCharacter:          "%s must be a single character, got '%s'",
+		MessageDuplicateTarget:          "'%s' and '%s' are both mapped to '%s'",
+		MessageAlreadyIn:                "%s '%s' is already in %s",
+		MessageInvalidRelativeDate:      "invalid relative date '%s', expected now, today, startOfWeek, startOfMonth, or startOfYear optionally followed by an offset like -30d (units: h for now only, d, w, m, y)",
+		MessageAtMost:                   "%s can have at most %d values, got %d",
+	},
+	LocaleJapanese: {
+		MessageUntitledConfigName:       "無題の設定_",
+		MessageUnsupportedSchemaVersion: "schemaVersion %d には対応していません。古いバージョンは MigrateConfig で読み込んでください",
+		MessageTypeRequired:             "type は必須です。組み込みのタイプ: %v",
+		MessageRequired:                 "%s は必須です",
+		MessageRequiredFor:              "%[2]s には %[1]s が必須です",
+		MessageNotNegative:              "%s に負の値は指定できません（指定値: %d）",
+		MessageCannotBeEmpty:            "%s を空にすることはできません",
+		MessageInvalidChoice:            "%[1]s '%[2]s' は無効です。%[3]s には %[4]v のいずれかを指定してください",
+		MessageCannotBeSetWith:          "%[2]s '%[3]s' では %[1]s を指定できません",
+		MessageMustDiffer:               "%[1]s には %[2]s '%[3]s' と異なる値を指定してください",
+		MessageOnlySupportedFor:         "%[1]s は %[2]s でのみ指定できます",
+		MessageCannotRead:               "%[1]s '%[2]s' を読み込めません: %[3]v",
+		MessageSingleCharacter:          "%[1]s には 1 文字を指定してください（指定値: '%[2]s'）",
+		MessageDuplicateTarget:          "'%[1]s' と '%[2]s' が同じ '%[3]s' に対応付けられています",
+		MessageAlreadyIn:                "%[1]s の '%[2]s' は既に %[3]s に含まれています",
+		MessageInvalidRelativeDate:      "相対日付 '%s' は無効です。now、today、startOfWeek、startOfMonth、startOfYear のいずれかに -30d のようなオフセットを任意で続けてください（単位: h は now のみ、d、w、m、y）",
+		MessageAtMost:                   "%[1]s に指定できる値は %[2]d 個までです（指定数: %[3]d）",
+	},
+}
+
+// Messages renders the localized messages.
+type Messages interface {
+	// Message renders the message of the key with the arguments.
+	Message(key MessageKey, args ...interface{}) string
+}
+
+// catalogMessages implements Messages over a message catalog falling back to English for the missing keys
+type catalogMessages struct {
+	catalog map[MessageKey]string
+}
+
+// Message implements Messages
+func (m catalogMessages) Message(key MessageKey, args ...interface{}) string {
+	format, ok := m.catalog[key]
+	if !ok {
+		format = messageCatalogs[LocaleEnglish][key]
+	}
+
+	return fmt.Sprintf(format, args...)
+}
+
+// SupportedLocales returns the locales having the message catalogs.
+func SupportedLocales() []Locale {
+	return []Locale{LocaleEnglish, LocaleJapanese}
+}
+
+// MessagesFor returns the Messages of the locale. Unsupported locales fall back to English.
+func MessagesFor(locale Locale) Messages {
+	catalog, ok := messageCatalogs[locale]
+	if !ok {
+		catalog = messageCatalogs[LocaleEnglish]
+	}
+
+	return catalogMessages{catalog: catalog}
+}
+
+// ValidationError represents a localizable validation failure of the Config.
+// Error renders the message in English; use Localize or LocalizeError for the other locales.
+type ValidationError struct {
+	Key  MessageKey
+	Args []interface{}
+}
+
+// newValidationError creates a new ValidationError of the message key.
+func newValidationError(key MessageKey, args ...interface{}) *ValidationError {
+	return &ValidationError{
+		Key:  key,
+		Args: args,
+	}
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Localize(LocaleEnglish)
+}
+
+// Localize renders the message in the locale.
+func (e *ValidationError) Localize(locale Locale) string {
+	return MessagesFor(locale).Message(e.Key, e.Args...)
+}
+
+// LocalizeError renders the error message in the locale.
+// The ValidationError in the chain is localized, and the field path prefixes (e.g. "filter[0]: ") are kept.
+// The other errors are rendered as they are.
+func LocalizeError(err error, locale Locale) string {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return err.Error()
+	}
+
+	message := err.Error()
+	english := validationErr.Error()
+	if !strings.HasSuffix(message, english) {
+		return message
+	}
+
+	return strings.TrimSuffix(message, english) + validationErr.Localize(locale)
+}
+
+// localizedError is an error whose message is rendered in a locale, wrapping the original error
+type localizedError struct {
+	message string
+	cause   error
+}
+
+// Error implements the error interface
+func (e *localizedError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the original error
+func (e *localizedError) Unwrap() error {
+	return e.cause
+}
+
+// ValidateWithLocale validates the Config like Validate, but generates the untitled name
+// and renders the error message in the locale. The returned error wraps the error of Validate.
+func (c *Config) ValidateWithLocale(locale Locale) error {
+	if c.Name == "" {
+		c.Name = generateUntitledName(locale)
+	}
+
+	if err := c.Validate(); err != nil {
+		return &localizedError{message: LocalizeError(err, locale), cause: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"bytes"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"strings"
+	"testing"
+)
+
+func TestValidateWithLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale Locale
+		want   string
+	}{
+		{
+			name:   "japanese",
+			locale: LocaleJapanese,
+			want:   "filter[0]: operator 'like' は無効です。operator には " + fmtList(SupportedFilterOperators()) + " のいずれかを指定してください",
+		},
+		{
+			name:   "english",
+			locale: LocaleEnglish,
+			want:   "filter[0]: invalid operator 'like', operator must be one of " + fmtList(SupportedFilterOperators()),
+		},
+		{
+			name:   "unsupported locale falls back to english",
+			locale: Locale("fr"),
+			want:   "filter[0]: invalid operator 'like', operator must be one of " + fmtList(SupportedFilterOperators()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Type:    "csv",
+				Source:  "sales.csv",
+				Filters: []FilterConfig{{Column: "region", Operator: "like", Value: "east", LogicalOperator: "and"}},
+			}
+
+			err := config.ValidateWithLocale(tt.locale)
+			if err == nil {
+				t.Fatal("ValidateWithLocale() error = nil, want the error of the invalid operator")
+			}
+			if got := err.Error(); got != tt.want {
+				t.Errorf("ValidateWithLocale() error = %q, want %q", got, tt.want)
+			}
+
+			// The localized error still wraps the ValidationError of Validate
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Key != MessageInvalidChoice {
+				t.Errorf("ValidateWithLocale() error = %v, want it to wrap the ValidationError of %s", err, MessageInvalidChoice)
+			}
+		})
+	}
+}
+
+func TestValidateWithLocaleUntitledName(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		prefix string
+	}{
+		{locale: LocaleJapanese, prefix: "無題の設定_"},
+		{locale: LocaleEnglish, prefix: "UntitledConfig_"},
+	}
+
+	for _, tt := range tests {
+		config := &Config{Type: "csv", Source: "sales.csv"}
+		if err := config.ValidateWithLocale(tt.locale); err != nil {
+			t.Fatalf("ValidateWithLocale(%s) error = %v", tt.locale, err)
+		}
+		if !strings.HasPrefix(config.Name, tt.prefix) || len(config.Name) == len(tt.prefix) {
+			t.Errorf("ValidateWithLocale(%s) name = %q, want %q followed by the random characters", tt.locale, config.Name, tt.prefix)
+		}
+	}
+}
+
+func TestValidateUntitledNameReproducible(t *testing.T) {
+	names := make([]string, 2)
+	for i := range names {
+		restore := utils.SetRandomReader(bytes.NewReader(bytes.Repeat([]byte{0xab}, 10)))
+		config := &Config{Type: "csv", Source: "sales.csv"}
+		err := config.Validate()
+		restore()
+		if err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		names[i] = config.Name
+	}
+
+	want := "UntitledConfig_" + strings.Repeat("ab", 10)
+	if names[0] != want || names[1] != want {
+		t.Errorf("Validate() names = %q, want %q every time", names, want)
+	}
+}
+
+func TestLocalizeErrorKeepsOtherErrors(t *testing.T) {
+	err := errors.New("failed to unmarshal")
+	if got := LocalizeError(err, LocaleJapanese); got != err.Error() {
+		t.Errorf("LocalizeError() = %q, want the message as it is", got)
+	}
+}
+
+func TestMessageCatalogsComplete(t *testing.T) {
+	english := messageCatalogs[LocaleEnglish]
+	for _, locale := range SupportedLocales() {
+		catalog := messageCatalogs[locale]
+		for key := range english {
+			if catalog[key] == "" {
+				t.Errorf("catalog of %s has no message of %s", locale, key)
+			}
+		}
+	}
+}
+
+// fmtList formats the list as the %v verb of the messages does.
+func fmtList(values []string) string {
+	return "[" + strings.Join(values, " ") + "]"
+}
@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"strconv"
+	"strings"
+)
+
+// CurrentSchemaVersion represents the Config schema version this package reads and writes.
+const CurrentSchemaVersion = 1
+
+// configMigration transforms a raw Config document from one schema version to the next one in place.
+type configMigration func(document map[string]interface{}) error
+
+// configMigrations holds the forward migrations keyed by the source schema version.
+// The migration registered at key N converts a version N document into a version N+1 document,
+// and must be added together with incrementing the CurrentSchemaVersion.
+var configMigrations = map[int]configMigration{}
+
+// MigrateConfig detects the schema version of the raw JSON document and applies forward migrations
+// up to the CurrentSchemaVersion. A document without "schemaVersion" is treated as version 1.
+// The returned Config is not validated, so the caller should call Validate.
+// The unknown fields are ignored for the forward compatibility (see MigrateConfigStrict).
+func MigrateConfig(raw []byte) (*Config, error) {
+	return migrateConfig(raw, false)
+}
+
+// MigrateConfigStrict is the same as MigrateConfig except that the unknown fields of the migrated document
+// (e.g. the misspelled "filtres") are rejected with a ConfigurationError naming the field.
+func MigrateConfigStrict(raw []byte) (*Config, error) {
+	return migrateConfig(raw, true)
+}
+
+// migrateConfig migrates the raw JSON document, disallowing the unknown fields if strict is true.
+func migrateConfig(raw []byte, strict bool) (*Config, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON to Config: %w", err)
+	}
+
+	version := 1
+	if rawVersion, ok := document["schemaVersion"]; ok {
+		number, ok := rawVersion.(float64)
+		if !ok || number != float64(int(number)) {
+			return nil, fmt.Errorf("schemaVersion must be an integer, got %v", rawVersion)
+		}
+		version = int(number)
+	}
+
+	if version < 1 {
+		return nil, fmt.Errorf("invalid schemaVersion %d, schemaVersion must be 1 or later", version)
+	}
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("unsupported schemaVersion %d, the latest supported version is %d", version, CurrentSchemaVersion)
+	}
+
+	for v := version; v < CurrentSchemaVersion; v++ {
+		migration, ok := configMigrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration found from schemaVersion %d", v)
+		}
+		if err := migration(document); err != nil {
+			return nil, fmt.Errorf("failed to migrate schemaVersion %d to %d: %w", v, v+1, err)
+		}
+	}
+	document["schemaVersion"] = CurrentSchemaVersion
+
+	migrated, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated Config: %w", err)
+	}
+
+	config := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(migrated))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(config); err != nil {
+		// The decoder reports the unknown field only by the message, e.g. json: unknown field "filtres"
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if unquoted, err := strconv.Unquote(field); err == nil {
+				field = unquoted
+			}
+			return nil, domainerrors.NewConfigurationError(field, fmt.Sprintf("unknown field '%s'", field), err)
+		}
+		return nil, fmt.Errorf("failed to unmarshal JSON to Config: %w", err)
+	}
+
+	return config, nil
+}
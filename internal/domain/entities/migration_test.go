@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{name: "without version", document: `{"name": "sales", "type": "csv", "source": "sales.csv"}`},
+		{name: "version 1", document: `{"schemaVersion": 1, "name": "sales", "type": "csv", "source": "sales.csv"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := MigrateConfig([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("MigrateConfig() error = %v", err)
+			}
+			if config.SchemaVersion != CurrentSchemaVersion {
+				t.Errorf("SchemaVersion = %d, want %d", config.SchemaVersion, CurrentSchemaVersion)
+			}
+			if config.Name != "sales" || config.Type != "csv" || config.Source != "sales.csv" {
+				t.Errorf("MigrateConfig() = %+v, want the fields of the document", config)
+			}
+			if err := config.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateConfigRejectsVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		message  string
+	}{
+		{name: "future version", document: `{"schemaVersion": 99, "type": "csv"}`, message: "unsupported schemaVersion 99"},
+		{name: "zero version", document: `{"schemaVersion": 0, "type": "csv"}`, message: "invalid schemaVersion 0"},
+		{name: "fractional version", document: `{"schemaVersion": 1.5, "type": "csv"}`, message: "schemaVersion must be an integer"},
+		{name: "string version", document: `{"schemaVersion": "1", "type": "csv"}`, message: "schemaVersion must be an integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MigrateConfig([]byte(tt.document))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("MigrateConfig() error = %v, want it to contain %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestFromJSONRejectsFutureVersion(t *testing.T) {
+	config := &Config{}
+	if err := config.FromJSON(`{"schemaVersion": 2, "type": "csv", "source": "sales.csv"}`); err == nil {
+		t.Error("FromJSON() error = nil, want the error of the unsupported version")
+	}
+}
@@ -0,0 +1,10 @@
+package entities
+
+import "github.com/go-gota/gota/series"
+
+// IsNull checks if the element is null, the missing value or the blank string.
+// Blank strings are treated as null because CSV and Google Sheets represent missing values as blank cells.
+// This is the null of every stage of the processing and of the column statistics.
+func IsNull(element series.Element) bool {
+	return element.IsNA() || (element.Type() == series.String && element.String() == "")
+}
@@ -1,24 +1,44 @@
 package entities
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
 	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"io"
+	"math"
+	"net/url"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Processing represents the result of a data operation, tracking metadata and excluding non-JSON marshaled data.
+// RejectedRows holds the rows diverted from the processing because of their bad data,
+// each of which is the values of the row followed by the reason of the rejection.
 type Processing struct {
 	// Data doesn't marshal to JSON well
-	Data     *dataframe.DataFrame `json:"-"`
-	Metadata ProcessingMetadata   `json:"metadata"`
+	Data         *dataframe.DataFrame `json:"-"`
+	Metadata     ProcessingMetadata   `json:"metadata"`
+	RejectedRows [][]interface{}      `json:"rejectedRows,omitempty"`
+
+	memorySampleInterval time.Duration        // Interval of sampling the peak memory of each step (0 for no sampling)
+	source               *dataframe.DataFrame // Source data measured for the BytesProcessed by CompleteProcess, released then
 }
 
 // ProcessingMetadata holds metadata about the processing of data, including rows, filters, performance, and memory usage.
 type ProcessingMetadata struct {
+	RunID                 string             `json:"runId"`                  // Unique ID of the run
+	MergedRunIDs          []string           `json:"mergedRunIds,omitempty"` // IDs of the runs combined by MergeMetadata
 	SourceTotalRows       int                `json:"sourceTotalRows"`
 	FilteredTotalRows     int                `json:"filterTotalRows"`
+	RemovedDuplicateRows  int                `json:"removedDuplicateRows"`
 	AppliedFilters        []string           `json:"appliedFilters"`
 	PerformedAggregations []string           `json:"performedAggregations"`
 	PerformedMerges       []string           `json:"performedMerges"`
@@ -29,33 +49,60 @@ type ProcessingMetadata struct {
 	DataSource            string             `json:"dataSource"`
 	MemoryStats           MemoryStats        `json:"memoryStats"`
 	StepPerformance       []PerformanceEntry `json:"stepPerformance"`
+	RowsPerSecond         float64            `json:"rowsPerSecond"`             // Source rows processed per second
+	BytesProcessed        uint64             `json:"bytesProcessed"`            // Total bytes of the source cell values, measured by CompleteProcess
+	Warnings              []string           `json:"warnings,omitempty"`        // Recoverable errors and warnings the run continued past
+	Timeout               time.Duration      `json:"timeout,omitempty"`         // Time limit of the whole run (0 for unlimited)
+	RejectedRowCount      int                `json:"rejectedRowCount"`          // Rows diverted to RejectedRows because of bad data
+	Watermark             string             `json:"watermark,omitempty"`       // New maximum of the watermark column, the Since of the next run
+	EffectiveConfig       *Config            `json:"effectiveConfig,omitempty"` // Config with the defaults filled by Validate, to reproduce the run
+	RenamedHeaders        []HeaderRename     `json:"renamedHeaders,omitempty"`  // Source headers cleaned of invisible characters or renamed to unique names
+}
+
+// HeaderRename represents a header of the source renamed by the data source because it has invisible characters
+// or surrounding whitespace, or it is blank or duplicated.
+// The config refers to the column by the Name.
+type HeaderRename struct {
+	Position int    `json:"position"` // 1-based position of the column in the source
+	Header   string `json:"header"`   // Header as it is in the source
+	Name     string `json:"name"`     // Unique column name the header is renamed to
 }
 
 // MemoryStats represents memory statistics during program execution.
 // It includes details about peak memory usage, allocations, GC count, and memory usage growth percentage.
 type MemoryStats struct {
-	PeakAllocBytes        uint64  `json:"peakAllocBytes"`        // Peak allocation in bytes
-	PeakSysBytes          uint64  `json:"peakSysBytes"`          // Peak system memory obtained from OS
-	TotalAllocBytes       uint64  `json:"totalAllocBytes"`       // Total bytes allocated (even if freed)
-	FinalAllocBytes       uint64  `json:"finalAllocBytes"`       // Bytes allocated and not yet freed
-	NumGC                 uint32  `json:"numGC"`                 // Number of garbage collections
-	MemoryIncreasePercent float64 `json:"memoryIncreasePercent"` // Percentage increase in memory usage
+	PeakAllocBytes        uint64  `json:"peakAllocBytes"`            // Peak allocation in bytes
+	PeakSysBytes          uint64  `json:"peakSysBytes"`              // Peak system memory obtained from OS
+	TotalAllocBytes       uint64  `json:"totalAllocBytes"`           // Total bytes allocated (even if freed)
+	FinalAllocBytes       uint64  `json:"finalAllocBytes"`           // Bytes allocated and not yet freed
+	PreGCAllocBytes       uint64  `json:"preGCAllocBytes,omitempty"` // Bytes allocated before the GC forced on completion (0 if not forced)
+	NumGC                 uint32  `json:"numGC"`                     // Number of garbage collections
+	MemoryIncreasePercent float64 `json:"memoryIncreasePercent"`     // Percentage increase in memory usage
 }
 
 // PerformanceEntry represents a record of performance metrics for a specific processing step.
 // It includes details about timing, input/output rows, and memory usage.
+// The memory values are the heap allocation (runtime.MemStats.Alloc) at the start and the end of the step.
+// MemoryDeltaBytes can be negative because the GC may free memory during the step.
 type PerformanceEntry struct {
-	StepName         string        `json:"stepName"`
-	StartTime        time.Time     `json:"startTime"`
-	EndTime          time.Time     `json:"endTime"`
-	Duration         time.Duration `json:"duration"`
-	InputRows        int           `json:"inputRows"`
-	OutputRows       int           `json:"outputRows"`
-	MemoryUsageBytes uint64        `json:"memoryUsageBytes"`
+	StepName          string        `json:"stepName"`
+	StartTime         time.Time     `json:"startTime"`
+	EndTime           time.Time     `json:"endTime"`
+	Duration          time.Duration `json:"duration"`
+	InputRows         int           `json:"inputRows"`
+	OutputRows        int           `json:"outputRows"`
+	MemoryUsageBytes  uint64        `json:"memoryUsageBytes"`          // Heap allocation at the end of the step (same as MemoryAfterBytes)
+	MemoryBeforeBytes uint64        `json:"memoryBeforeBytes"`         // Heap allocation at the start of the step
+	MemoryAfterBytes  uint64        `json:"memoryAfterBytes"`          // Heap allocation at the end of the step
+	MemoryDeltaBytes  int64         `json:"memoryDeltaBytes"`          // MemoryAfterBytes - MemoryBeforeBytes
+	PeakMemoryBytes   uint64        `json:"peakMemoryBytes,omitempty"` // Peak heap allocation sampled during the step (0 when not sampled)
+
+	sampler *memorySampler // sampler samples the peak memory from StartStep to EndStep if the sampling is enabled
 }
 
 // NewProcessing initializes a new Processing instance with provided data and configuration name.
 // It records initial memory statistics, start time, and sets up metadata for tracking processing operations.
+// The start time, the end time, and the step times are read from utils.Now, which tests can fix by utils.SetClock.
 func NewProcessing(data *dataframe.DataFrame, configName string) *Processing {
 	var initMemStats runtime.MemStats
 	runtime.ReadMemStats(&initMemStats)
@@ -66,13 +113,15 @@ func NewProcessing(data *dataframe.DataFrame, configName string) *Processing {
 	}
 
 	return &Processing{
-		Data: data,
+		Data:   data,
+		source: data,
 		Metadata: ProcessingMetadata{
+			RunID:                 newRunID(),
 			SourceTotalRows:       totalRows,
 			AppliedFilters:        make([]string, 0),
 			PerformedAggregations: make([]string, 0),
 			PerformedMerges:       make([]string, 0),
-			StartTime:             time.Now(),
+			StartTime:             utils.Now(),
 			ConfigName:            configName,
 			MemoryStats: MemoryStats{
 				PeakAllocBytes:  initMemStats.Alloc,
@@ -85,6 +134,29 @@ func NewProcessing(data *dataframe.DataFrame, configName string) *Processing {
 	}
 }
 
+// newRunID generates a new unique ID of the run.
+func newRunID() string {
+	return utils.RandomString(8)
+}
+
+// dataFrameBytes returns the total byte length of the string representation of all cells in the DataFrame.
+// The cells are measured one by one, so the string cells are not copied.
+func dataFrameBytes(data *dataframe.DataFrame) uint64 {
+	if data == nil {
+		return 0
+	}
+
+	var total uint64
+	for _, column := range data.Names() {
+		values := data.Col(column)
+		for row := 0; row < values.Len(); row++ {
+			total += uint64(len(values.Elem(row).String()))
+		}
+	}
+
+	return total
+}
+
 // AddFilter appends a filter expression to the list of applied filters in the metadata of the Processing instance.
 func (p *Processing) AddFilter(filterExp string) {
 	p.Metadata.AppliedFilters = append(p.Metadata.AppliedFilters, filterExp)
@@ -98,7 +170,13 @@ func (p *Processing) AddAggregation(aggregationMethod, targetColumn string) {
 
 // AddMerge appends a merge description to the list of performed merges in the metadata of the Processing instance.
 func (p *Processing) AddMerge(firstColumn, secondColumn, strategy string) {
-	merge := fmt.Sprintf("%s + %s (%s)", firstColumn, secondColumn, strategy)
+	p.AddMergeOf([]string{firstColumn, secondColumn}, strategy)
+}
+
+// AddMergeOf records the merge of the columns by the strategy in the metadata of the Processing instance,
+// for the strategies merging any number of columns such as coalesce.
+func (p *Processing) AddMergeOf(columns []string, strategy string) {
+	merge := fmt.Sprintf("%s (%s)", strings.Join(columns, " + "), strategy)
 	p.Metadata.PerformedMerges = append(p.Metadata.PerformedMerges, merge)
 }
 
@@ -109,27 +187,158 @@ func (p *Processing) UpdateRows(originalRows, filteredRows int) {
 	p.Metadata.FilteredTotalRows = filteredRows
 }
 
+// SetSourceData sets the fetched data of the Processing instance and records its rows in the metadata.
+// The bytes of the data are measured by CompleteProcess.
+// It is used when the Processing instance is created before the fetch to measure the fetch as well.
+func (p *Processing) SetSourceData(data *dataframe.DataFrame) {
+	p.Data = data
+	p.source = data
+	p.Metadata.SourceTotalRows = p.GetRowCount()
+}
+
+// AddWarning appends a warning to the metadata of the Processing instance.
+func (p *Processing) AddWarning(warning string) {
+	p.Metadata.Warnings = append(p.Metadata.Warnings, warning)
+}
+
+// AddRejectedRow appends the rejected row followed by the reason to the RejectedRows of the Processing instance
+// and counts it in the metadata.
+func (p *Processing) AddRejectedRow(row []interface{}, reason string) {
+	p.RejectedRows = append(p.RejectedRows, append(slices.Clone(row), reason))
+	p.Metadata.RejectedRowCount++
+}
+
+// AddRenamedHeader records the source header at the 1-based position renamed to the name in the metadata of the Processing instance.
+func (p *Processing) AddRenamedHeader(position int, header, name string) {
+	p.Metadata.RenamedHeaders = append(p.Metadata.RenamedHeaders, HeaderRename{Position: position, Header: header, Name: name})
+}
+
+// SetWatermark records the new watermark for the next run in the metadata of the Processing instance.
+func (p *Processing) SetWatermark(watermark string) {
+	p.Metadata.Watermark = watermark
+}
+
+// SetEffectiveConfig records a copy of the validated config in the metadata of the Processing instance,
+// so the run can be reproduced with the defaults it resolved. The credentials in the Source URL are redacted.
+func (p *Processing) SetEffectiveConfig(config *Config) {
+	if config == nil {
+		p.Metadata.EffectiveConfig = nil
+		return
+	}
+
+	effective := config.clone()
+	if source, err := url.Parse(effective.Source); err == nil && source.User != nil {
+		effective.Source = source.Redacted()
+	}
+	p.Metadata.EffectiveConfig = effective
+}
+
+// SetRemovedDuplicateRows records the number of rows removed by the dedup stage in the metadata of the Processing instance.
+func (p *Processing) SetRemovedDuplicateRows(removedRows int) {
+	p.Metadata.RemovedDuplicateRows = removedRows
+}
+
+// StartStep starts measuring a processing step and returns the entry to pass to EndStep.
+// The entry is recorded in the StepPerformance of the metadata when the step ends.
+// If the memory sampling is enabled (see SetMemorySampleInterval), the heap allocation is sampled until EndStep.
+func (p *Processing) StartStep(stepName string, inputRows int) *PerformanceEntry {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	entry := &PerformanceEntry{
+		StepName:          stepName,
+		StartTime:         utils.Now(),
+		InputRows:         inputRows,
+		MemoryBeforeBytes: memStats.Alloc,
+	}
+	if p.memorySampleInterval > 0 {
+		entry.sampler = startMemorySampler(p.memorySampleInterval, memStats.Alloc)
+	}
+
+	return entry
+}
+
+// SetMemorySampleInterval enables sampling the heap allocation of each step started afterwards at the interval,
+// recording the peak in the PeakMemoryBytes of the step and the PeakAllocBytes of the run. 0 disables the sampling (default).
+// A short interval catches the shorter peaks at the cost of more overhead, e.g. 10ms.
+func (p *Processing) SetMemorySampleInterval(interval time.Duration) {
+	p.memorySampleInterval = max(interval, 0)
+}
+
+// EndStep finishes measuring the step started by StartStep and records it in the metadata of the Processing instance.
+func (p *Processing) EndStep(entry *PerformanceEntry, outputRows int) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	entry.EndTime = utils.Now()
+	entry.Duration = entry.EndTime.Sub(entry.StartTime)
+	entry.OutputRows = outputRows
+	entry.MemoryAfterBytes = memStats.Alloc
+	entry.MemoryUsageBytes = memStats.Alloc
+	entry.MemoryDeltaBytes = int64(entry.MemoryAfterBytes) - int64(entry.MemoryBeforeBytes)
+	if entry.sampler != nil {
+		entry.PeakMemoryBytes = max(entry.sampler.Stop(), memStats.Alloc)
+		entry.sampler = nil
+		p.Metadata.MemoryStats.PeakAllocBytes = max(p.Metadata.MemoryStats.PeakAllocBytes, entry.PeakMemoryBytes)
+	}
+
+	p.Metadata.StepPerformance = append(p.Metadata.StepPerformance, *entry)
+}
+
 // SetDataSourceInfo updates the data source information in the metadata of the Processing instance.
 func (p *Processing) SetDataSourceInfo(info string) {
 	p.Metadata.DataSource = info
 }
 
 // CompleteProcess finalizes processing by capturing end time, calculating processing time, and updating memory usage statistics.
+// The final memory stats include the garbage not collected yet, see CompleteProcessWithGC to exclude it.
 func (p *Processing) CompleteProcess() {
-	p.Metadata.EndTime = time.Now()
+	p.complete(false)
+}
+
+// CompleteProcessWithGC is the same as CompleteProcess except that it forces a garbage collection before reading
+// the final memory stats, so the FinalAllocBytes is the memory retained by the result rather than inflated by the garbage.
+// The allocation before the collection is recorded in PreGCAllocBytes, so PreGCAllocBytes - FinalAllocBytes is the transient memory.
+// The collection stops the world, which takes longer as the heap grows, so it is opt-in.
+func (p *Processing) CompleteProcessWithGC() {
+	p.complete(true)
+}
+
+// complete implements CompleteProcess and CompleteProcessWithGC.
+func (p *Processing) complete(forceGC bool) {
+	p.Metadata.EndTime = utils.Now()
 	p.Metadata.ProcessingTime = p.Metadata.EndTime.Sub(p.Metadata.StartTime)
 
+	// Guard against the zero duration to avoid the division by zero
+	if p.Metadata.ProcessingTime > 0 {
+		p.Metadata.RowsPerSecond = float64(p.Metadata.SourceTotalRows) / p.Metadata.ProcessingTime.Seconds()
+	}
+	// Measured here only once per run, and the source is released not to retain it with the result
+	if p.source != nil {
+		p.Metadata.BytesProcessed = dataFrameBytes(p.source)
+		p.source = nil
+	}
+
+	// The peak is still the allocation at the start here
+	initialAllocBytes := p.Metadata.MemoryStats.PeakAllocBytes
+
 	// Capture final memory stats
 	var memStats runtime.MemStats
+	if forceGC {
+		runtime.ReadMemStats(&memStats)
+		p.Metadata.MemoryStats.PreGCAllocBytes = memStats.Alloc
+		p.Metadata.MemoryStats.PeakAllocBytes = max(p.Metadata.MemoryStats.PeakAllocBytes, memStats.Alloc)
+		runtime.GC()
+	}
 	runtime.ReadMemStats(&memStats)
 	p.Metadata.MemoryStats.FinalAllocBytes = memStats.Alloc
 	p.Metadata.MemoryStats.TotalAllocBytes = memStats.TotalAlloc
 	p.Metadata.MemoryStats.NumGC = memStats.NumGC
 
-	// Calculate memory increase percentage
-	if p.Metadata.MemoryStats.PeakAllocBytes > 0 {
+	// Calculate memory increase percentage, which is negative when the GC freed more than the run retained
+	if initialAllocBytes > 0 {
 		p.Metadata.MemoryStats.MemoryIncreasePercent =
-			float64(memStats.Alloc-p.Metadata.MemoryStats.PeakAllocBytes) / float64(p.Metadata.MemoryStats.PeakAllocBytes) * 100
+			(float64(memStats.Alloc) - float64(initialAllocBytes)) / float64(initialAllocBytes) * 100
 	}
 
 	// Update peak values if final values are higher
@@ -142,6 +351,95 @@ func (p *Processing) CompleteProcess() {
 	}
 }
 
+// MergeMetadata combines the metadata of the other processings into the metadata of the Processing instance,
+// e.g. to consolidate the partitioned runs. The Data is left untouched.
+// - Row counts (including RejectedRowCount) and BytesProcessed are summed
+// - Applied filters, aggregations, and merges are unioned in order of appearance
+// - StepPerformance entries, Warnings, and RenamedHeaders are concatenated
+// - Peak memory stats take the maximum
+// - StartTime takes the earliest and EndTime takes the latest, and ProcessingTime and RowsPerSecond are recomputed
+// - RunID is replaced with a new parent ID, and the IDs of the combined runs are recorded in MergedRunIDs
+// - ConfigName and EffectiveConfig are kept, and the distinct data sources are joined with "; "
+// - Watermark takes the greatest, compared as numbers if both are numbers, otherwise as strings
+func (p *Processing) MergeMetadata(others ...*Processing) {
+	merged := &p.Metadata
+	mergedRunIDs := []string{merged.RunID}
+	dataSources := []string{merged.DataSource}
+
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		metadata := other.Metadata
+
+		mergedRunIDs = append(mergedRunIDs, metadata.RunID)
+		merged.SourceTotalRows += metadata.SourceTotalRows
+		merged.FilteredTotalRows += metadata.FilteredTotalRows
+		merged.RemovedDuplicateRows += metadata.RemovedDuplicateRows
+		merged.RejectedRowCount += metadata.RejectedRowCount
+		merged.BytesProcessed += metadata.BytesProcessed
+
+		merged.AppliedFilters = appendDistinct(merged.AppliedFilters, metadata.AppliedFilters...)
+		merged.PerformedAggregations = appendDistinct(merged.PerformedAggregations, metadata.PerformedAggregations...)
+		merged.PerformedMerges = appendDistinct(merged.PerformedMerges, metadata.PerformedMerges...)
+		merged.StepPerformance = append(merged.StepPerformance, metadata.StepPerformance...)
+		merged.Warnings = append(merged.Warnings, metadata.Warnings...)
+		merged.RenamedHeaders = append(merged.RenamedHeaders, metadata.RenamedHeaders...)
+		dataSources = appendDistinct(dataSources, metadata.DataSource)
+		if compareWatermarks(metadata.Watermark, merged.Watermark) > 0 {
+			merged.Watermark = metadata.Watermark
+		}
+
+		merged.MemoryStats.PeakAllocBytes = max(merged.MemoryStats.PeakAllocBytes, metadata.MemoryStats.PeakAllocBytes)
+		merged.MemoryStats.PeakSysBytes = max(merged.MemoryStats.PeakSysBytes, metadata.MemoryStats.PeakSysBytes)
+
+		if !metadata.StartTime.IsZero() && (merged.StartTime.IsZero() || metadata.StartTime.Before(merged.StartTime)) {
+			merged.StartTime = metadata.StartTime
+		}
+		if metadata.EndTime.After(merged.EndTime) {
+			merged.EndTime = metadata.EndTime
+		}
+	}
+
+	if !merged.EndTime.IsZero() {
+		merged.ProcessingTime = merged.EndTime.Sub(merged.StartTime)
+		if merged.ProcessingTime > 0 {
+			merged.RowsPerSecond = float64(merged.SourceTotalRows) / merged.ProcessingTime.Seconds()
+		}
+	}
+
+	merged.RunID = newRunID()
+	merged.MergedRunIDs = mergedRunIDs
+	merged.DataSource = strings.Join(slices.DeleteFunc(dataSources, func(s string) bool { return s == "" }), "; ")
+}
+
+// compareWatermarks compares the watermarks as numbers if both are numbers, otherwise as strings.
+// The empty watermark is less than any other.
+func compareWatermarks(a, b string) int {
+	if a == "" || b == "" {
+		return cmp.Compare(len(a), len(b))
+	}
+
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		return cmp.Compare(x, y)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// appendDistinct appends the values not contained in the slice yet.
+func appendDistinct(slice []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(slice, value) {
+			slice = append(slice, value)
+		}
+	}
+
+	return slice
+}
+
 // ToJSON converts the Processing instance into a formatted JSON string and returns it. Returns an error if marshaling fails.
 func (p *Processing) ToJSON() (string, error) {
 	data, err := json.MarshalIndent(p, "", "    ")
@@ -152,6 +450,110 @@ func (p *Processing) ToJSON() (string, error) {
 	return string(data), nil
 }
 
+// ToCSV writes the Data as CSV to the writer with the GetColumnNames as the header.
+// The values are formatted as DataFrame.Records does, and the null values are written as "NaN" like the csv output.
+// Writes only the header if the Data has no rows, and nothing if the Data is nil.
+func (p *Processing) ToCSV(w io.Writer) error {
+	if p.Data == nil {
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(p.Data.Records()); err != nil {
+		return fmt.Errorf("failed to write Processing data to CSV: %w", err)
+	}
+
+	return nil
+}
+
+// DataToJSON converts the Data into a formatted JSON array of the row objects and returns it.
+// The keys of each object are in the order of the GetColumnNames, the values keep their types,
+// and the null and the non-finite values (NaN and Inf) are null as in the JSON output.
+// Returns "[]" if the Data is nil or has no rows.
+func (p *Processing) DataToJSON() (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	if p.Data != nil {
+		names := p.Data.Names()
+		keys := make([][]byte, len(names))
+		for j, name := range names {
+			key, err := json.Marshal(name)
+			if err != nil {
+				return "", fmt.Errorf("failed to marshal column name '%s' to JSON: %w", name, err)
+			}
+			keys[j] = key
+		}
+
+		for i := 0; i < p.Data.Nrow(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('{')
+			for j := range names {
+				if j > 0 {
+					buf.WriteByte(',')
+				}
+
+				encoded, err := json.Marshal(jsonValue(p.Data.Elem(i, j)))
+				if err != nil {
+					return "", fmt.Errorf("failed to marshal row %d of column '%s' to JSON: %w", i+1, names[j], err)
+				}
+
+				buf.Write(keys[j])
+				buf.WriteByte(':')
+				buf.Write(encoded)
+			}
+			buf.WriteByte('}')
+		}
+	}
+	buf.WriteByte(']')
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "    "); err != nil {
+		return "", fmt.Errorf("failed to format Processing data as JSON: %w", err)
+	}
+
+	return indented.String(), nil
+}
+
+// jsonValue returns the value of the element to encode as JSON: nil for the null and the non-finite values,
+// which JSON has no literals for, otherwise the value of its type.
+func jsonValue(element series.Element) interface{} {
+	if element.IsNA() {
+		return nil
+	}
+	if f, ok := element.Val().(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return nil
+	}
+
+	return element.Val()
+}
+
+// Summary returns a one-line human-readable summary of the processing throughput.
+// e.g. "processed 10000 rows in 1.2s (8333 rows/s)"
+// The processing time is rounded for reading (see roundDuration), while the rows per second use the exact time.
+func (p *Processing) Summary() string {
+	return fmt.Sprintf(
+		"processed %d rows in %s (%.0f rows/s)",
+		p.Metadata.SourceTotalRows,
+		roundDuration(p.Metadata.ProcessingTime),
+		p.Metadata.RowsPerSecond,
+	)
+}
+
+// roundDuration rounds the duration to a tenth of its unit for the seconds and longer (e.g. 1.2s or 2m3.5s),
+// and to the whole milliseconds or microseconds for the shorter ones (e.g. 35ms).
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
 // HasData checks if the Processing instance contains any data by verifying the length of the `Data` field. Returns true if data exists.
 func (p *Processing) HasData() bool {
 	return p.Data != nil && p.Data.Nrow() > 0
@@ -176,6 +578,11 @@ func (p *Processing) GetColumnCount() int {
 }
 
 // GetColumnNames returns a slice of strings representing the names of the columns in the Data field of the Processing instance.
+// Returns nil if Data is nil.
 func (p *Processing) GetColumnNames() []string {
-	panic("implement me")
+	if p.Data == nil {
+		return nil
+	}
+
+	return p.Data.Names()
 }
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// relativeDatePattern matches the relative date tokens; a base optionally followed by an offset, e.g. "now-30d"
+var relativeDatePattern = regexp.MustCompile(`^(now|today|startOfWeek|startOfMonth|startOfYear)(?:([+-])(\d+)([hdwmy]))?$`)
+
+// relativeDateCandidate matches the values meant as the relative date tokens,
+// so a misspelled token of a date-typed filter is rejected by Validate instead of being parsed as a date
+var relativeDateCandidate = regexp.MustCompile(`^(now|today|startOf[A-Za-z]*)([+-].*)?$`)
+
+// IsRelativeDate reports whether the value is meant as a relative date token (see ResolveRelativeDate).
+// The malformed tokens are included, so check them with ResolveRelativeDate.
+// Only the Value of the date-typed filters is read as a token (see FilterConfig).
+func IsRelativeDate(value string) bool {
+	return relativeDateCandidate.MatchString(value)
+}
+
+// ResolveRelativeDate resolves the relative date token against now, in the location of now.
+// The token is a base optionally followed by an offset of a signed integer and a unit:
+// - Bases: now, today, startOfWeek (Monday), startOfMonth, startOfYear
+// - Units: h (hours, now only), d (days), w (weeks), m (months), y (years)
+//
+// "now" keeps the time of now and the other bases resolve to the midnight, e.g. "today-7d" is the date a week ago.
+// The processor formats the resolved time in the date layout of the compared column, so "now-30d" compared with
+// a "2006-01-02" column is the date 30 days ago regardless of the time of now.
+// The month and year offsets normalize the overflowing days as time.AddDate does, e.g. March 31 minus 1m is March 3.
+// Returns a validation error if the value is not a valid token.
+func ResolveRelativeDate(value string, now time.Time) (time.Time, error) {
+	matches := relativeDatePattern.FindStringSubmatch(value)
+	if matches == nil || (matches[4] == "h" && matches[1] != "now") {
+		return time.Time{}, newValidationError(MessageInvalidRelativeDate, value)
+	}
+
+	base, sign, amount, unit := matches[1], matches[2], matches[3], matches[4]
+	offset := 0
+	if amount != "" {
+		n, err := strconv.Atoi(amount)
+		if err != nil {
+			return time.Time{}, newValidationError(MessageInvalidRelativeDate, value)
+		}
+		offset = n
+		if sign == "-" {
+			offset = -n
+		}
+	}
+
+	year, month, day := now.Date()
+	resolved := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	switch base {
+	case "now":
+		resolved = now
+	case "startOfWeek":
+		// Monday starts the week as ISO 8601
+		resolved = resolved.AddDate(0, 0, -(int(resolved.Weekday())+6)%7)
+	case "startOfMonth":
+		resolved = time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	case "startOfYear":
+		resolved = time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+	}
+
+	switch unit {
+	case "h":
+		resolved = resolved.Add(time.Duration(offset) * time.Hour)
+	case "d":
+		resolved = resolved.AddDate(0, 0, offset)
+	case "w":
+		resolved = resolved.AddDate(0, 0, 7*offset)
+	case "m":
+		resolved = resolved.AddDate(0, offset, 0)
+	case "y":
+		resolved = resolved.AddDate(offset, 0, 0)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolveRelativeDate(t *testing.T) {
+	// Sunday, in a zone other than UTC to check the location of now is kept
+	tokyo := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2024, time.March, 31, 15, 4, 5, 0, tokyo)
+
+	tests := []struct {
+		token string
+		want  time.Time
+	}{
+		{token: "now", want: now},
+		{token: "now-30d", want: time.Date(2024, time.March, 1, 15, 4, 5, 0, tokyo)},
+		{token: "now-2h", want: time.Date(2024, time.March, 31, 13, 4, 5, 0, tokyo)},
+		{token: "today", want: time.Date(2024, time.March, 31, 0, 0, 0, 0, tokyo)},
+		{token: "today+1d", want: time.Date(2024, time.April, 1, 0, 0, 0, 0, tokyo)},
+		{token: "startOfWeek", want: time.Date(2024, time.March, 25, 0, 0, 0, 0, tokyo)},
+		{token: "startOfWeek-1w", want: time.Date(2024, time.March, 18, 0, 0, 0, 0, tokyo)},
+		{token: "startOfMonth", want: time.Date(2024, time.March, 1, 0, 0, 0, 0, tokyo)},
+		{token: "startOfMonth-1m", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, tokyo)},
+		{token: "startOfYear+1y", want: time.Date(2025, time.January, 1, 0, 0, 0, 0, tokyo)},
+		// The overflowing days are normalized as time.AddDate does; February 31 is March 2 of the leap year
+		{token: "today-1m", want: time.Date(2024, time.March, 2, 0, 0, 0, 0, tokyo)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			got, err := ResolveRelativeDate(tt.token, now)
+			if err != nil {
+				t.Fatalf("ResolveRelativeDate(%q) error = %v", tt.token, err)
+			}
+			if !got.Equal(tt.want) || got.Location() != tokyo {
+				t.Errorf("ResolveRelativeDate(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRelativeDateMalformed(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 15, 4, 5, 0, time.UTC)
+
+	for _, token := range []string{"now-30", "now-30x", "now+d", "now-1.5d", "today-2h", "startOfMonht", "startOfMonth-", "Now", "now -30d"} {
+		t.Run(token, func(t *testing.T) {
+			_, err := ResolveRelativeDate(token, now)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Key != MessageInvalidRelativeDate {
+				t.Errorf("ResolveRelativeDate(%q) error = %v, want the ValidationError of %s", token, err, MessageInvalidRelativeDate)
+			}
+		})
+	}
+}
+
+func TestIsRelativeDate(t *testing.T) {
+	tests := map[string]bool{
+		"now-30d":      true,
+		"startOfMonth": true,
+		// The malformed tokens are meant as tokens, so Validate rejects them instead of parsing them as dates
+		"startOfMonht": true,
+		"now-30x":      true,
+		"2024-03-01":   false,
+		"yesterday":    false,
+		"nowhere":      false,
+	}
+
+	for value, want := range tests {
+		if got := IsRelativeDate(value); got != want {
+			t.Errorf("IsRelativeDate(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestFilterConfigValidateRelativeDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  FilterConfig
+		wantErr bool
+	}{
+		{name: "valid token", filter: FilterConfig{Column: "ordered", Operator: "gte", Value: "now-30d", ValueType: "date", LogicalOperator: "and"}},
+		{name: "malformed token", filter: FilterConfig{Column: "ordered", Operator: "gte", Value: "now-30x", ValueType: "date", LogicalOperator: "and"}, wantErr: true},
+		// Only the Value of the date-typed filters is read as a token
+		{name: "not date-typed", filter: FilterConfig{Column: "note", Operator: "eq", Value: "now-30x", LogicalOperator: "and"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			var validationErr *ValidationError
+			if tt.wantErr != (errors.As(err, &validationErr) && validationErr.Key == MessageInvalidRelativeDate) || (!tt.wantErr && err != nil) {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package entities
+
+import (
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"slices"
+	"strings"
+)
+
+// columnReference represents a column referenced by the Config and the field referencing it
+type columnReference struct {
+	column string
+	field  string
+}
+
+// ValidateAgainstSchema checks every column referenced by the Config exists in the fetched columns,
+// so the missing columns are reported up front instead of failing in the middle of processing.
+// It follows the stage order, so the result columns of the merges and the computed columns are available
+// to the later stages such as the post filters, and each aggregation sees only the grouping, passthrough, and result columns of the previous one.
+// The columns inside the computed expressions are validated by the processor when parsing them.
+// Returns a ConfigurationError listing all missing columns with the fields referencing them.
+func (c *Config) ValidateAgainstSchema(columnNames []string) error {
+	available := slices.Clone(columnNames)
+	missing := make([]columnReference, 0)
+	check := func(column, field string) {
+		if !slices.Contains(available, column) {
+			missing = append(missing, columnReference{column: column, field: field})
+		}
+	}
+
+	for i, normalize := range c.Normalize {
+		check(normalize.Column, fmt.Sprintf("normalize[%d].column", i))
+	}
+
+	for i, cast := range c.Casts {
+		check(cast.Column, fmt.Sprintf("casts[%d].column", i))
+	}
+
+	if c.Watermark != nil {
+		check(c.Watermark.Column, "watermark.column")
+	}
+
+	if c.Dedup != nil {
+		for i, column := range c.Dedup.Columns {
+			check(column, fmt.Sprintf("dedup.columns[%d]", i))
+		}
+	}
+
+	for i, fill := range c.FillNull {
+		check(fill.Column, fmt.Sprintf("fillNull[%d].column", i))
+	}
+
+	for i, filter := range c.Filters {
+		check(filter.Column, fmt.Sprintf("filters[%d].column", i))
+	}
+
+	for i, merge := range c.MergeColumns {
+		if merge.Strategy == "coalesce" {
+			for j, column := range merge.Columns {
+				check(column, fmt.Sprintf("mergeColumns[%d].columns[%d]", i, j))
+			}
+		} else {
+			check(merge.FirstColumn, fmt.Sprintf("mergeColumns[%d].firstColumn", i))
+			check(merge.SecondColumn, fmt.Sprintf("mergeColumns[%d].secondColumn", i))
+		}
+		resultColumnName := merge.ResultColumnName
+		if resultColumnName == "" {
+			resultColumnName = strings.Join(merge.SourceColumns(), "_")
+		}
+		available = append(available, resultColumnName)
+	}
+
+	for _, computed := range c.Computed {
+		available = append(available, computed.Name)
+	}
+
+	for i, filter := range c.PostFilters {
+		check(filter.Column, fmt.Sprintf("postFilters[%d].column", i))
+	}
+
+	for i, aggregationConfig := range c.Aggregations {
+		for j, column := range aggregationConfig.GroupingColumns {
+			check(column, fmt.Sprintf("aggregations[%d].groupingColumns[%d]", i, j))
+		}
+		for j, column := range aggregationConfig.PassthroughColumns {
+			check(column, fmt.Sprintf("aggregations[%d].passthroughColumns[%d]", i, j))
+		}
+
+		resultNames := make([]string, 0, len(aggregationConfig.Aggregations))
+		for j, aggregation := range aggregationConfig.Aggregations {
+			field := fmt.Sprintf("aggregations[%d].aggregations[%d]", i, j)
+			check(aggregation.Column, field+".column")
+			if aggregation.WeightColumn != "" {
+				check(aggregation.WeightColumn, field+".weightColumn")
+			}
+			if aggregation.Condition != nil {
+				check(aggregation.Condition.Column, field+".condition.column")
+			}
+
+			resultName := aggregation.ResultName
+			if resultName == "" {
+				resultName = aggregation.Column + "_" + aggregation.AggregateMethod
+			}
+			resultNames = append(resultNames, resultName)
+		}
+
+		// The next aggregation receives only the grouping, passthrough, and result columns
+		available = slices.Concat(aggregationConfig.GroupingColumns, aggregationConfig.PassthroughColumns, resultNames)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(missing))
+	for i, reference := range missing {
+		descriptions[i] = fmt.Sprintf("'%s' (%s)", reference.column, reference.field)
+	}
+
+	return domainerrors.NewConfigurationError("", fmt.Sprintf("referenced columns not found: %s", strings.Join(descriptions, ", ")), nil)
+}
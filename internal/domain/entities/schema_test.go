@@ -0,0 +1,94 @@
+package entities
+
+import (
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateAgainstSchema(t *testing.T) {
+	columns := []string{"status", "amount", "region", "cost"}
+
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		missing []string // the missing references in the error message, nil for valid
+	}{
+		{name: "valid", change: func(*Config) {}},
+		{
+			name:    "missing filter column",
+			change:  func(c *Config) { c.Filters[0].Column = "state" },
+			missing: []string{"'state' (filters[0].column)"},
+		},
+		{
+			name:    "missing grouping column",
+			change:  func(c *Config) { c.Aggregations[0].GroupingColumns = []string{"country"} },
+			missing: []string{"'country' (aggregations[0].groupingColumns[0])"},
+		},
+		{
+			name: "all missing columns",
+			change: func(c *Config) {
+				c.Filters[1].Column = "price"
+				c.Aggregations[0].GroupingColumns = []string{"country"}
+			},
+			missing: []string{"'price' (filters[1].column)", "'country' (aggregations[0].groupingColumns[0])"},
+		},
+		{
+			name: "merge result available to later stages",
+			change: func(c *Config) {
+				c.MergeColumns = []MergeConfig{{FirstColumn: "amount", SecondColumn: "cost", Strategy: "sum", ResultColumnName: "gross"}}
+				c.Aggregations[0].Aggregations[0].Column = "gross"
+			},
+		},
+		{
+			name: "computed column available to post filters",
+			change: func(c *Config) {
+				c.Computed = []ComputedColumn{{Name: "margin", Expression: "(amount - cost) / amount"}}
+				c.PostFilters = []FilterConfig{{Column: "margin", Operator: "gte", Value: "0.3", LogicalOperator: "and"}}
+			},
+		},
+		{
+			name: "computed column not available to filters",
+			change: func(c *Config) {
+				c.Computed = []ComputedColumn{{Name: "margin", Expression: "(amount - cost) / amount"}}
+				c.Filters[0].Column = "margin"
+				c.PostFilters = []FilterConfig{{Column: "profit", Operator: "gt", Value: "0", LogicalOperator: "and"}}
+			},
+			missing: []string{"'margin' (filters[0].column)", "'profit' (postFilters[0].column)"},
+		},
+		{
+			name: "aggregation result only for the next aggregation",
+			change: func(c *Config) {
+				c.Aggregations = append(c.Aggregations, AggregationConfig{
+					GroupingColumns: []string{"region"},
+					Aggregations:    []Aggregation{{Column: "amount", AggregateMethod: "max"}},
+				})
+			},
+			missing: []string{"'amount' (aggregations[1].aggregations[0].column)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := salesConfig()
+			tt.change(config)
+
+			err := config.ValidateAgainstSchema(columns)
+			if tt.missing == nil {
+				if err != nil {
+					t.Errorf("ValidateAgainstSchema() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var configurationError *domainerrors.ConfigurationError
+			if !errors.As(err, &configurationError) {
+				t.Fatalf("ValidateAgainstSchema() error = %v, want a ConfigurationError", err)
+			}
+			if want := "referenced columns not found: " + strings.Join(tt.missing, ", "); configurationError.Message != want {
+				t.Errorf("Message = %q, want %q", configurationError.Message, want)
+			}
+		})
+	}
+}
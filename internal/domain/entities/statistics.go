@@ -0,0 +1,72 @@
+package entities
+
+import "github.com/go-gota/gota/series"
+
+// ColumnStat represents the profile of a column for a quick data-quality check.
+// Null values are the missing values and the blank strings; they are excluded from the distinct count.
+// Min, Max, and Mean are only set for the numeric (int and float) columns with at least one non-null value.
+type ColumnStat struct {
+	Type          string   `json:"type"`
+	Count         int      `json:"count"`     // Number of the non-null values
+	NullCount     int      `json:"nullCount"` // Number of the null values
+	DistinctCount int      `json:"distinctCount"`
+	Min           *float64 `json:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty"`
+	Mean          *float64 `json:"mean,omitempty"`
+}
+
+// ColumnStatistics computes the ColumnStat of each column of Data keyed by the column name.
+// Returns an empty map if Data is nil.
+func (p *Processing) ColumnStatistics() map[string]ColumnStat {
+	stats := make(map[string]ColumnStat)
+	if p.Data == nil {
+		return stats
+	}
+
+	for _, name := range p.Data.Names() {
+		stats[name] = columnStat(p.Data.Col(name))
+	}
+
+	return stats
+}
+
+// columnStat computes the ColumnStat of the column.
+func columnStat(column series.Series) ColumnStat {
+	stat := ColumnStat{Type: string(column.Type())}
+	numeric := column.Type() == series.Int || column.Type() == series.Float
+
+	distinct := make(map[string]struct{})
+	var minValue, maxValue, sum float64
+	for i := 0; i < column.Len(); i++ {
+		element := column.Elem(i)
+		if IsNull(element) {
+			stat.NullCount++
+			continue
+		}
+
+		stat.Count++
+		distinct[element.String()] = struct{}{}
+
+		if !numeric {
+			continue
+		}
+		value := element.Float()
+		if stat.Count == 1 || value < minValue {
+			minValue = value
+		}
+		if stat.Count == 1 || value > maxValue {
+			maxValue = value
+		}
+		sum += value
+	}
+	stat.DistinctCount = len(distinct)
+
+	if numeric && stat.Count > 0 {
+		mean := sum / float64(stat.Count)
+		stat.Min = &minValue
+		stat.Max = &maxValue
+		stat.Mean = &mean
+	}
+
+	return stat
+}
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"fmt"
+	"github.com/go-gota/gota/dataframe"
+	"reflect"
+	"testing"
+)
+
+// float returns the pointer to the value for the expected ColumnStat.
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestColumnStatistics(t *testing.T) {
+	df := dataframe.LoadRecords([][]string{
+		{"name", "amount", "price", "paid", "empty"},
+		{"Alice", "10", "1.5", "true", ""},
+		{"Bob", "", "2.5", "false", ""},
+		{"Alice", "30", "", "true", ""},
+		{"", "20", "4", "", ""},
+	})
+	processing := NewProcessing(&df, "statistics")
+
+	want := map[string]ColumnStat{
+		"name":   {Type: "string", Count: 3, NullCount: 1, DistinctCount: 2},
+		"amount": {Type: "int", Count: 3, NullCount: 1, DistinctCount: 3, Min: float(10), Max: float(30), Mean: float(20)},
+		"price":  {Type: "float", Count: 3, NullCount: 1, DistinctCount: 3, Min: float(1.5), Max: float(4), Mean: float(8.0 / 3)},
+		"paid":   {Type: "bool", Count: 3, NullCount: 1, DistinctCount: 2},
+		"empty":  {Type: "string", Count: 0, NullCount: 4, DistinctCount: 0},
+	}
+
+	got := processing.ColumnStatistics()
+	for name, stat := range want {
+		if !reflect.DeepEqual(got[name], stat) {
+			t.Errorf("ColumnStatistics()[%q] = %s, want %s", name, formatStat(got[name]), formatStat(stat))
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("ColumnStatistics() has %d columns, want %d", len(got), len(want))
+	}
+}
+
+func TestColumnStatisticsNoData(t *testing.T) {
+	if got := NewProcessing(nil, "statistics").ColumnStatistics(); got == nil || len(got) != 0 {
+		t.Errorf("ColumnStatistics() = %#v, want an empty map", got)
+	}
+}
+
+// formatStat formats the stat with the values of Min, Max, and Mean instead of their pointers.
+func formatStat(stat ColumnStat) string {
+	value := func(p *float64) interface{} {
+		if p == nil {
+			return nil
+		}
+		return *p
+	}
+
+	return fmt.Sprintf("{Type:%s Count:%d NullCount:%d DistinctCount:%d Min:%v Max:%v Mean:%v}",
+		stat.Type, stat.Count, stat.NullCount, stat.DistinctCount, value(stat.Min), value(stat.Max), value(stat.Mean))
+}
@@ -0,0 +1,138 @@
+package entities
+
+import (
+	"slices"
+	"sync"
+)
+
+// The supported values of the configuration fields.
+// These are the single source of truth shared by the validations and the processor implementations.
+var (
+	filterOperators = []string{"eq", "neq", "gt", "gte", "lt", "lte", "in", "notIn", "containsAny", "containsAll"}
+	// listOperators is the filter operators comparing with the list of the values instead of the single value
+	listOperators = []string{"in", "notIn", "containsAny", "containsAll"}
+	// delimitedOperators is the list operators splitting the cell into the values by the Delimiter
+	delimitedOperators = []string{"containsAny", "containsAll"}
+	// filterValueTypes is the types the Value of the filters can be compared as instead of the column type
+	filterValueTypes = []string{"date"}
+	logicalOperators = []string{"and", "or"}
+	mergeStrategies  = []string{"concat", "sum", "first", "second", "divide", "percentage", "coalesce"}
+	aggregateMethods = []string{"sum", "avg", "min", "max", "count", "median", "weightedAvg", "sharePercent"}
+	castTypes        = []string{"int", "float", "string", "date"}
+	dedupKeeps       = []string{"first", "last"}
+	fillMethods      = []string{"literal", "mean", "zero", "forward"}
+	// builtinSourceTypes is the source types of the built-in data sources; custom types can be added by the registry
+	builtinSourceTypes = []string{"csv", "googlesheets"}
+	// builtinOutputFormats is the formats of the built-in outputs; custom formats can be added by the registry
+	builtinOutputFormats = []string{"console", "csv", "json", "report"}
+)
+
+// The source types and the output formats accepted by Config.Validate.
+// They start with the built-in ones and are extended by RegisterSourceType and RegisterOutputFormat.
+var (
+	registeredMu            sync.RWMutex
+	registeredSourceTypes   = slices.Clone(builtinSourceTypes)
+	registeredOutputFormats = slices.Clone(builtinOutputFormats)
+)
+
+// SupportedFilterOperators returns the operators accepted by FilterConfig.Validate.
+func SupportedFilterOperators() []string {
+	return slices.Clone(filterOperators)
+}
+
+// IsListOperator reports whether the filter operator compares with the list of the values (Values or ValuesFile)
+// instead of the single Value.
+func IsListOperator(operator string) bool {
+	return slices.Contains(listOperators, operator)
+}
+
+// IsDelimitedOperator reports whether the filter operator splits the cell by the Delimiter
+// and tests the membership of the list of the values in the split values.
+func IsDelimitedOperator(operator string) bool {
+	return slices.Contains(delimitedOperators, operator)
+}
+
+// SupportedFilterValueTypes returns the value types accepted by FilterConfig.Validate.
+func SupportedFilterValueTypes() []string {
+	return slices.Clone(filterValueTypes)
+}
+
+// SupportedLogicalOperators returns the logical operators accepted by FilterConfig.Validate.
+func SupportedLogicalOperators() []string {
+	return slices.Clone(logicalOperators)
+}
+
+// SupportedMergeStrategies returns the strategies accepted by MergeConfig.Validate.
+func SupportedMergeStrategies() []string {
+	return slices.Clone(mergeStrategies)
+}
+
+// SupportedAggregateMethods returns the aggregate methods accepted by Aggregation.Validate.
+func SupportedAggregateMethods() []string {
+	return slices.Clone(aggregateMethods)
+}
+
+// SupportedCastTypes returns the target types accepted by CastConfig.Validate.
+func SupportedCastTypes() []string {
+	return slices.Clone(castTypes)
+}
+
+// SupportedDedupKeeps returns the keep values accepted by DedupConfig.Validate.
+func SupportedDedupKeeps() []string {
+	return slices.Clone(dedupKeeps)
+}
+
+// SupportedFillMethods returns the fill methods accepted by FillConfig.Validate.
+func SupportedFillMethods() []string {
+	return slices.Clone(fillMethods)
+}
+
+// BuiltinSourceTypes returns the source types of the built-in data sources.
+// The types available at runtime are validated by ValidateType against the data source registry.
+func BuiltinSourceTypes() []string {
+	return slices.Clone(builtinSourceTypes)
+}
+
+// RegisterSourceType adds the source type to the types accepted by Config.Validate.
+// The registry calls it for every registered data source, so a custom type registered in any registry passes Validate,
+// and the registry checks the type is registered in itself (see registry.ValidateConfig).
+func RegisterSourceType(sourceType string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	registeredSourceTypes = addSorted(registeredSourceTypes, sourceType)
+}
+
+// RegisterOutputFormat adds the output format to the formats accepted by Config.Validate like RegisterSourceType.
+func RegisterOutputFormat(format string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	registeredOutputFormats = addSorted(registeredOutputFormats, format)
+}
+
+// SupportedSourceTypes returns the source types accepted by Config.Validate in sorted order.
+func SupportedSourceTypes() []string {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	return slices.Clone(registeredSourceTypes)
+}
+
+// SupportedOutputFormats returns the output formats accepted by Config.Validate in sorted order.
+func SupportedOutputFormats() []string {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	return slices.Clone(registeredOutputFormats)
+}
+
+// addSorted inserts the value into the sorted values unless it is already contained.
+func addSorted(values []string, value string) []string {
+	i, found := slices.BinarySearch(values, value)
+	if found {
+		return values
+	}
+
+	return slices.Insert(values, i, value)
+}
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"regexp"
+)
+
+// placeholderPattern matches the template placeholders like {{region}} (spaces inside the braces are allowed)
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Render returns a new Config whose placeholders like {{region}} are substituted with the vars and validates it.
+// Placeholders are substituted in Name, Source, Destination, and the Value of each filter and post filter;
+// the receiver is not modified.
+// Returns a ConfigurationError for the first placeholder without a corresponding variable.
+func (c *Config) Render(vars map[string]string) (*Config, error) {
+	rendered := c.clone()
+
+	var err error
+	if rendered.Name, err = renderPlaceholders("name", rendered.Name, vars); err != nil {
+		return nil, err
+	}
+	if rendered.Source, err = renderPlaceholders("source", rendered.Source, vars); err != nil {
+		return nil, err
+	}
+	if rendered.Destination, err = renderPlaceholders("destination", rendered.Destination, vars); err != nil {
+		return nil, err
+	}
+	for i := range rendered.Filters {
+		field := fmt.Sprintf("filters[%d].value", i)
+		if rendered.Filters[i].Value, err = renderPlaceholders(field, rendered.Filters[i].Value, vars); err != nil {
+			return nil, err
+		}
+	}
+	for i := range rendered.PostFilters {
+		field := fmt.Sprintf("postFilters[%d].value", i)
+		if rendered.PostFilters[i].Value, err = renderPlaceholders(field, rendered.PostFilters[i].Value, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rendered.Validate(); err != nil {
+		return nil, domainerrors.NewConfigurationError("", fmt.Sprintf("rendered config is invalid: %v", err), err)
+	}
+
+	return rendered, nil
+}
+
+// renderPlaceholders substitutes the placeholders in the value of the field with the vars.
+func renderPlaceholders(field, value string, vars map[string]string) (string, error) {
+	var missing string
+	rendered := placeholderPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		substitute, ok := vars[name]
+		if !ok {
+			if missing == "" {
+				missing = name
+			}
+			return placeholder
+		}
+
+		return substitute
+	})
+
+	if missing != "" {
+		return "", domainerrors.NewConfigurationError(field, fmt.Sprintf("unresolved placeholder {{%s}}", missing), nil)
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"testing"
+)
+
+// regionTemplate returns the sales config templated by the region.
+func regionTemplate() *Config {
+	config := salesConfig()
+	config.Name = "sales-{{region}}"
+	config.Source = "data/{{ region }}/sales.csv"
+	config.Destination = "out/{{region}}-{{year}}.csv"
+	config.Filters[0] = FilterConfig{Column: "region", Operator: "eq", Value: "{{region}}", LogicalOperator: "and"}
+
+	return config
+}
+
+func TestConfigRender(t *testing.T) {
+	template := regionTemplate()
+
+	rendered, err := template.Render(map[string]string{"region": "east", "year": "2024", "unused": "x"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if got, want := rendered.Filters[0].Value, "east"; got != want {
+		t.Errorf("Filters[0].Value = %q, want %q", got, want)
+	}
+	if got, want := rendered.Name, "sales-east"; got != want {
+		t.Errorf("Name = %q, want %q", got, want)
+	}
+	if got, want := rendered.Source, "data/east/sales.csv"; got != want {
+		t.Errorf("Source = %q, want %q", got, want)
+	}
+	if got, want := rendered.Destination, "out/east-2024.csv"; got != want {
+		t.Errorf("Destination = %q, want %q", got, want)
+	}
+
+	// The template is reusable for the other regions
+	if template.Filters[0].Value != "{{region}}" || template.Source != "data/{{ region }}/sales.csv" {
+		t.Errorf("Render() modified the template: %+v", template)
+	}
+}
+
+func TestConfigRenderMissingVariable(t *testing.T) {
+	_, err := regionTemplate().Render(map[string]string{"region": "east"})
+
+	var configurationError *domainerrors.ConfigurationError
+	if !errors.As(err, &configurationError) {
+		t.Fatalf("Render() error = %v, want a ConfigurationError", err)
+	}
+	if configurationError.Field != "destination" {
+		t.Errorf("Field = %q, want %q", configurationError.Field, "destination")
+	}
+}
+
+func TestConfigRenderInvalidResult(t *testing.T) {
+	template := salesConfig()
+	template.Filters[1].Value = "{{minimum}}"
+
+	// The rendered value is empty, so the rendered config fails the validation
+	if _, err := template.Render(map[string]string{"minimum": ""}); !domainerrors.IsConfigurationError(err) {
+		t.Errorf("Render() error = %v, want a ConfigurationError", err)
+	}
+}
@@ -15,6 +15,10 @@ type AuthenticationError struct {
 
 	// MaxRetries defines the maximum number of retry attempts allowed for an operation before failing.
 	MaxRetries int
+
+	// Permanent indicates that retrying cannot succeed (e.g. an invalid API key or a revoked token)
+	// regardless of the retry attempt.
+	Permanent bool
 }
 
 // Error implements the error interface
@@ -28,7 +32,12 @@ func (e *AuthenticationError) Unwrap() error {
 }
 
 // IsRetryable determines if the error condition is retryable based on the current retry attempt and the maximum retries allowed.
+// A Permanent error is never retryable.
 func (e *AuthenticationError) IsRetryable() bool {
+	if e.Permanent {
+		return false
+	}
+
 	return e.RetryAttempt < e.MaxRetries
 }
 
@@ -59,6 +68,17 @@ func NewAuthenticationErrorWithMaxRetries(message string, cause error, maxRetrie
 	}
 }
 
+// NewPermanentAuthenticationError creates a new AuthenticationError that is not retryable regardless of the retry attempt.
+// It should be used for the failures that retrying cannot resolve (e.g. an invalid API key or a revoked token),
+// while the transient failures (e.g. 429 or 503) should use NewAuthenticationError to be retried.
+func NewPermanentAuthenticationError(message string, cause error) *AuthenticationError {
+	return &AuthenticationError{
+		Message:   message,
+		Cause:     cause,
+		Permanent: true,
+	}
+}
+
 // IsAuthenticationError checks if the provided error is of type AuthenticationError.
 func IsAuthenticationError(err error) bool {
 	var authenticationError *AuthenticationError
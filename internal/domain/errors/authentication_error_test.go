@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+// retryUntilDone retries the operation failing with the error while the error is retryable,
+// and returns the number of the retries.
+func retryUntilDone(err *AuthenticationError) int {
+	retries := 0
+	for err.IsRetryable() {
+		err.IncrementRetryAttempt()
+		retries++
+	}
+
+	return retries
+}
+
+func TestAuthenticationErrorIsRetryable(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         *AuthenticationError
+		wantRetry   bool
+		wantRetries int
+	}{
+		{name: "transient", err: NewAuthenticationError("rate limited", nil), wantRetry: true, wantRetries: 3},
+		{name: "transient with max retries", err: NewAuthenticationErrorWithMaxRetries("unavailable", nil, 5), wantRetry: true, wantRetries: 5},
+		{name: "transient without retries", err: NewAuthenticationErrorWithMaxRetries("unavailable", nil, 0), wantRetry: false, wantRetries: 0},
+		{name: "permanent", err: NewPermanentAuthenticationError("invalid API key", nil), wantRetry: false, wantRetries: 0},
+		{
+			name:        "permanent regardless of the attempt",
+			err:         &AuthenticationError{Message: "token revoked", MaxRetries: 3, Permanent: true},
+			wantRetry:   false,
+			wantRetries: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.IsRetryable(); got != tt.wantRetry {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetry)
+			}
+			if got := retryUntilDone(tt.err); got != tt.wantRetries {
+				t.Errorf("retries = %d, want %d", got, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestNewPermanentAuthenticationError(t *testing.T) {
+	cause := errors.New("401 invalid_client")
+	err := NewPermanentAuthenticationError("invalid API key", cause)
+
+	if !err.Permanent {
+		t.Error("Permanent = false, want true")
+	}
+	if !IsAuthenticationError(err) || !errors.Is(err, cause) {
+		t.Errorf("error = %v, want an AuthenticationError wrapping %v", err, cause)
+	}
+	if got, want := err.Error(), "authentication error: invalid API key"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
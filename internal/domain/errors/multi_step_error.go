@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MultiStepError represents the DataProcessErrors of several steps of a run collected in the order they occurred.
+// errors.As and errors.Is see each contained DataProcessError through Unwrap.
+type MultiStepError struct {
+	Errors []*DataProcessError
+}
+
+// Error implements the error interface
+func (e *MultiStepError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d data process errors: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the contained DataProcessErrors, enabling errors.As and errors.Is to inspect each of them.
+func (e *MultiStepError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// Add appends the DataProcessError of a step. The nil error is ignored.
+func (e *MultiStepError) Add(err *DataProcessError) {
+	if err != nil {
+		e.Errors = append(e.Errors, err)
+	}
+}
+
+// Recoverable checks if all contained errors are recoverable. An empty MultiStepError is not recoverable
+// because there is nothing to recover from.
+func (e *MultiStepError) Recoverable() bool {
+	if len(e.Errors) == 0 {
+		return false
+	}
+
+	for _, err := range e.Errors {
+		if !err.IsRecoverable() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Steps returns the step names of the contained errors in order. A step failing several times appears as many times.
+func (e *MultiStepError) Steps() []string {
+	steps := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		steps[i] = err.Step
+	}
+
+	return steps
+}
+
+// ErrorOrNil returns the MultiStepError if it contains any error, otherwise nil,
+// so that the collected errors can be returned without the typed nil pitfall.
+func (e *MultiStepError) ErrorOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// NewMultiStepError creates a new MultiStepError containing the given DataProcessErrors. The nil errors are ignored.
+func NewMultiStepError(errs ...*DataProcessError) *MultiStepError {
+	multiStepError := &MultiStepError{}
+	for _, err := range errs {
+		multiStepError.Add(err)
+	}
+
+	return multiStepError
+}
+
+// IsMultiStepError checks if the given error is of type MultiStepError or wraps a MultiStepError.
+func IsMultiStepError(err error) bool {
+	var multiStepError *MultiStepError
+	ok := errors.As(err, &multiStepError)
+
+	return ok
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMultiStepErrorRecoverable(t *testing.T) {
+	recoverable := NewRecoverableDataProcessError("cast", "cannot cast 'abc' to int", nil, "skip the row")
+	fatal := NewDataProcessError("merge", "sum strategy requires numeric columns", nil)
+
+	tests := []struct {
+		name string
+		errs []*DataProcessError
+		want bool
+	}{
+		{name: "recoverable only", errs: []*DataProcessError{recoverable, recoverable}, want: true},
+		{name: "recoverable and non-recoverable", errs: []*DataProcessError{recoverable, fatal}, want: false},
+		{name: "non-recoverable only", errs: []*DataProcessError{fatal}, want: false},
+		{name: "empty", errs: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewMultiStepError(tt.errs...).Recoverable(); got != tt.want {
+				t.Errorf("Recoverable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiStepErrorContainedErrors(t *testing.T) {
+	recoverable := NewRecoverableDataProcessError("cast", "cannot cast 'abc' to int", nil, "skip the row")
+	fatal := NewDataProcessError("filter", "filter is canceled", context.Canceled)
+
+	multi := NewMultiStepError(recoverable, nil, fatal)
+	if got, want := multi.Steps(), []string{"cast", "filter"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Steps() = %v, want %v", got, want)
+	}
+	want := "2 data process errors: " + recoverable.Error() + "; " + fatal.Error()
+	if got := multi.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	// errors.As finds the first contained DataProcessError, and errors.Is sees the causes of all of them
+	err := fmt.Errorf("run failed: %w", multi)
+	var processErr *DataProcessError
+	if !errors.As(err, &processErr) || processErr != recoverable {
+		t.Errorf("errors.As() = %v, want the cast error", processErr)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("errors.Is(context.Canceled) = false, want true through the filter error")
+	}
+	if !IsMultiStepError(err) {
+		t.Errorf("IsMultiStepError() = false, want true for the wrapped MultiStepError")
+	}
+	if IsMultiStepError(fatal) {
+		t.Errorf("IsMultiStepError() = true, want false for a DataProcessError")
+	}
+
+	// A single error reads as the error itself
+	if got := NewMultiStepError(fatal).Error(); got != fatal.Error() {
+		t.Errorf("Error() of a single error = %q, want %q", got, fatal.Error())
+	}
+}
+
+func TestMultiStepErrorOrNil(t *testing.T) {
+	multi := NewMultiStepError()
+	if err := multi.ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() of no errors = %v, want nil", err)
+	}
+
+	multi.Add(nil)
+	multi.Add(NewDataProcessError("output", "failed to write", nil))
+	if err := multi.ErrorOrNil(); err == nil || len(multi.Errors) != 1 {
+		t.Errorf("ErrorOrNil() = %v with %d errors, want the MultiStepError of 1 error", err, len(multi.Errors))
+	}
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import "context"
+
+// TokenProvider supplies the access tokens for the data sources requiring authentication (e.g. OAuth2)
+// Implementations should be safe for concurrent use
+type TokenProvider interface {
+	// Token returns the current access token
+	// ctx: context for cancellation and timeout control
+	// Returns: access token or error if no token is available
+	Token(ctx context.Context) (string, error)
+
+	// Refresh renews the access token after the current token is rejected
+	// ctx: context for cancellation and timeout control
+	// Returns: error if the token cannot be renewed
+	//
+	// Implementation notes:
+	// - Token should return the renewed token after Refresh succeeds
+	Refresh(ctx context.Context) error
+}
@@ -6,10 +6,27 @@ import (
 )
 
 // DataSourceConfig represents the configuration required for retrieving data from a specific source.
+// Range may list several ranges separated by commas for the sources fetching multiple ranges at once,
+// and Ranges is the alternative to list them as a slice (only one of them can be set).
+// ColumnMapping renames the fetched headers (keys) to the canonical column names (values) right after fetch,
+// so the rest of the configuration refers to the canonical names. See entities.ValidateColumnMapping.
+// Options holds the source-specific options, which each source decodes into its own struct by DecodeOptions.
 type DataSourceConfig struct {
-	Type   string `json:"type"`
-	Source string `json:"source"`
-	Range  string `json:"range"`
+	Type          string                 `json:"type"`
+	Source        string                 `json:"source"`
+	Range         string                 `json:"range"`
+	Ranges        []string               `json:"ranges,omitempty"`
+	ColumnMapping map[string]string      `json:"columnMapping,omitempty"`
+	Options       map[string]interface{} `json:"options,omitempty"`
+}
+
+// Capabilities describes the features a data source supports.
+// Front-ends can use it to enable or disable features per source.
+type Capabilities struct {
+	SupportsRange              bool   `json:"supportsRange"`              // Source can fetch a part of the data by Range
+	SupportsStreaming          bool   `json:"supportsStreaming"`          // Source can provide the data incrementally
+	SupportsRowCountEstimation bool   `json:"supportsRowCountEstimation"` // Source can estimate the row count without a full fetch
+	RequiredAuth               string `json:"requiredAuth"`               // Authentication method required ("none", "oauth2", etc.)
 }
 
 // DataSource abstracts data retrieval from various sources
@@ -25,9 +42,24 @@ type DataSource interface {
 	// - Should handle authentication automatically (OAuth2, API keys, etc.)
 	// - Should validate source configuration before attempting fetch
 	// - Should return descriptive errors for common failure scenarios
+	// - Should rename the headers by the ColumnMapping of the config before returning
 	// - Should support context cancellation for long-running operations
+	// - Should report the fetched rows to the ProgressFunc of the context (see WithProgress) if any
 	Fetch(ctx context.Context, config DataSourceConfig) (*dataframe.DataFrame, error)
 
+	// Sample retrieves only the first rows of the source to preview the columns and their inferred types
+	// ctx: context for cancellation and timeout control
+	// config: source-specific configuration (same as Fetch)
+	// n: maximum number of rows to return (must be positive)
+	// Returns: DataFrame of at most n rows, or error if sampling fails
+	//
+	// Implementation notes:
+	// - Should stop reading after n rows where the source allows it instead of fetching the whole data
+	// - Should return all rows without error when the source has fewer than n rows
+	// - Should infer the column types from the sampled rows only, so they may differ from the types of Fetch
+	// - Should rename the headers by the ColumnMapping of the config as Fetch does
+	Sample(ctx context.Context, config DataSourceConfig, n int) (*dataframe.DataFrame, error)
+
 	// Validate checks if the source configuration is valid
 	// config: source configuration to valid
 	// Returns: error if configuration is invalid, nil if valid
@@ -41,10 +73,30 @@ type DataSource interface {
 
 	// GetSourceInfo returns human-readable information about the data source
 	// config: source configuration
-	// Returns: descriptive string about the source (e.g., "Google Sheets: MySheet (100 rows)")
+	// Returns: descriptive string about the source (e.g., "googlesheets: MySheet (~100 rows, 5 columns)")
+	//
+	// Implementation notes:
+	// - Should use EstimateRowCount instead of fetching the whole data
+	// - Should follow the format "<type>: <source> (~<rows> rows, <columns> columns)"
+	// - Should fall back to "<type>: <source>" when no estimate is possible
 	GetSourceInfo(config DataSourceConfig) string
 
 	// SupportedTypes returns a list of the source types this implementation supports
 	// Returns: slice of supported type strings (e.g., ["googlesheets", "csv])
 	SupportedTypes() []string
+
+	// GetCapabilities returns the features this implementation supports for the given configuration
+	// config: source configuration
+	// Returns: Capabilities describing range, streaming, row count estimation, and authentication support
+	GetCapabilities(config DataSourceConfig) Capabilities
+
+	// EstimateRowCount estimates the number of data rows without fetching the whole data
+	// ctx: context for cancellation and timeout control
+	// config: source configuration
+	// Returns: estimated row count (-1 if an estimate isn't feasible), or error if estimation fails
+	//
+	// Implementation notes:
+	// - Should be much cheaper than Fetch (e.g., counting newlines, reading metadata)
+	// - The result is an estimate, so it may differ from the fetched row count
+	EstimateRowCount(ctx context.Context, config DataSourceConfig) (int, error)
 }
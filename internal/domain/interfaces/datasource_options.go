@@ -0,0 +1,45 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"strconv"
+	"strings"
+)
+
+// DecodeOptions decodes the Options of the config into dst, a pointer to the source-specific options struct
+// whose fields are tagged with the option names (e.g. `json:"delimiter"`). The fields of the missing options are left
+// as they are, so dst can be initialized with the defaults. The unknown options and the values of the wrong type
+// are rejected with a ConfigurationError naming the option.
+func (c DataSourceConfig) DecodeOptions(dst interface{}) error {
+	if len(c.Options) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(c.Options)
+	if err != nil {
+		return domainerrors.NewConfigurationError("options", "options must be JSON values", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return domainerrors.NewConfigurationError("options."+typeErr.Field, fmt.Sprintf("%s must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value), err)
+		}
+		// The decoder reports the unknown field only by the message, e.g. json: unknown field "delimitr"
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if unquoted, err := strconv.Unquote(field); err == nil {
+				field = unquoted
+			}
+			return domainerrors.NewConfigurationError("options."+field, fmt.Sprintf("unknown option '%s' for %s data source", field, c.Type), err)
+		}
+		return domainerrors.NewConfigurationError("options", "failed to decode options", err)
+	}
+
+	return nil
+}
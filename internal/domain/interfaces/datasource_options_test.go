@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"testing"
+)
+
+// testCSVOptions is the options struct of a CSV-like source decoded by DecodeOptions
+type testCSVOptions struct {
+	Delimiter string `json:"delimiter"`
+	Encoding  string `json:"encoding"`
+	SkipRows  int    `json:"skipRows"`
+	Header    bool   `json:"header"`
+}
+
+func TestDataSourceConfigDecodeOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    testCSVOptions
+	}{
+		{
+			name:    "all options",
+			options: map[string]interface{}{"delimiter": ";", "encoding": "shift_jis", "skipRows": 2.0, "header": false},
+			want:    testCSVOptions{Delimiter: ";", Encoding: "shift_jis", SkipRows: 2, Header: false},
+		},
+		{
+			name:    "missing options keep the defaults",
+			options: map[string]interface{}{"skipRows": 1},
+			want:    testCSVOptions{Delimiter: ",", Encoding: "utf-8", SkipRows: 1, Header: true},
+		},
+		{
+			name:    "no options",
+			options: nil,
+			want:    testCSVOptions{Delimiter: ",", Encoding: "utf-8", Header: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testCSVOptions{Delimiter: ",", Encoding: "utf-8", Header: true}
+			config := DataSourceConfig{Type: "csv", Source: "data.csv", Options: tt.options}
+			if err := config.DecodeOptions(&got); err != nil {
+				t.Fatalf("DecodeOptions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSourceConfigDecodeOptionsInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   map[string]interface{}
+		wantField string
+	}{
+		{name: "string for an int", options: map[string]interface{}{"skipRows": "two"}, wantField: "options.skipRows"},
+		{name: "fractional number for an int", options: map[string]interface{}{"skipRows": 1.5}, wantField: "options.skipRows"},
+		{name: "number for a string", options: map[string]interface{}{"delimiter": 59}, wantField: "options.delimiter"},
+		{name: "string for a bool", options: map[string]interface{}{"header": "yes"}, wantField: "options.header"},
+		{name: "unknown option", options: map[string]interface{}{"delimitr": ";"}, wantField: "options.delimitr"},
+		{name: "not a JSON value", options: map[string]interface{}{"delimiter": func() {}}, wantField: "options"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DataSourceConfig{Type: "csv", Source: "data.csv", Options: tt.options}
+			err := config.DecodeOptions(&testCSVOptions{})
+
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("DecodeOptions() error = %v, want a ConfigurationError", err)
+			}
+			if configErr.Field != tt.wantField {
+				t.Errorf("DecodeOptions() error field = %q, want %q", configErr.Field, tt.wantField)
+			}
+		})
+	}
+}
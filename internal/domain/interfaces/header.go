@@ -0,0 +1,28 @@
+package interfaces
+
+import "context"
+
+// HeaderRenameFunc receives a header of the source renamed by the data source because it has invisible characters
+// or surrounding whitespace, or it is blank or duplicated
+// position: 1-based position of the column in the source
+// header: header as it is in the source (may be blank)
+// name: unique column name the header is renamed to, which the config refers to
+//
+// Implementation notes:
+// - Should be invoked once for each renamed header in the column order
+// - Should not be invoked concurrently, so the callback doesn't need to be goroutine-safe
+type HeaderRenameFunc func(position int, header, name string)
+
+// headerRenameKey is the context key of the HeaderRenameFunc
+type headerRenameKey struct{}
+
+// WithHeaderRenameFunc returns a copy of the context carrying the HeaderRenameFunc invoked by the data source renaming the headers.
+func WithHeaderRenameFunc(ctx context.Context, rename HeaderRenameFunc) context.Context {
+	return context.WithValue(ctx, headerRenameKey{}, rename)
+}
+
+// HeaderRenameFuncFromContext returns the HeaderRenameFunc carried by the context, or nil if none is set.
+func HeaderRenameFuncFromContext(ctx context.Context) HeaderRenameFunc {
+	rename, _ := ctx.Value(headerRenameKey{}).(HeaderRenameFunc)
+	return rename
+}
@@ -0,0 +1,32 @@
+package interfaces
+
+import "time"
+
+// Observer receives the start and the end of each step of a run, e.g. to report them to a custom telemetry
+//
+// Implementation notes:
+// - OnStepEnd is invoked once for each OnStepStart with the same name, even if the step fails
+// - The steps of a run never overlap, but the observer of a processor used concurrently must be goroutine-safe
+// - Should return promptly because the run waits for the callbacks
+type Observer interface {
+	// OnStepStart is invoked before the step runs
+	// name: step name, e.g. "fetch", "filter", "merge", "aggregate", "output"
+	// (the stages are named after the Processor methods in lower camel case, e.g. "fillNull")
+	// inputRows: rows of the input of the step (0 for fetch, the left input for join)
+	OnStepStart(name string, inputRows int)
+
+	// OnStepEnd is invoked after the step returns
+	// name: step name passed to OnStepStart
+	// outputRows: rows of the result of the step (the input rows if the step fails)
+	// d: duration of the step
+	// err: error of the step, or nil if it succeeds
+	OnStepEnd(name string, outputRows int, d time.Duration, err error)
+}
+
+// ObservableProcessor is implemented by the processors notifying an Observer of their stages,
+// so the runs using the processor notify the same Observer of their other steps (the fetch and the output)
+type ObservableProcessor interface {
+	// Observer returns the Observer notified of the stages of the processor
+	// Returns: the Observer, never nil
+	Observer() Observer
+}
@@ -4,17 +4,89 @@ import (
 	"context"
 	"github.com/SHIMA0111/kanjo/internal/domain/entities"
 	"github.com/go-gota/gota/dataframe"
+	"maps"
 )
 
 // OutputConfig represents configuration for output formatting and destination
 type OutputConfig struct {
-	Format      string                 `json:"format"`                // "csv", "console", "json"
+	Format      string                 `json:"format"`                // "csv", "console", "json", "report"
 	Destination string                 `json:"destination,omitempty"` // file path for file outputs
 	Options     map[string]interface{} `json:"options,omitempty"`     // format-specific options
+
+	// Metadata is the processing metadata written when the includeMetadata option is set.
+	// It is set by the caller running the pipeline, not loaded from the configuration file.
+	Metadata *entities.ProcessingMetadata `json:"-"`
+}
+
+// defaultOutputOptions holds the documented default options for each output format
+var defaultOutputOptions = map[string]map[string]interface{}{
+	"csv": {
+		"delimiter":          ",",     // Field delimiter (single character)
+		"header":             true,    // Write the header line
+		"quoteAll":           false,   // Quote all fields instead of only the fields requiring quotes
+		"lineEnding":         "lf",    // Line ending; lf or crlf
+		"showTotals":         false,   // Append a totals footer row
+		"totalsLabel":        "Total", // Label of the totals footer row
+		"totalsMethod":       "sum",   // Method computing the totals; sum or avg
+		"thousandsSeparator": false,   // Group the integer digits of the numeric columns by thousands
+		"currencySymbol":     "",      // Symbol prefixed to the values of the numeric columns
+		"includeMetadata":    "none",  // Write the metadata to the sidecar file; none or sidecar
+		"partitionBy":        "",      // Column splitting the output into the partition directories (empty for a single file)
+		"nullValue":          "NaN",   // Text of the null values
+	},
+	"console": {
+		"border":             "box",   // Border style; box, ascii, or none
+		"maxColWidth":        30,      // Maximum display width of each column (truncated with an ellipsis)
+		"showTotals":         false,   // Append a totals footer row
+		"totalsLabel":        "Total", // Label of the totals footer row
+		"totalsMethod":       "sum",   // Method computing the totals; sum or avg
+		"thousandsSeparator": false,   // Group the integer digits of the numeric columns by thousands
+		"currencySymbol":     "",      // Symbol prefixed to the values of the numeric columns
+		"nullValue":          "NaN",   // Text of the null values
+	},
+	"json": {
+		"indent":          "    ", // Indent string for each nesting level
+		"includeMetadata": "none", // Write the metadata; none, embed, or sidecar
+		"partitionBy":     "",     // Column splitting the output into the partition directories (empty for a single file)
+	},
+	"report": {
+		"style": "text", // Style of the report; text or markdown
+	},
+}
+
+// ApplyOutputDefaults fills the missing options of the config with the documented defaults of its format.
+// User-supplied options are preserved, and unknown formats are left untouched.
+// The Options map is replaced with a filled copy, so the map shared with the caller's OutputConfig is never modified.
+func ApplyOutputDefaults(config *OutputConfig) {
+	defaults, ok := defaultOutputOptions[config.Format]
+	if !ok {
+		return
+	}
+
+	options := make(map[string]interface{}, len(config.Options)+len(defaults))
+	maps.Copy(options, config.Options)
+	config.Options = options
+
+	for key, value := range defaults {
+		if _, exists := config.Options[key]; !exists {
+			config.Options[key] = value
+		}
+	}
+}
+
+// PreviewResult represents a structured preview of the output data for front-ends rendering their own tables
+type PreviewResult struct {
+	Columns     []string        `json:"columns"`     // Column names in order
+	ColumnTypes []string        `json:"columnTypes"` // Column types in order ("string", "int", "float", "bool")
+	Rows        [][]interface{} `json:"rows"`        // Typed row values (nil for null values)
+	TotalRows   int             `json:"totalRows"`   // Row count of the whole data
+	Truncated   bool            `json:"truncated"`   // True if Rows doesn't contain all rows
 }
 
 // Output handles result output in various formats and destinations
 // Implementations should handle file creation, formatting, and error recovery
+// Callers must call Close when they finish using the output, even after a failed Write,
+// and must not use the output after Close.
 type Output interface {
 	// Write outputs the process result according to the provided configuration
 	// ctx: context for cancellation and timeout control
@@ -23,13 +95,43 @@ type Output interface {
 	// Returns: error if write operation fails
 	//
 	// Implementation notes:
+	// - Should apply ApplyOutputDefaults to the config before use
 	// - Should create output directories if they don't exist
 	// - Should handle file permissions and disk space issues gracefully
 	// - Should validate output configuration before attempting writing
 	// - Should support context cancellation for log-running operations
 	// - Should preserve data formatting and types when possible
+	// - Should flush the written data to the destination before returning nil
 	Write(ctx context.Context, df *dataframe.DataFrame, config OutputConfig) error
 
+	// WriteStream outputs the rows received from the channel incrementally without materializing the whole data
+	// ctx: context for cancellation and timeout control
+	// rows: channel of the rows closed by the producer after the last row
+	// header: column names of the rows
+	// config: output configuration specifying format and destination
+	// Returns: error if write operation fails or is canceled
+	//
+	// Implementation notes:
+	// - Should apply ApplyOutputDefaults to the config before use
+	// - Should validate output configuration before receiving any row
+	// - Should receive the rows only as fast as they are written, so a slow destination blocks the producer (backpressure)
+	// - Should reject rows whose length differs from the header
+	// - Should stop receiving on error or cancellation; the producer must select on ctx.Done() to avoid blocking forever
+	// - Should not leave a partial file at the destination on error or cancellation
+	WriteStream(ctx context.Context, rows <-chan []string, header []string, config OutputConfig) error
+
+	// Close flushes the buffered data and releases the resources held by the output
+	// Returns: error if flushing or releasing fails
+	//
+	// The data written by Write may stay pending until Close (e.g. the CSV output moves its flushed temporary file
+	// to the destination), so the written data is complete only after Close returns nil. The outputs finishing their work in Write
+	// (e.g. the JSON output) have nothing to release and return nil.
+	//
+	// Implementation notes:
+	// - Should not close the writers provided by the caller, only flush them
+	// - Should be safe to call more than once
+	Close() error
+
 	// Validate checks if the output configuration is valid for this output target
 	// config: output configuration to validate
 	// Returns: error if configuration is invalid, nil if valid
@@ -56,4 +158,21 @@ type Output interface {
 	// maxRows: maximum number of rows to include in preview (0 for all)
 	// Returns: string preview of the output or error if preview fails
 	Preview(result *entities.Processing, config OutputConfig, maxRows int) (string, error)
+
+	// EstimateSize estimates the byte size of the whole output without writing
+	// result: process result to estimate
+	// config: output configuration
+	// Returns: estimated byte size or error if the configuration is invalid
+	//
+	// Implementation notes:
+	// - Should render a sample of the rows and extrapolate the size to the total rows, so the size is an estimate
+	// - Should be exact for the small data rendered as a whole
+	EstimateSize(result *entities.Processing, config OutputConfig) (int64, error)
+
+	// PreviewStructured generates a structured preview of the output for front-ends
+	// result: process result to preview
+	// config: output configuration
+	// maxRows: maximum number of rows to include in preview (0 for all)
+	// Returns: PreviewResult containing columns, typed rows, total row count, and truncation flag
+	PreviewStructured(result *entities.Processing, config OutputConfig, maxRows int) (*PreviewResult, error)
 }
@@ -0,0 +1,142 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"math"
+)
+
+// OptionBool returns the bool option of the key, or def if the option is missing or not a bool.
+func (c OutputConfig) OptionBool(key string, def bool) bool {
+	value, err := c.OptionBoolStrict(key, def)
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// OptionBoolStrict returns the bool option of the key, or def if the option is missing.
+// Returns a ConfigurationError of the option if it is not a bool.
+func (c OutputConfig) OptionBoolStrict(key string, def bool) (bool, error) {
+	value, ok := c.Options[key]
+	if !ok {
+		return def, nil
+	}
+
+	b, ok := value.(bool)
+	if !ok {
+		return def, optionTypeError(key, "a bool", value)
+	}
+
+	return b, nil
+}
+
+// OptionString returns the string option of the key, or def if the option is missing or not a string.
+func (c OutputConfig) OptionString(key string, def string) string {
+	value, err := c.OptionStringStrict(key, def)
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// OptionStringStrict returns the string option of the key, or def if the option is missing.
+// Returns a ConfigurationError of the option if it is not a string.
+func (c OutputConfig) OptionStringStrict(key string, def string) (string, error) {
+	value, ok := c.Options[key]
+	if !ok {
+		return def, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return def, optionTypeError(key, "a string", value)
+	}
+
+	return s, nil
+}
+
+// OptionInt returns the integer option of the key, or def if the option is missing or not an integer.
+// The integral floats are accepted because JSON numbers are decoded as float64.
+func (c OutputConfig) OptionInt(key string, def int) int {
+	value, err := c.OptionIntStrict(key, def)
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// OptionIntStrict returns the integer option of the key, or def if the option is missing.
+// The integral floats and json.Number are accepted. Returns a ConfigurationError of the option if it is not an integer.
+func (c OutputConfig) OptionIntStrict(key string, def int) (int, error) {
+	value, ok := c.Options[key]
+	if !ok {
+		return def, nil
+	}
+
+	number, ok := optionNumber(value)
+	// Beyond 2^53, the floats cannot represent every integer
+	if !ok || number != math.Trunc(number) || math.Abs(number) > 1<<53 {
+		return def, optionTypeError(key, "an integer", value)
+	}
+
+	return int(number), nil
+}
+
+// OptionFloat returns the numeric option of the key as a float, or def if the option is missing or not a number.
+func (c OutputConfig) OptionFloat(key string, def float64) float64 {
+	value, err := c.OptionFloatStrict(key, def)
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// OptionFloatStrict returns the numeric option of the key as a float, or def if the option is missing.
+// Any integer, float, or json.Number is accepted. Returns a ConfigurationError of the option if it is not a number.
+func (c OutputConfig) OptionFloatStrict(key string, def float64) (float64, error) {
+	value, ok := c.Options[key]
+	if !ok {
+		return def, nil
+	}
+
+	number, ok := optionNumber(value)
+	if !ok {
+		return def, optionTypeError(key, "a number", value)
+	}
+
+	return number, nil
+}
+
+// optionNumber converts the numeric option value into a float.
+func optionNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		// JSON numbers are decoded as float64
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case json.Number:
+		// json.Decoder.UseNumber decodes the numbers as json.Number
+		number, err := v.Float64()
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// optionTypeError returns the ConfigurationError of the option of the unexpected type.
+func optionTypeError(key, expected string, value interface{}) error {
+	return domainerrors.NewConfigurationError("options."+key, fmt.Sprintf("%s must be %s, got %v", key, expected, value), nil)
+}
@@ -0,0 +1,140 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// decodedOutputConfig returns the OutputConfig of the options decoded from the JSON, as the config file is read.
+func decodedOutputConfig(t *testing.T, options string, useNumber bool) OutputConfig {
+	t.Helper()
+
+	decoder := json.NewDecoder(strings.NewReader(options))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	config := OutputConfig{}
+	if err := decoder.Decode(&config.Options); err != nil {
+		t.Fatalf("decode %s: %v", options, err)
+	}
+
+	return config
+}
+
+func TestOutputConfigOptionGetters(t *testing.T) {
+	config := decodedOutputConfig(t, `{"header": false, "delimiter": ";", "indent": 4, "ratio": 0.5, "decimals": 2.5}`, false)
+
+	if got := config.OptionBool("header", true); got != false {
+		t.Errorf("OptionBool(header) = %v, want false", got)
+	}
+	if got := config.OptionBool("missing", true); got != true {
+		t.Errorf("OptionBool(missing) = %v, want the default true", got)
+	}
+	if got := config.OptionBool("delimiter", true); got != true {
+		t.Errorf("OptionBool(delimiter) = %v, want the default true", got)
+	}
+
+	if got := config.OptionString("delimiter", ","); got != ";" {
+		t.Errorf("OptionString(delimiter) = %q, want %q", got, ";")
+	}
+	if got := config.OptionString("missing", ","); got != "," {
+		t.Errorf("OptionString(missing) = %q, want the default %q", got, ",")
+	}
+	if got := config.OptionString("indent", ","); got != "," {
+		t.Errorf("OptionString(indent) = %q, want the default %q", got, ",")
+	}
+
+	// The JSON numbers are decoded as float64, and the integral ones are integers
+	if got := config.OptionInt("indent", 2); got != 4 {
+		t.Errorf("OptionInt(indent) = %d, want 4", got)
+	}
+	if got := config.OptionInt("decimals", 2); got != 2 {
+		t.Errorf("OptionInt(decimals) = %d, want the default 2", got)
+	}
+	if got := config.OptionInt("missing", 2); got != 2 {
+		t.Errorf("OptionInt(missing) = %d, want the default 2", got)
+	}
+
+	if got := config.OptionFloat("ratio", 1); got != 0.5 {
+		t.Errorf("OptionFloat(ratio) = %v, want 0.5", got)
+	}
+	if got := config.OptionFloat("indent", 1); got != 4 {
+		t.Errorf("OptionFloat(indent) = %v, want 4", got)
+	}
+	if got := config.OptionFloat("header", 1); got != 1 {
+		t.Errorf("OptionFloat(header) = %v, want the default 1", got)
+	}
+}
+
+func TestOutputConfigOptionNumberCoercion(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantInt   int
+		wantFloat float64
+	}{
+		{name: "int", value: 3, wantInt: 3, wantFloat: 3},
+		{name: "int64", value: int64(3), wantInt: 3, wantFloat: 3},
+		{name: "integral float64", value: 3.0, wantInt: 3, wantFloat: 3},
+		{name: "fractional float64", value: 3.5, wantInt: -1, wantFloat: 3.5},
+		{name: "float32", value: float32(0.25), wantInt: -1, wantFloat: 0.25},
+		{name: "json.Number", value: json.Number("7"), wantInt: 7, wantFloat: 7},
+		{name: "fractional json.Number", value: json.Number("7.5"), wantInt: -1, wantFloat: 7.5},
+		{name: "beyond 2^53", value: float64(1 << 60), wantInt: -1, wantFloat: 1 << 60},
+		{name: "NaN", value: math.NaN(), wantInt: -1, wantFloat: -1},
+		{name: "Inf", value: math.Inf(1), wantInt: -1, wantFloat: -1},
+		{name: "string", value: "3", wantInt: -1, wantFloat: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := OutputConfig{Options: map[string]interface{}{"n": tt.value}}
+			if got := config.OptionInt("n", -1); got != tt.wantInt {
+				t.Errorf("OptionInt(%v) = %d, want %d", tt.value, got, tt.wantInt)
+			}
+			if got := config.OptionFloat("n", -1); got != tt.wantFloat {
+				t.Errorf("OptionFloat(%v) = %v, want %v", tt.value, got, tt.wantFloat)
+			}
+		})
+	}
+
+	// json.Decoder.UseNumber decodes the numbers as json.Number
+	config := decodedOutputConfig(t, `{"indent": 4, "ratio": 0.5}`, true)
+	if got := config.OptionInt("indent", 2); got != 4 {
+		t.Errorf("OptionInt(indent) of json.Number = %d, want 4", got)
+	}
+	if got := config.OptionFloat("ratio", 1); got != 0.5 {
+		t.Errorf("OptionFloat(ratio) of json.Number = %v, want 0.5", got)
+	}
+}
+
+func TestOutputConfigOptionStrict(t *testing.T) {
+	config := OutputConfig{Options: map[string]interface{}{"header": "yes", "delimiter": 1, "indent": 2.5, "ratio": "half"}}
+
+	checks := map[string]func() error{
+		"header":    func() error { _, err := config.OptionBoolStrict("header", true); return err },
+		"delimiter": func() error { _, err := config.OptionStringStrict("delimiter", ","); return err },
+		"indent":    func() error { _, err := config.OptionIntStrict("indent", 2); return err },
+		"ratio":     func() error { _, err := config.OptionFloatStrict("ratio", 1); return err },
+	}
+	for key, check := range checks {
+		err := check()
+		var configErr *domainerrors.ConfigurationError
+		if !errors.As(err, &configErr) || configErr.Field != "options."+key {
+			t.Errorf("strict getter of %s error = %v, want a ConfigurationError of options.%s", key, err, key)
+		}
+	}
+
+	// The missing options are the defaults without an error
+	empty := OutputConfig{}
+	if got, err := empty.OptionIntStrict("indent", 2); err != nil || got != 2 {
+		t.Errorf("OptionIntStrict(missing) = %d, %v, want the default 2 and no error", got, err)
+	}
+	if got, err := empty.OptionBoolStrict("header", true); err != nil || got != true {
+		t.Errorf("OptionBoolStrict(missing) = %v, %v, want the default true and no error", got, err)
+	}
+}
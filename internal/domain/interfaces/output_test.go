@@ -0,0 +1,42 @@
+package interfaces
+
+import (
+	"testing"
+)
+
+func TestApplyOutputDefaults(t *testing.T) {
+	config := OutputConfig{Format: "csv"}
+	ApplyOutputDefaults(&config)
+
+	for key, want := range defaultOutputOptions["csv"] {
+		if got, ok := config.Options[key]; !ok || got != want {
+			t.Errorf("Options[%q] = %v, want the default %v", key, got, want)
+		}
+	}
+}
+
+func TestApplyOutputDefaultsPreservesUserValues(t *testing.T) {
+	options := map[string]interface{}{"delimiter": ";", "header": false, "custom": 1}
+	config := OutputConfig{Format: "csv", Options: options}
+	ApplyOutputDefaults(&config)
+
+	for key, want := range map[string]interface{}{"delimiter": ";", "header": false, "custom": 1, "quoteAll": false} {
+		if got := config.Options[key]; got != want {
+			t.Errorf("Options[%q] = %v, want %v", key, got, want)
+		}
+	}
+
+	// The map of the caller is not modified
+	if len(options) != 3 {
+		t.Errorf("caller options = %v, want the 3 options it had", options)
+	}
+}
+
+func TestApplyOutputDefaultsUnknownFormat(t *testing.T) {
+	config := OutputConfig{Format: "parquet"}
+	ApplyOutputDefaults(&config)
+
+	if config.Options != nil {
+		t.Errorf("Options = %v, want nil for the unknown format", config.Options)
+	}
+}
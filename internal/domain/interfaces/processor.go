@@ -10,6 +10,77 @@ import (
 // All operations should be performed in a way that preserves data integrity
 // and provides meaningful error messages for debugging
 type Processor interface {
+	// Normalize trims the whitespace of the string columns according to the normalize configurations
+	// data: input DataFrame to normalize
+	// config: slice of normalize configurations defining the column and the optional normalizations
+	// Returns: DataFrame with the normalized values or error if normalize fails
+	//
+	// Implementation notes:
+	// - Should be applied right after fetch, before the other stages compare the values
+	// - Should validate that the columns exist and are string columns
+	// - Should keep the null values null
+	Normalize(ctx context.Context, data *dataframe.DataFrame, config []entities.NormalizeConfig) (*dataframe.DataFrame, error)
+
+	// Cast forces the columns to the types according to the cast configurations
+	// data: input DataFrame to cast
+	// config: slice of cast configurations defining the column and the target type
+	// Returns: DataFrame with the cast columns or error if any value cannot be cast
+	//
+	// Implementation notes:
+	// - Should be applied right after fetch, before any other step
+	// - Should keep the null values as null
+	// - Should return a recoverable error naming the column, the row, and the value that cannot be cast
+	Cast(ctx context.Context, data *dataframe.DataFrame, config []entities.CastConfig) (*dataframe.DataFrame, error)
+
+	// Watermark keeps only the rows newer than the watermark of the previous run
+	// data: input DataFrame to filter
+	// config: watermark configuration defining the compared column and the previous watermark
+	// Returns: DataFrame of the rows whose value is greater than the watermark, the new watermark, or error if it fails
+	//
+	// Implementation notes:
+	// - Should validate that the column exists and its type is comparable
+	// - Should return the maximum value of the kept rows as the new watermark (the previous one if no rows are kept)
+	// - Should preserve the original order of the kept rows
+	Watermark(ctx context.Context, data *dataframe.DataFrame, config entities.WatermarkConfig) (*dataframe.DataFrame, string, error)
+
+	// Dedup removes duplicate rows according to the dedup configuration
+	// data: input DataFrame to deduplicate
+	// config: dedup configuration defining the compared columns and which row to keep
+	// Returns: DataFrame without duplicate rows or error if dedup fails
+	//
+	// Implementation notes:
+	// - Should compare the whole row when no columns are specified
+	// - Should validate that the compared columns exist
+	// - Should preserve the original order of the kept rows
+	Dedup(ctx context.Context, data *dataframe.DataFrame, config entities.DedupConfig) (*dataframe.DataFrame, error)
+
+	// Distinct returns the unique rows over the columns without aggregating them
+	// data: input DataFrame to take the unique rows from
+	// columns: columns compared and kept in the result (all columns if empty)
+	// Returns: DataFrame of only the columns with the unique rows or error if distinct fails
+	//
+	// Implementation notes:
+	// - Should validate that the columns exist
+	// - Should preserve the order of the first occurrence of each row
+	// - Should treat the null values as equal to each other
+	Distinct(ctx context.Context, data *dataframe.DataFrame, columns []string) (*dataframe.DataFrame, error)
+
+	// FillNull fills null (missing or blank) values of the columns according to the fill configurations
+	// data: input DataFrame to fill
+	// config: slice of fill configurations defining the column and the fill method
+	// Returns: DataFrame with filled values or error if fill fails
+	//
+	// Supported fill methods:
+	// - literal: Fill with the specified value
+	// - mean: Fill with the mean of the non-null values (numeric column only)
+	// - zero: Fill with 0
+	// - forward: Fill with the last non-null value above
+	//
+	// Implementation notes:
+	// - Should be applied before filtering, merging, and aggregation
+	// - Should validate that target columns exist
+	FillNull(ctx context.Context, data *dataframe.DataFrame, config []entities.FillConfig) (*dataframe.DataFrame, error)
+
 	// Filter applies filter expressions to the data and returns filtered DataFrame
 	// data: input DataFrame to filter
 	// config: slice of filter configurations defining how to filter columns
@@ -19,6 +90,8 @@ type Processor interface {
 	// - Equality: ==, !=
 	// - Comparison: <, <=, >, >=
 	// - String operations: contains, startWith, endWith
+	// - Membership: in, notIn (the Values or the values of the ValuesFile)
+	// - Delimited membership: containsAny, containsAll (the cell split by the Delimiter against the Values)
 	//
 	// Supported logical operator combine filters:
 	// - or: Combine the next filter with OR condition
@@ -29,18 +102,32 @@ type Processor interface {
 	// - Should handle type conversions automatically (string to date, etc.)
 	// - Should provide detailed error messages for invalid expressions
 	// - Should preserve original column type in filtered result
+	// - Should never match the null (missing or blank) values, as FillNull treats them
 	Filter(ctx context.Context, data *dataframe.DataFrame, config []entities.FilterConfig) (*dataframe.DataFrame, error)
 
+	// FilterMask returns the indexes of the rows the filter configurations match instead of the filtered DataFrame
+	// data: input DataFrame to filter
+	// config: slice of filter configurations combined as Filter does
+	// Returns: 0-based indexes of the matching rows in the row order, or error if filter expression is invalid
+	//
+	// Implementation notes:
+	// - Should return exactly the rows Filter keeps, e.g. to highlight them in a UI
+	// - Should return an empty slice if no rows match, even when Filter rejects the empty result
+	FilterMask(ctx context.Context, data *dataframe.DataFrame, config []entities.FilterConfig) ([]int, error)
+
 	// Merge combines columns according to the provided merge configurations
 	// data: input DataFrame to perform merge operations on
 	// config: slice of merge configurations defining how to combine columns
 	// Returns: DataFrame with merged columns or error if merge fails
 	//
 	// Supported merge strategies:
-	// - concat: Concatenate the columns data
+	// - concat: Concatenate the columns data joined by the Separator (quoting the values containing it as in RFC 4180)
 	// - sum: Sum the columns data (if specified non-numeric column, returns error)
 	// - first: Prior the first column data, and if the first column is missing, the second value represented
 	// - second: Prior the second column data (the thought is the same as the `first` strategy)
+	// - divide: first / second (if specified non-numeric column, returns error)
+	// - percentage: first / second * 100 rounded to the DecimalPlaces (if specified non-numeric column, returns error)
+	// - coalesce: The first non-null value of the Columns, or the DefaultValues[0] if all are null
 	//
 	// Implementation notes:
 	// - Should validate that source columns exist before merging
@@ -49,6 +136,18 @@ type Processor interface {
 	// - Should create new result columns without modifying originals
 	Merge(ctx context.Context, data *dataframe.DataFrame, config []entities.MergeConfig) (*dataframe.DataFrame, error)
 
+	// Compute adds the computed columns evaluated from the arithmetic expressions
+	// data: DataFrame to add the columns to
+	// config: computed columns applied in order (a later expression can refer to an earlier computed column)
+	// Returns: new DataFrame with the computed float columns or error if computation fails
+	//
+	// Implementation notes:
+	// - Should parse every expression and validate the referenced columns before computing any column
+	// - Should reject non-numeric referenced columns and names colliding with existing columns
+	// - Should produce null for the rows with a null operand
+	// - Should produce null for a division by zero unless the implementation is configured otherwise
+	Compute(ctx context.Context, data *dataframe.DataFrame, config []entities.ComputedColumn) (*dataframe.DataFrame, error)
+
 	// Aggregate performs grouping and aggregation operations on the data
 	// data: input DataFrame to aggregate
 	// config: slice of aggregate
@@ -61,29 +160,88 @@ type Processor interface {
 	// - max: Maximum data of the specified column data each group
 	// - count: Counting data number of the specified column data in each group
 	// - median: Median of the specified column data each group
+	//   (the average of the two middle values for an even count,
+	//   or the estimate in constant memory if the Aggregation is Approximate)
+	// - weightedAvg: Average of the specified column data weighted by the WeightColumn each group
+	//   (null if the total weight of the group is zero unless the implementation is configured otherwise)
+	// - sharePercent: Total of the specified column data each group as a percentage of the total of all groups
+	//   (null if the total of all groups is zero unless the implementation is configured otherwise)
 	//
 	// Implementation notes:
 	// - Should validate that target columns exist and are appropriate for aggregation method
 	// - Should handle multiple grouping columns correctly
 	// - Should preserve grouping column values in result
+	// - Should order the groups deterministically by the values of the grouping columns (nulls last)
 	// - Should handle null/missing values appropriately for each aggregation type
+	// - Should apply the aggregation only to rows matching the Condition when it is set
+	//   (0 for count and null for the other methods when no rows match)
+	// - Should report the progress to the ProgressFunc of the context (see WithProgress) if any
 	Aggregate(ctx context.Context, data *dataframe.DataFrame, config []entities.AggregationConfig) (*dataframe.DataFrame, error)
 
+	// Join combines two DataFrames by the key columns
+	// left: left side DataFrame of the join
+	// right: right side DataFrame of the join
+	// config: join configuration defining the key columns and the join type
+	// Returns: joined DataFrame or error if join fails
+	//
+	// Supported join types:
+	// - inner: Only rows whose key exists in both DataFrames
+	// - left: All rows of the left DataFrame, missing right values are null
+	// - right: All rows of the right DataFrame, missing left values are null
+	// - outer: All rows of both DataFrames
+	//
+	// Implementation notes:
+	// - Should validate that the key columns exist in both DataFrames
+	// - Should name the result key column after the left key
+	// - Should suffix overlapping non-key columns with "_left" and "_right"
+	Join(ctx context.Context, left, right *dataframe.DataFrame, config entities.JoinConfig) (*dataframe.DataFrame, error)
+
+	// InspectTypes reports the columns that look mistyped after fetch
+	// data: fetched DataFrame to inspect
+	// Returns: warnings of the suspicious columns (empty if none)
+	//
+	// Implementation notes:
+	// - Should report the string columns whose values are mostly numeric with the offending sample values
+	// - Should ignore the null values
+	InspectTypes(data *dataframe.DataFrame) []entities.TypeWarning
+
 	// ValidateExpression checks if a filter expression is syntactically valid
 	// expression: filter expression to validate
 	// columnNames: available column names for validate
 	// Returns: error if expression is invalid, nil if valid
+	//
+	// Syntax: terms of "column operator value" joined by "and" / "or"
+	// e.g. `price >= 100 and "customer name" eq 'ACME Inc.' or status == paid`
+	//
+	// Implementation notes:
+	// - Should report the 1-based character position of the invalid part
+	// - Should validate that the referenced columns exist in columnNames
+	// - Should validate that the operators are supported
 	ValidateExpression(expression string, columnNames []string) error
 
+	// Explain describes the planned stages of the config in order without fetching the data
+	// config: the config to explain
+	// Returns: readable plan with one line per stage or error if the config is invalid
+	//
+	// Implementation notes:
+	// - Should validate the config first, and show the defaults it resolves
+	// - Should show the filters as they are combined by the logical operators
+	// - Should show the grouping columns and the result names of the aggregations
+	Explain(config *entities.Config) (string, error)
+
 	// GetSupportedOperators returns a list of supported filter operators
 	// Returns: slice of operator strings (e.g., ["==", "!=", "<", "<=", ">", ">="])
 	GetSupportedOperators() []string
 
+	// GetSupportedLogicalOperators returns a list of supported logical operators combining filters
+	// Returns: slice of logical operator strings (e.g., ["and", "or"])
+	GetSupportedLogicalOperators() []string
+
 	// GetSupportedMergeStrategies returns a list of merge strategies
 	// Returns: slice of strategies (e.g., ["concat", "sum", "first", "second"])
 	GetSupportedMergeStrategies() []string
 
 	// GetSupportedAggregations returns a list of supported aggregation types
-	// Returns: slice of aggregation type strings (e.g., ["sum", "avg", "min", "max", "count"])
+	// Returns: slice of aggregation type strings (e.g., ["sum", "avg", "min", "max", "count", "median", "weightedAvg", "sharePercent"])
 	GetSupportedAggregations() []string
 }
@@ -0,0 +1,27 @@
+package interfaces
+
+import "context"
+
+// ProgressFunc receives the progress of a long-running step such as fetch and aggregation
+// done: units (usually rows) processed so far, never decreasing within a step
+// total: estimated units of the whole step, or -1 when unknown
+//
+// Implementation notes:
+// - Should be invoked periodically, not for every row, to keep the overhead low
+// - Should be invoked with done equal to total when the step completes (total is corrected to the actual count)
+// - Should not be invoked concurrently, so the callback doesn't need to be goroutine-safe
+type ProgressFunc func(done, total int)
+
+// progressKey is the context key of the ProgressFunc
+type progressKey struct{}
+
+// WithProgress returns a copy of the context carrying the ProgressFunc invoked by the data sources and the processor.
+func WithProgress(ctx context.Context, progress ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// ProgressFromContext returns the ProgressFunc carried by the context, or nil if none is set.
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return progress
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import "context"
+
+// RejectFunc receives a row diverted from the processing because of its bad data (e.g. a value that cannot be cast)
+// step: step rejecting the row (e.g. "cast", "compute")
+// row: values of the row in the input of the step in the column order (nil for null values)
+// reason: description of the failure naming the column and the value
+//
+// Implementation notes:
+// - Should be invoked once for each rejected row in the row order
+// - Should not be invoked concurrently, so the callback doesn't need to be goroutine-safe
+type RejectFunc func(step string, row []interface{}, reason string)
+
+// rejectKey is the context key of the RejectFunc
+type rejectKey struct{}
+
+// WithRejectFunc returns a copy of the context carrying the RejectFunc invoked by the processor collecting the bad rows.
+func WithRejectFunc(ctx context.Context, reject RejectFunc) context.Context {
+	return context.WithValue(ctx, rejectKey{}, reject)
+}
+
+// RejectFuncFromContext returns the RejectFunc carried by the context, or nil if none is set.
+func RejectFuncFromContext(ctx context.Context) RejectFunc {
+	reject, _ := ctx.Value(rejectKey{}).(RejectFunc)
+	return reject
+}
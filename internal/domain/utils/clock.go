@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time, so the timing of the processing can be made deterministic in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// realClock implements Clock with time.Now
+type realClock struct{}
+
+// Now implements Clock
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+var (
+	clockMu sync.RWMutex
+	// clock is the source of Now; the real clock unless replaced by SetClock
+	clock Clock = realClock{}
+)
+
+// SetClock replaces the source of Now with the clock, e.g. a fake clock asserting the exact processing time in tests,
+// and returns the function restoring the previous source. A nil clock restores the real clock.
+func SetClock(c Clock) (restore func()) {
+	if c == nil {
+		c = realClock{}
+	}
+
+	clockMu.Lock()
+	previous := clock
+	clock = c
+	clockMu.Unlock()
+
+	return func() {
+		clockMu.Lock()
+		clock = previous
+		clockMu.Unlock()
+	}
+}
+
+// Now returns the current time of the clock; time.Now unless the clock is replaced by SetClock.
+// The timing of the processing and the relative dates of the filters read the current time from here.
+func Now() time.Time {
+	clockMu.RLock()
+	c := clock
+	clockMu.RUnlock()
+
+	return c.Now()
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// fixedClock is a Clock always returning the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestSetClock(t *testing.T) {
+	fixed := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	fake := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	restore := SetClock(fixedClock(fixed))
+	if got := Now(); !got.Equal(fixed) {
+		t.Errorf("Now() = %v, want the fixed %v", got, fixed)
+	}
+
+	// The clocks set in turn are restored in the reverse order
+	restoreNested := SetClock(fixedClock(fake))
+	if got := Now(); !got.Equal(fake) {
+		t.Errorf("Now() = %v, want the nested %v", got, fake)
+	}
+	restoreNested()
+	if got := Now(); !got.Equal(fixed) {
+		t.Errorf("Now() after restoring the nested clock = %v, want %v", got, fixed)
+	}
+
+	// A nil clock is the real clock
+	restoreReal := SetClock(nil)
+	before := time.Now()
+	if got := Now(); got.Before(before) || got.After(time.Now()) {
+		t.Errorf("Now() of the nil clock = %v, want the current time", got)
+	}
+	restoreReal()
+
+	restore()
+	before = time.Now()
+	if got := Now(); got.Before(before) || got.After(time.Now()) {
+		t.Errorf("Now() after restoring = %v, want the current time of the real clock", got)
+	}
+}
@@ -3,15 +3,47 @@ package utils
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"io"
+	"sync"
 )
 
+var (
+	randomReaderMu sync.RWMutex
+	// randomReader is the source of RandomString; crypto/rand unless replaced by SetRandomReader
+	randomReader io.Reader = rand.Reader
+)
+
+// SetRandomReader replaces the source of RandomString with the reader, e.g. a fixed byte sequence
+// for the reproducible config names in tests and benchmarks, and returns the function restoring the previous source.
+// A nil reader restores crypto/rand. The reader must provide enough bytes, or RandomString panics.
+func SetRandomReader(reader io.Reader) (restore func()) {
+	if reader == nil {
+		reader = rand.Reader
+	}
+
+	randomReaderMu.Lock()
+	previous := randomReader
+	randomReader = reader
+	randomReaderMu.Unlock()
+
+	return func() {
+		randomReaderMu.Lock()
+		randomReader = previous
+		randomReaderMu.Unlock()
+	}
+}
+
 // RandomString generates a random hexadecimal string of the specified length.
-// The function uses cryptographic randomness for secure generation.
+// The function uses cryptographic randomness for secure generation unless the source is replaced by SetRandomReader.
 func RandomString(length int) string {
 	resultBytes := make([]byte, length)
 
-	if _, err := rand.Read(resultBytes); err != nil {
-		// From the crypto/rand manual contract us that never return error
+	randomReaderMu.RLock()
+	reader := randomReader
+	randomReaderMu.RUnlock()
+
+	if _, err := io.ReadFull(reader, resultBytes); err != nil {
+		// From the crypto/rand manual contract us that never return error, so only a replaced source can fail
 		panic(err)
 	}
 
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+// countingReader is a deterministic reader of the byte sequence 0, 1, 2, ...
+type countingReader struct {
+	next byte
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.next
+		r.next++
+	}
+
+	return len(p), nil
+}
+
+func TestRandomStringWithFixedReader(t *testing.T) {
+	restore := SetRandomReader(&countingReader{})
+	t.Cleanup(restore)
+
+	// Each byte is encoded as two hex digits, and the sequence continues across the calls
+	if got, want := RandomString(4), "00010203"; got != want {
+		t.Errorf("RandomString(4) = %q, want %q", got, want)
+	}
+	if got, want := RandomString(2), "0405"; got != want {
+		t.Errorf("RandomString(2) = %q, want %q", got, want)
+	}
+
+	// The same reader gives the same output
+	SetRandomReader(bytes.NewReader([]byte{0xde, 0xad, 0xbe, 0xef}))
+	if got, want := RandomString(4), "deadbeef"; got != want {
+		t.Errorf("RandomString(4) = %q, want %q", got, want)
+	}
+}
+
+func TestSetRandomReaderRestore(t *testing.T) {
+	restore := SetRandomReader(bytes.NewReader(make([]byte, 4)))
+	if got := RandomString(4); got != "00000000" {
+		t.Errorf("RandomString(4) = %q, want %q", got, "00000000")
+	}
+	restore()
+
+	// crypto/rand is restored, which never gives the same 16 bytes twice in practice
+	first, second := RandomString(16), RandomString(16)
+	if len(first) != 32 || first == second {
+		t.Errorf("RandomString(16) = %q and %q, want two different 32-digit strings", first, second)
+	}
+
+	// The nil reader restores crypto/rand as well
+	t.Cleanup(SetRandomReader(nil))
+	if got := RandomString(8); len(got) != 16 {
+		t.Errorf("RandomString(8) = %q, want 16 digits", got)
+	}
+}
+
+func TestRandomStringPanicsOnShortReader(t *testing.T) {
+	t.Cleanup(SetRandomReader(bytes.NewReader([]byte{1, 2})))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RandomString() didn't panic, want the panic of the exhausted reader")
+		}
+	}()
+	RandomString(4)
+}
@@ -0,0 +1,343 @@
+package datasource
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ensure CSVDataSource implements the DataSource interface
+var _ interfaces.DataSource = (*CSVDataSource)(nil)
+
+// CSVDataSource retrieves data from a local CSV file.
+// The Source of the DataSourceConfig represents the file path, and the first line is treated as the header.
+// The blank and duplicated headers are renamed to unique names (see sanitizeHeader) before the ColumnMapping is applied.
+// The files with the ".gz" extension are decompressed with gzip transparently.
+// The Options of the DataSourceConfig are decoded into csvSourceOptions.
+type CSVDataSource struct{}
+
+// csvSourceOptions holds the options of the CSV data source
+type csvSourceOptions struct {
+	Delimiter string `json:"delimiter"` // Field delimiter (single character, default ",")
+}
+
+// parseCSVSourceOptions decodes and validates the options of the config.
+func parseCSVSourceOptions(config interfaces.DataSourceConfig) (csvSourceOptions, error) {
+	options := csvSourceOptions{
+		Delimiter: ",",
+	}
+	if err := config.DecodeOptions(&options); err != nil {
+		return options, err
+	}
+
+	if utf8.RuneCountInString(options.Delimiter) != 1 {
+		return options, domainerrors.NewConfigurationError("options.delimiter", fmt.Sprintf("delimiter must be a single character, got '%s'", options.Delimiter), nil)
+	}
+	if r, _ := utf8.DecodeRuneInString(options.Delimiter); r == '"' || r == '\r' || r == '\n' {
+		return options, domainerrors.NewConfigurationError("options.delimiter", fmt.Sprintf("delimiter cannot be %q", r), nil)
+	}
+
+	return options, nil
+}
+
+// newCSVReader creates the CSV reader of the file with the options.
+func newCSVReader(file io.Reader, options csvSourceOptions) *csv.Reader {
+	reader := csv.NewReader(file)
+	reader.Comma, _ = utf8.DecodeRuneInString(options.Delimiter)
+
+	return reader
+}
+
+// NewCSVDataSource creates a new CSVDataSource instance.
+func NewCSVDataSource() *CSVDataSource {
+	return &CSVDataSource{}
+}
+
+// progressInterval is the number of rows between the progress reports of the fetch
+const progressInterval = 10000
+
+// Fetch reads the CSV file specified by the config and returns it as a DataFrame.
+// When the context carries a ProgressFunc, the read rows are reported against the total estimated by EstimateRowCount.
+func (c *CSVDataSource) Fetch(ctx context.Context, config interfaces.DataSourceConfig) (*dataframe.DataFrame, error) {
+	if err := c.Validate(config); err != nil {
+		return nil, err
+	}
+	options, err := parseCSVSourceOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", "fetch is canceled", err)
+	}
+
+	file, err := openCSV("fetch", config.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	progress := interfaces.ProgressFromContext(ctx)
+	total := -1
+	if progress != nil {
+		if estimate, err := c.EstimateRowCount(ctx, config); err == nil {
+			total = estimate
+		}
+	}
+
+	// Same as dataframe.ReadCSV except that the records are read one by one to report the progress
+	reader := newCSVReader(file, options)
+	records := make([][]string, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readError("fetch", config.Source, "failed to read CSV", err)
+		}
+		records = append(records, record)
+
+		if rows := len(records) - 1; progress != nil && rows > 0 && rows%progressInterval == 0 {
+			progress(rows, total)
+		}
+	}
+	if progress != nil {
+		rows := max(len(records)-1, 0)
+		progress(rows, rows)
+	}
+
+	df := loadRecords(ctx, records)
+	if df.Err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", fmt.Sprintf("failed to read CSV '%s'", config.Source), df.Err)
+	}
+	if err := applyColumnMapping(&df, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	return &df, nil
+}
+
+// Sample reads the header and only the first n rows of the CSV file, and returns them as a DataFrame.
+// The column types are inferred from the sampled rows.
+func (c *CSVDataSource) Sample(ctx context.Context, config interfaces.DataSourceConfig, n int) (*dataframe.DataFrame, error) {
+	if err := validateSampleSize(n); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(config); err != nil {
+		return nil, err
+	}
+	options, err := parseCSVSourceOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("sample", "sample is canceled", err)
+	}
+
+	file, err := openCSV("sample", config.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// The header and n rows
+	reader := newCSVReader(file, options)
+	records := make([][]string, 0, n+1)
+	for len(records) <= n {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readError("sample", config.Source, "failed to read CSV", err)
+		}
+		records = append(records, record)
+	}
+
+	df := loadRecords(ctx, records)
+	if df.Err != nil {
+		return nil, domainerrors.NewDataProcessError("sample", fmt.Sprintf("failed to read CSV '%s'", config.Source), df.Err)
+	}
+	if err := applyColumnMapping(&df, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	return &df, nil
+}
+
+// Validate checks the config has the supported type, valid options and ColumnMapping, and points to an existing file.
+func (c *CSVDataSource) Validate(config interfaces.DataSourceConfig) error {
+	if !slices.Contains(c.SupportedTypes(), config.Type) {
+		return domainerrors.NewConfigurationError("type", fmt.Sprintf("unsupported type '%s' for CSV data source", config.Type), nil)
+	}
+	if config.Source == "" {
+		return domainerrors.NewConfigurationError("source", "source is required", nil)
+	}
+
+	info, err := os.Stat(config.Source)
+	if err != nil {
+		return domainerrors.NewConfigurationError("source", fmt.Sprintf("cannot access '%s'", config.Source), err)
+	}
+	if info.IsDir() {
+		return domainerrors.NewConfigurationError("source", fmt.Sprintf("'%s' is a directory", config.Source), nil)
+	}
+	if _, err := parseCSVSourceOptions(config); err != nil {
+		return err
+	}
+
+	return validateColumnMapping(config)
+}
+
+// GetSourceInfo returns human-readable information about the CSV file with the estimated dimensions.
+func (c *CSVDataSource) GetSourceInfo(config interfaces.DataSourceConfig) string {
+	rows, err := c.EstimateRowCount(context.Background(), config)
+	if err != nil {
+		return formatSourceInfo("csv", config.Source, -1, -1)
+	}
+
+	return formatSourceInfo("csv", config.Source, rows, c.countColumns(config))
+}
+
+// countColumns reads only the header line of the CSV file and returns the number of columns.
+// Returns -1 if the header cannot be read.
+func (c *CSVDataSource) countColumns(config interfaces.DataSourceConfig) int {
+	options, err := parseCSVSourceOptions(config)
+	if err != nil {
+		return -1
+	}
+
+	file, err := openCSV("estimate", config.Source)
+	if err != nil {
+		return -1
+	}
+	defer file.Close()
+
+	header, err := newCSVReader(file, options).Read()
+	if err != nil {
+		return -1
+	}
+
+	return len(header)
+}
+
+// SupportedTypes returns the source types supported by CSVDataSource.
+func (c *CSVDataSource) SupportedTypes() []string {
+	return []string{"csv"}
+}
+
+// GetCapabilities returns the features supported by CSVDataSource.
+// CSV files are always read as a whole and don't require any authentication.
+func (c *CSVDataSource) GetCapabilities(_ interfaces.DataSourceConfig) interfaces.Capabilities {
+	return interfaces.Capabilities{
+		SupportsRange:              false,
+		SupportsStreaming:          false,
+		SupportsRowCountEstimation: true,
+		RequiredAuth:               "none",
+	}
+}
+
+// EstimateRowCount estimates the row count of the CSV file by counting newlines, excluding the header line.
+// Newlines within quoted fields are also counted, so the result can be larger than the actual row count.
+// The gzipped files are decompressed to count the newlines, which is still cheaper than parsing them.
+func (c *CSVDataSource) EstimateRowCount(ctx context.Context, config interfaces.DataSourceConfig) (int, error) {
+	if err := c.Validate(config); err != nil {
+		return -1, err
+	}
+
+	file, err := openCSV("estimate", config.Source)
+	if err != nil {
+		return -1, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 32*1024)
+	lines := 0
+	var lastByte byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return -1, domainerrors.NewDataProcessError("estimate", "estimation is canceled", err)
+		}
+
+		n, err := file.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			lastByte = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return -1, readError("estimate", config.Source, "failed to read", err)
+		}
+	}
+
+	// The last line doesn't always end with the newline
+	if lastByte != 0 && lastByte != '\n' {
+		lines++
+	}
+
+	// Exclude the header line
+	if lines == 0 {
+		return 0, nil
+	}
+
+	return lines - 1, nil
+}
+
+// gzipReadCloser closes both the gzip reader and the underlying file
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the gzip reader and the file.
+func (g *gzipReadCloser) Close() error {
+	return errors.Join(g.Reader.Close(), g.file.Close())
+}
+
+// openCSV opens the CSV file, decompressing it with gzip if the path has the ".gz" extension.
+// Returns a DataProcessError of the step if the file cannot be opened or doesn't have a valid gzip header.
+func openCSV(step, path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError(step, fmt.Sprintf("failed to open '%s'", path), err)
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".gz") {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, domainerrors.NewDataProcessError(step, fmt.Sprintf("'%s' is not a valid gzip file", path), err)
+	}
+
+	return &gzipReadCloser{Reader: reader, file: file}, nil
+}
+
+// readError describes the error reading the file, telling the corrupt gzip stream apart from the other errors.
+func readError(step, path, message string, err error) error {
+	var corrupt flate.CorruptInputError
+	if errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) || errors.As(err, &corrupt) ||
+		(errors.Is(err, io.ErrUnexpectedEOF) && strings.EqualFold(filepath.Ext(path), ".gz")) {
+		return domainerrors.NewDataProcessError(step, fmt.Sprintf("gzip stream of '%s' is corrupt or truncated", path), err)
+	}
+
+	return domainerrors.NewDataProcessError(step, fmt.Sprintf("%s '%s'", message, path), err)
+}
@@ -0,0 +1,256 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// salesFixture is the CSV fixture of the header and 5 rows of the columns id, name, region, and amount
+const salesFixture = "testdata/sales.csv"
+
+// writeFile writes the content to the file of the name in a temporary directory of the test and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	return path
+}
+
+// csvConfig returns the config of the CSV data source reading the file.
+func csvConfig(path string) interfaces.DataSourceConfig {
+	return interfaces.DataSourceConfig{Type: "csv", Source: path}
+}
+
+func TestCSVDataSourceGetCapabilities(t *testing.T) {
+	got := NewCSVDataSource().GetCapabilities(csvConfig("data.csv"))
+	want := interfaces.Capabilities{
+		SupportsRange:              false,
+		SupportsStreaming:          false,
+		SupportsRowCountEstimation: true,
+		RequiredAuth:               "none",
+	}
+	if got != want {
+		t.Errorf("GetCapabilities() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCSVDataSourceEstimateRowCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "trailing newline", content: "a,b\n1,2\n3,4\n", want: 2},
+		{name: "no trailing newline", content: "a,b\n1,2\n3,4", want: 2},
+		{name: "header only", content: "a,b\n", want: 0},
+		{name: "empty", content: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCSVDataSource().EstimateRowCount(context.Background(), csvConfig(writeFile(t, "data.csv", tt.content)))
+			if err != nil {
+				t.Fatalf("EstimateRowCount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EstimateRowCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("fixture", func(t *testing.T) {
+		got, err := NewCSVDataSource().EstimateRowCount(context.Background(), csvConfig(salesFixture))
+		if err != nil {
+			t.Fatalf("EstimateRowCount() error = %v", err)
+		}
+		if got != 5 {
+			t.Errorf("EstimateRowCount() = %d, want 5", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		got, err := NewCSVDataSource().EstimateRowCount(context.Background(), csvConfig(filepath.Join(t.TempDir(), "missing.csv")))
+		if err == nil {
+			t.Fatal("EstimateRowCount() error = nil, want the error of the missing file")
+		}
+		if got != -1 {
+			t.Errorf("EstimateRowCount() = %d, want -1", got)
+		}
+	})
+}
+
+func TestCSVDataSourceGetSourceInfo(t *testing.T) {
+	if got, want := NewCSVDataSource().GetSourceInfo(csvConfig(salesFixture)), "csv: testdata/sales.csv (~5 rows, 4 columns)"; got != want {
+		t.Errorf("GetSourceInfo() = %q, want %q", got, want)
+	}
+
+	// The missing file cannot be estimated
+	missing := filepath.Join(t.TempDir(), "missing.csv")
+	if got, want := NewCSVDataSource().GetSourceInfo(csvConfig(missing)), "csv: "+missing; got != want {
+		t.Errorf("GetSourceInfo() = %q, want %q", got, want)
+	}
+}
+
+// progressCall is a call of the ProgressFunc
+type progressCall struct {
+	done, total int
+}
+
+// recordProgress returns the context carrying the ProgressFunc recording its calls.
+func recordProgress(calls *[]progressCall) context.Context {
+	return interfaces.WithProgress(context.Background(), func(done, total int) {
+		*calls = append(*calls, progressCall{done: done, total: total})
+	})
+}
+
+func TestCSVDataSourceFetchReportsProgress(t *testing.T) {
+	const rows = 2*progressInterval + 5000
+	var content strings.Builder
+	content.WriteString("id,amount\n")
+	for i := range rows {
+		fmt.Fprintf(&content, "%d,%d\n", i, i%100)
+	}
+	path := writeFile(t, "data.csv", content.String())
+
+	var calls []progressCall
+	df, err := NewCSVDataSource().Fetch(recordProgress(&calls), csvConfig(path))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if df.Nrow() != rows {
+		t.Errorf("Fetch() rows = %d, want %d", df.Nrow(), rows)
+	}
+
+	// done increases every progressInterval rows, and the final call reports done equal to the total
+	want := []progressCall{{progressInterval, rows}, {2 * progressInterval, rows}, {rows, rows}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+// gzipSalesFixture is the gzipped salesFixture
+const gzipSalesFixture = "testdata/sales.csv.gz"
+
+func TestCSVDataSourceFetchGzip(t *testing.T) {
+	want, err := NewCSVDataSource().Fetch(context.Background(), csvConfig(salesFixture))
+	if err != nil {
+		t.Fatalf("Fetch(%s) error = %v", salesFixture, err)
+	}
+
+	got, err := NewCSVDataSource().Fetch(context.Background(), csvConfig(gzipSalesFixture))
+	if err != nil {
+		t.Fatalf("Fetch(%s) error = %v", gzipSalesFixture, err)
+	}
+	if !reflect.DeepEqual(got.Records(), want.Records()) {
+		t.Errorf("Fetch(%s) records = %v, want %v", gzipSalesFixture, got.Records(), want.Records())
+	}
+	if !reflect.DeepEqual(got.Types(), want.Types()) {
+		t.Errorf("Fetch(%s) types = %v, want %v", gzipSalesFixture, got.Types(), want.Types())
+	}
+
+	sample, err := NewCSVDataSource().Sample(context.Background(), csvConfig(gzipSalesFixture), 2)
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if sample.Nrow() != 2 {
+		t.Errorf("Sample() rows = %d, want 2", sample.Nrow())
+	}
+
+	count, err := NewCSVDataSource().EstimateRowCount(context.Background(), csvConfig(gzipSalesFixture))
+	if err != nil {
+		t.Fatalf("EstimateRowCount() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("EstimateRowCount() = %d, want 5", count)
+	}
+}
+
+func TestCSVDataSourceFetchCorruptGzip(t *testing.T) {
+	fixture, err := os.ReadFile(gzipSalesFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", gzipSalesFixture, err)
+	}
+
+	// The gzip trailer is the CRC-32 and the size of the content, 4 bytes each
+	badChecksum := bytes.Clone(fixture)
+	badChecksum[len(badChecksum)-8] ^= 0xff
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{name: "not gzip", content: []byte("id,name\n1,Alice\n"), want: "is not a valid gzip file"},
+		{name: "truncated", content: fixture[:len(fixture)/2], want: "is corrupt or truncated"},
+		{name: "bad checksum", content: badChecksum, want: "is corrupt or truncated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "data.csv.gz", string(tt.content))
+
+			_, err := NewCSVDataSource().Fetch(context.Background(), csvConfig(path))
+			var processErr *domainerrors.DataProcessError
+			if !errors.As(err, &processErr) || processErr.Step != "fetch" || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Fetch() error = %v, want a DataProcessError of fetch containing %q", err, tt.want)
+			}
+
+			if _, err := NewCSVDataSource().EstimateRowCount(context.Background(), csvConfig(path)); !strings.Contains(fmt.Sprint(err), tt.want) {
+				t.Errorf("EstimateRowCount() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVDataSourceFetchDelimiterOption(t *testing.T) {
+	path := writeFile(t, "semicolon.csv", "id;name\n1;Smith, Alice\n2;Bob\n")
+
+	config := csvConfig(path)
+	config.Options = map[string]interface{}{"delimiter": ";"}
+	df, err := NewCSVDataSource().Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := [][]string{{"id", "name"}, {"1", "Smith, Alice"}, {"2", "Bob"}}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records = %v, want %v", got, want)
+	}
+}
+
+func TestCSVDataSourceValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{name: "wrong type", options: map[string]interface{}{"delimiter": 59}},
+		{name: "multiple characters", options: map[string]interface{}{"delimiter": ";;"}},
+		{name: "quote", options: map[string]interface{}{"delimiter": `"`}},
+		{name: "unknown option", options: map[string]interface{}{"encoding": "utf-8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvConfig(salesFixture)
+			config.Options = tt.options
+			_, err := NewCSVDataSource().Fetch(context.Background(), config)
+
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || !strings.HasPrefix(configErr.Field, "options.") {
+				t.Errorf("Fetch() error = %v, want a ConfigurationError of the option", err)
+			}
+		})
+	}
+}
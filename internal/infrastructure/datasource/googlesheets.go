@@ -0,0 +1,592 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Ensure GoogleSheetsDataSource implements the DataSource interface
+var _ interfaces.DataSource = (*GoogleSheetsDataSource)(nil)
+
+const (
+	// googleSheetsBaseURL is the endpoint of the Google Sheets API v4
+	googleSheetsBaseURL = "https://sheets.googleapis.com/v4/spreadsheets"
+	// defaultSheetsRange is the range fetched when the Range of the config is empty (all rows of the first sheet)
+	defaultSheetsRange = "A:ZZ"
+)
+
+// sheetsValueRange represents the response body of the values endpoint
+type sheetsValueRange struct {
+	Range  string     `json:"range"`
+	Values [][]string `json:"values"`
+}
+
+// sheetsBatchValueRanges represents the response body of the values:batchGet endpoint
+type sheetsBatchValueRanges struct {
+	ValueRanges []sheetsValueRange `json:"valueRanges"`
+}
+
+// sheetsSpreadsheet represents the response body of the spreadsheets.get endpoint limited to the grid sizes of the sheets
+type sheetsSpreadsheet struct {
+	Sheets []struct {
+		Properties struct {
+			Title          string `json:"title"`
+			GridProperties struct {
+				RowCount int `json:"rowCount"`
+			} `json:"gridProperties"`
+		} `json:"properties"`
+	} `json:"sheets"`
+}
+
+// a1CellsPattern matches the cells part of the A1 notation (e.g. "A1:D100", "A:ZZ", "B2", "1:10")
+// and captures the start column, the start row, the end column, and the end row
+var a1CellsPattern = regexp.MustCompile(`^([A-Za-z]*)(\d*)(?::([A-Za-z]*)(\d*))?$`)
+
+// GoogleSheetsDataSource retrieves data from a Google Sheets spreadsheet through the Sheets API v4.
+// The Source of the DataSourceConfig represents the spreadsheet ID, and the Range represents the A1 notation
+// (e.g. "Sheet1!A1:D100"). The first row of the range is treated as the header, and its blank and duplicated
+// headers are renamed (see sanitizeHeader).
+// Multiple ranges (comma-separated Range or Ranges) are fetched in one batchGet request and row-bound in order;
+// each range must have the same header.
+// When the API rejects the token with 401, the token is refreshed and the request is retried up to
+// the MaxRetries of the AuthenticationError. The transient failures (429, 500, 502, 503, 504, and the network errors)
+// are retried with the exponential backoff configured by the Options (see retrySourceOptions), and the other
+// statuses fail fast. The cancellation of the context stops the wait between the attempts.
+type GoogleSheetsDataSource struct {
+	tokenProvider interfaces.TokenProvider
+	client        *http.Client
+	baseURL       string
+}
+
+// NewGoogleSheetsDataSource creates a new GoogleSheetsDataSource authenticating with the token provider.
+func NewGoogleSheetsDataSource(tokenProvider interfaces.TokenProvider) *GoogleSheetsDataSource {
+	return NewGoogleSheetsDataSourceWithClient(tokenProvider, http.DefaultClient, googleSheetsBaseURL)
+}
+
+// NewGoogleSheetsDataSourceWithClient creates a new GoogleSheetsDataSource with the HTTP client and the API endpoint.
+func NewGoogleSheetsDataSourceWithClient(tokenProvider interfaces.TokenProvider, client *http.Client, baseURL string) *GoogleSheetsDataSource {
+	return &GoogleSheetsDataSource{
+		tokenProvider: tokenProvider,
+		client:        client,
+		baseURL:       baseURL,
+	}
+}
+
+// Fetch retrieves the values of the ranges and returns them as a DataFrame.
+// Rows shorter than the header are padded with blank cells because the API omits the trailing blank cells.
+// The header rows of the second and later ranges are dropped after checking they match the first one.
+// The values arrive in one response, so the ProgressFunc of the context is only invoked on completion.
+func (g *GoogleSheetsDataSource) Fetch(ctx context.Context, config interfaces.DataSourceConfig) (*dataframe.DataFrame, error) {
+	if err := g.Validate(config); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", "fetch is canceled", err)
+	}
+
+	records, err := g.fetchRecords(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	df := loadRecords(ctx, records)
+	if df.Err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", fmt.Sprintf("failed to load values of '%s'", config.Source), df.Err)
+	}
+	if err := applyColumnMapping(&df, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	if progress := interfaces.ProgressFromContext(ctx); progress != nil {
+		progress(df.Nrow(), df.Nrow())
+	}
+
+	return &df, nil
+}
+
+// Sample returns the first n rows of the ranges with the column types inferred from them.
+// The API returns the values of a range at once, so the whole ranges are requested and the rows after n are dropped.
+func (g *GoogleSheetsDataSource) Sample(ctx context.Context, config interfaces.DataSourceConfig, n int) (*dataframe.DataFrame, error) {
+	if err := validateSampleSize(n); err != nil {
+		return nil, err
+	}
+	if err := g.Validate(config); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("sample", "sample is canceled", err)
+	}
+
+	records, err := g.fetchRecords(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header and n rows
+	df := loadRecords(ctx, records[:min(len(records), n+1)])
+	if df.Err != nil {
+		return nil, domainerrors.NewDataProcessError("sample", fmt.Sprintf("failed to load values of '%s'", config.Source), df.Err)
+	}
+	if err := applyColumnMapping(&df, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	return &df, nil
+}
+
+// sheetsSourceOptions holds the options of the Google Sheets data source
+type sheetsSourceOptions struct {
+	retrySourceOptions
+}
+
+// parseSheetsSourceOptions decodes and validates the options of the config and returns the retry policy of them.
+func parseSheetsSourceOptions(config interfaces.DataSourceConfig) (retryPolicy, error) {
+	options := sheetsSourceOptions{
+		retrySourceOptions: defaultRetrySourceOptions(),
+	}
+	if err := config.DecodeOptions(&options); err != nil {
+		return retryPolicy{}, err
+	}
+
+	return options.policy()
+}
+
+// fetchRecords requests the values of the ranges of the config and returns them as the records with a single header.
+func (g *GoogleSheetsDataSource) fetchRecords(ctx context.Context, config interfaces.DataSourceConfig) ([][]string, error) {
+	retry, err := parseSheetsSourceOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := sheetRanges(config)
+	var valueRanges []sheetsValueRange
+	if len(ranges) == 1 {
+		valueRanges, err = g.fetchValues(ctx, config.Source, ranges[0], retry)
+	} else {
+		valueRanges, err = g.fetchBatchValues(ctx, config.Source, ranges, retry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return unionSheetRecords(valueRanges, ranges)
+}
+
+// fetchValues requests the values of the single range.
+func (g *GoogleSheetsDataSource) fetchValues(ctx context.Context, source, valueRange string, retry retryPolicy) ([]sheetsValueRange, error) {
+	endpoint := fmt.Sprintf("%s/%s/values/%s", g.baseURL, url.PathEscape(source), url.PathEscape(valueRange))
+
+	body, err := g.request(ctx, "fetch", endpoint, source, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	var response sheetsValueRange
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", "failed to decode the Sheets API response", err)
+	}
+
+	return []sheetsValueRange{response}, nil
+}
+
+// fetchBatchValues requests the values of the multiple ranges at once with the batchGet API.
+func (g *GoogleSheetsDataSource) fetchBatchValues(ctx context.Context, source string, ranges []string, retry retryPolicy) ([]sheetsValueRange, error) {
+	query := url.Values{"ranges": ranges}
+	endpoint := fmt.Sprintf("%s/%s/values:batchGet?%s", g.baseURL, url.PathEscape(source), query.Encode())
+
+	body, err := g.request(ctx, "fetch", endpoint, source, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	var response sheetsBatchValueRanges
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", "failed to decode the Sheets API response", err)
+	}
+	if len(response.ValueRanges) != len(ranges) {
+		return nil, domainerrors.NewDataProcessError(
+			"fetch",
+			fmt.Sprintf("Sheets API returned %d ranges for the %d requested ranges of '%s'", len(response.ValueRanges), len(ranges), source),
+			nil,
+		)
+	}
+
+	return response.ValueRanges, nil
+}
+
+// request requests the endpoint for the step and returns the body of the successful response,
+// refreshing the token and retrying on 401, and retrying the transient failures with the backoff of the retry policy.
+// The 401 retries don't count as the attempts of the retry policy.
+// The invalid endpoint fails before any attempt, so only the network errors and the retryable statuses are retried.
+func (g *GoogleSheetsDataSource) request(ctx context.Context, step, endpoint, source string, retry retryPolicy) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError(step, "failed to build the Sheets API request", err)
+	}
+
+	var authErr *domainerrors.AuthenticationError
+	attempt := 1
+	for {
+		token, err := g.tokenProvider.Token(ctx)
+		if err != nil {
+			return nil, domainerrors.NewAuthenticationError("failed to get access token", err)
+		}
+
+		status, body, err := g.get(request, step, token)
+		// The network errors are transient unless the context is done
+		if (err != nil && ctx.Err() == nil) || isRetryableStatus(status) {
+			if attempt >= retry.maxAttempts {
+				if err != nil {
+					return nil, err
+				}
+				return nil, domainerrors.NewDataProcessError(
+					step,
+					fmt.Sprintf("Sheets API returned status %d for '%s' after %d attempts: %s", status, source, attempt, truncateBody(body)),
+					nil,
+				)
+			}
+			if err := retry.wait(ctx, step, attempt); err != nil {
+				return nil, err
+			}
+			attempt++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+			return body, nil
+		case http.StatusUnauthorized:
+			if authErr == nil {
+				authErr = domainerrors.NewAuthenticationError(fmt.Sprintf("access token is rejected for '%s'", source), nil)
+			}
+			if !authErr.IsRetryable() {
+				return nil, authErr
+			}
+			authErr.IncrementRetryAttempt()
+
+			if err := g.tokenProvider.Refresh(ctx); err != nil {
+				authErr.Message = "failed to refresh access token"
+				authErr.Cause = err
+				return nil, authErr
+			}
+		case http.StatusForbidden:
+			// Refreshing doesn't grant the permission
+			return nil, domainerrors.NewPermanentAuthenticationError(
+				fmt.Sprintf("permission denied for '%s': %s", source, truncateBody(body)), nil,
+			)
+		default:
+			return nil, domainerrors.NewDataProcessError(
+				step,
+				fmt.Sprintf("Sheets API returned status %d for '%s': %s", status, source, truncateBody(body)),
+				nil,
+			)
+		}
+	}
+}
+
+// get sends a copy of the GET request of the step with the bearer token and returns the status code and the body.
+// The returned error is the network error of the attempt.
+func (g *GoogleSheetsDataSource) get(request *http.Request, step, token string) (int, []byte, error) {
+	request = request.Clone(request.Context())
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return 0, nil, domainerrors.NewDataProcessError(step, "failed to request the Sheets API", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, nil, domainerrors.NewDataProcessError(step, "failed to read the Sheets API response", err)
+	}
+
+	return response.StatusCode, body, nil
+}
+
+// sheetRanges returns the ranges to fetch from the comma-separated Range or the Ranges of the config.
+// The commas in the quoted sheet names (e.g. 'Sales, 2024'!A:D) don't separate the ranges.
+func sheetRanges(config interfaces.DataSourceConfig) []string {
+	if len(config.Ranges) > 0 {
+		return config.Ranges
+	}
+
+	ranges := make([]string, 0)
+	var current strings.Builder
+	quoted := false
+	for _, r := range config.Range {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			ranges = append(ranges, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	ranges = append(ranges, strings.TrimSpace(current.String()))
+
+	if len(ranges) == 1 && ranges[0] == "" {
+		return []string{defaultSheetsRange}
+	}
+
+	return ranges
+}
+
+// unionSheetRecords converts the values of the ranges into the records and row-binds them.
+// Returns a DataProcessError listing the differing columns if the header of a range differs from the first range.
+func unionSheetRecords(valueRanges []sheetsValueRange, ranges []string) ([][]string, error) {
+	var union [][]string
+	for i, valueRange := range valueRanges {
+		records, err := sheetRecords(valueRange.Values)
+		if err != nil {
+			if len(ranges) > 1 {
+				return nil, domainerrors.NewDataProcessError("fetch", fmt.Sprintf("range '%s' is invalid", ranges[i]), err)
+			}
+			return nil, err
+		}
+
+		if i == 0 {
+			union = records
+			continue
+		}
+
+		header := union[0]
+		if !slices.Equal(records[0], header) {
+			return nil, domainerrors.NewDataProcessError(
+				"fetch",
+				fmt.Sprintf("header of range '%s' doesn't match range '%s': %s", ranges[i], ranges[0], headerDifference(header, records[0])),
+				nil,
+			)
+		}
+		union = append(union, records[1:]...)
+	}
+
+	return union, nil
+}
+
+// headerDifference describes the columns differing between the headers.
+func headerDifference(expected, actual []string) string {
+	missing := make([]string, 0)
+	for _, column := range expected {
+		if !slices.Contains(actual, column) {
+			missing = append(missing, column)
+		}
+	}
+	extra := make([]string, 0)
+	for _, column := range actual {
+		if !slices.Contains(expected, column) {
+			extra = append(extra, column)
+		}
+	}
+
+	if len(missing) == 0 && len(extra) == 0 {
+		return fmt.Sprintf("columns are in a different order %v, expected %v", actual, expected)
+	}
+
+	return fmt.Sprintf("missing columns %v, unexpected columns %v", missing, extra)
+}
+
+// sheetRecords converts the values into the records with the header, padding the short rows with blank cells.
+func sheetRecords(values [][]string) ([][]string, error) {
+	if len(values) == 0 {
+		return nil, domainerrors.NewDataProcessError("fetch", "the range has no header row", nil)
+	}
+
+	header := values[0]
+	records := make([][]string, len(values))
+	records[0] = header
+	for i, row := range values[1:] {
+		if len(row) > len(header) {
+			return nil, domainerrors.NewDataProcessError("fetch", fmt.Sprintf("row %d has %d cells, more than the %d header cells", i+1, len(row), len(header)), nil)
+		}
+
+		record := make([]string, len(header))
+		copy(record, row)
+		records[i+1] = record
+	}
+
+	return records, nil
+}
+
+// truncateBody shortens the response body for the error messages.
+func truncateBody(body []byte) string {
+	const maxLength = 200
+	if len(body) > maxLength {
+		return string(body[:maxLength]) + "..."
+	}
+
+	return string(body)
+}
+
+// Validate checks the config has the supported type, a spreadsheet ID, valid options, and a valid ColumnMapping,
+// and the token provider is set.
+func (g *GoogleSheetsDataSource) Validate(config interfaces.DataSourceConfig) error {
+	if !slices.Contains(g.SupportedTypes(), config.Type) {
+		return domainerrors.NewConfigurationError("type", fmt.Sprintf("unsupported type '%s' for Google Sheets data source", config.Type), nil)
+	}
+	if config.Source == "" {
+		return domainerrors.NewConfigurationError("source", "source (spreadsheet ID) is required", nil)
+	}
+	if config.Range != "" && len(config.Ranges) > 0 {
+		return domainerrors.NewConfigurationError("ranges", "range and ranges cannot be set at the same time", nil)
+	}
+	if slices.Contains(sheetRanges(config), "") {
+		return domainerrors.NewConfigurationError("ranges", "ranges cannot contain an empty range", nil)
+	}
+	if g.tokenProvider == nil {
+		return domainerrors.NewConfigurationError("tokenProvider", "token provider is required for Google Sheets", nil)
+	}
+
+	if _, err := parseSheetsSourceOptions(config); err != nil {
+		return err
+	}
+
+	return validateColumnMapping(config)
+}
+
+// GetSourceInfo returns human-readable information about the spreadsheet.
+// The dimensions are unknown without requesting the API, so they are omitted.
+func (g *GoogleSheetsDataSource) GetSourceInfo(config interfaces.DataSourceConfig) string {
+	source := config.Source
+	if config.Range != "" || len(config.Ranges) > 0 {
+		source = fmt.Sprintf("%s [%s]", source, strings.Join(sheetRanges(config), ", "))
+	}
+
+	return formatSourceInfo("googlesheets", source, -1, -1)
+}
+
+// SupportedTypes returns the source types supported by GoogleSheetsDataSource.
+func (g *GoogleSheetsDataSource) SupportedTypes() []string {
+	return []string{"googlesheets"}
+}
+
+// GetCapabilities returns the features supported by GoogleSheetsDataSource.
+// The range is fetched at once with OAuth2 authentication, and the rows are estimated from the grid sizes of the sheets.
+func (g *GoogleSheetsDataSource) GetCapabilities(_ interfaces.DataSourceConfig) interfaces.Capabilities {
+	return interfaces.Capabilities{
+		SupportsRange:              true,
+		SupportsStreaming:          false,
+		SupportsRowCountEstimation: true,
+		RequiredAuth:               "oauth2",
+	}
+}
+
+// EstimateRowCount estimates the row count of the ranges from the grid sizes of their sheets, excluding the header row
+// of each range. The grid sizes are requested with spreadsheets.get (with the same retries as the fetch) instead of
+// the values. The grid includes the blank rows after the data, so the result can be larger than the actual row count.
+// Returns -1 if the sheet of a range or its grid size cannot be resolved (e.g. a named range).
+func (g *GoogleSheetsDataSource) EstimateRowCount(ctx context.Context, config interfaces.DataSourceConfig) (int, error) {
+	if err := g.Validate(config); err != nil {
+		return -1, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return -1, domainerrors.NewDataProcessError("estimate", "estimation is canceled", err)
+	}
+
+	retry, err := parseSheetsSourceOptions(config)
+	if err != nil {
+		return -1, err
+	}
+
+	query := url.Values{"fields": {"sheets.properties(title,gridProperties.rowCount)"}}
+	endpoint := fmt.Sprintf("%s/%s?%s", g.baseURL, url.PathEscape(config.Source), query.Encode())
+	body, err := g.request(ctx, "estimate", endpoint, config.Source, retry)
+	if err != nil {
+		return -1, err
+	}
+
+	var response sheetsSpreadsheet
+	if err := json.Unmarshal(body, &response); err != nil {
+		return -1, domainerrors.NewDataProcessError("estimate", "failed to decode the Sheets API response", err)
+	}
+
+	total := 0
+	for _, valueRange := range sheetRanges(config) {
+		rows, ok := response.rangeRows(valueRange)
+		if !ok {
+			return -1, nil
+		}
+		total += rows
+	}
+
+	return total, nil
+}
+
+// rangeRows returns the rows of the range within the grid of its sheet, excluding the header row.
+// The range without a sheet name refers to the first sheet. Returns false if the sheet or the range cannot be resolved.
+func (s sheetsSpreadsheet) rangeRows(valueRange string) (int, bool) {
+	sheet, cells := splitSheetRange(valueRange)
+	matches := a1CellsPattern.FindStringSubmatch(cells)
+	if matches == nil {
+		return 0, false
+	}
+
+	gridRows := -1
+	for i, candidate := range s.Sheets {
+		if (sheet == "" && i == 0) || (sheet != "" && candidate.Properties.Title == sheet) {
+			gridRows = candidate.Properties.GridProperties.RowCount
+			break
+		}
+	}
+	if gridRows < 0 {
+		return 0, false
+	}
+
+	first, last := 1, gridRows
+	if matches[2] != "" {
+		first, _ = strconv.Atoi(matches[2])
+		if !strings.Contains(cells, ":") {
+			// A single cell
+			last = first
+		}
+	}
+	if matches[4] != "" {
+		end, _ := strconv.Atoi(matches[4])
+		last = min(end, gridRows)
+	}
+
+	// Exclude the header row
+	return max(last-first, 0), true
+}
+
+// splitSheetRange splits the A1 notation into the sheet name and the cells. The quotes of the sheet name are removed.
+// A range without "!" is the cells of the first sheet if it looks like the cells (e.g. "A:ZZ"), otherwise the whole sheet.
+func splitSheetRange(valueRange string) (string, string) {
+	i := strings.LastIndex(valueRange, "!")
+	if i < 0 {
+		if a1CellsPattern.MatchString(valueRange) {
+			return "", valueRange
+		}
+		return unquoteSheetName(valueRange), ""
+	}
+
+	return unquoteSheetName(valueRange[:i]), valueRange[i+1:]
+}
+
+// unquoteSheetName removes the quotes of the sheet name (e.g. 'Sales, 2024' or 'Bob”s').
+func unquoteSheetName(name string) string {
+	if len(name) >= 2 && strings.HasPrefix(name, "'") && strings.HasSuffix(name, "'") {
+		return strings.ReplaceAll(name[1:len(name)-1], "''", "'")
+	}
+
+	return name
+}
@@ -0,0 +1,526 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticTokenProvider supplies a fixed token and counts the supplied tokens and the refreshes
+type staticTokenProvider struct {
+	token     string
+	tokens    int
+	refreshes int
+}
+
+func (p *staticTokenProvider) Token(context.Context) (string, error) {
+	p.tokens++
+	return p.token, nil
+}
+
+func (p *staticTokenProvider) Refresh(context.Context) error {
+	p.refreshes++
+	return nil
+}
+
+// sheetsConfig returns the config of the Google Sheets data source fetching the range of the spreadsheet.
+func sheetsConfig(spreadsheetID, valueRange string) interfaces.DataSourceConfig {
+	return interfaces.DataSourceConfig{Type: "googlesheets", Source: spreadsheetID, Range: valueRange}
+}
+
+func TestGoogleSheetsDataSourceGetCapabilities(t *testing.T) {
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, "http://127.0.0.1")
+	got := source.GetCapabilities(sheetsConfig("sheet", "Sheet1!A1:C10"))
+	want := interfaces.Capabilities{
+		SupportsRange:              true,
+		SupportsStreaming:          false,
+		SupportsRowCountEstimation: true,
+		RequiredAuth:               "oauth2",
+	}
+	if got != want {
+		t.Errorf("GetCapabilities() = %+v, want %+v", got, want)
+	}
+
+	if csv := NewCSVDataSource().GetCapabilities(csvConfig("data.csv")); got == csv {
+		t.Errorf("GetCapabilities() = %+v, want capabilities distinct from the CSV source", got)
+	}
+}
+
+// gridSheets is the spreadsheets.get response of the fake Sheets API with the grid sizes of three sheets
+const gridSheets = `{"sheets":[
+	{"properties":{"title":"Sheet1","gridProperties":{"rowCount":100}}},
+	{"properties":{"title":"Sales, 2024","gridProperties":{"rowCount":20}}},
+	{"properties":{"title":"Bob's","gridProperties":{"rowCount":5}}}
+]}`
+
+// gridSheetsServer starts the fake Sheets API answering spreadsheets.get with gridSheets after failing the first
+// failures requests with 503, and records the requested fields.
+func gridSheetsServer(t *testing.T, fields *[]string, failures int) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*fields = append(*fields, r.URL.Query().Get("fields"))
+		if len(*fields) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/sheet" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, gridSheets)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestGoogleSheetsDataSourceEstimateRowCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		config interfaces.DataSourceConfig
+		want   int
+	}{
+		{name: "default range of the first sheet", config: sheetsConfig("sheet", ""), want: 99},
+		{name: "bounded range", config: sheetsConfig("sheet", "Sheet1!A1:C10"), want: 9},
+		{name: "range beyond the grid", config: sheetsConfig("sheet", "Sheet1!A1:C500"), want: 99},
+		{name: "range from a later row", config: sheetsConfig("sheet", "Sheet1!A51:C"), want: 49},
+		{name: "quoted sheet name", config: sheetsConfig("sheet", "'Sales, 2024'!A:D"), want: 19},
+		{name: "whole sheet", config: sheetsConfig("sheet", "Bob's"), want: 4},
+		{name: "multiple ranges", config: interfaces.DataSourceConfig{Type: "googlesheets", Source: "sheet", Ranges: []string{"Sheet1!A1:C10", "'Sales, 2024'!A:D"}}, want: 28},
+		{name: "unknown sheet", config: sheetsConfig("sheet", "Missing!A:C"), want: -1},
+		{name: "named range", config: sheetsConfig("sheet", "Totals_2024"), want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			baseURL := gridSheetsServer(t, &fields, 0)
+			source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, baseURL)
+
+			got, err := source.EstimateRowCount(context.Background(), tt.config)
+			if err != nil {
+				t.Fatalf("EstimateRowCount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EstimateRowCount() = %d, want %d", got, tt.want)
+			}
+
+			// Only the grid sizes are requested, once for all ranges
+			want := []string{"sheets.properties(title,gridProperties.rowCount)"}
+			if !reflect.DeepEqual(fields, want) {
+				t.Errorf("requested fields = %v, want %v", fields, want)
+			}
+		})
+	}
+}
+
+func TestGoogleSheetsDataSourceEstimateRowCountRetries(t *testing.T) {
+	var fields []string
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, gridSheetsServer(t, &fields, 2))
+
+	config := retrySheetsConfig(3, "1ms")
+	config.Range = "Sheet1!A1:C10"
+	got, err := source.EstimateRowCount(context.Background(), config)
+	if err != nil {
+		t.Fatalf("EstimateRowCount() error = %v", err)
+	}
+	if got != 9 {
+		t.Errorf("EstimateRowCount() = %d, want 9", got)
+	}
+	if len(fields) != 3 {
+		t.Errorf("requests = %d, want 3", len(fields))
+	}
+}
+
+func TestGoogleSheetsDataSourceEstimateRowCountFailure(t *testing.T) {
+	var fields []string
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, gridSheetsServer(t, &fields, 0))
+
+	// The unknown spreadsheet fails rather than estimating -1
+	got, err := source.EstimateRowCount(context.Background(), sheetsConfig("missing", ""))
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) || processErr.Step != "estimate" {
+		t.Fatalf("EstimateRowCount() error = %v, want a DataProcessError of the estimate step", err)
+	}
+	if got != -1 {
+		t.Errorf("EstimateRowCount() = %d, want -1", got)
+	}
+}
+
+func TestGoogleSheetsDataSourceGetSourceInfo(t *testing.T) {
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, "http://127.0.0.1:0")
+
+	// The source info doesn't request the API, so only the type and the source are shown
+	tests := []struct {
+		config interfaces.DataSourceConfig
+		want   string
+	}{
+		{config: sheetsConfig("sheet", ""), want: "googlesheets: sheet"},
+		{config: sheetsConfig("sheet", "Sheet1!A1:C10"), want: "googlesheets: sheet [Sheet1!A1:C10]"},
+	}
+	for _, tt := range tests {
+		if got := source.GetSourceInfo(tt.config); got != tt.want {
+			t.Errorf("GetSourceInfo() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// rotatingTokenProvider supplies the expired token until it is refreshed, and then the fresh token.
+// The refresh fails with refreshErr if it is set.
+type rotatingTokenProvider struct {
+	refreshes  int
+	refreshErr error
+}
+
+func (p *rotatingTokenProvider) Token(context.Context) (string, error) {
+	if p.refreshes == 0 {
+		return "expired", nil
+	}
+	return "fresh", nil
+}
+
+func (p *rotatingTokenProvider) Refresh(context.Context) error {
+	p.refreshes++
+	return p.refreshErr
+}
+
+// sheetsServer starts the fake Sheets API accepting only the tokens in accepted, and counts the requests.
+func sheetsServer(t *testing.T, requests *int, accepted ...string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if !slices.Contains(accepted, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"range":"Sheet1!A1:B3","values":[["name","amount"],["Alice","10"],["Bob","20"]]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestGoogleSheetsDataSourceRefreshesOnUnauthorized(t *testing.T) {
+	requests := 0
+	provider := &rotatingTokenProvider{}
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, sheetsServer(t, &requests, "fresh"))
+
+	df, err := source.Fetch(context.Background(), sheetsConfig("sheet", "Sheet1!A1:B3"))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := [][]string{{"name", "amount"}, {"Alice", "10"}, {"Bob", "20"}}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records = %v, want %v", got, want)
+	}
+	if provider.refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", provider.refreshes)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want the rejected request and its retry", requests)
+	}
+}
+
+func TestGoogleSheetsDataSourceUnauthorizedAfterMaxRetries(t *testing.T) {
+	requests := 0
+	provider := &rotatingTokenProvider{}
+	// Neither token is accepted, so every retry is rejected again
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, sheetsServer(t, &requests))
+
+	_, err := source.Fetch(context.Background(), sheetsConfig("sheet", "Sheet1!A1:B3"))
+
+	var authErr *domainerrors.AuthenticationError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Fetch() error = %v, want an AuthenticationError", err)
+	}
+	if authErr.RetryAttempt != authErr.MaxRetries {
+		t.Errorf("RetryAttempt = %d, want MaxRetries %d", authErr.RetryAttempt, authErr.MaxRetries)
+	}
+	if provider.refreshes != authErr.MaxRetries {
+		t.Errorf("refreshes = %d, want %d", provider.refreshes, authErr.MaxRetries)
+	}
+	if requests != authErr.MaxRetries+1 {
+		t.Errorf("requests = %d, want %d", requests, authErr.MaxRetries+1)
+	}
+}
+
+func TestGoogleSheetsDataSourceRefreshFailure(t *testing.T) {
+	requests := 0
+	refreshErr := errors.New("refresh token revoked")
+	provider := &rotatingTokenProvider{refreshErr: refreshErr}
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, sheetsServer(t, &requests, "fresh"))
+
+	_, err := source.Fetch(context.Background(), sheetsConfig("sheet", "Sheet1!A1:B3"))
+	if !domainerrors.IsAuthenticationError(err) || !errors.Is(err, refreshErr) {
+		t.Fatalf("Fetch() error = %v, want an AuthenticationError caused by %v", err, refreshErr)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want no retry after the failed refresh", requests)
+	}
+}
+
+// batchSheetsServer starts the fake Sheets API answering the batchGet request with the values of the requested
+// ranges, and records the requested paths and ranges.
+func batchSheetsServer(t *testing.T, values map[string][][]string, paths *[]string, requested *[][]string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.Path)
+		ranges := r.URL.Query()["ranges"]
+		*requested = append(*requested, ranges)
+
+		response := sheetsBatchValueRanges{}
+		for _, valueRange := range ranges {
+			response.ValueRanges = append(response.ValueRanges, sheetsValueRange{Range: valueRange, Values: values[valueRange]})
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestGoogleSheetsDataSourceFetchMultipleRanges(t *testing.T) {
+	values := map[string][][]string{
+		"East!A1:B3":       {{"name", "amount"}, {"Alice", "10"}, {"Bob", "20"}},
+		"'West, 2024'!A:B": {{"name", "amount"}, {"Carol", "30"}, {"Dave"}},
+	}
+	want := [][]string{{"name", "amount"}, {"Alice", "10"}, {"Bob", "20"}, {"Carol", "30"}, {"Dave", "NaN"}}
+
+	tests := []struct {
+		name   string
+		config interfaces.DataSourceConfig
+	}{
+		{name: "comma-separated range", config: sheetsConfig("sheet", "East!A1:B3, 'West, 2024'!A:B")},
+		{
+			name:   "ranges",
+			config: interfaces.DataSourceConfig{Type: "googlesheets", Source: "sheet", Ranges: []string{"East!A1:B3", "'West, 2024'!A:B"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			var requested [][]string
+			source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, batchSheetsServer(t, values, &paths, &requested))
+
+			df, err := source.Fetch(context.Background(), tt.config)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if got := df.Records(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Fetch() records = %v, want %v", got, want)
+			}
+
+			wantRequested := [][]string{{"East!A1:B3", "'West, 2024'!A:B"}}
+			if !reflect.DeepEqual(paths, []string{"/sheet/values:batchGet"}) || !reflect.DeepEqual(requested, wantRequested) {
+				t.Errorf("requests = %v %v, want one batchGet of %v", paths, requested, wantRequested)
+			}
+		})
+	}
+}
+
+func TestGoogleSheetsDataSourceFetchMismatchedRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		second [][]string
+		want   string
+	}{
+		{
+			name:   "different columns",
+			second: [][]string{{"name", "total"}, {"Carol", "30"}},
+			want:   "header of range 'West!A:B' doesn't match range 'East!A:B': missing columns [amount], unexpected columns [total]",
+		},
+		{
+			name:   "different order",
+			second: [][]string{{"amount", "name"}, {"30", "Carol"}},
+			want:   "columns are in a different order [amount name], expected [name amount]",
+		},
+		{
+			name:   "no header",
+			second: nil,
+			want:   "range 'West!A:B' is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string][][]string{
+				"East!A:B": {{"name", "amount"}, {"Alice", "10"}},
+				"West!A:B": tt.second,
+			}
+			var paths []string
+			var requested [][]string
+			source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, batchSheetsServer(t, values, &paths, &requested))
+
+			_, err := source.Fetch(context.Background(), sheetsConfig("sheet", "East!A:B,West!A:B"))
+			var processErr *domainerrors.DataProcessError
+			if !errors.As(err, &processErr) {
+				t.Fatalf("Fetch() error = %v, want a DataProcessError", err)
+			}
+			if processErr.Step != "fetch" || !strings.Contains(processErr.Error(), tt.want) {
+				t.Errorf("Fetch() error = %v (step %q), want the fetch step containing %q", err, processErr.Step, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoogleSheetsDataSourceForbiddenIsPermanent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+	provider := &rotatingTokenProvider{}
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, server.URL)
+
+	_, err := source.Fetch(context.Background(), sheetsConfig("sheet", "Sheet1!A1:B3"))
+
+	var authErr *domainerrors.AuthenticationError
+	if !errors.As(err, &authErr) || !authErr.Permanent || authErr.IsRetryable() {
+		t.Fatalf("Fetch() error = %v, want a permanent AuthenticationError", err)
+	}
+	if requests != 1 || provider.refreshes != 0 {
+		t.Errorf("requests = %d, refreshes = %d, want a single request without refreshing", requests, provider.refreshes)
+	}
+}
+
+// flakySheetsServer starts the fake Sheets API failing the first failures requests with the status, and counts
+// the requests.
+func flakySheetsServer(t *testing.T, requests *int, failures, status int) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *requests <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = io.WriteString(w, `{"range":"Sheet1!A1:B3","values":[["name","amount"],["Alice","10"],["Bob","20"]]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// retrySheetsConfig returns the config of the Google Sheets data source with the retry options.
+func retrySheetsConfig(maxAttempts int, baseDelay string) interfaces.DataSourceConfig {
+	config := sheetsConfig("sheet", "Sheet1!A1:B3")
+	config.Options = map[string]interface{}{"maxAttempts": maxAttempts, "retryBaseDelay": baseDelay}
+
+	return config
+}
+
+func TestGoogleSheetsDataSourceRetriesTransientFailures(t *testing.T) {
+	requests := 0
+	provider := &staticTokenProvider{token: "token"}
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, flakySheetsServer(t, &requests, 2, http.StatusServiceUnavailable))
+
+	df, err := source.Fetch(context.Background(), retrySheetsConfig(3, "1ms"))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := [][]string{{"name", "amount"}, {"Alice", "10"}, {"Bob", "20"}}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records = %v, want %v", got, want)
+	}
+	if requests != 3 || provider.refreshes != 0 {
+		t.Errorf("requests = %d, refreshes = %d, want the 2 failures and the success without refreshing", requests, provider.refreshes)
+	}
+}
+
+func TestGoogleSheetsDataSourceRetriesExhausted(t *testing.T) {
+	requests := 0
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, flakySheetsServer(t, &requests, 3, http.StatusTooManyRequests))
+
+	_, err := source.Fetch(context.Background(), retrySheetsConfig(2, "1ms"))
+
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) || !strings.Contains(processErr.Error(), "status 429") || !strings.Contains(processErr.Error(), "after 2 attempts") {
+		t.Fatalf("Fetch() error = %v, want the DataProcessError of status 429 after 2 attempts", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want maxAttempts 2", requests)
+	}
+}
+
+func TestGoogleSheetsDataSourceNonRetryableStatusFailsFast(t *testing.T) {
+	requests := 0
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, flakySheetsServer(t, &requests, 1, http.StatusNotFound))
+
+	if _, err := source.Fetch(context.Background(), retrySheetsConfig(3, "1ms")); err == nil {
+		t.Fatalf("Fetch() error = nil, want the failure of status 404")
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want no retry of status 404", requests)
+	}
+}
+
+func TestGoogleSheetsDataSourceRetryCanceled(t *testing.T) {
+	requests := 0
+	source := NewGoogleSheetsDataSourceWithClient(&staticTokenProvider{token: "token"}, http.DefaultClient, flakySheetsServer(t, &requests, 3, http.StatusServiceUnavailable))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := source.Fetch(ctx, retrySheetsConfig(3, "1h"))
+
+	// The cancellation stops the wait of an hour before the second attempt
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Fetch() error = %v, want the cancellation while waiting to retry", err)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Errorf("Fetch() returned after %v, want it to stop waiting on the cancellation", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want no retry after the cancellation", requests)
+	}
+}
+
+func TestGoogleSheetsDataSourceInvalidEndpointNotRetried(t *testing.T) {
+	// The control character makes the endpoint an invalid URL, which no retry can fix
+	provider := &staticTokenProvider{token: "token"}
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, "http://127.0.0.1/\x7f")
+
+	_, err := source.Fetch(context.Background(), retrySheetsConfig(3, "1h"))
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) || !strings.Contains(processErr.Message, "failed to build the Sheets API request") {
+		t.Fatalf("Fetch() error = %v, want the DataProcessError of building the request", err)
+	}
+	if provider.tokens != 0 {
+		t.Errorf("tokens = %d, want 0 for the request failing before any attempt", provider.tokens)
+	}
+}
+
+func TestGoogleSheetsDataSourceRetriesNetworkErrors(t *testing.T) {
+	// The closed server refuses the connections, which may succeed on a later attempt
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	provider := &staticTokenProvider{token: "token"}
+	source := NewGoogleSheetsDataSourceWithClient(provider, http.DefaultClient, server.URL)
+
+	_, err := source.Fetch(context.Background(), retrySheetsConfig(3, "1ms"))
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) || !strings.Contains(processErr.Message, "failed to request the Sheets API") {
+		t.Fatalf("Fetch() error = %v, want the DataProcessError of the network error", err)
+	}
+	if provider.tokens != 3 {
+		t.Errorf("tokens = %d, want 3 attempts", provider.tokens)
+	}
+}
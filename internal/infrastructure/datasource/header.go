@@ -0,0 +1,89 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"strings"
+)
+
+// loadRecords sanitizes the header (the first record) by sanitizeHeader and loads the records as a DataFrame.
+func loadRecords(ctx context.Context, records [][]string) dataframe.DataFrame {
+	if len(records) > 0 {
+		records[0] = sanitizeHeader(ctx, records[0])
+	}
+
+	return dataframe.LoadRecords(records)
+}
+
+// invisibleHeaderChars are the characters stripped from the headers: the BOM and the zero-width characters,
+// invisible in the source but making the name differ from the one in the config, e.g. "\ufeffid" never matches "id"
+const invisibleHeaderChars = "\ufeff\u200b\u200c\u200d\u2060"
+
+// cleanHeader strips the invisible characters (see invisibleHeaderChars) and the surrounding whitespace of the header.
+func cleanHeader(header string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(invisibleHeaderChars, r) {
+			return -1
+		}
+		return r
+	}, header)
+
+	return strings.TrimSpace(cleaned)
+}
+
+// sanitizeHeader cleans the headers by cleanHeader, then renames the blank and the duplicated headers
+// so that every column can be referenced by a unique name.
+// Gota would rename them as well, but also renames the first of the duplicated headers, e.g. "name" to "name_0".
+// The renaming of the cleaned headers is deterministic:
+// - The first occurrence of a header keeps its name
+// - The n-th occurrence of a duplicated header is renamed to "<header>_<n>", e.g. the second "name" is "name_2"
+// - The blank (empty after cleaning) header at the 1-based position p is renamed to "col_<p>", e.g. "col_3"
+// - A new name already taken by another header is suffixed again with the smallest free number, e.g. "name_2_2"
+//
+// Each header whose name changes, by the cleaning or the renaming, is reported with its original text
+// to the HeaderRenameFunc of the context if any. The header is modified in place and returned.
+func sanitizeHeader(ctx context.Context, header []string) []string {
+	cleaned := make([]string, len(header))
+	// The first occurrences keep their names, so they are reserved before any renaming
+	taken := make(map[string]bool, len(header))
+	for i, name := range header {
+		cleaned[i] = cleanHeader(name)
+		if cleaned[i] != "" {
+			taken[cleaned[i]] = true
+		}
+	}
+
+	rename := interfaces.HeaderRenameFuncFromContext(ctx)
+	seen := make(map[string]int, len(header))
+	for i, name := range header {
+		renamed := cleaned[i]
+		if renamed == "" {
+			renamed = fmt.Sprintf("col_%d", i+1)
+		} else {
+			seen[renamed]++
+			if seen[renamed] > 1 {
+				renamed = fmt.Sprintf("%s_%d", renamed, seen[renamed])
+			}
+		}
+
+		if renamed != cleaned[i] && taken[renamed] {
+			base := renamed
+			for n := 2; taken[renamed]; n++ {
+				renamed = fmt.Sprintf("%s_%d", base, n)
+			}
+		}
+		taken[renamed] = true
+		if renamed == name {
+			continue
+		}
+		header[i] = renamed
+
+		if rename != nil {
+			rename(i+1, name, renamed)
+		}
+	}
+
+	return header
+}
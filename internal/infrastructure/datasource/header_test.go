@@ -0,0 +1,131 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"reflect"
+	"testing"
+)
+
+// recordRenames returns the context recording the renamed headers as "<position>:<header>-><name>".
+func recordRenames(renames *[]string) context.Context {
+	return interfaces.WithHeaderRenameFunc(context.Background(), func(position int, header, name string) {
+		*renames = append(*renames, fmt.Sprintf("%d:%s->%s", position, header, name))
+	})
+}
+
+func TestSanitizeHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      []string
+		want        []string
+		wantRenames []string
+	}{
+		{
+			name:        "unique",
+			header:      []string{"id", "name"},
+			want:        []string{"id", "name"},
+			wantRenames: nil,
+		},
+		{
+			name:        "duplicated",
+			header:      []string{"name", "id", "name", "name"},
+			want:        []string{"name", "id", "name_2", "name_3"},
+			wantRenames: []string{"3:name->name_2", "4:name->name_3"},
+		},
+		{
+			name:        "blank",
+			header:      []string{"id", "", "  ", "amount"},
+			want:        []string{"id", "col_2", "col_3", "amount"},
+			wantRenames: []string{"2:->col_2", "3:  ->col_3"},
+		},
+		{
+			name:        "new name taken by another header",
+			header:      []string{"name", "name", "name_2", "", "col_4"},
+			want:        []string{"name", "name_2_2", "name_2", "col_4_2", "col_4"},
+			wantRenames: []string{"2:name->name_2_2", "4:->col_4_2"},
+		},
+		{
+			name:        "invisible characters and whitespace",
+			header:      []string{"\ufeffid", " name ", "na\u200bme"},
+			want:        []string{"id", "name", "name_2"},
+			wantRenames: []string{"1:\ufeffid->id", "2: name ->name", "3:na\u200bme->name_2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var renames []string
+			got := sanitizeHeader(recordRenames(&renames), append([]string(nil), tt.header...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sanitizeHeader(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+			if !reflect.DeepEqual(renames, tt.wantRenames) {
+				t.Errorf("sanitizeHeader(%q) renames = %q, want %q", tt.header, renames, tt.wantRenames)
+			}
+
+			// The renaming is stable, so the sanitized header is kept as it is
+			var again []string
+			if stable := sanitizeHeader(recordRenames(&again), append([]string(nil), got...)); !reflect.DeepEqual(stable, got) || len(again) > 0 {
+				t.Errorf("sanitizeHeader(%q) = %q with renames %q, want it unchanged", got, stable, again)
+			}
+		})
+	}
+}
+
+func TestCSVDataSourceFetchRenamesHeaders(t *testing.T) {
+	path := writeFile(t, "headers.csv", "name,,name\nAlice,1,Smith\n")
+
+	var renames []string
+	df, err := NewCSVDataSource().Fetch(recordRenames(&renames), csvConfig(path))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got, want := df.Names(), []string{"name", "col_2", "name_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() names = %v, want %v", got, want)
+	}
+	if want := []string{"2:->col_2", "3:name->name_2"}; !reflect.DeepEqual(renames, want) {
+		t.Errorf("Fetch() renames = %q, want %q", renames, want)
+	}
+	if got := df.Col("name_2").Records(); !reflect.DeepEqual(got, []string{"Smith"}) {
+		t.Errorf("Fetch() name_2 = %v, want [Smith]", got)
+	}
+}
+
+func TestCleanHeader(t *testing.T) {
+	tests := map[string]string{
+		"id":                      "id",
+		"\ufeffid":                "id",
+		"\u200bor\u200cder\u200d": "order",
+		"\u2060 amount \t":        "amount",
+		"first name":              "first name",
+		"\ufeff\u200b":            "",
+	}
+
+	for header, want := range tests {
+		if got := cleanHeader(header); got != want {
+			t.Errorf("cleanHeader(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCSVDataSourceFetchCleansHeaders(t *testing.T) {
+	// The BOM of the file is read as a part of the first header, and the second has zero-width spaces
+	path := writeFile(t, "bom.csv", "\ufeffid,\u200bam\u200bount\u200b\n1,10\n")
+
+	var renames []string
+	df, err := NewCSVDataSource().Fetch(recordRenames(&renames), csvConfig(path))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got, want := df.Names(), []string{"id", "amount"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() names = %q, want %q", got, want)
+	}
+	if want := []string{"1:\ufeffid->id", "2:\u200bam\u200bount\u200b->amount"}; !reflect.DeepEqual(renames, want) {
+		t.Errorf("Fetch() renames = %q, want %q", renames, want)
+	}
+	if got := df.Col("id").Records(); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Fetch() id = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,24 @@
+package datasource
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatSourceInfo builds the standardized source information string shared by all data sources.
+// Format: "<type>: <source> (~<rows> rows, <columns> columns)"
+// The dimensions are omitted when the row count is negative (no estimate),
+// and the column part is omitted when the column count is negative (unknown).
+func formatSourceInfo(sourceType, source string, rows, columns int) string {
+	info := fmt.Sprintf("%s: %s", sourceType, source)
+	if rows < 0 {
+		return info
+	}
+
+	dimensions := []string{fmt.Sprintf("~%d rows", rows)}
+	if columns >= 0 {
+		dimensions = append(dimensions, fmt.Sprintf("%d columns", columns))
+	}
+
+	return fmt.Sprintf("%s (%s)", info, strings.Join(dimensions, ", "))
+}
@@ -0,0 +1,22 @@
+package datasource
+
+import "testing"
+
+func TestFormatSourceInfo(t *testing.T) {
+	tests := []struct {
+		rows    int
+		columns int
+		want    string
+	}{
+		{rows: 100, columns: 5, want: "csv: data.csv (~100 rows, 5 columns)"},
+		{rows: 100, columns: -1, want: "csv: data.csv (~100 rows)"},
+		{rows: 0, columns: 2, want: "csv: data.csv (~0 rows, 2 columns)"},
+		{rows: -1, columns: 5, want: "csv: data.csv"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSourceInfo("csv", "data.csv", tt.rows, tt.columns); got != tt.want {
+			t.Errorf("formatSourceInfo(%d, %d) = %q, want %q", tt.rows, tt.columns, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package datasource
+
+import (
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"maps"
+	"slices"
+)
+
+// validateColumnMapping checks the ColumnMapping of the config. Returns a ConfigurationError if it is invalid.
+func validateColumnMapping(config interfaces.DataSourceConfig) error {
+	if err := entities.ValidateColumnMapping(config.ColumnMapping); err != nil {
+		return domainerrors.NewConfigurationError("columnMapping", err.Error(), err)
+	}
+
+	return nil
+}
+
+// applyColumnMapping renames the headers of the fetched DataFrame by the mapping in place.
+// Every mapped header must exist, and the renamed column must not collide with the header left as it is.
+func applyColumnMapping(df *dataframe.DataFrame, mapping map[string]string) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	names := df.Names()
+	renamed := make([]string, len(names))
+	for i, name := range names {
+		renamed[i] = name
+		if target, ok := mapping[name]; ok {
+			renamed[i] = target
+		}
+	}
+
+	for _, header := range slices.Sorted(maps.Keys(mapping)) {
+		target := mapping[header]
+		if !slices.Contains(names, header) {
+			return domainerrors.NewDataProcessError("fetch", fmt.Sprintf("mapped header '%s' not found in the fetched columns %v", header, names), nil)
+		}
+		if i := slices.Index(names, target); i >= 0 {
+			if _, mapped := mapping[target]; !mapped {
+				return domainerrors.NewDataProcessError("fetch", fmt.Sprintf("header '%s' is mapped to the existing column '%s'", header, names[i]), nil)
+			}
+		}
+	}
+
+	if err := df.SetNames(renamed...); err != nil {
+		return domainerrors.NewDataProcessError("fetch", "failed to rename the fetched columns", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package datasource
+
+import (
+	"context"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVDataSourceFetchColumnMapping(t *testing.T) {
+	path := writeFile(t, "data.csv", "Order Date,売上 金額,region\n2024-01-05,100,east\n2024-01-06,200,west\n")
+
+	config := csvConfig(path)
+	config.ColumnMapping = map[string]string{"Order Date": "order_date", "売上 金額": "sales_amount"}
+	df, err := NewCSVDataSource().Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := [][]string{
+		{"order_date", "sales_amount", "region"},
+		{"2024-01-05", "100", "east"},
+		{"2024-01-06", "200", "west"},
+	}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records = %v, want %v", got, want)
+	}
+}
+
+func TestCSVDataSourceFetchColumnMappingInvalid(t *testing.T) {
+	path := writeFile(t, "data.csv", "Order Date,Order ID,region\n2024-01-05,1,east\n")
+
+	tests := []struct {
+		name      string
+		mapping   map[string]string
+		configErr bool
+		want      string
+	}{
+		{
+			name:      "duplicate target",
+			mapping:   map[string]string{"Order Date": "order", "Order ID": "order"},
+			configErr: true,
+			want:      "'order'",
+		},
+		{
+			name:      "empty target",
+			mapping:   map[string]string{"Order Date": ""},
+			configErr: true,
+			want:      "Order Date",
+		},
+		{
+			name:    "missing header",
+			mapping: map[string]string{"Ship Date": "ship_date"},
+			want:    "mapped header 'Ship Date' not found",
+		},
+		{
+			name:    "collision with a header left as it is",
+			mapping: map[string]string{"Order Date": "region"},
+			want:    "header 'Order Date' is mapped to the existing column 'region'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := csvConfig(path)
+			config.ColumnMapping = tt.mapping
+
+			_, err := NewCSVDataSource().Fetch(context.Background(), config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Fetch() error = %v, want containing %q", err, tt.want)
+			}
+			if got := domainerrors.IsConfigurationError(err); got != tt.configErr {
+				t.Errorf("Fetch() error is ConfigurationError = %v, want %v", got, tt.configErr)
+			}
+		})
+	}
+
+	// Swapping two headers is not a collision
+	config := csvConfig(path)
+	config.ColumnMapping = map[string]string{"Order Date": "Order ID", "Order ID": "Order Date"}
+	df, err := NewCSVDataSource().Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got, want := df.Names(), []string{"Order ID", "Order Date", "region"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() names = %v, want %v", got, want)
+	}
+}
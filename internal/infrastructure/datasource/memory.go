@@ -0,0 +1,139 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"slices"
+)
+
+// Ensure InMemoryDataSource implements the DataSource interface
+var _ interfaces.DataSource = (*InMemoryDataSource)(nil)
+
+// InMemoryDataSource provides an existing DataFrame as a data source without touching disk or network.
+// It is useful for unit tests and chaining the output of one pipeline into another.
+// The Source of the DataSourceConfig is ignored.
+type InMemoryDataSource struct {
+	data *dataframe.DataFrame
+}
+
+// NewInMemoryDataSource creates a new InMemoryDataSource holding the given DataFrame.
+func NewInMemoryDataSource(data *dataframe.DataFrame) *InMemoryDataSource {
+	return &InMemoryDataSource{
+		data: data,
+	}
+}
+
+// NewInMemoryDataSourceFromRecords creates a new InMemoryDataSource from the headers and string rows.
+// Column types are detected from the values the same way as reading CSV.
+func NewInMemoryDataSourceFromRecords(headers []string, rows [][]string) *InMemoryDataSource {
+	records := make([][]string, 0, len(rows)+1)
+	records = append(records, headers)
+	records = append(records, rows...)
+
+	df := dataframe.LoadRecords(records)
+
+	return NewInMemoryDataSource(&df)
+}
+
+// Fetch returns a copy of the stored DataFrame so that the caller cannot modify the stored data.
+func (m *InMemoryDataSource) Fetch(ctx context.Context, config interfaces.DataSourceConfig) (*dataframe.DataFrame, error) {
+	if err := m.Validate(config); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("fetch", "fetch is canceled", err)
+	}
+
+	df := m.data.Copy()
+	if err := applyColumnMapping(&df, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	if progress := interfaces.ProgressFromContext(ctx); progress != nil {
+		progress(df.Nrow(), df.Nrow())
+	}
+
+	return &df, nil
+}
+
+// Sample returns a copy of the first n rows of the stored DataFrame, keeping the column types of the stored DataFrame.
+func (m *InMemoryDataSource) Sample(ctx context.Context, config interfaces.DataSourceConfig, n int) (*dataframe.DataFrame, error) {
+	if err := validateSampleSize(n); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(config); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("sample", "sample is canceled", err)
+	}
+
+	head, err := headRows(m.data, n)
+	if err != nil {
+		return nil, err
+	}
+
+	df := head.Copy()
+	if err := applyColumnMapping(&df, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	return &df, nil
+}
+
+// Validate checks the config has the supported type, no options, and a valid ColumnMapping, and the stored DataFrame is available.
+func (m *InMemoryDataSource) Validate(config interfaces.DataSourceConfig) error {
+	if !slices.Contains(m.SupportedTypes(), config.Type) {
+		return domainerrors.NewConfigurationError("type", fmt.Sprintf("unsupported type '%s' for in-memory data source", config.Type), nil)
+	}
+	if m.data == nil {
+		return domainerrors.NewConfigurationError("source", "in-memory data source has no DataFrame", nil)
+	}
+	if m.data.Err != nil {
+		return domainerrors.NewConfigurationError("source", "in-memory DataFrame has an error", m.data.Err)
+	}
+
+	if err := validateNoOptions(config); err != nil {
+		return err
+	}
+
+	return validateColumnMapping(config)
+}
+
+// GetSourceInfo returns human-readable information including the dimensions of the stored DataFrame.
+func (m *InMemoryDataSource) GetSourceInfo(_ interfaces.DataSourceConfig) string {
+	if m.data == nil {
+		return formatSourceInfo("memory", "DataFrame", -1, -1)
+	}
+
+	return formatSourceInfo("memory", "DataFrame", m.data.Nrow(), m.data.Ncol())
+}
+
+// SupportedTypes returns the source types supported by InMemoryDataSource.
+func (m *InMemoryDataSource) SupportedTypes() []string {
+	return []string{"memory"}
+}
+
+// GetCapabilities returns the features supported by InMemoryDataSource.
+func (m *InMemoryDataSource) GetCapabilities(_ interfaces.DataSourceConfig) interfaces.Capabilities {
+	return interfaces.Capabilities{
+		SupportsRange:              false,
+		SupportsStreaming:          false,
+		SupportsRowCountEstimation: true,
+		RequiredAuth:               "none",
+	}
+}
+
+// EstimateRowCount returns the exact row count of the stored DataFrame.
+func (m *InMemoryDataSource) EstimateRowCount(_ context.Context, config interfaces.DataSourceConfig) (int, error) {
+	if err := m.Validate(config); err != nil {
+		return -1, err
+	}
+
+	return m.data.Nrow(), nil
+}
@@ -0,0 +1,77 @@
+package datasource
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"reflect"
+	"testing"
+)
+
+func TestInMemoryDataSourceFetch(t *testing.T) {
+	source := NewInMemoryDataSourceFromRecords([]string{"id", "name"}, [][]string{{"1", "Alice"}, {"2", "Bob"}})
+
+	// The Source is ignored
+	df, err := source.Fetch(context.Background(), interfaces.DataSourceConfig{Type: "memory", Source: "anything"})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := [][]string{{"id", "name"}, {"1", "Alice"}, {"2", "Bob"}}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records = %v, want %v", got, want)
+	}
+
+	// The fetched frame is a copy, so modifying it doesn't change the stored frame
+	df.Elem(0, 1).Set("Mallory")
+	again, err := source.Fetch(context.Background(), interfaces.DataSourceConfig{Type: "memory"})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := again.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records after modifying the previous result = %v, want %v", got, want)
+	}
+}
+
+func TestInMemoryDataSourceGetSourceInfo(t *testing.T) {
+	source := NewInMemoryDataSourceFromRecords([]string{"id", "name", "amount"}, [][]string{{"1", "Alice", "3"}, {"2", "Bob", "4"}})
+
+	if got, want := source.GetSourceInfo(interfaces.DataSourceConfig{Type: "memory"}), "memory: DataFrame (~2 rows, 3 columns)"; got != want {
+		t.Errorf("GetSourceInfo() = %q, want %q", got, want)
+	}
+}
+
+func TestInMemoryDataSourceValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		source *InMemoryDataSource
+		config interfaces.DataSourceConfig
+	}{
+		{name: "nil frame", source: NewInMemoryDataSource(nil), config: interfaces.DataSourceConfig{Type: "memory"}},
+		{
+			name:   "unsupported type",
+			source: NewInMemoryDataSourceFromRecords([]string{"id"}, [][]string{{"1"}}),
+			config: interfaces.DataSourceConfig{Type: "csv"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.source.Validate(tt.config); err == nil {
+				t.Error("Validate() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestInMemoryDataSourceFetchReportsProgress(t *testing.T) {
+	source := NewInMemoryDataSourceFromRecords([]string{"id"}, [][]string{{"1"}, {"2"}, {"3"}})
+
+	var calls []progressCall
+	if _, err := source.Fetch(recordProgress(&calls), interfaces.DataSourceConfig{Type: "memory"}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// The frame is already in memory, so only the completion is reported
+	if want := []progressCall{{3, 3}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
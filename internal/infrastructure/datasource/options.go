@@ -0,0 +1,10 @@
+package datasource
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+)
+
+// validateNoOptions rejects the Options of the config for the sources having no options.
+func validateNoOptions(config interfaces.DataSourceConfig) error {
+	return config.DecodeOptions(&struct{}{})
+}
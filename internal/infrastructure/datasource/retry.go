@@ -0,0 +1,86 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// retryableStatuses are the HTTP statuses of the transient failures worth retrying
+var retryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// maxRetryDelay caps the exponential backoff delay
+const maxRetryDelay = 30 * time.Second
+
+// retrySourceOptions holds the retry options of the sources requesting over HTTP
+type retrySourceOptions struct {
+	MaxAttempts    int    `json:"maxAttempts"`    // Attempts of a request including the first one (default 3, 1 for no retries)
+	RetryBaseDelay string `json:"retryBaseDelay"` // Delay before the first retry as a Go duration, doubled for each further retry (default "500ms")
+}
+
+// defaultRetrySourceOptions returns the default retry options.
+func defaultRetrySourceOptions() retrySourceOptions {
+	return retrySourceOptions{
+		MaxAttempts:    3,
+		RetryBaseDelay: "500ms",
+	}
+}
+
+// retryPolicy is the parsed retry options
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// policy validates the retry options and returns the retry policy of them.
+func (o retrySourceOptions) policy() (retryPolicy, error) {
+	if o.MaxAttempts < 1 {
+		return retryPolicy{}, domainerrors.NewConfigurationError("options.maxAttempts", fmt.Sprintf("maxAttempts must be at least 1, got %d", o.MaxAttempts), nil)
+	}
+
+	delay, err := time.ParseDuration(o.RetryBaseDelay)
+	if err != nil || delay < 0 {
+		return retryPolicy{}, domainerrors.NewConfigurationError("options.retryBaseDelay", fmt.Sprintf("retryBaseDelay must be a non-negative duration like \"500ms\", got '%s'", o.RetryBaseDelay), err)
+	}
+
+	return retryPolicy{maxAttempts: o.MaxAttempts, baseDelay: delay}, nil
+}
+
+// isRetryableStatus reports whether the HTTP status is a transient failure worth retrying.
+func isRetryableStatus(status int) bool {
+	return slices.Contains(retryableStatuses, status)
+}
+
+// delay returns the backoff delay before the retry following the failed attempt (1-based):
+// the base delay doubled for each previous retry, capped at maxRetryDelay.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	delay := p.baseDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// wait sleeps the backoff delay after the failed attempt, returning early with a DataProcessError of the step
+// if the context is done.
+func (p retryPolicy) wait(ctx context.Context, step string, attempt int) error {
+	timer := time.NewTimer(p.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return domainerrors.NewDataProcessError(step, fmt.Sprintf("%s is canceled while waiting to retry", step), ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
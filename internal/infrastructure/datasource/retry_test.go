@@ -0,0 +1,78 @@
+package datasource
+
+import (
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"testing"
+	"time"
+)
+
+func TestRetrySourceOptionsPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   retrySourceOptions
+		want      retryPolicy
+		wantField string
+	}{
+		{name: "default", options: defaultRetrySourceOptions(), want: retryPolicy{maxAttempts: 3, baseDelay: 500 * time.Millisecond}},
+		{name: "no retries", options: retrySourceOptions{MaxAttempts: 1, RetryBaseDelay: "0s"}, want: retryPolicy{maxAttempts: 1}},
+		{name: "zero attempts", options: retrySourceOptions{MaxAttempts: 0, RetryBaseDelay: "1s"}, wantField: "options.maxAttempts"},
+		{name: "malformed delay", options: retrySourceOptions{MaxAttempts: 3, RetryBaseDelay: "soon"}, wantField: "options.retryBaseDelay"},
+		{name: "negative delay", options: retrySourceOptions{MaxAttempts: 3, RetryBaseDelay: "-1s"}, wantField: "options.retryBaseDelay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.policy()
+			if tt.wantField == "" {
+				if err != nil || got != tt.want {
+					t.Errorf("policy() = %+v, %v, want %+v", got, err, tt.want)
+				}
+				return
+			}
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+				t.Errorf("policy() error = %v, want a ConfigurationError of %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 10, baseDelay: 500 * time.Millisecond}
+
+	tests := map[int]time.Duration{
+		1: 500 * time.Millisecond,
+		2: time.Second,
+		3: 2 * time.Second,
+		// The doubling is capped at maxRetryDelay
+		8:  maxRetryDelay,
+		64: maxRetryDelay,
+	}
+	for attempt, want := range tests {
+		if got := policy.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := map[int]bool{
+		429: true,
+		500: true,
+		502: true,
+		503: true,
+		504: true,
+		400: false,
+		401: false,
+		403: false,
+		404: false,
+		501: false,
+	}
+
+	for status, want := range tests {
+		if got := isRetryableStatus(status); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package datasource
+
+import (
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+)
+
+// validateSampleSize checks the number of the sampled rows is positive.
+func validateSampleSize(n int) error {
+	if n <= 0 {
+		return domainerrors.NewConfigurationError("n", fmt.Sprintf("sample size must be positive, got %d", n), nil)
+	}
+
+	return nil
+}
+
+// headRows returns the first n rows of the DataFrame, or the DataFrame itself if it has n rows or fewer.
+func headRows(df *dataframe.DataFrame, n int) (*dataframe.DataFrame, error) {
+	if df.Nrow() <= n {
+		return df, nil
+	}
+
+	rows := make([]int, n)
+	for i := range rows {
+		rows[i] = i
+	}
+
+	head := df.Subset(rows)
+	if head.Err != nil {
+		return nil, domainerrors.NewDataProcessError("sample", "failed to subset rows", head.Err)
+	}
+
+	return &head, nil
+}
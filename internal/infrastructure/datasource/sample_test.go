@@ -0,0 +1,112 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/series"
+	"reflect"
+	"testing"
+)
+
+func TestCSVDataSourceSample(t *testing.T) {
+	full, err := NewCSVDataSource().Fetch(context.Background(), csvConfig(salesFixture))
+	if err != nil {
+		t.Fatalf("Fetch(%s) error = %v", salesFixture, err)
+	}
+	records := full.Records()
+
+	tests := []struct {
+		name string
+		n    int
+		want [][]string
+	}{
+		{name: "fewer rows than the file", n: 2, want: records[:3]},
+		{name: "as many rows as the file", n: 5, want: records},
+		{name: "more rows than the file", n: 100, want: records},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := NewCSVDataSource().Sample(context.Background(), csvConfig(salesFixture), tt.n)
+			if err != nil {
+				t.Fatalf("Sample(%d) error = %v", tt.n, err)
+			}
+			if got := df.Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sample(%d) records = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVDataSourceSampleInfersTypesFromSampledRows(t *testing.T) {
+	// The amount of the third row isn't numeric, which only the full read sees
+	path := writeFile(t, "sample.csv", "id,amount\n1,10\n2,20\n3,n/a\n")
+
+	df, err := NewCSVDataSource().Sample(context.Background(), csvConfig(path), 2)
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if got := df.Col("amount").Type(); got != series.Int {
+		t.Errorf("Sample() type of amount = %v, want %v", got, series.Int)
+	}
+
+	full, err := NewCSVDataSource().Fetch(context.Background(), csvConfig(path))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := full.Col("amount").Type(); got != series.String {
+		t.Errorf("Fetch() type of amount = %v, want %v", got, series.String)
+	}
+}
+
+func TestSampleInvalidSize(t *testing.T) {
+	sources := map[string]interfaces.DataSource{
+		"csv":    NewCSVDataSource(),
+		"memory": NewInMemoryDataSourceFromRecords([]string{"id"}, [][]string{{"1"}}),
+	}
+
+	for name, source := range sources {
+		for _, n := range []int{0, -1} {
+			_, err := source.Sample(context.Background(), interfaces.DataSourceConfig{Type: name, Source: salesFixture}, n)
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || configErr.Field != "n" {
+				t.Errorf("%s Sample(%d) error = %v, want a ConfigurationError of n", name, n, err)
+			}
+		}
+	}
+}
+
+func TestInMemoryDataSourceSample(t *testing.T) {
+	source := NewInMemoryDataSourceFromRecords([]string{"id", "name"}, [][]string{{"1", "Alice"}, {"2", "Bob"}, {"3", "Carol"}})
+
+	tests := []struct {
+		n    int
+		want [][]string
+	}{
+		{n: 2, want: [][]string{{"id", "name"}, {"1", "Alice"}, {"2", "Bob"}}},
+		{n: 10, want: [][]string{{"id", "name"}, {"1", "Alice"}, {"2", "Bob"}, {"3", "Carol"}}},
+	}
+
+	for _, tt := range tests {
+		df, err := source.Sample(context.Background(), interfaces.DataSourceConfig{Type: "memory"}, tt.n)
+		if err != nil {
+			t.Fatalf("Sample(%d) error = %v", tt.n, err)
+		}
+		if got := df.Records(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Sample(%d) records = %v, want %v", tt.n, got, tt.want)
+		}
+
+		// The sample is a copy, so modifying it doesn't change the stored frame
+		df.Elem(0, 1).Set("Mallory")
+	}
+
+	df, err := source.Fetch(context.Background(), interfaces.DataSourceConfig{Type: "memory"})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := df.Elem(0, 1).String(); got != "Alice" {
+		t.Errorf("Fetch() name of the first row after modifying the samples = %q, want %q", got, "Alice")
+	}
+}
@@ -0,0 +1,291 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strings"
+)
+
+// Ensure UnionDataSource implements the DataSource interface
+var _ interfaces.DataSource = (*UnionDataSource)(nil)
+
+// UnionMember pairs a DataSource with the configuration used to fetch from it.
+type UnionMember struct {
+	Source interfaces.DataSource
+	Config interfaces.DataSourceConfig
+}
+
+// UnionDataSource fetches data from multiple sources and row-binds them into a single DataFrame.
+// All members must provide the same column names with compatible types (int and float are compatible as float).
+type UnionDataSource struct {
+	members []UnionMember
+}
+
+// NewUnionDataSource creates a new UnionDataSource combining the given members in order.
+func NewUnionDataSource(members ...UnionMember) *UnionDataSource {
+	return &UnionDataSource{
+		members: members,
+	}
+}
+
+// Fetch retrieves the data from every member and row-binds them in the member order.
+// Each member is fetched with its own configuration, and only the ColumnMapping of the config is applied
+// to the combined data.
+func (u *UnionDataSource) Fetch(ctx context.Context, config interfaces.DataSourceConfig) (*dataframe.DataFrame, error) {
+	if err := u.Validate(config); err != nil {
+		return nil, err
+	}
+
+	// The progress of the members is reported cumulatively, and the total is unknown until the last member
+	progress := interfaces.ProgressFromContext(ctx)
+	fetched := 0
+
+	var result *dataframe.DataFrame
+	for i, member := range u.members {
+		memberCtx := ctx
+		if progress != nil {
+			offset := fetched
+			memberCtx = interfaces.WithProgress(ctx, func(done, _ int) {
+				progress(offset+done, -1)
+			})
+		}
+
+		df, err := member.Source.Fetch(memberCtx, member.Config)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("fetch", fmt.Sprintf("failed to fetch union member[%d]", i), err)
+		}
+
+		fetched += df.Nrow()
+
+		if result == nil {
+			result = df
+			continue
+		}
+
+		combined, err := unionDataFrames(*result, *df)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("union", fmt.Sprintf("union member[%d] is not compatible: %v", i, err), err)
+		}
+		result = &combined
+	}
+
+	if progress != nil {
+		progress(fetched, fetched)
+	}
+
+	if err := applyColumnMapping(result, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Sample samples the members in order until n rows are collected and row-binds the samples.
+// The members after the one completing n rows are not read at all, and only the ColumnMapping of the config
+// is applied to the combined sample as Fetch does.
+func (u *UnionDataSource) Sample(ctx context.Context, config interfaces.DataSourceConfig, n int) (*dataframe.DataFrame, error) {
+	if err := validateSampleSize(n); err != nil {
+		return nil, err
+	}
+	if err := u.Validate(config); err != nil {
+		return nil, err
+	}
+
+	var result *dataframe.DataFrame
+	for i, member := range u.members {
+		remaining := n
+		if result != nil {
+			remaining -= result.Nrow()
+		}
+		if remaining <= 0 {
+			break
+		}
+
+		df, err := member.Source.Sample(ctx, member.Config, remaining)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("sample", fmt.Sprintf("failed to sample union member[%d]", i), err)
+		}
+
+		if result == nil {
+			result = df
+			continue
+		}
+
+		combined, err := unionDataFrames(*result, *df)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("union", fmt.Sprintf("union member[%d] is not compatible: %v", i, err), err)
+		}
+		result = &combined
+	}
+
+	if err := applyColumnMapping(result, config.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Validate checks that the union has at least one member, every member configuration is valid,
+// and the config has no options (the members have their own) and a valid ColumnMapping.
+func (u *UnionDataSource) Validate(config interfaces.DataSourceConfig) error {
+	if len(u.members) == 0 {
+		return domainerrors.NewConfigurationError("source", "union data source requires at least one member", nil)
+	}
+
+	for i, member := range u.members {
+		if member.Source == nil {
+			return domainerrors.NewConfigurationError("source", fmt.Sprintf("union member[%d] has no data source", i), nil)
+		}
+		if err := member.Source.Validate(member.Config); err != nil {
+			return domainerrors.NewConfigurationError("source", fmt.Sprintf("union member[%d] is invalid", i), err)
+		}
+	}
+
+	if err := validateNoOptions(config); err != nil {
+		return err
+	}
+
+	return validateColumnMapping(config)
+}
+
+// GetSourceInfo returns human-readable information listing all members with the estimated total rows.
+func (u *UnionDataSource) GetSourceInfo(config interfaces.DataSourceConfig) string {
+	infos := make([]string, 0, len(u.members))
+	for _, member := range u.members {
+		if member.Source == nil {
+			continue
+		}
+		infos = append(infos, member.Source.GetSourceInfo(member.Config))
+	}
+
+	rows, err := u.EstimateRowCount(context.Background(), config)
+	if err != nil {
+		rows = -1
+	}
+
+	return formatSourceInfo("union", fmt.Sprintf("[%s]", strings.Join(infos, ", ")), rows, -1)
+}
+
+// SupportedTypes returns the source types supported by UnionDataSource.
+func (u *UnionDataSource) SupportedTypes() []string {
+	return []string{"union"}
+}
+
+// GetCapabilities returns the features supported by all members.
+// A feature is supported only when every member supports it.
+func (u *UnionDataSource) GetCapabilities(_ interfaces.DataSourceConfig) interfaces.Capabilities {
+	capabilities := interfaces.Capabilities{
+		SupportsRange:              false,
+		SupportsStreaming:          len(u.members) > 0,
+		SupportsRowCountEstimation: len(u.members) > 0,
+		RequiredAuth:               "none",
+	}
+
+	for _, member := range u.members {
+		if member.Source == nil {
+			continue
+		}
+
+		memberCapabilities := member.Source.GetCapabilities(member.Config)
+		capabilities.SupportsStreaming = capabilities.SupportsStreaming && memberCapabilities.SupportsStreaming
+		capabilities.SupportsRowCountEstimation = capabilities.SupportsRowCountEstimation && memberCapabilities.SupportsRowCountEstimation
+		if memberCapabilities.RequiredAuth != "" && memberCapabilities.RequiredAuth != "none" {
+			capabilities.RequiredAuth = memberCapabilities.RequiredAuth
+		}
+	}
+
+	return capabilities
+}
+
+// EstimateRowCount sums the estimates of all members. Returns -1 if any member cannot estimate.
+func (u *UnionDataSource) EstimateRowCount(ctx context.Context, config interfaces.DataSourceConfig) (int, error) {
+	if err := u.Validate(config); err != nil {
+		return -1, err
+	}
+
+	total := 0
+	for _, member := range u.members {
+		count, err := member.Source.EstimateRowCount(ctx, member.Config)
+		if err != nil {
+			return -1, err
+		}
+		if count < 0 {
+			return -1, nil
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
+// unionDataFrames row-binds two DataFrames after checking the column names and types are compatible.
+// Int and float columns are combined as float columns.
+func unionDataFrames(left, right dataframe.DataFrame) (dataframe.DataFrame, error) {
+	leftTypes := columnTypes(left)
+	rightTypes := columnTypes(right)
+
+	differences := make([]string, 0)
+	for _, name := range left.Names() {
+		rightType, ok := rightTypes[name]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("'%s' (missing in right)", name))
+			continue
+		}
+		if !compatibleTypes(leftTypes[name], rightType) {
+			differences = append(differences, fmt.Sprintf("'%s' (%s vs %s)", name, leftTypes[name], rightType))
+		}
+	}
+	for _, name := range right.Names() {
+		if _, ok := leftTypes[name]; !ok {
+			differences = append(differences, fmt.Sprintf("'%s' (missing in left)", name))
+		}
+	}
+
+	if len(differences) > 0 {
+		return dataframe.DataFrame{}, fmt.Errorf("schema mismatch in columns: %s", strings.Join(differences, ", "))
+	}
+
+	// Promote the int columns to float when the other side is float
+	for _, name := range left.Names() {
+		if leftTypes[name] == rightTypes[name] {
+			continue
+		}
+		left = left.Mutate(series.New(left.Col(name).Float(), series.Float, name))
+		right = right.Mutate(series.New(right.Col(name).Float(), series.Float, name))
+	}
+
+	combined := left.RBind(right)
+	if combined.Err != nil {
+		return dataframe.DataFrame{}, combined.Err
+	}
+
+	return combined, nil
+}
+
+// columnTypes returns a map of column names to their series types.
+func columnTypes(df dataframe.DataFrame) map[string]series.Type {
+	types := make(map[string]series.Type, df.Ncol())
+	colTypes := df.Types()
+	for i, name := range df.Names() {
+		types[name] = colTypes[i]
+	}
+
+	return types
+}
+
+// compatibleTypes checks if two series types can be row-bound without losing data.
+func compatibleTypes(a, b series.Type) bool {
+	if a == b {
+		return true
+	}
+
+	isNumeric := func(t series.Type) bool {
+		return t == series.Int || t == series.Float
+	}
+
+	return isNumeric(a) && isNumeric(b)
+}
@@ -0,0 +1,83 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/series"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memoryMember returns the union member of the in-memory source of the records.
+func memoryMember(headers []string, rows ...[]string) UnionMember {
+	return UnionMember{
+		Source: NewInMemoryDataSourceFromRecords(headers, rows),
+		Config: interfaces.DataSourceConfig{Type: "memory"},
+	}
+}
+
+func TestUnionDataSourceFetch(t *testing.T) {
+	union := NewUnionDataSource(
+		memoryMember([]string{"id", "amount"}, []string{"1", "10"}, []string{"2", "20"}),
+		memoryMember([]string{"amount", "id"}, []string{"2.5", "3"}),
+	)
+
+	df, err := union.Fetch(context.Background(), interfaces.DataSourceConfig{Type: "union"})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := [][]string{{"id", "amount"}, {"1", "10.000000"}, {"2", "20.000000"}, {"3", "2.500000"}}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() records = %v, want %v", got, want)
+	}
+	if got := df.Col("amount").Type(); got != series.Float {
+		t.Errorf("amount type = %s, want the int column promoted to %s", got, series.Float)
+	}
+}
+
+func TestUnionDataSourceFetchIncompatible(t *testing.T) {
+	tests := []struct {
+		name    string
+		second  UnionMember
+		message string
+	}{
+		{
+			name:    "missing column",
+			second:  memoryMember([]string{"id"}, []string{"3"}),
+			message: "'amount' (missing in right)",
+		},
+		{
+			name:    "extra column",
+			second:  memoryMember([]string{"id", "amount", "note"}, []string{"3", "1", "x"}),
+			message: "'note' (missing in left)",
+		},
+		{
+			name:    "incompatible type",
+			second:  memoryMember([]string{"id", "amount"}, []string{"3", "high"}),
+			message: "'amount' (int vs string)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			union := NewUnionDataSource(memoryMember([]string{"id", "amount"}, []string{"1", "10"}), tt.second)
+
+			_, err := union.Fetch(context.Background(), interfaces.DataSourceConfig{Type: "union"})
+			if err == nil {
+				t.Fatal("Fetch() error = nil, want the schema mismatch")
+			}
+			if cause := errors.Unwrap(err); cause == nil || !strings.Contains(cause.Error(), tt.message) {
+				t.Errorf("Fetch() error cause = %v, want it to contain %q", cause, tt.message)
+			}
+		})
+	}
+}
+
+func TestUnionDataSourceValidateNoMembers(t *testing.T) {
+	if err := NewUnionDataSource().Validate(interfaces.DataSourceConfig{Type: "union"}); err == nil {
+		t.Error("Validate() error = nil, want the error of no members")
+	}
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"sync"
+)
+
+// Ensure CollectOutput implements the Output interface
+var _ interfaces.Output = (*CollectOutput)(nil)
+
+// CollectOutput keeps the result in memory as the rows of Collect instead of writing it anywhere,
+// for the library consumers processing the result further in Go. The rows of the last Write are returned by Rows.
+// The Destination of the config is ignored, and the format is "collect" without any options.
+type CollectOutput struct {
+	mu   sync.Mutex
+	rows []map[string]interface{}
+}
+
+// NewCollectOutput creates a new CollectOutput instance.
+func NewCollectOutput() *CollectOutput {
+	return &CollectOutput{}
+}
+
+// Collect materializes the rows of the DataFrame into the maps from the column names to the values.
+// The values have the Go types of the column types:
+// - string columns: string
+// - int columns: int
+// - float columns: float64
+// - bool columns: bool
+// - null values of any column: nil
+func Collect(df *dataframe.DataFrame) ([]map[string]interface{}, error) {
+	if df == nil {
+		return nil, domainerrors.NewDataProcessError("output", "no data to collect", nil)
+	}
+
+	names := df.Names()
+	rows := make([]map[string]interface{}, df.Nrow())
+	for i := range rows {
+		row := make(map[string]interface{}, len(names))
+		for j, name := range names {
+			row[name] = nil
+			if element := df.Elem(i, j); !element.IsNA() {
+				row[name] = element.Val()
+			}
+		}
+		rows[i] = row
+	}
+
+	return rows, nil
+}
+
+// Write collects the rows of the DataFrame (see Collect), replacing the rows of the previous Write.
+func (c *CollectOutput) Write(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	if err := c.Validate(config); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	rows, err := Collect(df)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.rows = rows
+	c.mu.Unlock()
+
+	return nil
+}
+
+// WriteStream collects the streamed rows, replacing the rows of the previous Write.
+// The values are kept as strings because the streamed rows have no column types.
+// The rows are replaced only when the stream completes, so the previous rows remain on error or cancellation.
+func (c *CollectOutput) WriteStream(ctx context.Context, rows <-chan []string, header []string, config interfaces.OutputConfig) error {
+	if err := c.Validate(config); err != nil {
+		return err
+	}
+
+	collected := make([]map[string]interface{}, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return domainerrors.NewDataProcessError("output", "output is canceled", ctx.Err())
+		case record, ok := <-rows:
+			if !ok {
+				c.mu.Lock()
+				c.rows = collected
+				c.mu.Unlock()
+				return nil
+			}
+			if len(record) != len(header) {
+				return domainerrors.NewDataProcessError("output", fmt.Sprintf("row %d has %d fields, expected %d", len(collected)+1, len(record), len(header)), nil)
+			}
+
+			row := make(map[string]interface{}, len(header))
+			for j, name := range header {
+				row[name] = record[j]
+			}
+			collected = append(collected, row)
+		}
+	}
+}
+
+// Rows returns the rows collected by the last Write or WriteStream, or nil if nothing is collected yet.
+func (c *CollectOutput) Rows() []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rows
+}
+
+// Close does nothing because CollectOutput holds no resources. The collected rows remain available.
+func (c *CollectOutput) Close() error {
+	return nil
+}
+
+// Validate checks the config has the collect format and no options.
+func (c *CollectOutput) Validate(config interfaces.OutputConfig) error {
+	if config.Format != "collect" {
+		return domainerrors.NewConfigurationError("format", fmt.Sprintf("unsupported format '%s' for collect output, supported: %v", config.Format, c.SupportedFormats()), nil)
+	}
+	for key := range config.Options {
+		return domainerrors.NewConfigurationError("options."+key, fmt.Sprintf("unknown option '%s' for collect output", key), nil)
+	}
+
+	return nil
+}
+
+// SupportedFormats returns the output formats supported by CollectOutput.
+func (c *CollectOutput) SupportedFormats() []string {
+	return []string{"collect"}
+}
+
+// GetFormatOptions returns no options because the collect output has none.
+func (c *CollectOutput) GetFormatOptions(_ string) map[string]string {
+	return map[string]string{}
+}
+
+// Preview renders the result data up to maxRows rows (0 for all) as the console table,
+// because the collected rows have no text representation of their own.
+func (c *CollectOutput) Preview(result *entities.Processing, config interfaces.OutputConfig, maxRows int) (string, error) {
+	if err := c.Validate(config); err != nil {
+		return "", err
+	}
+
+	return NewConsoleOutput().Preview(result, interfaces.OutputConfig{Format: "console"}, maxRows)
+}
+
+// EstimateSize estimates the byte size of the result data encoded as JSON, a rough measure of the collected rows.
+func (c *CollectOutput) EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error) {
+	if err := c.Validate(config); err != nil {
+		return 0, err
+	}
+
+	return estimateSize(result, func(w io.Writer, df *dataframe.DataFrame) error {
+		return writeJSON(w, df, jsonOptions{})
+	})
+}
+
+// PreviewStructured returns the structured preview of the result data up to maxRows rows (0 for all).
+func (c *CollectOutput) PreviewStructured(result *entities.Processing, _ interfaces.OutputConfig, maxRows int) (*interfaces.PreviewResult, error) {
+	return buildPreviewResult(result, maxRows)
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"context"
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"reflect"
+	"testing"
+)
+
+// collectFrame returns the DataFrame of a column of each type, with a null in each.
+func collectFrame() *dataframe.DataFrame {
+	df := dataframe.New(
+		series.New([]interface{}{"Alice", nil}, series.String, "name"),
+		series.New([]interface{}{10, nil}, series.Int, "quantity"),
+		series.New([]interface{}{nil, 2.5}, series.Float, "price"),
+		series.New([]interface{}{true, nil}, series.Bool, "paid"),
+	)
+
+	return &df
+}
+
+func TestCollect(t *testing.T) {
+	rows, err := Collect(collectFrame())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	// reflect.DeepEqual compares the dynamic types too, so 10 must be an int and 2.5 a float64
+	want := []map[string]interface{}{
+		{"name": "Alice", "quantity": 10, "price": nil, "paid": true},
+		{"name": nil, "quantity": nil, "price": 2.5, "paid": nil},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Collect() = %v, want %v", rows, want)
+	}
+	for column, wantType := range map[string]reflect.Type{
+		"name":     reflect.TypeOf(""),
+		"quantity": reflect.TypeOf(0),
+		"paid":     reflect.TypeOf(false),
+	} {
+		if got := reflect.TypeOf(rows[0][column]); got != wantType {
+			t.Errorf("Collect() %s type = %v, want %v", column, got, wantType)
+		}
+	}
+	if got := reflect.TypeOf(rows[1]["price"]); got != reflect.TypeOf(0.0) {
+		t.Errorf("Collect() price type = %v, want float64", got)
+	}
+
+	var processErr *domainerrors.DataProcessError
+	if _, err := Collect(nil); !errors.As(err, &processErr) {
+		t.Errorf("Collect(nil) error = %v, want a DataProcessError", err)
+	}
+}
+
+func TestCollectOutputWrite(t *testing.T) {
+	output := NewCollectOutput()
+	if rows := output.Rows(); rows != nil {
+		t.Errorf("Rows() before Write = %v, want nil", rows)
+	}
+
+	config := interfaces.OutputConfig{Format: "collect"}
+	if err := output.Write(context.Background(), collectFrame(), config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if rows := output.Rows(); len(rows) != 2 || rows[0]["quantity"] != 10 {
+		t.Errorf("Rows() = %v, want the 2 collected rows", rows)
+	}
+
+	// The streamed rows have no column types, so the values are kept as strings
+	rows := make(chan []string, 2)
+	rows <- []string{"Bob", "3"}
+	rows <- []string{"Carol", "4"}
+	close(rows)
+	if err := output.WriteStream(context.Background(), rows, []string{"name", "quantity"}, config); err != nil {
+		t.Fatalf("WriteStream() error = %v", err)
+	}
+	want := []map[string]interface{}{{"name": "Bob", "quantity": "3"}, {"name": "Carol", "quantity": "4"}}
+	if got := output.Rows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rows() after WriteStream = %v, want %v", got, want)
+	}
+}
+
+func TestCollectOutputValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    interfaces.OutputConfig
+		wantField string
+	}{
+		{name: "collect", config: interfaces.OutputConfig{Format: "collect"}},
+		{name: "other format", config: interfaces.OutputConfig{Format: "csv"}, wantField: "format"},
+		{name: "option", config: interfaces.OutputConfig{Format: "collect", Options: map[string]interface{}{"indent": ""}}, wantField: "options.indent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCollectOutput().Validate(tt.config)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want a ConfigurationError of %s", err, tt.wantField)
+			}
+		})
+	}
+}
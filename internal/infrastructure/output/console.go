@@ -0,0 +1,397 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Ensure ConsoleOutput implements the Output interface
+var _ interfaces.Output = (*ConsoleOutput)(nil)
+
+// borderStyle holds the characters drawing the table borders
+type borderStyle struct {
+	horizontal string
+	vertical   string
+	// Corners and junctions: top (left, middle, right), middle, bottom
+	topLeft, topMiddle, topRight          string
+	midLeft, midMiddle, midRight          string
+	bottomLeft, bottomMiddle, bottomRight string
+}
+
+// borderStyles maps the border option values to the border styles
+var borderStyles = map[string]*borderStyle{
+	"box": {
+		horizontal: "─", vertical: "│",
+		topLeft: "┌", topMiddle: "┬", topRight: "┐",
+		midLeft: "├", midMiddle: "┼", midRight: "┤",
+		bottomLeft: "└", bottomMiddle: "┴", bottomRight: "┘",
+	},
+	"ascii": {
+		horizontal: "-", vertical: "|",
+		topLeft: "+", topMiddle: "+", topRight: "+",
+		midLeft: "+", midMiddle: "+", midRight: "+",
+		bottomLeft: "+", bottomMiddle: "+", bottomRight: "+",
+	},
+	// none draws no borders and separates the columns with spaces
+	"none": nil,
+}
+
+// consoleOptions holds the parsed options of the console output
+type consoleOptions struct {
+	border      string
+	maxColWidth int
+	totals      totalsOptions
+	numbers     numberFormatOptions
+	nullValue   string
+}
+
+// ConsoleOutput writes the result to the terminal as an aligned table.
+// Numeric columns are right-aligned and the other columns are left-aligned.
+// Values wider than maxColWidth are truncated with an ellipsis.
+type ConsoleOutput struct {
+	writer io.Writer
+}
+
+// NewConsoleOutput creates a new ConsoleOutput writing to the standard output.
+func NewConsoleOutput() *ConsoleOutput {
+	return NewConsoleOutputWithWriter(os.Stdout)
+}
+
+// NewConsoleOutputWithWriter creates a new ConsoleOutput writing to the given writer.
+func NewConsoleOutputWithWriter(writer io.Writer) *ConsoleOutput {
+	return &ConsoleOutput{
+		writer: writer,
+	}
+}
+
+// Write renders the DataFrame as a table and writes it to the writer. The Destination of the config is ignored.
+func (c *ConsoleOutput) Write(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseConsoleOptions(config)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	if df == nil {
+		return domainerrors.NewDataProcessError("output", "no data to write", nil)
+	}
+
+	if _, err := io.WriteString(c.writer, renderTable(df, df.Nrow(), options)); err != nil {
+		return domainerrors.NewDataProcessError("output", "failed to write to console", err)
+	}
+	if err := flushWriter(c.writer); err != nil {
+		return domainerrors.NewDataProcessError("output", "failed to flush the console", err)
+	}
+
+	return nil
+}
+
+// Close flushes the writer if it is buffered. The writer is not closed because ConsoleOutput doesn't own it.
+func (c *ConsoleOutput) Close() error {
+	if err := flushWriter(c.writer); err != nil {
+		return domainerrors.NewDataProcessError("output", "failed to flush the console", err)
+	}
+
+	return nil
+}
+
+// WriteStream is not supported by ConsoleOutput because the column widths require all rows.
+func (c *ConsoleOutput) WriteStream(_ context.Context, _ <-chan []string, _ []string, _ interfaces.OutputConfig) error {
+	return domainerrors.NewDataProcessError("output", "streaming is not supported for console output", nil)
+}
+
+// Validate checks the config has the console format and valid options.
+func (c *ConsoleOutput) Validate(config interfaces.OutputConfig) error {
+	if !slices.Contains(c.SupportedFormats(), config.Format) {
+		return domainerrors.NewConfigurationError("format", fmt.Sprintf("unsupported format '%s' for console output", config.Format), nil)
+	}
+
+	_, err := parseConsoleOptions(config)
+
+	return err
+}
+
+// SupportedFormats returns the output formats supported by ConsoleOutput.
+func (c *ConsoleOutput) SupportedFormats() []string {
+	return []string{"console"}
+}
+
+// GetFormatOptions returns the available options of the console output and their descriptions.
+func (c *ConsoleOutput) GetFormatOptions(format string) map[string]string {
+	if format != "console" {
+		return map[string]string{}
+	}
+
+	options := map[string]string{
+		"border":      "Border style; box, ascii, or none (default: box)",
+		"maxColWidth": "Maximum display width of each column, longer values are truncated with an ellipsis (default: 30)",
+		"nullValue":   nullValueFormatOption,
+	}
+	maps.Copy(options, totalsFormatOptions)
+	maps.Copy(options, numberFormatFormatOptions)
+
+	return options
+}
+
+// Preview renders the table of the result data up to maxRows rows (0 for all) using the same renderer as Write.
+func (c *ConsoleOutput) Preview(result *entities.Processing, config interfaces.OutputConfig, maxRows int) (string, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseConsoleOptions(config)
+	if err != nil {
+		return "", err
+	}
+
+	if result == nil || result.Data == nil {
+		return "", domainerrors.NewDataProcessError("preview", "no data to preview", nil)
+	}
+
+	totalRows := result.Data.Nrow()
+	rowCount := totalRows
+	if maxRows > 0 && rowCount > maxRows {
+		rowCount = maxRows
+	}
+
+	table := renderTable(result.Data, rowCount, options)
+	if rowCount < totalRows {
+		table += fmt.Sprintf("(showing %d of %d rows)\n", rowCount, totalRows)
+	}
+
+	return table, nil
+}
+
+// EstimateSize estimates the byte size of the table of the result data.
+func (c *ConsoleOutput) EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseConsoleOptions(config)
+	if err != nil {
+		return 0, err
+	}
+
+	return estimateSize(result, func(w io.Writer, df *dataframe.DataFrame) error {
+		_, err := io.WriteString(w, renderTable(df, df.Nrow(), options))
+		return err
+	})
+}
+
+// PreviewStructured returns the structured preview of the result data up to maxRows rows (0 for all).
+func (c *ConsoleOutput) PreviewStructured(result *entities.Processing, _ interfaces.OutputConfig, maxRows int) (*interfaces.PreviewResult, error) {
+	return buildPreviewResult(result, maxRows)
+}
+
+// parseConsoleOptions reads and validates the console options of the config.
+// Missing options are treated as their defaults.
+func parseConsoleOptions(config interfaces.OutputConfig) (consoleOptions, error) {
+	options := consoleOptions{
+		border:      "box",
+		maxColWidth: 30,
+		nullValue:   defaultNullValue,
+	}
+
+	if value, ok := config.Options["border"]; ok {
+		border, _ := value.(string)
+		if _, ok := borderStyles[border]; !ok {
+			return options, domainerrors.NewConfigurationError("options.border", fmt.Sprintf("border must be one of [box ascii none], got %v", value), nil)
+		}
+		options.border = border
+	}
+
+	width, err := config.OptionIntStrict("maxColWidth", options.maxColWidth)
+	if err != nil {
+		return options, err
+	}
+	if width < 1 {
+		return options, domainerrors.NewConfigurationError("options.maxColWidth", fmt.Sprintf("maxColWidth must be positive, got %d", width), nil)
+	}
+	options.maxColWidth = width
+
+	nullValue, err := parseNullValueOption(config, options.nullValue, true)
+	if err != nil {
+		return options, err
+	}
+	options.nullValue = nullValue
+
+	totals, err := parseTotalsOptions(config)
+	if err != nil {
+		return options, err
+	}
+	options.totals = totals
+
+	numbers, err := parseNumberFormatOptions(config)
+	if err != nil {
+		return options, err
+	}
+	options.numbers = numbers
+
+	// The console has neither a document to embed the metadata in nor a destination to write the sidecar next to
+	if _, err := parseMetadataOption(config, ""); err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+// renderTable renders the first rowCount rows of the DataFrame as an aligned table.
+// The totals footer row is computed over all rows and rendered after a separator line when enabled.
+func renderTable(df *dataframe.DataFrame, rowCount int, options consoleOptions) string {
+	names := df.Names()
+	types := df.Types()
+
+	// Build the cells and measure the column widths
+	cells := make([][]string, rowCount+1)
+	cells[0] = make([]string, len(names))
+	for j, name := range names {
+		cells[0][j] = truncateWidth(name, options.maxColWidth)
+	}
+	formatted := options.numbers.formattedColumns(df)
+	for i := 0; i < rowCount; i++ {
+		cells[i+1] = make([]string, len(names))
+		for j := range names {
+			element := df.Elem(i, j)
+			cell := options.nullValue
+			if !element.IsNA() {
+				cell = options.numbers.formatElement(element, formatted != nil && formatted[j])
+			}
+			cells[i+1][j] = truncateWidth(cell, options.maxColWidth)
+		}
+	}
+
+	footer := totalsRow(df, options.totals)
+	if footer != nil {
+		options.numbers.formatRow(footer, types, formatted)
+		for j := range footer {
+			footer[j] = truncateWidth(footer[j], options.maxColWidth)
+		}
+		cells = append(cells, footer)
+	}
+
+	widths := make([]int, len(names))
+	for _, row := range cells {
+		for j, cell := range row {
+			widths[j] = max(widths[j], displayWidth(cell))
+		}
+	}
+
+	rightAligned := make([]bool, len(names))
+	for j, t := range types {
+		rightAligned[j] = t == series.Int || t == series.Float
+	}
+
+	style := borderStyles[options.border]
+
+	var builder strings.Builder
+	if style != nil {
+		builder.WriteString(borderLine(widths, style.topLeft, style.topMiddle, style.topRight, style.horizontal))
+	}
+	for i, row := range cells {
+		if footer != nil && i == len(cells)-1 && style != nil {
+			builder.WriteString(borderLine(widths, style.midLeft, style.midMiddle, style.midRight, style.horizontal))
+		}
+		builder.WriteString(rowLine(row, widths, rightAligned, style))
+		if i == 0 && style != nil {
+			builder.WriteString(borderLine(widths, style.midLeft, style.midMiddle, style.midRight, style.horizontal))
+		}
+	}
+	if style != nil {
+		builder.WriteString(borderLine(widths, style.bottomLeft, style.bottomMiddle, style.bottomRight, style.horizontal))
+	}
+
+	return builder.String()
+}
+
+// borderLine renders a horizontal border line.
+func borderLine(widths []int, left, middle, right, horizontal string) string {
+	segments := make([]string, len(widths))
+	for i, width := range widths {
+		segments[i] = strings.Repeat(horizontal, width+2)
+	}
+
+	return left + strings.Join(segments, middle) + right + "\n"
+}
+
+// rowLine renders a row with the padded cells.
+func rowLine(row []string, widths []int, rightAligned []bool, style *borderStyle) string {
+	padded := make([]string, len(row))
+	for j, cell := range row {
+		padding := strings.Repeat(" ", widths[j]-displayWidth(cell))
+		if rightAligned[j] {
+			padded[j] = padding + cell
+		} else {
+			padded[j] = cell + padding
+		}
+	}
+
+	if style == nil {
+		return strings.TrimRight(strings.Join(padded, "  "), " ") + "\n"
+	}
+
+	separator := " " + style.vertical + " "
+
+	return style.vertical + " " + strings.Join(padded, separator) + " " + style.vertical + "\n"
+}
+
+// truncateWidth truncates the value to the display width with an ellipsis if it is wider than maxWidth.
+func truncateWidth(value string, maxWidth int) string {
+	if displayWidth(value) <= maxWidth {
+		return value
+	}
+
+	const ellipsis = "…"
+	var builder strings.Builder
+	width := 0
+	for _, r := range value {
+		w := runeWidth(r)
+		if width+w > maxWidth-1 {
+			break
+		}
+		builder.WriteRune(r)
+		width += w
+	}
+	builder.WriteString(ellipsis)
+
+	return builder.String()
+}
+
+// displayWidth returns the display width of the value on the terminal.
+func displayWidth(value string) int {
+	width := 0
+	for _, r := range value {
+		width += runeWidth(r)
+	}
+
+	return width
+}
+
+// runeWidth returns the display width of the rune; East Asian wide characters take 2 columns.
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0x303E,   // CJK Radicals, Kangxi Radicals, CJK Symbols and Punctuation
+		r >= 0x3041 && r <= 0x33FF,   // Hiragana, Katakana, CJK Compatibility
+		r >= 0x3400 && r <= 0x4DBF,   // CJK Unified Ideographs Extension A
+		r >= 0x4E00 && r <= 0x9FFF,   // CJK Unified Ideographs
+		r >= 0xA000 && r <= 0xA4CF,   // Yi
+		r >= 0xAC00 && r <= 0xD7A3,   // Hangul Syllables
+		r >= 0xF900 && r <= 0xFAFF,   // CJK Compatibility Ideographs
+		r >= 0xFE30 && r <= 0xFE4F,   // CJK Compatibility Forms
+		r >= 0xFF00 && r <= 0xFF60,   // Fullwidth Forms
+		r >= 0xFFE0 && r <= 0xFFE6,   // Fullwidth Signs
+		r >= 0x20000 && r <= 0x3FFFD: // CJK Unified Ideographs Extension B and later
+		return 2
+	default:
+		return 1
+	}
+}
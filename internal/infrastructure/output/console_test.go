@@ -0,0 +1,118 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"testing"
+)
+
+// writeConsole writes the DataFrame with the console output and the options, and returns the rendered table.
+func writeConsole(t *testing.T, options map[string]interface{}, records ...[]string) string {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	config := interfaces.OutputConfig{Format: "console", Options: options}
+	if err := NewConsoleOutputWithWriter(&buffer).Write(context.Background(), loadFrame(records...), config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	return buffer.String()
+}
+
+func TestConsoleOutputAlignment(t *testing.T) {
+	got := writeConsole(t, nil,
+		[]string{"name", "amount"},
+		[]string{"Al", "5"},
+		[]string{"Bobby", "1234"},
+	)
+
+	want := "" +
+		"┌───────┬────────┐\n" +
+		"│ name  │ amount │\n" +
+		"├───────┼────────┤\n" +
+		"│ Al    │      5 │\n" +
+		"│ Bobby │   1234 │\n" +
+		"└───────┴────────┘\n"
+	if got != want {
+		t.Errorf("Write() rendered\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConsoleOutputBorders(t *testing.T) {
+	records := [][]string{{"name", "amount"}, {"Al", "5"}}
+
+	tests := []struct {
+		border string
+		want   string
+	}{
+		{
+			border: "ascii",
+			want:   "+------+--------+\n| name | amount |\n+------+--------+\n| Al   |      5 |\n+------+--------+\n",
+		},
+		{
+			border: "none",
+			want:   "name  amount\nAl         5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.border, func(t *testing.T) {
+			if got := writeConsole(t, map[string]interface{}{"border": tt.border}, records...); got != tt.want {
+				t.Errorf("Write() rendered %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleOutputTruncation(t *testing.T) {
+	got := writeConsole(t, map[string]interface{}{"border": "none", "maxColWidth": 5},
+		[]string{"name", "amount"},
+		[]string{"Alexandra", "123456789"},
+		[]string{"Bob", "1"},
+	)
+
+	want := "name   amou…\nAlex…  1234…\nBob        1\n"
+	if got != want {
+		t.Errorf("Write() rendered %q, want %q", got, want)
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		value    string
+		maxWidth int
+		want     string
+	}{
+		{value: "short", maxWidth: 5, want: "short"},
+		{value: "longer", maxWidth: 5, want: "long…"},
+		{value: "日本語テキスト", maxWidth: 6, want: "日本…"},
+		{value: "abc", maxWidth: 1, want: "…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateWidth(tt.value, tt.maxWidth); got != tt.want {
+			t.Errorf("truncateWidth(%q, %d) = %q, want %q", tt.value, tt.maxWidth, got, tt.want)
+		}
+		if got := displayWidth(truncateWidth(tt.value, tt.maxWidth)); got > tt.maxWidth {
+			t.Errorf("displayWidth(truncateWidth(%q, %d)) = %d, want at most %d", tt.value, tt.maxWidth, got, tt.maxWidth)
+		}
+	}
+}
+
+func TestConsoleOutputInvalidOptions(t *testing.T) {
+	valid := interfaces.OutputConfig{Format: "console", Options: map[string]interface{}{"border": "ascii", "maxColWidth": 10}}
+	if err := NewConsoleOutput().Validate(valid); err != nil {
+		t.Fatalf("Validate() error = %v, want nil for the valid options", err)
+	}
+
+	for _, options := range []map[string]interface{}{
+		{"border": "double"},
+		{"maxColWidth": 0},
+	} {
+		config := interfaces.OutputConfig{Format: "console", Options: options}
+		if err := NewConsoleOutput().Validate(config); err == nil {
+			t.Errorf("Validate() error = nil, want an error for the options %v", options)
+		}
+	}
+}
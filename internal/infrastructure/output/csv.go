@@ -0,0 +1,525 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Ensure CSVOutput implements the Output interface
+var _ interfaces.Output = (*CSVOutput)(nil)
+
+// csvLineEndings maps the lineEnding option values to the actual line endings
+var csvLineEndings = map[string]string{
+	"lf":   "\n",
+	"crlf": "\r\n",
+}
+
+// csvOptions holds the parsed options of the CSV output
+type csvOptions struct {
+	delimiter   rune
+	header      bool
+	quoteAll    bool
+	lineEnding  string
+	totals      totalsOptions
+	numbers     numberFormatOptions
+	metadata    string
+	partitionBy string
+	nullValue   string
+}
+
+// CSVOutput writes the result as a CSV file following RFC 4180.
+// Fields containing the delimiter, quotes, or newlines are quoted, and the quotes in the field are escaped by doubling.
+// Write writes and flushes a temporary file, and Close (or the next Write) moves it to the destination,
+// so the destination is complete from the moment it appears and exists only after Close.
+type CSVOutput struct {
+	mu          sync.Mutex
+	file        *os.File
+	destination string
+	metadata    *entities.ProcessingMetadata // metadata is written to the sidecar file on Close if set
+}
+
+// NewCSVOutput creates a new CSVOutput instance.
+func NewCSVOutput() *CSVOutput {
+	return &CSVOutput{}
+}
+
+// Write writes the DataFrame to the CSV file specified by the Destination of the config.
+// The rows are written through a buffer of csvStreamBufferSize bytes to a temporary file in the destination directory,
+// which is flushed before Write returns and kept open for Close to move it to the destination and write the sidecar
+// metadata file. On error, the temporary file is removed and the existing destination is left untouched.
+// A file left open by the previous Write is moved first.
+// The partitioned output writes and closes each partition file in Write.
+func (c *CSVOutput) Write(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := c.Validate(config); err != nil {
+		return err
+	}
+
+	options, err := parseCSVOptions(config)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := requireMetadata(config, options.metadata)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	if df == nil {
+		return domainerrors.NewDataProcessError("output", "no data to write", nil)
+	}
+
+	if options.partitionBy != "" {
+		err := writePartitions(ctx, df, config, options.partitionBy, "csv", func(w io.Writer, part *dataframe.DataFrame) error {
+			return writeCSV(w, csvRecords(part, -1, options), options)
+		})
+		if err == nil && options.metadata == metadataSidecar {
+			err = writeMetadataSidecar(config.Destination, metadata)
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.closeFile(); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(config.Destination)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create directory for '%s'", config.Destination), err)
+	}
+
+	file, err := createPartialFile(config.Destination)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriterSize(file, csvStreamBufferSize)
+	if err := writeCSV(writer, csvRecords(df, -1, options), options); err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write '%s'", config.Destination), err)
+	}
+
+	c.file, c.destination, c.metadata = file, config.Destination, nil
+	if options.metadata == metadataSidecar {
+		c.metadata = metadata
+	}
+
+	return nil
+}
+
+// WriteStream writes the streamed rows to the CSV file specified by the Destination of the config.
+// The rows are written to a temporary file in the destination directory through a buffer and the file is renamed
+// to the destination only after the channel is closed, so the destination is never left partially written.
+// On error or cancellation, the temporary file is removed and the existing destination is left untouched.
+// The totals footer needs all rows, so showTotals is not supported, and neither is the number formatting
+// because the streamed rows have no column types.
+// The sidecar metadata file is written after the rename when includeMetadata is sidecar.
+func (c *CSVOutput) WriteStream(ctx context.Context, rows <-chan []string, header []string, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := c.Validate(config); err != nil {
+		return err
+	}
+
+	options, err := parseCSVOptions(config)
+	if err != nil {
+		return err
+	}
+	if options.totals.show {
+		return domainerrors.NewConfigurationError("options.showTotals", "showTotals is not supported for streaming output", nil)
+	}
+	if options.numbers.enabled() {
+		return domainerrors.NewConfigurationError("options", "number formatting is not supported for streaming output because the column types are unknown", nil)
+	}
+	if options.partitionBy != "" {
+		return domainerrors.NewConfigurationError("options.partitionBy", "partitionBy is not supported for streaming output", nil)
+	}
+
+	metadata, err := requireMetadata(config, options.metadata)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	dir := filepath.Dir(config.Destination)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create directory for '%s'", config.Destination), err)
+	}
+
+	file, err := createPartialFile(config.Destination)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCSVStream(ctx, file, rows, header, options); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to close '%s'", config.Destination), err)
+	}
+
+	if err := os.Rename(file.Name(), config.Destination); err != nil {
+		_ = os.Remove(file.Name())
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to move the written file to '%s'", config.Destination), err)
+	}
+
+	if options.metadata == metadataSidecar {
+		return writeMetadataSidecar(config.Destination, metadata)
+	}
+
+	return nil
+}
+
+// Close moves the file of the last Write to the destination and writes its sidecar metadata file.
+// Calling Close without an open file does nothing.
+func (c *CSVOutput) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeFile()
+}
+
+// closeFile closes the temporary file left open by Write if any, moves it to the destination, and writes
+// the sidecar metadata file after it. The temporary file is removed on error. The caller must hold the mutex.
+func (c *CSVOutput) closeFile() error {
+	if c.file == nil {
+		return nil
+	}
+
+	file, destination, metadata := c.file, c.destination, c.metadata
+	c.file, c.destination, c.metadata = nil, "", nil
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to close '%s'", destination), err)
+	}
+	if err := os.Rename(file.Name(), destination); err != nil {
+		_ = os.Remove(file.Name())
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to move the written file to '%s'", destination), err)
+	}
+
+	if metadata != nil {
+		return writeMetadataSidecar(destination, metadata)
+	}
+
+	return nil
+}
+
+// createPartialFile creates the temporary file in the directory of the destination to be renamed to it once written.
+func createPartialFile(destination string) (*os.File, error) {
+	file, err := os.CreateTemp(filepath.Dir(destination), "."+filepath.Base(destination)+".*.partial")
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create temporary file for '%s'", destination), err)
+	}
+
+	// CreateTemp creates the file readable only by the owner, so align the permission with os.Create
+	if err := file.Chmod(0o644); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to set permission for '%s'", destination), err)
+	}
+
+	return file, nil
+}
+
+// Validate checks the config has the csv format, a destination, and valid options.
+func (c *CSVOutput) Validate(config interfaces.OutputConfig) error {
+	if !slices.Contains(c.SupportedFormats(), config.Format) {
+		return domainerrors.NewConfigurationError("format", fmt.Sprintf("unsupported format '%s' for CSV output", config.Format), nil)
+	}
+	if config.Destination == "" {
+		return domainerrors.NewConfigurationError("destination", "destination is required", nil)
+	}
+
+	_, err := parseCSVOptions(config)
+
+	return err
+}
+
+// SupportedFormats returns the output formats supported by CSVOutput.
+func (c *CSVOutput) SupportedFormats() []string {
+	return []string{"csv"}
+}
+
+// GetFormatOptions returns the available options of the CSV output and their descriptions.
+func (c *CSVOutput) GetFormatOptions(format string) map[string]string {
+	if format != "csv" {
+		return map[string]string{}
+	}
+
+	options := map[string]string{
+		"delimiter":       "Field delimiter as a single character (default: \",\")",
+		"header":          "Write the header line (default: true)",
+		"quoteAll":        "Quote all fields instead of only the fields requiring quotes (default: false)",
+		"lineEnding":      "Line ending; lf or crlf (default: lf)",
+		"includeMetadata": metadataFormatOption,
+		"partitionBy":     partitionFormatOption,
+		"nullValue":       nullValueFormatOption,
+	}
+	maps.Copy(options, totalsFormatOptions)
+	maps.Copy(options, numberFormatFormatOptions)
+
+	return options
+}
+
+// Preview renders the CSV text of the result data up to maxRows rows (0 for all).
+func (c *CSVOutput) Preview(result *entities.Processing, config interfaces.OutputConfig, maxRows int) (string, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseCSVOptions(config)
+	if err != nil {
+		return "", err
+	}
+
+	if result == nil || result.Data == nil {
+		return "", domainerrors.NewDataProcessError("preview", "no data to preview", nil)
+	}
+
+	rowCount := -1
+	if maxRows > 0 {
+		rowCount = maxRows
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, csvRecords(result.Data, rowCount, options), options); err != nil {
+		return "", domainerrors.NewDataProcessError("preview", "failed to render CSV", err)
+	}
+
+	return buf.String(), nil
+}
+
+// EstimateSize estimates the byte size of the CSV file of the result data. The sidecar metadata file is not counted.
+func (c *CSVOutput) EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseCSVOptions(config)
+	if err != nil {
+		return 0, err
+	}
+
+	return estimateSize(result, func(w io.Writer, df *dataframe.DataFrame) error {
+		return writeCSV(w, csvRecords(df, -1, options), options)
+	})
+}
+
+// PreviewStructured returns the structured preview of the result data up to maxRows rows (0 for all).
+func (c *CSVOutput) PreviewStructured(result *entities.Processing, _ interfaces.OutputConfig, maxRows int) (*interfaces.PreviewResult, error) {
+	return buildPreviewResult(result, maxRows)
+}
+
+// parseCSVOptions reads and validates the CSV options of the config.
+// Missing options are treated as their defaults.
+func parseCSVOptions(config interfaces.OutputConfig) (csvOptions, error) {
+	options := csvOptions{
+		delimiter:  ',',
+		header:     true,
+		quoteAll:   false,
+		lineEnding: csvLineEndings["lf"],
+		nullValue:  defaultNullValue,
+	}
+
+	if value, ok := config.Options["delimiter"]; ok {
+		delimiter, ok := value.(string)
+		if !ok || utf8.RuneCountInString(delimiter) != 1 {
+			return options, domainerrors.NewConfigurationError("options.delimiter", fmt.Sprintf("delimiter must be a single character, got %v", value), nil)
+		}
+
+		r, _ := utf8.DecodeRuneInString(delimiter)
+		if r == '"' || r == '\r' || r == '\n' {
+			return options, domainerrors.NewConfigurationError("options.delimiter", fmt.Sprintf("delimiter cannot be %q", r), nil)
+		}
+		options.delimiter = r
+	}
+
+	header, err := config.OptionBoolStrict("header", options.header)
+	if err != nil {
+		return options, err
+	}
+	options.header = header
+
+	quoteAll, err := config.OptionBoolStrict("quoteAll", options.quoteAll)
+	if err != nil {
+		return options, err
+	}
+	options.quoteAll = quoteAll
+
+	if value, ok := config.Options["lineEnding"]; ok {
+		name, _ := value.(string)
+		lineEnding, ok := csvLineEndings[name]
+		if !ok {
+			return options, domainerrors.NewConfigurationError("options.lineEnding", fmt.Sprintf("lineEnding must be one of [lf crlf], got %v", value), nil)
+		}
+		options.lineEnding = lineEnding
+	}
+
+	totals, err := parseTotalsOptions(config)
+	if err != nil {
+		return options, err
+	}
+	options.totals = totals
+
+	numbers, err := parseNumberFormatOptions(config)
+	if err != nil {
+		return options, err
+	}
+	options.numbers = numbers
+
+	metadata, err := parseMetadataOption(config, metadataSidecar, metadataSidecar)
+	if err != nil {
+		return options, err
+	}
+	options.metadata = metadata
+
+	partitionBy, err := parsePartitionOption(config)
+	if err != nil {
+		return options, err
+	}
+	options.partitionBy = partitionBy
+
+	// The fields with the line breaks are quoted, so any text is allowed
+	nullValue, err := parseNullValueOption(config, options.nullValue, false)
+	if err != nil {
+		return options, err
+	}
+	options.nullValue = nullValue
+
+	return options, nil
+}
+
+// csvRecords returns the header and the first rowCount rows (all rows if negative) of the DataFrame as the records
+// with the numbers formatted by the options. The totals row computed over all rows follows if enabled.
+func csvRecords(df *dataframe.DataFrame, rowCount int, options csvOptions) [][]string {
+	records := df.Records()
+	if rowCount >= 0 && len(records) > rowCount+1 {
+		records = records[:rowCount+1]
+	}
+
+	formatted := options.numbers.formattedColumns(df)
+	if formatted != nil {
+		for i := 1; i < len(records); i++ {
+			for j := range records[i] {
+				if formatted[j] {
+					records[i][j] = options.numbers.formatElement(df.Elem(i-1, j), true)
+				}
+			}
+		}
+	}
+	replaceNullRecords(df, records, options.nullValue)
+
+	// The footer is computed over all rows and always shown
+	if footer := totalsRow(df, options.totals); footer != nil {
+		options.numbers.formatRow(footer, df.Types(), formatted)
+		records = append(records, footer)
+	}
+
+	return records
+}
+
+// writeCSV writes the records (the first record is the header) to the writer with the options.
+func writeCSV(w io.Writer, records [][]string, options csvOptions) error {
+	if !options.header && len(records) > 0 {
+		records = records[1:]
+	}
+
+	delimiter := string(options.delimiter)
+	for _, record := range records {
+		fields := make([]string, len(record))
+		for i, field := range record {
+			fields[i] = quoteCSVField(field, options)
+		}
+
+		if _, err := io.WriteString(w, strings.Join(fields, delimiter)+options.lineEnding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvStreamBufferSize is the buffer size of the CSV writers; the buffer is flushed to the file whenever it fills up
+const csvStreamBufferSize = 64 * 1024
+
+// writeCSVStream writes the header and the rows received from the channel to the writer until the channel is closed.
+func writeCSVStream(ctx context.Context, w io.Writer, rows <-chan []string, header []string, options csvOptions) error {
+	buffered := bufio.NewWriterSize(w, csvStreamBufferSize)
+	delimiter := string(options.delimiter)
+	writeRecord := func(record []string) error {
+		fields := make([]string, len(record))
+		for i, field := range record {
+			fields[i] = quoteCSVField(field, options)
+		}
+		_, err := buffered.WriteString(strings.Join(fields, delimiter) + options.lineEnding)
+
+		return err
+	}
+
+	if options.header {
+		if err := writeRecord(header); err != nil {
+			return domainerrors.NewDataProcessError("output", "failed to write the header", err)
+		}
+	}
+
+	rowIndex := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return domainerrors.NewDataProcessError("output", fmt.Sprintf("output is canceled after %d rows", rowIndex), ctx.Err())
+		case row, ok := <-rows:
+			if !ok {
+				if err := buffered.Flush(); err != nil {
+					return domainerrors.NewDataProcessError("output", "failed to flush the rows", err)
+				}
+				return nil
+			}
+
+			if len(row) != len(header) {
+				return domainerrors.NewDataProcessError("output", fmt.Sprintf("row %d has %d fields, expected %d", rowIndex, len(row), len(header)), nil)
+			}
+			if err := writeRecord(row); err != nil {
+				return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write row %d", rowIndex), err)
+			}
+			rowIndex++
+		}
+	}
+}
+
+// quoteCSVField quotes the field if required (or quoteAll is set) and escapes the quotes by doubling them.
+func quoteCSVField(field string, options csvOptions) string {
+	needsQuote := options.quoteAll ||
+		strings.ContainsRune(field, options.delimiter) ||
+		strings.ContainsAny(field, "\"\r\n")
+	if !needsQuote {
+		return field
+	}
+
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
@@ -0,0 +1,345 @@
+package output
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// loadFrame loads the records (the first one is the header) into a DataFrame detecting the column types as CSV.
+func loadFrame(records ...[]string) *dataframe.DataFrame {
+	df := dataframe.LoadRecords(records)
+
+	return &df
+}
+
+// readFile returns the content of the file, failing the test if it cannot be read.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+
+	return string(content)
+}
+
+// writeOutputFile writes the DataFrame with the output in the format and the options to a temporary file,
+// closes the output, and returns the content of the file.
+func writeOutputFile(t *testing.T, output interfaces.Output, df *dataframe.DataFrame, format string, options map[string]interface{}) string {
+	t.Helper()
+
+	destination := filepath.Join(t.TempDir(), "out."+format)
+	config := interfaces.OutputConfig{Format: format, Destination: destination, Options: options}
+	if err := output.Write(context.Background(), df, config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	return readFile(t, destination)
+}
+
+// writeCSVFile writes the DataFrame with the CSV output and the options, and returns the content of the file.
+func writeCSVFile(t *testing.T, df *dataframe.DataFrame, options map[string]interface{}) string {
+	t.Helper()
+
+	return writeOutputFile(t, NewCSVOutput(), df, "csv", options)
+}
+
+func TestCSVOutputDelimiterAndQuoting(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "note", "amount"},
+		[]string{"Alice", "a;b", "10"},
+		[]string{"Bob", `say "hi"`, "20"},
+	)
+
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    string
+	}{
+		{
+			name:    "default",
+			options: nil,
+			want:    "name,note,amount\nAlice,a;b,10\nBob,\"say \"\"hi\"\"\",20\n",
+		},
+		{
+			name:    "semicolon",
+			options: map[string]interface{}{"delimiter": ";"},
+			want:    "name;note;amount\nAlice;\"a;b\";10\nBob;\"say \"\"hi\"\"\";20\n",
+		},
+		{
+			name:    "quote all",
+			options: map[string]interface{}{"quoteAll": true},
+			want:    "\"name\",\"note\",\"amount\"\n\"Alice\",\"a;b\",\"10\"\n\"Bob\",\"say \"\"hi\"\"\",\"20\"\n",
+		},
+		{
+			name:    "crlf without header",
+			options: map[string]interface{}{"header": false, "lineEnding": "crlf"},
+			want:    "Alice,a;b,10\r\nBob,\"say \"\"hi\"\"\",20\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writeCSVFile(t, df, tt.options); got != tt.want {
+				t.Errorf("written CSV = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVOutputRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"name", "note"},
+		{"Smith, Alice", `she said "hi", then left`},
+		{"Bob", "line one\nline two"},
+		{"Carol", `"quoted", "twice"`},
+		{"Dave", "tab\tand ;semicolon"},
+	}
+
+	for _, delimiter := range []string{",", ";", "\t"} {
+		t.Run(fmt.Sprintf("delimiter %q", delimiter), func(t *testing.T) {
+			written := writeCSVFile(t, loadFrame(records...), map[string]interface{}{"delimiter": delimiter})
+
+			reader := csv.NewReader(strings.NewReader(written))
+			reader.Comma = []rune(delimiter)[0]
+			got, err := reader.ReadAll()
+			if err != nil {
+				t.Fatalf("read back %q: %v", written, err)
+			}
+			if !reflect.DeepEqual(got, records) {
+				t.Errorf("read back = %q, want %q", got, records)
+			}
+		})
+	}
+}
+
+func TestCSVOutputInvalidDelimiter(t *testing.T) {
+	for _, delimiter := range []string{"", ";;", `"`, "\n"} {
+		config := interfaces.OutputConfig{Format: "csv", Destination: "out.csv", Options: map[string]interface{}{"delimiter": delimiter}}
+		if err := NewCSVOutput().Validate(config); err == nil {
+			t.Errorf("Validate() error = nil, want an error for the delimiter %q", delimiter)
+		}
+	}
+}
+
+func TestCSVOutputWriteStream(t *testing.T) {
+	const rowCount = 5000
+	destination := filepath.Join(t.TempDir(), "stream.csv")
+
+	rows := make(chan []string)
+	go func() {
+		defer close(rows)
+		for i := 0; i < rowCount; i++ {
+			rows <- []string{strconv.Itoa(i), fmt.Sprintf("name %d", i)}
+		}
+	}()
+
+	config := interfaces.OutputConfig{Format: "csv", Destination: destination}
+	if err := NewCSVOutput().WriteStream(context.Background(), rows, []string{"id", "name"}, config); err != nil {
+		t.Fatalf("WriteStream() error = %v", err)
+	}
+
+	var want strings.Builder
+	want.WriteString("id,name\n")
+	for i := 0; i < rowCount; i++ {
+		fmt.Fprintf(&want, "%d,name %d\n", i, i)
+	}
+	if got := readFile(t, destination); got != want.String() {
+		t.Errorf("streamed CSV has %d bytes, want %d bytes", len(got), want.Len())
+	}
+	assertNoPartialFiles(t, filepath.Dir(destination), "stream.csv")
+}
+
+func TestCSVOutputWriteStreamCanceled(t *testing.T) {
+	dir := t.TempDir()
+	destination := filepath.Join(dir, "stream.csv")
+	const existing = "id,name\n0,previous\n"
+	if err := os.WriteFile(destination, []byte(existing), 0o644); err != nil {
+		t.Fatalf("write %s: %v", destination, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := make(chan []string)
+	go func() {
+		// The producer stops on the cancellation without closing the channel, as a canceled producer would
+		for i := 0; ; i++ {
+			if i == 1000 {
+				cancel()
+			}
+			select {
+			case rows <- []string{strconv.Itoa(i), "new"}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	config := interfaces.OutputConfig{Format: "csv", Destination: destination}
+	err := NewCSVOutput().WriteStream(ctx, rows, []string{"id", "name"}, config)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("WriteStream() error = %v, want context.Canceled", err)
+	}
+
+	if got := readFile(t, destination); got != existing {
+		t.Errorf("destination = %q, want the existing content %q", got, existing)
+	}
+	assertNoPartialFiles(t, dir, "stream.csv")
+}
+
+func TestCSVOutputWriteStreamRowWidth(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "stream.csv")
+	rows := make(chan []string, 2)
+	rows <- []string{"1", "Alice"}
+	rows <- []string{"2"}
+	close(rows)
+
+	config := interfaces.OutputConfig{Format: "csv", Destination: destination}
+	if err := NewCSVOutput().WriteStream(context.Background(), rows, []string{"id", "name"}, config); err == nil {
+		t.Fatal("WriteStream() error = nil, want the error of the short row")
+	}
+	if _, err := os.Stat(destination); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat(destination) error = %v, want the destination not created", err)
+	}
+	assertNoPartialFiles(t, filepath.Dir(destination))
+}
+
+// assertNoPartialFiles fails the test unless the directory contains only the wanted files,
+// i.e. no temporary files are left behind.
+func assertNoPartialFiles(t *testing.T, dir string, want ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read %s: %v", dir, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("files in %s = %v, want %v", dir, names, want)
+	}
+}
+
+// fileSize returns the size of the file, failing the test if it cannot be read.
+func fileSize(t *testing.T, path string) int {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+
+	return int(info.Size())
+}
+
+func TestCSVOutputMovesOnClose(t *testing.T) {
+	tests := []struct {
+		name string
+		rows int
+	}{
+		{name: "smaller than the buffer", rows: 10},
+		{name: "larger than the buffer", rows: 20_000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := [][]string{{"id", "name"}}
+			for i := range tt.rows {
+				records = append(records, []string{strconv.Itoa(i), fmt.Sprintf("name-%d", i)})
+			}
+			var want strings.Builder
+			for _, record := range records {
+				want.WriteString(strings.Join(record, ",") + "\n")
+			}
+
+			destination := filepath.Join(t.TempDir(), "out.csv")
+			output := NewCSVOutput()
+			if err := output.Write(context.Background(), loadFrame(records...), interfaces.OutputConfig{Format: "csv", Destination: destination}); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			// Write flushes the whole data to the temporary file, and the destination appears only on Close
+			if _, err := os.Stat(destination); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Stat(destination) error = %v before Close, want the destination not created", err)
+			}
+			partials, err := filepath.Glob(filepath.Join(filepath.Dir(destination), ".out.csv.*.partial"))
+			if err != nil || len(partials) != 1 {
+				t.Fatalf("partial files = %v (err = %v), want one", partials, err)
+			}
+			if size := fileSize(t, partials[0]); size != want.Len() {
+				t.Errorf("size before Close = %d, want the flushed %d", size, want.Len())
+			}
+
+			if err := output.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if got := readFile(t, destination); got != want.String() {
+				t.Errorf("content after Close has %d bytes, want %d", len(got), want.Len())
+			}
+			assertNoPartialFiles(t, filepath.Dir(destination), "out.csv")
+
+			// Closing again has nothing to flush
+			if err := output.Close(); err != nil {
+				t.Errorf("second Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCSVOutputWriteClosesPreviousFile(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.csv"), filepath.Join(dir, "second.csv")
+	output := NewCSVOutput()
+
+	if err := output.Write(context.Background(), loadFrame([]string{"id"}, []string{"1"}), interfaces.OutputConfig{Format: "csv", Destination: first}); err != nil {
+		t.Fatalf("Write(first) error = %v", err)
+	}
+	if err := output.Write(context.Background(), loadFrame([]string{"id"}, []string{"2"}), interfaces.OutputConfig{Format: "csv", Destination: second}); err != nil {
+		t.Fatalf("Write(second) error = %v", err)
+	}
+
+	// The second Write moves the file of the first one, and Close moves the second
+	if got, want := readFile(t, first), "id\n1\n"; got != want {
+		t.Errorf("first file = %q, want %q", got, want)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got, want := readFile(t, second), "id\n2\n"; got != want {
+		t.Errorf("second file = %q, want %q", got, want)
+	}
+}
+
+func TestCSVOutputFailedCloseRemovesPartialFile(t *testing.T) {
+	// The destination taken by a directory cannot be replaced by the written file
+	dir := t.TempDir()
+	destination := filepath.Join(dir, "out.csv")
+	if err := os.Mkdir(destination, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", destination, err)
+	}
+
+	output := NewCSVOutput()
+	if err := output.Write(context.Background(), loadFrame([]string{"id"}, []string{"1"}), interfaces.OutputConfig{Format: "csv", Destination: destination}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err == nil {
+		t.Fatal("Close() error = nil, want the error of moving the file")
+	}
+	assertNoPartialFiles(t, dir, "out.csv")
+}
@@ -0,0 +1,17 @@
+package output
+
+import "io"
+
+// flusher is implemented by the buffered writers such as bufio.Writer
+type flusher interface {
+	Flush() error
+}
+
+// flushWriter flushes the writer if it buffers the written data. The other writers are left untouched.
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
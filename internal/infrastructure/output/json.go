@@ -0,0 +1,279 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// jsonOptions holds the parsed options of the JSON output
+type jsonOptions struct {
+	indent      string
+	metadata    string
+	partitionBy string
+}
+
+// jsonFormatOptions describes the options of the JSON output
+var jsonFormatOptions = map[string]string{
+	"indent":          "Indent string for each nesting level, empty for the compact output (default: 4 spaces)",
+	"includeMetadata": metadataFormatOption,
+	"partitionBy":     partitionFormatOption,
+}
+
+// parseJSONOptions reads and validates the JSON options of the config.
+// Missing options are treated as their defaults.
+func parseJSONOptions(config interfaces.OutputConfig) (jsonOptions, error) {
+	options := jsonOptions{
+		indent: "    ",
+	}
+
+	if value, ok := config.Options["indent"]; ok {
+		indent, ok := value.(string)
+		if !ok || strings.Trim(indent, " \t") != "" {
+			return options, domainerrors.NewConfigurationError("options.indent", fmt.Sprintf("indent must be a string of spaces or tabs, got %v", value), nil)
+		}
+		options.indent = indent
+	}
+
+	metadata, err := parseMetadataOption(config, metadataEmbed, metadataEmbed, metadataSidecar)
+	if err != nil {
+		return options, err
+	}
+	options.metadata = metadata
+
+	partitionBy, err := parsePartitionOption(config)
+	if err != nil {
+		return options, err
+	}
+	options.partitionBy = partitionBy
+
+	if err := rejectNullValueOption(config, "json"); err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+// writeJSON writes the rows of the DataFrame as a JSON array of objects keeping the column order.
+// Values keep their types, and null (or non-finite) values are written as null.
+func writeJSON(w io.Writer, df *dataframe.DataFrame, options jsonOptions) error {
+	names := df.Names()
+	keys := make([]string, len(names))
+	for j, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		keys[j] = string(key)
+	}
+
+	newline, separator := "", ":"
+	if options.indent != "" {
+		newline, separator = "\n", ": "
+	}
+	rowIndent := newline + options.indent
+	fieldIndent := rowIndent + options.indent
+
+	var builder strings.Builder
+	builder.WriteString("[")
+	for i := 0; i < df.Nrow(); i++ {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(rowIndent + "{")
+		for j := range names {
+			if j > 0 {
+				builder.WriteString(",")
+			}
+
+			encoded, err := json.Marshal(jsonValue(df.Elem(i, j)))
+			if err != nil {
+				return err
+			}
+			builder.WriteString(fieldIndent + keys[j] + separator + string(encoded))
+		}
+		builder.WriteString(rowIndent + "}")
+	}
+	if df.Nrow() > 0 {
+		builder.WriteString(newline)
+	}
+	builder.WriteString("]" + newline)
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// jsonValue returns the value of the element to encode as JSON: nil for the null and the non-finite values
+// (NaN and Inf), which JSON has no literals for, otherwise the value of its type.
+func jsonValue(element series.Element) interface{} {
+	if element.IsNA() {
+		return nil
+	}
+	if f, ok := element.Val().(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return nil
+	}
+
+	return element.Val()
+}
+
+// Ensure JSONOutput implements the Output interface
+var _ interfaces.Output = (*JSONOutput)(nil)
+
+// JSONOutput writes the result as a JSON array of objects keeping the column order and the value types.
+type JSONOutput struct{}
+
+// NewJSONOutput creates a new JSONOutput instance.
+func NewJSONOutput() *JSONOutput {
+	return &JSONOutput{}
+}
+
+// Write writes the DataFrame to the JSON file specified by the Destination of the config.
+func (j *JSONOutput) Write(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := j.Validate(config); err != nil {
+		return err
+	}
+
+	options, err := parseJSONOptions(config)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := requireMetadata(config, options.metadata)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	if df == nil {
+		return domainerrors.NewDataProcessError("output", "no data to write", nil)
+	}
+
+	if options.partitionBy != "" {
+		err := writePartitions(ctx, df, config, options.partitionBy, "json", func(w io.Writer, part *dataframe.DataFrame) error {
+			if options.metadata == metadataEmbed {
+				return writeJSONWithMetadata(w, part, metadata, options)
+			}
+			return writeJSON(w, part, options)
+		})
+		if err == nil && options.metadata == metadataSidecar {
+			err = writeMetadataSidecar(config.Destination, metadata)
+		}
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.Destination), 0o755); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create directory for '%s'", config.Destination), err)
+	}
+
+	file, err := os.Create(config.Destination)
+	if err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create '%s'", config.Destination), err)
+	}
+
+	if options.metadata == metadataEmbed {
+		err = writeJSONWithMetadata(file, df, metadata, options)
+	} else {
+		err = writeJSON(file, df, options)
+	}
+	if err != nil {
+		_ = file.Close()
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write '%s'", config.Destination), err)
+	}
+
+	if err := file.Close(); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to close '%s'", config.Destination), err)
+	}
+
+	if options.metadata == metadataSidecar {
+		return writeMetadataSidecar(config.Destination, metadata)
+	}
+
+	return nil
+}
+
+// WriteStream is not supported by JSONOutput because the rows are streamed as strings without the value types.
+func (j *JSONOutput) WriteStream(_ context.Context, _ <-chan []string, _ []string, _ interfaces.OutputConfig) error {
+	return domainerrors.NewDataProcessError("output", "streaming is not supported for json output", nil)
+}
+
+// Close does nothing because each Write opens and closes its own file.
+func (j *JSONOutput) Close() error {
+	return nil
+}
+
+// Validate checks the config has the json format, a destination, and valid options.
+func (j *JSONOutput) Validate(config interfaces.OutputConfig) error {
+	if !slices.Contains(j.SupportedFormats(), config.Format) {
+		return domainerrors.NewConfigurationError("format", fmt.Sprintf("unsupported format '%s' for JSON output", config.Format), nil)
+	}
+	if config.Destination == "" {
+		return domainerrors.NewConfigurationError("destination", "destination is required", nil)
+	}
+
+	_, err := parseJSONOptions(config)
+
+	return err
+}
+
+// SupportedFormats returns the output formats supported by JSONOutput.
+func (j *JSONOutput) SupportedFormats() []string {
+	return []string{"json"}
+}
+
+// GetFormatOptions returns the available options of the JSON output and their descriptions.
+func (j *JSONOutput) GetFormatOptions(format string) map[string]string {
+	if format != "json" {
+		return map[string]string{}
+	}
+
+	return maps.Clone(jsonFormatOptions)
+}
+
+// Preview renders the JSON text of the result data up to maxRows rows (0 for all).
+// The metadata is embedded when includeMetadata is embed; the sidecar file is not previewed,
+// and the partitions are previewed as a whole.
+func (j *JSONOutput) Preview(result *entities.Processing, config interfaces.OutputConfig, maxRows int) (string, error) {
+	options := maps.Clone(config.Options)
+	if options["includeMetadata"] == metadataSidecar {
+		delete(options, "includeMetadata")
+	}
+	delete(options, "partitionBy")
+
+	// The destination is not required for the preview
+	return NewWriterOutput(io.Discard).Preview(result, interfaces.OutputConfig{Format: "json", Options: options, Metadata: config.Metadata}, maxRows)
+}
+
+// EstimateSize estimates the byte size of the JSON file of the result data including the embedded metadata.
+// The sidecar metadata file is not counted, and the partitions are estimated as a whole.
+func (j *JSONOutput) EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error) {
+	options := maps.Clone(config.Options)
+	if options["includeMetadata"] == metadataSidecar {
+		delete(options, "includeMetadata")
+	}
+	delete(options, "partitionBy")
+
+	// The destination is not required for the estimate
+	return NewWriterOutput(io.Discard).EstimateSize(result, interfaces.OutputConfig{Format: "json", Options: options, Metadata: config.Metadata})
+}
+
+// PreviewStructured returns the structured preview of the result data up to maxRows rows (0 for all).
+func (j *JSONOutput) PreviewStructured(result *entities.Processing, _ interfaces.OutputConfig, maxRows int) (*interfaces.PreviewResult, error) {
+	return buildPreviewResult(result, maxRows)
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nonFiniteFrame returns the DataFrame of the float values NaN, Inf, and -Inf, which JSON has no literals for.
+func nonFiniteFrame() *dataframe.DataFrame {
+	df := dataframe.New(
+		series.New([]string{"a", "b", "c", "d"}, series.String, "name"),
+		series.New([]float64{math.NaN(), math.Inf(1), math.Inf(-1), 1.5}, series.Float, "ratio"),
+	)
+
+	return &df
+}
+
+func TestJSONOutputNonFiniteValues(t *testing.T) {
+	for _, indent := range []string{"", "  "} {
+		got := writeOutputFile(t, NewJSONOutput(), nonFiniteFrame(), "json", map[string]interface{}{"indent": indent})
+		if !json.Valid([]byte(got)) {
+			t.Fatalf("Write(indent %q) = %s, want valid JSON", indent, got)
+		}
+		for _, literal := range []string{"NaN", "Inf"} {
+			if strings.Contains(got, literal) {
+				t.Errorf("Write(indent %q) = %s, want no %s literal", indent, got, literal)
+			}
+		}
+
+		var rows []map[string]interface{}
+		if err := json.Unmarshal([]byte(got), &rows); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		want := []interface{}{nil, nil, nil, 1.5}
+		for i, row := range rows {
+			if row["ratio"] != want[i] {
+				t.Errorf("Write(indent %q) ratio of row %d = %v, want %v", indent, i+1, row["ratio"], want[i])
+			}
+		}
+	}
+}
+
+func TestPreviewStructuredNonFiniteValues(t *testing.T) {
+	preview, err := NewJSONOutput().PreviewStructured(entities.NewProcessing(nonFiniteFrame(), "preview"), interfaces.OutputConfig{Format: "json"}, 0)
+	if err != nil {
+		t.Fatalf("PreviewStructured() error = %v", err)
+	}
+
+	want := [][]interface{}{{"a", nil}, {"b", nil}, {"c", nil}, {"d", 1.5}}
+	if !reflect.DeepEqual(preview.Rows, want) {
+		t.Errorf("Rows = %#v, want %#v", preview.Rows, want)
+	}
+	// The front-ends encode the rows as JSON, which fails on the non-finite values
+	if _, err := json.Marshal(preview.Rows); err != nil {
+		t.Errorf("json.Marshal(Rows) error = %v", err)
+	}
+}
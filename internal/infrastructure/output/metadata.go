@@ -0,0 +1,122 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"os"
+	"slices"
+)
+
+// Modes of the includeMetadata option
+const (
+	metadataNone    = "none"    // Don't write the metadata
+	metadataEmbed   = "embed"   // Embed the metadata in the output as {"metadata": {...}, "data": [...]}
+	metadataSidecar = "sidecar" // Write the metadata to "<destination>.meta.json" next to the output
+)
+
+// metadataSidecarSuffix is appended to the destination to name the sidecar metadata file
+const metadataSidecarSuffix = ".meta.json"
+
+// metadataFormatOption describes the includeMetadata option shared by the outputs writing the metadata
+const metadataFormatOption = "Write the processing metadata; none, embed (json only), sidecar (<destination>.meta.json), " +
+	"or a bool where true selects the default mode of the format (default: none)"
+
+// parseMetadataOption reads and validates the includeMetadata option of the config.
+// true selects defaultMode (rejected if empty), false is the same as "none", and the modes not in supported are rejected.
+// Missing option is treated as "none".
+func parseMetadataOption(config interfaces.OutputConfig, defaultMode string, supported ...string) (string, error) {
+	value, ok := config.Options["includeMetadata"]
+	if !ok {
+		return metadataNone, nil
+	}
+
+	mode := ""
+	switch v := value.(type) {
+	case bool:
+		mode = metadataNone
+		if v {
+			mode = defaultMode
+		}
+	case string:
+		mode = v
+	}
+
+	if mode == metadataNone {
+		return mode, nil
+	}
+	if !slices.Contains(supported, mode) {
+		if mode == metadataEmbed && slices.Contains(supported, metadataSidecar) {
+			return "", domainerrors.NewConfigurationError("options.includeMetadata", fmt.Sprintf("metadata cannot be embedded in %s, use sidecar instead", config.Format), nil)
+		}
+		return "", domainerrors.NewConfigurationError("options.includeMetadata", fmt.Sprintf("includeMetadata must be one of %v for %s, got %v", append([]string{metadataNone}, supported...), config.Format, value), nil)
+	}
+
+	return mode, nil
+}
+
+// requireMetadata returns the metadata of the config, or a ConfigurationError if the metadata is required but missing.
+func requireMetadata(config interfaces.OutputConfig, mode string) (*entities.ProcessingMetadata, error) {
+	if mode == metadataNone {
+		return nil, nil
+	}
+	if config.Metadata == nil {
+		return nil, domainerrors.NewConfigurationError("metadata", fmt.Sprintf("metadata is required to include it (includeMetadata: %s)", mode), nil)
+	}
+
+	return config.Metadata, nil
+}
+
+// writeMetadataSidecar writes the metadata as an indented JSON to "<destination>.meta.json".
+func writeMetadataSidecar(destination string, metadata *entities.ProcessingMetadata) error {
+	path := destination + metadataSidecarSuffix
+
+	data, err := json.MarshalIndent(metadata, "", "    ")
+	if err != nil {
+		return domainerrors.NewDataProcessError("output", "failed to marshal the metadata", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write '%s'", path), err)
+	}
+
+	return nil
+}
+
+// writeJSONWithMetadata writes the metadata and the rows of the DataFrame as {"metadata": {...}, "data": [...]}
+// with the same indentation as writeJSON.
+func writeJSONWithMetadata(w io.Writer, df *dataframe.DataFrame, metadata *entities.ProcessingMetadata, options jsonOptions) error {
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	var compact bytes.Buffer
+	compact.WriteString(`{"metadata":`)
+	compact.Write(encodedMetadata)
+	compact.WriteString(`,"data":`)
+	if err := writeJSON(&compact, df, jsonOptions{}); err != nil {
+		return err
+	}
+	compact.WriteString("}")
+
+	if options.indent == "" {
+		_, err := compact.WriteTo(w)
+		return err
+	}
+
+	// json.Indent keeps the key order, so the column order of the rows is preserved
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", options.indent); err != nil {
+		return err
+	}
+	indented.WriteString("\n")
+	_, err = indented.WriteTo(w)
+
+	return err
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testMetadata returns the metadata of a small run to include in the output.
+func testMetadata() *entities.ProcessingMetadata {
+	return &entities.ProcessingMetadata{
+		RunID:             "run-1",
+		SourceTotalRows:   3,
+		FilteredTotalRows: 2,
+		AppliedFilters:    []string{"amount gt 5"},
+		ConfigName:        "metadata",
+		DataSource:        "memory",
+	}
+}
+
+func TestJSONOutputEmbedsMetadata(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount"},
+		[]string{"Alice", "10"},
+		[]string{"Bob", "20"},
+	)
+
+	for _, value := range []interface{}{"embed", true} {
+		destination := filepath.Join(t.TempDir(), "out.json")
+		config := interfaces.OutputConfig{
+			Format:      "json",
+			Destination: destination,
+			Options:     map[string]interface{}{"includeMetadata": value},
+			Metadata:    testMetadata(),
+		}
+		output := NewJSONOutput()
+		if err := output.Write(context.Background(), df, config); err != nil {
+			t.Fatalf("Write(includeMetadata: %v) error = %v", value, err)
+		}
+		if err := output.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		var got struct {
+			Metadata entities.ProcessingMetadata `json:"metadata"`
+			Data     []map[string]interface{}    `json:"data"`
+		}
+		decoder := json.NewDecoder(strings.NewReader(readFile(t, destination)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&got); err != nil {
+			t.Fatalf("includeMetadata: %v: decode error = %v", value, err)
+		}
+
+		if !reflect.DeepEqual(got.Metadata, *testMetadata()) {
+			t.Errorf("includeMetadata: %v: metadata = %+v, want %+v", value, got.Metadata, *testMetadata())
+		}
+		wantData := []map[string]interface{}{
+			{"name": "Alice", "amount": float64(10)},
+			{"name": "Bob", "amount": float64(20)},
+		}
+		if !reflect.DeepEqual(got.Data, wantData) {
+			t.Errorf("includeMetadata: %v: data = %v, want %v", value, got.Data, wantData)
+		}
+		if _, err := os.Stat(destination + metadataSidecarSuffix); !os.IsNotExist(err) {
+			t.Errorf("includeMetadata: %v: sidecar exists (err = %v), want no sidecar for embed", value, err)
+		}
+	}
+}
+
+func TestCSVOutputWritesMetadataSidecar(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount"},
+		[]string{"Alice", "10"},
+	)
+
+	for _, value := range []interface{}{"sidecar", true} {
+		destination := filepath.Join(t.TempDir(), "out.csv")
+		config := interfaces.OutputConfig{
+			Format:      "csv",
+			Destination: destination,
+			Options:     map[string]interface{}{"includeMetadata": value},
+			Metadata:    testMetadata(),
+		}
+		output := NewCSVOutput()
+		if err := output.Write(context.Background(), df, config); err != nil {
+			t.Fatalf("Write(includeMetadata: %v) error = %v", value, err)
+		}
+		// The sidecar describes the complete file, so it follows the file on Close
+		if _, err := os.Stat(destination + metadataSidecarSuffix); !os.IsNotExist(err) {
+			t.Errorf("includeMetadata: %v: sidecar exists before Close (err = %v)", value, err)
+		}
+		if err := output.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if got, want := readFile(t, destination), "name,amount\nAlice,10\n"; got != want {
+			t.Errorf("includeMetadata: %v: csv = %q, want %q", value, got, want)
+		}
+
+		var got entities.ProcessingMetadata
+		if err := json.Unmarshal([]byte(readFile(t, destination+".meta.json")), &got); err != nil {
+			t.Fatalf("includeMetadata: %v: decode sidecar error = %v", value, err)
+		}
+		if !reflect.DeepEqual(got, *testMetadata()) {
+			t.Errorf("includeMetadata: %v: sidecar = %+v, want %+v", value, got, *testMetadata())
+		}
+	}
+}
+
+func TestCSVOutputWithoutMetadataOption(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "out.csv")
+	config := interfaces.OutputConfig{Format: "csv", Destination: destination, Metadata: testMetadata()}
+	output := NewCSVOutput()
+	if err := output.Write(context.Background(), loadFrame([]string{"name"}, []string{"Alice"}), config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err := os.Stat(destination + metadataSidecarSuffix); !os.IsNotExist(err) {
+		t.Errorf("sidecar exists (err = %v), want no sidecar without includeMetadata", err)
+	}
+}
+
+func TestMetadataOptionInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   interfaces.Output
+		format   string
+		value    interface{}
+		metadata *entities.ProcessingMetadata
+		field    string
+		contains string
+	}{
+		{name: "embed in csv", output: NewCSVOutput(), format: "csv", value: "embed", metadata: testMetadata(), field: "options.includeMetadata", contains: "use sidecar instead"},
+		{name: "unknown mode", output: NewJSONOutput(), format: "json", value: "inline", metadata: testMetadata(), field: "options.includeMetadata", contains: "must be one of"},
+		{name: "missing metadata", output: NewJSONOutput(), format: "json", value: "embed", metadata: nil, field: "metadata", contains: "metadata is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destination := filepath.Join(t.TempDir(), "out."+tt.format)
+			config := interfaces.OutputConfig{
+				Format:      tt.format,
+				Destination: destination,
+				Options:     map[string]interface{}{"includeMetadata": tt.value},
+				Metadata:    tt.metadata,
+			}
+
+			err := tt.output.Write(context.Background(), loadFrame([]string{"name"}, []string{"Alice"}), config)
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("Write() error = %v, want ConfigurationError", err)
+			}
+			if configErr.Field != tt.field || !strings.Contains(configErr.Message, tt.contains) {
+				t.Errorf("Write() error = %v, want field %q containing %q", err, tt.field, tt.contains)
+			}
+			if _, err := os.Stat(destination); !os.IsNotExist(err) {
+				t.Errorf("destination exists (err = %v), want nothing written", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package output
+
+import (
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"strings"
+)
+
+// defaultNullValue is the text of the null values by default, the same as gota renders them
+const defaultNullValue = "NaN"
+
+// nullValueFormatOption describes the nullValue option shared by the text outputs
+const nullValueFormatOption = "Text of the null values, e.g. \"\", \"NULL\", or \"\\N\" (default: NaN)"
+
+// parseNullValueOption reads and validates the nullValue option of the config. Missing option is treated as def.
+// The values with a line break are rejected when singleLine is set because they break the rows of the table.
+func parseNullValueOption(config interfaces.OutputConfig, def string, singleLine bool) (string, error) {
+	nullValue, err := config.OptionStringStrict("nullValue", def)
+	if err != nil {
+		return def, err
+	}
+	if singleLine && strings.ContainsAny(nullValue, "\r\n") {
+		return def, domainerrors.NewConfigurationError("options.nullValue", "nullValue cannot contain line breaks", nil)
+	}
+
+	return nullValue, nil
+}
+
+// rejectNullValueOption rejects the nullValue option for the outputs writing the null values as they are, e.g. JSON null.
+func rejectNullValueOption(config interfaces.OutputConfig, format string) error {
+	if _, ok := config.Options["nullValue"]; ok {
+		return domainerrors.NewConfigurationError("options.nullValue", "nullValue is not supported for "+format+" output, the null values are written as null", nil)
+	}
+
+	return nil
+}
+
+// replaceNullRecords replaces the cells of the null values in the records of the DataFrame (the first record is the header) with nullValue.
+func replaceNullRecords(df *dataframe.DataFrame, records [][]string, nullValue string) {
+	for i := 1; i < len(records) && i <= df.Nrow(); i++ {
+		for j := range records[i] {
+			if df.Elem(i-1, j).IsNA() {
+				records[i][j] = nullValue
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strings"
+	"testing"
+)
+
+// nullFrame returns the DataFrame of a null string and a null integer.
+func nullFrame() *dataframe.DataFrame {
+	df := dataframe.New(
+		series.New([]interface{}{"Alice", nil, "Carol"}, series.String, "name"),
+		series.New([]interface{}{10, 20, nil}, series.Int, "amount"),
+	)
+
+	return &df
+}
+
+func TestCSVOutputNullValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    string
+	}{
+		{name: "default", options: nil, want: "name,amount\nAlice,10\nNaN,20\nCarol,NaN\n"},
+		{name: "empty string", options: map[string]interface{}{"nullValue": ""}, want: "name,amount\nAlice,10\n,20\nCarol,\n"},
+		{name: "NULL", options: map[string]interface{}{"nullValue": "NULL"}, want: "name,amount\nAlice,10\nNULL,20\nCarol,NULL\n"},
+		{name: `\N`, options: map[string]interface{}{"nullValue": `\N`}, want: "name,amount\nAlice,10\n\\N,20\nCarol,\\N\n"},
+		// The fields with the line breaks are quoted
+		{name: "line break", options: map[string]interface{}{"nullValue": "n/\na"}, want: "name,amount\nAlice,10\n\"n/\na\",20\nCarol,\"n/\na\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writeCSVFile(t, nullFrame(), tt.options); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONOutputWritesRealNull(t *testing.T) {
+	got := writeOutputFile(t, NewJSONOutput(), nullFrame(), "json", map[string]interface{}{"indent": ""})
+	want := `[{"name":"Alice","amount":10},{"name":null,"amount":20},{"name":"Carol","amount":null}]`
+	if strings.TrimSpace(got) != want {
+		t.Errorf("Write() = %s, want %s", got, want)
+	}
+
+	// The JSON nulls are always real nulls, so the option is rejected
+	err := NewJSONOutput().Validate(interfaces.OutputConfig{Format: "json", Destination: "out.json", Options: map[string]interface{}{"nullValue": "NULL"}})
+	var configErr *domainerrors.ConfigurationError
+	if !errors.As(err, &configErr) || configErr.Field != "options.nullValue" {
+		t.Errorf("Validate(nullValue) error = %v, want a ConfigurationError of options.nullValue", err)
+	}
+}
+
+func TestConsoleOutputNullValue(t *testing.T) {
+	var buffer bytes.Buffer
+	config := interfaces.OutputConfig{Format: "console", Options: map[string]interface{}{"nullValue": "NULL", "border": "none"}}
+	if err := NewConsoleOutputWithWriter(&buffer).Write(context.Background(), nullFrame(), config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, line := range []string{"NULL       20", "Carol    NULL"} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("Write() rendered\n%s\nwant the line %q", buffer.String(), line)
+		}
+	}
+}
+
+func TestReportOutputNullValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    string
+	}{
+		{name: "markdown default", options: map[string]interface{}{"style": "markdown", "fields": []interface{}{}}, want: "|  | 20 |"},
+		{name: "markdown NULL", options: map[string]interface{}{"style": "markdown", "fields": []interface{}{}, "nullValue": "NULL"}, want: "| NULL | 20 |"},
+		{name: "text default", options: map[string]interface{}{"fields": []interface{}{}}, want: "| NaN   |     20 |"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writeReportFile(t, nullFrame(), reportMetadata(), tt.options)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Write() =\n%s\nwant the line %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNullValueOptionInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		output interfaces.Output
+		config interfaces.OutputConfig
+	}{
+		{name: "not a string", output: NewCSVOutput(), config: interfaces.OutputConfig{Format: "csv", Destination: "out.csv", Options: map[string]interface{}{"nullValue": 0}}},
+		{name: "line break in a table", output: NewConsoleOutput(), config: interfaces.OutputConfig{Format: "console", Options: map[string]interface{}{"nullValue": "n/\na"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.output.Validate(tt.config)
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || configErr.Field != "options.nullValue" {
+				t.Errorf("Validate() error = %v, want a ConfigurationError of options.nullValue", err)
+			}
+		})
+	}
+}
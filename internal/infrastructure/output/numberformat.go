@@ -0,0 +1,193 @@
+package output
+
+import (
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalPlaces is the maximum value of the decimalPlaces option
+const maxDecimalPlaces = 10
+
+// numberFormatOptions holds the parsed number formatting options of the text-based outputs.
+// The formatting applies to the non-null values of the numeric columns (and their totals) listed in columns,
+// or all numeric columns if columns is nil.
+type numberFormatOptions struct {
+	thousandsSeparator bool
+	decimalPlaces      int // -1 keeps the integers as they are and formats the floats with the minimal digits
+	currencySymbol     string
+	columns            []string
+}
+
+// numberFormatFormatOptions describes the number formatting options shared by the text-based outputs
+var numberFormatFormatOptions = map[string]string{
+	"thousandsSeparator": "Group the integer digits of the numeric columns by thousands with commas (default: false)",
+	"decimalPlaces":      fmt.Sprintf("Number of the decimal places of the numeric columns, 0 to %d (default: unset)", maxDecimalPlaces),
+	"currencySymbol":     "Symbol prefixed to the values of the numeric columns, e.g. \"$\" (default: none)",
+	"formatColumns":      "Names of the numeric columns to format (default: all numeric columns)",
+}
+
+// parseNumberFormatOptions reads and validates the number formatting options of the config.
+// Missing options are treated as their defaults.
+func parseNumberFormatOptions(config interfaces.OutputConfig) (numberFormatOptions, error) {
+	options := numberFormatOptions{
+		decimalPlaces: -1,
+	}
+
+	separator, err := config.OptionBoolStrict("thousandsSeparator", options.thousandsSeparator)
+	if err != nil {
+		return options, err
+	}
+	options.thousandsSeparator = separator
+
+	if _, ok := config.Options["decimalPlaces"]; ok {
+		places, err := config.OptionIntStrict("decimalPlaces", options.decimalPlaces)
+		if err != nil {
+			return options, err
+		}
+		if places < 0 || places > maxDecimalPlaces {
+			return options, domainerrors.NewConfigurationError("options.decimalPlaces", fmt.Sprintf("decimalPlaces must be between 0 and %d, got %d", maxDecimalPlaces, places), nil)
+		}
+		options.decimalPlaces = places
+	}
+
+	symbol, err := config.OptionStringStrict("currencySymbol", options.currencySymbol)
+	if err != nil {
+		return options, err
+	}
+	options.currencySymbol = symbol
+
+	if value, ok := config.Options["formatColumns"]; ok {
+		columns, ok := stringList(value)
+		if !ok {
+			return options, domainerrors.NewConfigurationError("options.formatColumns", fmt.Sprintf("formatColumns must be a list of column names, got %v", value), nil)
+		}
+		options.columns = columns
+	}
+
+	return options, nil
+}
+
+// stringList converts the option value into a string slice accepting both []string and the decoded JSON array.
+func stringList(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list[i] = s
+		}
+		return list, true
+	default:
+		return nil, false
+	}
+}
+
+// enabled returns true if any formatting is configured.
+func (o numberFormatOptions) enabled() bool {
+	return o.thousandsSeparator || o.decimalPlaces >= 0 || o.currencySymbol != ""
+}
+
+// formattedColumns returns the flags of the columns of the DataFrame to format, or nil if no formatting is configured.
+func (o numberFormatOptions) formattedColumns(df *dataframe.DataFrame) []bool {
+	if !o.enabled() {
+		return nil
+	}
+
+	flags := make([]bool, df.Ncol())
+	for j, t := range df.Types() {
+		isNumber := t == series.Int || t == series.Float
+		flags[j] = isNumber && (o.columns == nil || slices.Contains(o.columns, df.Names()[j]))
+	}
+
+	return flags
+}
+
+// format formats the number. isInt keeps the value without the decimal places unless decimalPlaces is set.
+func (o numberFormatOptions) format(value float64, isInt bool) string {
+	var text string
+	switch {
+	case o.decimalPlaces >= 0:
+		text = strconv.FormatFloat(math.Abs(value), 'f', o.decimalPlaces, 64)
+	case isInt:
+		text = strconv.FormatFloat(math.Abs(value), 'f', 0, 64)
+	default:
+		text = strconv.FormatFloat(math.Abs(value), 'f', -1, 64)
+	}
+
+	if o.thousandsSeparator {
+		integer, fraction, hasFraction := strings.Cut(text, ".")
+		text = groupThousands(integer)
+		if hasFraction {
+			text += "." + fraction
+		}
+	}
+
+	sign := ""
+	if value < 0 && strings.Trim(text, "0.,") != "" {
+		sign = "-"
+	}
+
+	return sign + o.currencySymbol + text
+}
+
+// formatElement formats the element of the flagged column, or returns its string as it is.
+func (o numberFormatOptions) formatElement(element series.Element, formatted bool) string {
+	if !formatted || element.IsNA() {
+		return element.String()
+	}
+	value := element.Float()
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return element.String()
+	}
+
+	return o.format(value, element.Type() == series.Int)
+}
+
+// formatRow formats the numeric cells of the columns flagged by columns in place, such as the totals row.
+// Blank and non-numeric cells (e.g. the totals label) are kept.
+func (o numberFormatOptions) formatRow(row []string, types []series.Type, columns []bool) {
+	for j, cell := range row {
+		if columns == nil || !columns[j] || cell == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(cell, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		row[j] = o.format(value, types[j] == series.Int)
+	}
+}
+
+// groupThousands inserts the commas between every three digits from the right.
+func groupThousands(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var builder strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		builder.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if builder.Len() > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(digits[i : i+3])
+	}
+
+	return builder.String()
+}
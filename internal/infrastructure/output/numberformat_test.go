@@ -0,0 +1,99 @@
+package output
+
+import (
+	"context"
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"path/filepath"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		options numberFormatOptions
+		value   float64
+		isInt   bool
+		want    string
+	}{
+		{name: "integer with separators", options: numberFormatOptions{thousandsSeparator: true, decimalPlaces: -1}, value: 1000000, isInt: true, want: "1,000,000"},
+		{name: "short integer", options: numberFormatOptions{thousandsSeparator: true, decimalPlaces: -1}, value: 999, isInt: true, want: "999"},
+		{name: "negative integer", options: numberFormatOptions{thousandsSeparator: true, decimalPlaces: -1}, value: -1234567, isInt: true, want: "-1,234,567"},
+		{name: "currency with two decimals", options: numberFormatOptions{thousandsSeparator: true, decimalPlaces: 2, currencySymbol: "$"}, value: 1234.5, want: "$1,234.50"},
+		{name: "negative currency", options: numberFormatOptions{decimalPlaces: 2, currencySymbol: "¥"}, value: -0.126, want: "-¥0.13"},
+		{name: "rounded to zero", options: numberFormatOptions{decimalPlaces: 1}, value: -0.01, want: "0.0"},
+		{name: "minimal digits", options: numberFormatOptions{decimalPlaces: -1}, value: 2.5, want: "2.5"},
+		{name: "integer with decimal places", options: numberFormatOptions{decimalPlaces: 2}, value: 7, isInt: true, want: "7.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.format(tt.value, tt.isInt); got != tt.want {
+				t.Errorf("format(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleOutputNumberFormat(t *testing.T) {
+	got := writeConsole(t,
+		map[string]interface{}{"thousandsSeparator": true, "decimalPlaces": 2, "currencySymbol": "$", "formatColumns": []interface{}{"revenue"}},
+		[]string{"name", "units", "revenue"},
+		[]string{"Alice", "1000000", "1234.5"},
+		[]string{"Bob", "42", "NaN"},
+	)
+
+	// Only the listed column is formatted, and the null value is kept
+	want := "" +
+		"┌───────┬─────────┬───────────┐\n" +
+		"│ name  │   units │   revenue │\n" +
+		"├───────┼─────────┼───────────┤\n" +
+		"│ Alice │ 1000000 │ $1,234.50 │\n" +
+		"│ Bob   │      42 │       NaN │\n" +
+		"└───────┴─────────┴───────────┘\n"
+	if got != want {
+		t.Errorf("Write() rendered\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCSVOutputNumberFormat(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "units", "revenue"},
+		[]string{"Alice", "1000000", "1234.5"},
+		[]string{"Bob", "42", "0.125"},
+	)
+
+	got := writeCSVFile(t, df, map[string]interface{}{"thousandsSeparator": true})
+	want := "name,units,revenue\nAlice,\"1,000,000\",\"1,234.5\"\nBob,42,0.125\n"
+	if got != want {
+		t.Errorf("Write() = %q, want %q", got, want)
+	}
+}
+
+func TestNumberFormatInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		field   string
+	}{
+		{name: "negative decimal places", options: map[string]interface{}{"decimalPlaces": -1}, field: "options.decimalPlaces"},
+		{name: "too many decimal places", options: map[string]interface{}{"decimalPlaces": maxDecimalPlaces + 1}, field: "options.decimalPlaces"},
+		{name: "non-list columns", options: map[string]interface{}{"formatColumns": "revenue"}, field: "options.formatColumns"},
+	}
+
+	outputs := map[string]interfaces.Output{"console": NewConsoleOutput(), "csv": NewCSVOutput()}
+	for _, tt := range tests {
+		for format, output := range outputs {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				config := interfaces.OutputConfig{Format: format, Destination: filepath.Join(t.TempDir(), "out."+format), Options: tt.options}
+
+				err := output.Write(context.Background(), loadFrame([]string{"revenue"}, []string{"1"}), config)
+				var configErr *domainerrors.ConfigurationError
+				if !errors.As(err, &configErr) || configErr.Field != tt.field {
+					t.Errorf("Write() error = %v, want a ConfigurationError of %s", err, tt.field)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Names of the partitions of the null and the empty values of the partition column
+const (
+	partitionNullValue  = "__null__"
+	partitionEmptyValue = "__empty__"
+)
+
+// partitionWarningCount is the number of the partitions above which the partitioning is warned as high cardinality
+const partitionWarningCount = 100
+
+// partitionFormatOption describes the partitionBy option shared by the file outputs
+const partitionFormatOption = "Column splitting the output into <destination>/<column>=<value>/part.<format> files, " +
+	"treating the destination as a directory; null values go to " + partitionNullValue + " (default: none)"
+
+// parsePartitionOption reads and validates the partitionBy option of the config. Missing option is treated as none.
+func parsePartitionOption(config interfaces.OutputConfig) (string, error) {
+	column, err := config.OptionStringStrict("partitionBy", "")
+	if err != nil {
+		return "", err
+	}
+	if column != "" && strings.TrimSpace(column) == "" {
+		return "", domainerrors.NewConfigurationError("options.partitionBy", "partitionBy cannot be blank", nil)
+	}
+
+	return column, nil
+}
+
+// writePartitions splits the rows of the DataFrame by the values of the column and writes each partition
+// by write to "<destination>/<column>=<value>/part.<extension>" in the order of the values.
+// The partitions keep the partition column, and the values are escaped to be safe as a directory name
+// (see escapePartitionValue). The files of the partitions not in the DataFrame are left as they are.
+// More than partitionWarningCount partitions are warned in the Warnings of the metadata if any.
+func writePartitions(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig, column, extension string, write func(w io.Writer, part *dataframe.DataFrame) error) error {
+	if !slices.Contains(df.Names(), column) {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("partition column '%s' not found", column), nil)
+	}
+
+	partitions := make(map[string][]int)
+	values := df.Col(column)
+	for row := 0; row < values.Len(); row++ {
+		value := partitionNullValue
+		if element := values.Elem(row); !element.IsNA() {
+			value = escapePartitionValue(element.String())
+		}
+		partitions[value] = append(partitions[value], row)
+	}
+
+	if len(partitions) > partitionWarningCount && config.Metadata != nil {
+		config.Metadata.Warnings = append(config.Metadata.Warnings, fmt.Sprintf("partition column '%s' has high cardinality: %d partitions", column, len(partitions)))
+	}
+
+	keys := make([]string, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return domainerrors.NewDataProcessError("output", "output is canceled", err)
+		}
+
+		part := df.Subset(partitions[key])
+		if part.Err != nil {
+			return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to subset partition '%s'", key), part.Err)
+		}
+
+		path := filepath.Join(config.Destination, fmt.Sprintf("%s=%s", escapePartitionValue(column), key), "part."+extension)
+		if err := writeFile(path, func(w io.Writer) error { return write(w, &part) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// escapePartitionValue percent-encodes the characters of the value unsafe in a directory name, e.g. "a/b" is "a%2Fb".
+// The letters, digits, spaces, and "-_.~" are kept, and the dot-only values are encoded not to refer to the directories.
+// The empty value is named partitionEmptyValue.
+func escapePartitionValue(value string) string {
+	if value == "" {
+		return partitionEmptyValue
+	}
+
+	var builder strings.Builder
+	dotsOnly := strings.Trim(value, ".") == ""
+	for _, b := range []byte(value) {
+		safe := b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || strings.IndexByte(" -_.~", b) >= 0
+		if !safe || (dotsOnly && b == '.') {
+			builder.WriteString(fmt.Sprintf("%%%02X", b))
+			continue
+		}
+		builder.WriteByte(b)
+	}
+
+	return builder.String()
+}
+
+// writeFile creates the file of the path with its directory and writes it by write. The partially written file is removed on error.
+func writeFile(path string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create directory for '%s'", path), err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create '%s'", path), err)
+	}
+
+	if err := write(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write '%s'", path), err)
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(path)
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to close '%s'", path), err)
+	}
+
+	return nil
+}
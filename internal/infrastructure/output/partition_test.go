@@ -0,0 +1,203 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// partitionFrame returns the orders of the regions, including a null region, an empty region, and a region unsafe
+// as a directory name.
+func partitionFrame() *dataframe.DataFrame {
+	df := dataframe.New(
+		series.New([]interface{}{"1", "2", "3", "4", "5", "6", "7"}, series.Int, "id"),
+		series.New([]interface{}{"US", "EU", "US", nil, "a/b", "", "US"}, series.String, "region"),
+	)
+
+	return &df
+}
+
+// partitionFiles returns the paths of the files under the directory relative to it.
+func partitionFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(relative))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walk %s: %v", dir, err)
+	}
+
+	return files
+}
+
+func TestCSVOutputPartitionBy(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "orders")
+	config := interfaces.OutputConfig{Format: "csv", Destination: destination, Options: map[string]interface{}{"partitionBy": "region"}}
+	output := NewCSVOutput()
+	if err := output.Write(context.Background(), partitionFrame(), config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The partitions in the order of the values, each with its rows under the header
+	want := map[string]string{
+		"region=EU/part.csv":        "id,region\n2,EU\n",
+		"region=US/part.csv":        "id,region\n1,US\n3,US\n7,US\n",
+		"region=__empty__/part.csv": "id,region\n6,\n",
+		"region=__null__/part.csv":  "id,region\n4,NaN\n",
+		"region=a%2Fb/part.csv":     "id,region\n5,a/b\n",
+	}
+	wantFiles := []string{"region=EU/part.csv", "region=US/part.csv", "region=__empty__/part.csv", "region=__null__/part.csv", "region=a%2Fb/part.csv"}
+	if got := partitionFiles(t, destination); !reflect.DeepEqual(got, wantFiles) {
+		t.Fatalf("partition files = %v, want %v", got, wantFiles)
+	}
+	for file, content := range want {
+		if got := readFile(t, filepath.Join(destination, file)); got != content {
+			t.Errorf("%s = %q, want %q", file, got, content)
+		}
+	}
+}
+
+func TestJSONOutputPartitionBy(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "orders")
+	config := interfaces.OutputConfig{Format: "json", Destination: destination, Options: map[string]interface{}{"partitionBy": "region", "indent": ""}}
+	output := NewJSONOutput()
+	if err := output.Write(context.Background(), partitionFrame(), config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	wantRows := map[string]int{
+		"region=EU/part.json":        1,
+		"region=US/part.json":        3,
+		"region=__empty__/part.json": 1,
+		"region=__null__/part.json":  1,
+		"region=a%2Fb/part.json":     1,
+	}
+	files := partitionFiles(t, destination)
+	if len(files) != len(wantRows) {
+		t.Fatalf("partition files = %v, want %d files", files, len(wantRows))
+	}
+	for file, want := range wantRows {
+		var rows []map[string]interface{}
+		if err := json.Unmarshal([]byte(readFile(t, filepath.Join(destination, file))), &rows); err != nil {
+			t.Fatalf("decode %s: %v", file, err)
+		}
+		if len(rows) != want {
+			t.Errorf("%s has %d rows, want %d", file, len(rows), want)
+		}
+	}
+}
+
+func TestPartitionByHighCardinalityWarning(t *testing.T) {
+	ids := make([]int, partitionWarningCount+1)
+	for i := range ids {
+		ids[i] = i
+	}
+	df := dataframe.New(series.New(ids, series.Int, "id"))
+
+	for _, count := range []int{partitionWarningCount, partitionWarningCount + 1} {
+		metadata := testMetadata()
+		destination := filepath.Join(t.TempDir(), "ids")
+		config := interfaces.OutputConfig{Format: "csv", Destination: destination, Options: map[string]interface{}{"partitionBy": "id"}, Metadata: metadata}
+		head := df.Subset(ids[:count])
+		if err := NewCSVOutput().Write(context.Background(), &head, config); err != nil {
+			t.Fatalf("Write(%d partitions) error = %v", count, err)
+		}
+
+		if got := len(partitionFiles(t, destination)); got != count {
+			t.Errorf("Write(%d partitions) wrote %d files", count, got)
+		}
+		warned := len(metadata.Warnings) == 1 && strings.Contains(metadata.Warnings[0], fmt.Sprintf("%d partitions", count))
+		if warned != (count > partitionWarningCount) {
+			t.Errorf("Write(%d partitions) warnings = %v, want a warning only above %d partitions", count, metadata.Warnings, partitionWarningCount)
+		}
+	}
+}
+
+func TestPartitionByInvalid(t *testing.T) {
+	t.Run("missing column", func(t *testing.T) {
+		config := interfaces.OutputConfig{Format: "csv", Destination: filepath.Join(t.TempDir(), "orders"), Options: map[string]interface{}{"partitionBy": "country"}}
+		err := NewCSVOutput().Write(context.Background(), partitionFrame(), config)
+
+		var processErr *domainerrors.DataProcessError
+		if !errors.As(err, &processErr) || !strings.Contains(processErr.Error(), "partition column 'country' not found") {
+			t.Errorf("Write() error = %v, want the missing partition column", err)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		validate func(config interfaces.OutputConfig) error
+		format   string
+		options  map[string]interface{}
+	}{
+		{name: "blank", validate: NewCSVOutput().Validate, format: "csv", options: map[string]interface{}{"partitionBy": " "}},
+		{name: "not a string", validate: NewJSONOutput().Validate, format: "json", options: map[string]interface{}{"partitionBy": 1}},
+		{name: "writer output", validate: NewWriterOutput(nil).Validate, format: "csv", options: map[string]interface{}{"partitionBy": "region"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(interfaces.OutputConfig{Format: tt.format, Destination: "orders", Options: tt.options})
+
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || configErr.Field != "options.partitionBy" {
+				t.Errorf("Validate() error = %v, want a ConfigurationError of options.partitionBy", err)
+			}
+		})
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		rows := make(chan []string)
+		close(rows)
+		config := interfaces.OutputConfig{Format: "csv", Destination: filepath.Join(t.TempDir(), "orders"), Options: map[string]interface{}{"partitionBy": "region"}}
+		err := NewCSVOutput().WriteStream(context.Background(), rows, []string{"id", "region"}, config)
+
+		var configErr *domainerrors.ConfigurationError
+		if !errors.As(err, &configErr) || configErr.Field != "options.partitionBy" {
+			t.Errorf("WriteStream() error = %v, want a ConfigurationError of options.partitionBy", err)
+		}
+	})
+}
+
+func TestEscapePartitionValue(t *testing.T) {
+	tests := map[string]string{
+		"US":        "US",
+		"New York":  "New York",
+		"a/b":       "a%2Fb",
+		`a\b`:       "a%5Cb",
+		"..":        "%2E%2E",
+		"v1.2":      "v1.2",
+		"":          partitionEmptyValue,
+		"100%":      "100%25",
+		"東京":        "%E6%9D%B1%E4%BA%AC",
+		"key=value": "key%3Dvalue",
+	}
+
+	for value, want := range tests {
+		if got := escapePartitionValue(value); got != want {
+			t.Errorf("escapePartitionValue(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+)
+
+// buildPreviewResult builds the structured preview of the result data up to maxRows rows (0 for all).
+// This is shared by all outputs because the structured preview doesn't depend on the format.
+func buildPreviewResult(result *entities.Processing, maxRows int) (*interfaces.PreviewResult, error) {
+	if result == nil || result.Data == nil {
+		return nil, domainerrors.NewDataProcessError("preview", "no data to preview", nil)
+	}
+
+	df := result.Data
+	totalRows := df.Nrow()
+	rowCount := totalRows
+	if maxRows > 0 && rowCount > maxRows {
+		rowCount = maxRows
+	}
+
+	columnTypes := make([]string, 0, df.Ncol())
+	for _, t := range df.Types() {
+		columnTypes = append(columnTypes, string(t))
+	}
+
+	rows := make([][]interface{}, rowCount)
+	for i := 0; i < rowCount; i++ {
+		row := make([]interface{}, df.Ncol())
+		for j := 0; j < df.Ncol(); j++ {
+			// The rows are encoded as JSON by the front-ends, so the non-finite values are null as well
+			row[j] = jsonValue(df.Elem(i, j))
+		}
+		rows[i] = row
+	}
+
+	return &interfaces.PreviewResult{
+		Columns:     df.Names(),
+		ColumnTypes: columnTypes,
+		Rows:        rows,
+		TotalRows:   totalRows,
+		Truncated:   rowCount < totalRows,
+	}, nil
+}
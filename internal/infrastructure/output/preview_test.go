@@ -0,0 +1,86 @@
+package output
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"reflect"
+	"testing"
+)
+
+func TestPreviewStructured(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount", "paid"},
+		[]string{"Alice", "10", "true"},
+		[]string{"Bob", "2.5", "false"},
+		[]string{"Carol", "", "true"},
+	)
+	result := entities.NewProcessing(df, "preview")
+
+	tests := []struct {
+		name      string
+		maxRows   int
+		rows      int
+		truncated bool
+	}{
+		{name: "all rows", maxRows: 0, rows: 3, truncated: false},
+		{name: "exact limit", maxRows: 3, rows: 3, truncated: false},
+		{name: "truncated", maxRows: 2, rows: 2, truncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := NewJSONOutput().PreviewStructured(result, interfaces.OutputConfig{Format: "json"}, tt.maxRows)
+			if err != nil {
+				t.Fatalf("PreviewStructured() error = %v", err)
+			}
+
+			if got := len(preview.Rows); got != tt.rows {
+				t.Errorf("len(Rows) = %d, want %d", got, tt.rows)
+			}
+			if preview.Truncated != tt.truncated {
+				t.Errorf("Truncated = %v, want %v", preview.Truncated, tt.truncated)
+			}
+			if preview.TotalRows != df.Nrow() {
+				t.Errorf("TotalRows = %d, want %d", preview.TotalRows, df.Nrow())
+			}
+			if !reflect.DeepEqual(preview.Columns, df.Names()) {
+				t.Errorf("Columns = %v, want %v", preview.Columns, df.Names())
+			}
+			if want := []string{"string", "float", "bool"}; !reflect.DeepEqual(preview.ColumnTypes, want) {
+				t.Errorf("ColumnTypes = %v, want %v", preview.ColumnTypes, want)
+			}
+			for i, row := range preview.Rows {
+				if len(row) != df.Ncol() {
+					t.Errorf("len(Rows[%d]) = %d, want %d", i, len(row), df.Ncol())
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewStructuredValues(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount", "paid"},
+		[]string{"Alice", "10", "true"},
+		[]string{"Carol", "", "false"},
+	)
+
+	preview, err := NewCSVOutput().PreviewStructured(entities.NewProcessing(df, "preview"), interfaces.OutputConfig{Format: "csv"}, 0)
+	if err != nil {
+		t.Fatalf("PreviewStructured() error = %v", err)
+	}
+
+	want := [][]interface{}{
+		{"Alice", 10, true},
+		{"Carol", nil, false},
+	}
+	if !reflect.DeepEqual(preview.Rows, want) {
+		t.Errorf("Rows = %#v, want %#v", preview.Rows, want)
+	}
+}
+
+func TestPreviewStructuredNoData(t *testing.T) {
+	if _, err := NewCSVOutput().PreviewStructured(entities.NewProcessing(nil, "preview"), interfaces.OutputConfig{Format: "csv"}, 0); err == nil {
+		t.Error("PreviewStructured() error = nil, want an error without data")
+	}
+}
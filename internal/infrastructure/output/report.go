@@ -0,0 +1,408 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ensure ReportOutput implements the Output interface
+var _ interfaces.Output = (*ReportOutput)(nil)
+
+// Styles of the report
+const (
+	reportStyleText     = "text"
+	reportStyleMarkdown = "markdown"
+)
+
+// reportFields is the metadata fields the report can show in the order of the header block
+var reportFields = []string{
+	"configName", "runId", "dataSource", "rows", "filters", "merges", "aggregations", "processingTime", "warnings", "rejectedRows",
+}
+
+// reportOptions holds the parsed options of the report output
+type reportOptions struct {
+	style     string
+	fields    []string
+	numbers   numberFormatOptions
+	nullValue string
+}
+
+// reportEntry is a line of the header block; either a single value or a list of values
+type reportEntry struct {
+	label  string
+	value  string
+	list   []string
+	isList bool
+}
+
+// ReportOutput writes a human-readable report to the file specified by the Destination of the config,
+// a header block summarizing the ProcessingMetadata followed by the data table, as plain text or Markdown.
+// The metadata is required and given by the Metadata of the config.
+type ReportOutput struct{}
+
+// NewReportOutput creates a new ReportOutput instance.
+func NewReportOutput() *ReportOutput {
+	return &ReportOutput{}
+}
+
+// Write writes the report of the DataFrame and the metadata of the config to the Destination of the config.
+func (r *ReportOutput) Write(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := r.Validate(config); err != nil {
+		return err
+	}
+
+	options, err := parseReportOptions(config)
+	if err != nil {
+		return err
+	}
+
+	if config.Metadata == nil {
+		return domainerrors.NewConfigurationError("metadata", "metadata is required for report output", nil)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	if df == nil {
+		return domainerrors.NewDataProcessError("output", "no data to write", nil)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.Destination), 0o755); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create directory for '%s'", config.Destination), err)
+	}
+
+	file, err := os.Create(config.Destination)
+	if err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to create '%s'", config.Destination), err)
+	}
+
+	if err := writeReport(file, df, df.Nrow(), config.Metadata, options); err != nil {
+		_ = file.Close()
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write '%s'", config.Destination), err)
+	}
+
+	if err := file.Close(); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to close '%s'", config.Destination), err)
+	}
+
+	return nil
+}
+
+// WriteStream is not supported by ReportOutput because the report summarizes the completed processing.
+func (r *ReportOutput) WriteStream(_ context.Context, _ <-chan []string, _ []string, _ interfaces.OutputConfig) error {
+	return domainerrors.NewDataProcessError("output", "streaming is not supported for report output", nil)
+}
+
+// Close does nothing because ReportOutput holds no resources.
+func (r *ReportOutput) Close() error {
+	return nil
+}
+
+// Validate checks the config has the report format, a destination, and valid options.
+func (r *ReportOutput) Validate(config interfaces.OutputConfig) error {
+	if !slices.Contains(r.SupportedFormats(), config.Format) {
+		return domainerrors.NewConfigurationError("format", fmt.Sprintf("unsupported format '%s' for report output", config.Format), nil)
+	}
+	if config.Destination == "" {
+		return domainerrors.NewConfigurationError("destination", "destination is required", nil)
+	}
+
+	_, err := parseReportOptions(config)
+
+	return err
+}
+
+// SupportedFormats returns the output formats supported by ReportOutput.
+func (r *ReportOutput) SupportedFormats() []string {
+	return []string{"report"}
+}
+
+// GetFormatOptions returns the available options of the report output and their descriptions.
+func (r *ReportOutput) GetFormatOptions(format string) map[string]string {
+	if format != "report" {
+		return map[string]string{}
+	}
+
+	options := map[string]string{
+		"style":     "Style of the report; text or markdown (default: text)",
+		"fields":    fmt.Sprintf("Metadata fields shown in the header block in this order, any of %v (default: all)", reportFields),
+		"nullValue": "Text of the null values in the table (default: NaN for text, blank for markdown)",
+	}
+	maps.Copy(options, numberFormatFormatOptions)
+
+	return options
+}
+
+// Preview renders the report of the result data up to maxRows rows (0 for all).
+// The metadata of the result is reported unless the config provides other metadata.
+func (r *ReportOutput) Preview(result *entities.Processing, config interfaces.OutputConfig, maxRows int) (string, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseReportOptions(config)
+	if err != nil {
+		return "", err
+	}
+
+	if result == nil || result.Data == nil {
+		return "", domainerrors.NewDataProcessError("preview", "no data to preview", nil)
+	}
+
+	metadata := config.Metadata
+	if metadata == nil {
+		metadata = &result.Metadata
+	}
+
+	rowCount := result.Data.Nrow()
+	if maxRows > 0 && rowCount > maxRows {
+		rowCount = maxRows
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, result.Data, rowCount, metadata, options); err != nil {
+		return "", domainerrors.NewDataProcessError("preview", "failed to render the report", err)
+	}
+	if rowCount < result.Data.Nrow() {
+		buf.WriteString(fmt.Sprintf("(showing %d of %d rows)\n", rowCount, result.Data.Nrow()))
+	}
+
+	return buf.String(), nil
+}
+
+// EstimateSize estimates the byte size of the report of the result data.
+// The metadata of the result is reported unless the config provides other metadata.
+func (r *ReportOutput) EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	options, err := parseReportOptions(config)
+	if err != nil {
+		return 0, err
+	}
+
+	metadata := config.Metadata
+	if metadata == nil && result != nil {
+		metadata = &result.Metadata
+	}
+
+	return estimateSize(result, func(w io.Writer, df *dataframe.DataFrame) error {
+		return writeReport(w, df, df.Nrow(), metadata, options)
+	})
+}
+
+// PreviewStructured returns the structured preview of the result data up to maxRows rows (0 for all).
+func (r *ReportOutput) PreviewStructured(result *entities.Processing, _ interfaces.OutputConfig, maxRows int) (*interfaces.PreviewResult, error) {
+	return buildPreviewResult(result, maxRows)
+}
+
+// parseReportOptions reads and validates the report options of the config.
+// Missing options are treated as their defaults.
+func parseReportOptions(config interfaces.OutputConfig) (reportOptions, error) {
+	options := reportOptions{
+		style:  reportStyleText,
+		fields: reportFields,
+	}
+
+	if value, ok := config.Options["style"]; ok {
+		style, _ := value.(string)
+		if style != reportStyleText && style != reportStyleMarkdown {
+			return options, domainerrors.NewConfigurationError("options.style", fmt.Sprintf("style must be one of [text markdown], got %v", value), nil)
+		}
+		options.style = style
+	}
+
+	if value, ok := config.Options["fields"]; ok {
+		var fields []string
+		switch v := value.(type) {
+		case []string:
+			fields = v
+		case []interface{}:
+			// JSON arrays are decoded as []interface{}
+			for _, item := range v {
+				field, ok := item.(string)
+				if !ok {
+					return options, domainerrors.NewConfigurationError("options.fields", fmt.Sprintf("fields must be a list of strings, got %v", value), nil)
+				}
+				fields = append(fields, field)
+			}
+		default:
+			return options, domainerrors.NewConfigurationError("options.fields", fmt.Sprintf("fields must be a list of strings, got %v", value), nil)
+		}
+
+		for _, field := range fields {
+			if !slices.Contains(reportFields, field) {
+				return options, domainerrors.NewConfigurationError("options.fields", fmt.Sprintf("unknown field '%s', fields must be any of %v", field, reportFields), nil)
+			}
+		}
+		options.fields = fields
+	}
+
+	numbers, err := parseNumberFormatOptions(config)
+	if err != nil {
+		return options, err
+	}
+	options.numbers = numbers
+
+	// The Markdown tables leave the null values blank by default
+	nullValue := defaultNullValue
+	if options.style == reportStyleMarkdown {
+		nullValue = ""
+	}
+	if nullValue, err = parseNullValueOption(config, nullValue, true); err != nil {
+		return options, err
+	}
+	options.nullValue = nullValue
+
+	return options, nil
+}
+
+// reportEntries builds the lines of the header block of the fields from the metadata.
+// The output rows are the rows of the reported data, which can differ from the filtered rows after the aggregations.
+func reportEntries(metadata *entities.ProcessingMetadata, outputRows int, fields []string) []reportEntry {
+	entries := make([]reportEntry, 0, len(fields))
+	for _, field := range fields {
+		switch field {
+		case "configName":
+			entries = append(entries, reportEntry{label: "Config", value: metadata.ConfigName})
+		case "runId":
+			entries = append(entries, reportEntry{label: "Run ID", value: metadata.RunID})
+		case "dataSource":
+			entries = append(entries, reportEntry{label: "Data source", value: metadata.DataSource})
+		case "rows":
+			rows := fmt.Sprintf("%d in, %d after filters, %d out", metadata.SourceTotalRows, metadata.FilteredTotalRows, outputRows)
+			if metadata.RemovedDuplicateRows > 0 {
+				rows += fmt.Sprintf(" (%d duplicates removed)", metadata.RemovedDuplicateRows)
+			}
+			entries = append(entries, reportEntry{label: "Rows", value: rows})
+		case "filters":
+			entries = append(entries, reportEntry{label: "Filters", list: metadata.AppliedFilters, isList: true})
+		case "merges":
+			entries = append(entries, reportEntry{label: "Merges", list: metadata.PerformedMerges, isList: true})
+		case "aggregations":
+			entries = append(entries, reportEntry{label: "Aggregations", list: metadata.PerformedAggregations, isList: true})
+		case "processingTime":
+			entries = append(entries, reportEntry{label: "Processing time", value: metadata.ProcessingTime.Round(time.Millisecond).String()})
+		case "warnings":
+			entries = append(entries, reportEntry{label: "Warnings", list: metadata.Warnings, isList: true})
+		case "rejectedRows":
+			entries = append(entries, reportEntry{label: "Rejected rows", value: fmt.Sprintf("%d", metadata.RejectedRowCount)})
+		}
+	}
+
+	return entries
+}
+
+// writeReport writes the header block of the metadata and the table of the first rowCount rows in the style.
+// The empty lists are shown as "none".
+func writeReport(w io.Writer, df *dataframe.DataFrame, rowCount int, metadata *entities.ProcessingMetadata, options reportOptions) error {
+	if metadata == nil {
+		return fmt.Errorf("no metadata to report")
+	}
+
+	entries := reportEntries(metadata, df.Nrow(), options.fields)
+
+	var builder strings.Builder
+	if options.style == reportStyleMarkdown {
+		for _, entry := range entries {
+			switch {
+			case !entry.isList:
+				builder.WriteString(fmt.Sprintf("- **%s:** %s\n", entry.label, escapeMarkdown(entry.value)))
+			case len(entry.list) == 0:
+				builder.WriteString(fmt.Sprintf("- **%s:** none\n", entry.label))
+			default:
+				builder.WriteString(fmt.Sprintf("- **%s:**\n", entry.label))
+				for _, item := range entry.list {
+					builder.WriteString(fmt.Sprintf("  - `%s`\n", strings.ReplaceAll(item, "`", "'")))
+				}
+			}
+		}
+		if len(entries) > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(markdownTable(df, rowCount, options.numbers, options.nullValue))
+	} else {
+		labelWidth := 0
+		for _, entry := range entries {
+			labelWidth = max(labelWidth, len(entry.label)+1)
+		}
+		for _, entry := range entries {
+			label := fmt.Sprintf("%-*s ", labelWidth, entry.label+":")
+			switch {
+			case !entry.isList:
+				builder.WriteString(label + entry.value + "\n")
+			case len(entry.list) == 0:
+				builder.WriteString(label + "none\n")
+			default:
+				builder.WriteString(strings.TrimRight(label, " ") + "\n")
+				for _, item := range entry.list {
+					builder.WriteString("  - " + item + "\n")
+				}
+			}
+		}
+		if len(entries) > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(renderTable(df, rowCount, consoleOptions{border: "ascii", maxColWidth: 30, numbers: options.numbers, nullValue: options.nullValue}))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// markdownTable renders the first rowCount rows of the DataFrame as a Markdown table.
+// Numeric columns are right-aligned and formatted by the number formatting options, and the null values are rendered as nullValue.
+func markdownTable(df *dataframe.DataFrame, rowCount int, numbers numberFormatOptions, nullValue string) string {
+	names := df.Names()
+	types := df.Types()
+
+	header := make([]string, len(names))
+	alignments := make([]string, len(names))
+	for j, name := range names {
+		header[j] = escapeMarkdown(name)
+		alignments[j] = "---"
+		if types[j] == series.Int || types[j] == series.Float {
+			alignments[j] = "---:"
+		}
+	}
+
+	formatted := numbers.formattedColumns(df)
+
+	var builder strings.Builder
+	builder.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	builder.WriteString("| " + strings.Join(alignments, " | ") + " |\n")
+	for i := 0; i < rowCount; i++ {
+		cells := make([]string, len(names))
+		for j := range names {
+			cells[j] = escapeMarkdown(nullValue)
+			if element := df.Elem(i, j); !element.IsNA() {
+				cells[j] = escapeMarkdown(numbers.formatElement(element, formatted != nil && formatted[j]))
+			}
+		}
+		builder.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+
+	return builder.String()
+}
+
+// escapeMarkdown escapes the pipes and the newlines breaking a line of the Markdown table.
+// The backslashes are escaped first so that a backslash in the value cannot unescape the following pipe.
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	value = strings.ReplaceAll(value, "\r", "<br>")
+
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
@@ -0,0 +1,180 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportMetadata returns the metadata of a filtered and merged run to report.
+func reportMetadata() *entities.ProcessingMetadata {
+	metadata := testMetadata()
+	metadata.AppliedFilters = []string{"amount gt 5", "region in [east west]"}
+	metadata.PerformedMerges = []string{"concat(first, last)"}
+	metadata.ProcessingTime = 1234567 * time.Microsecond
+
+	return metadata
+}
+
+// writeReportFile writes the report of the DataFrame with the metadata and the options, and returns the content.
+func writeReportFile(t *testing.T, df *dataframe.DataFrame, metadata *entities.ProcessingMetadata, options map[string]interface{}) string {
+	t.Helper()
+
+	destination := filepath.Join(t.TempDir(), "out.report")
+	config := interfaces.OutputConfig{Format: "report", Destination: destination, Options: options, Metadata: metadata}
+	if err := NewReportOutput().Write(context.Background(), df, config); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	return readFile(t, destination)
+}
+
+func TestReportOutputHeader(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount"},
+		[]string{"Alice", "10"},
+		[]string{"Bob", "20"},
+	)
+
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    string
+	}{
+		{
+			name:    "text",
+			options: nil,
+			want: "Config:          metadata\n" +
+				"Run ID:          run-1\n" +
+				"Data source:     memory\n" +
+				"Rows:            3 in, 2 after filters, 2 out\n" +
+				"Filters:\n" +
+				"  - amount gt 5\n" +
+				"  - region in [east west]\n" +
+				"Merges:\n" +
+				"  - concat(first, last)\n" +
+				"Aggregations:    none\n" +
+				"Processing time: 1.235s\n" +
+				"Warnings:        none\n" +
+				"Rejected rows:   0\n" +
+				"\n" +
+				"+-------+--------+\n" +
+				"| name  | amount |\n" +
+				"+-------+--------+\n" +
+				"| Alice |     10 |\n" +
+				"| Bob   |     20 |\n" +
+				"+-------+--------+\n",
+		},
+		{
+			name:    "markdown",
+			options: map[string]interface{}{"style": "markdown"},
+			want: "- **Config:** metadata\n" +
+				"- **Run ID:** run-1\n" +
+				"- **Data source:** memory\n" +
+				"- **Rows:** 3 in, 2 after filters, 2 out\n" +
+				"- **Filters:**\n" +
+				"  - `amount gt 5`\n" +
+				"  - `region in [east west]`\n" +
+				"- **Merges:**\n" +
+				"  - `concat(first, last)`\n" +
+				"- **Aggregations:** none\n" +
+				"- **Processing time:** 1.235s\n" +
+				"- **Warnings:** none\n" +
+				"- **Rejected rows:** 0\n" +
+				"\n" +
+				"| name | amount |\n" +
+				"| --- | ---: |\n" +
+				"| Alice | 10 |\n" +
+				"| Bob | 20 |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writeReportFile(t, df, reportMetadata(), tt.options)
+			if got != tt.want {
+				t.Errorf("report = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportOutputFields(t *testing.T) {
+	df := loadFrame([]string{"name", "amount"}, []string{"Alice", "10"})
+
+	// Only the selected fields appear, in the given order
+	got := writeReportFile(t, df, reportMetadata(), map[string]interface{}{"fields": []interface{}{"filters", "rows"}})
+	header, _, _ := strings.Cut(got, "\n\n")
+	want := []string{"Filters:", "  - amount gt 5", "  - region in [east west]", "Rows:    3 in, 2 after filters, 1 out"}
+	if lines := strings.Split(header, "\n"); !reflect.DeepEqual(lines, want) {
+		t.Errorf("header = %q, want %q", lines, want)
+	}
+
+	// No fields leave only the table
+	got = writeReportFile(t, df, reportMetadata(), map[string]interface{}{"fields": []string{}})
+	if !strings.HasPrefix(got, "+") {
+		t.Errorf("report = %q, want only the table", got)
+	}
+}
+
+func TestReportOutputInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		metadata *entities.ProcessingMetadata
+		field    string
+	}{
+		{name: "unknown style", options: map[string]interface{}{"style": "html"}, metadata: reportMetadata(), field: "options.style"},
+		{name: "unknown field", options: map[string]interface{}{"fields": []string{"rows", "cost"}}, metadata: reportMetadata(), field: "options.fields"},
+		{name: "fields not a list", options: map[string]interface{}{"fields": "rows"}, metadata: reportMetadata(), field: "options.fields"},
+		{name: "missing metadata", metadata: nil, field: "metadata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := interfaces.OutputConfig{
+				Format:      "report",
+				Destination: filepath.Join(t.TempDir(), "out.report"),
+				Options:     tt.options,
+				Metadata:    tt.metadata,
+			}
+
+			err := NewReportOutput().Write(context.Background(), loadFrame([]string{"name"}, []string{"Alice"}), config)
+			var configErr *domainerrors.ConfigurationError
+			if !errors.As(err, &configErr) || configErr.Field != tt.field {
+				t.Errorf("Write() error = %v, want a ConfigurationError of %s", err, tt.field)
+			}
+		})
+	}
+
+	if got := NewReportOutput().SupportedFormats(); !reflect.DeepEqual(got, []string{"report"}) {
+		t.Errorf("SupportedFormats() = %v, want [report]", got)
+	}
+}
+
+func TestReportOutputMarkdownEscaping(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "note"},
+		[]string{"a|b", "line one\nline two"},
+		[]string{`back\slash`, `\|`},
+	)
+
+	got := writeReportFile(t, df, reportMetadata(), map[string]interface{}{"style": "markdown", "fields": []string{}})
+
+	// Every row stays on its line with the same number of cells, and the pipes in the values don't split the cells
+	want := "| name | note |\n" +
+		"| --- | --- |\n" +
+		"| a\\|b | line one<br>line two |\n" +
+		"| back\\\\slash | \\\\\\| |\n"
+	if got != want {
+		t.Errorf("report = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bytes"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"math"
+)
+
+// sizeSampleRows is the number of the rows rendered to estimate the output size
+const sizeSampleRows = 100
+
+// estimateSize estimates the byte size of the result data rendered by render.
+// The data up to sizeSampleRows rows is rendered as a whole, so the size is exact.
+// Otherwise, sizeSampleRows evenly spaced rows are rendered, and the size of a row and the fixed size
+// (e.g. the header, the brackets, or the totals footer) are derived from the sizes of the first sample row alone
+// and of all sample rows, then extrapolated to the total rows. The estimate is off when the sampled rows
+// are not representative, e.g. when the lengths of the values are skewed toward a few rows.
+func estimateSize(result *entities.Processing, render func(w io.Writer, df *dataframe.DataFrame) error) (int64, error) {
+	if result == nil || result.Data == nil {
+		return 0, domainerrors.NewDataProcessError("estimate", "no data to estimate", nil)
+	}
+
+	df := result.Data
+	totalRows := df.Nrow()
+	if totalRows <= sizeSampleRows {
+		size, err := renderedSize(df, render)
+		return int64(size), err
+	}
+
+	indexes := make([]int, sizeSampleRows)
+	for i := range indexes {
+		indexes[i] = i * totalRows / sizeSampleRows
+	}
+
+	sample := df.Subset(indexes)
+	sampleSize, err := renderedSize(&sample, render)
+	if err != nil {
+		return 0, err
+	}
+
+	first := df.Subset(indexes[:1])
+	firstSize, err := renderedSize(&first, render)
+	if err != nil {
+		return 0, err
+	}
+
+	rowSize := float64(sampleSize-firstSize) / float64(sizeSampleRows-1)
+	fixedSize := max(float64(firstSize)-rowSize, 0)
+
+	return int64(math.Round(fixedSize + rowSize*float64(totalRows))), nil
+}
+
+// renderedSize returns the byte size of the DataFrame rendered by render.
+func renderedSize(df *dataframe.DataFrame, render func(w io.Writer, df *dataframe.DataFrame) error) (int, error) {
+	var buf bytes.Buffer
+	if err := render(&buf, df); err != nil {
+		return 0, domainerrors.NewDataProcessError("estimate", "failed to render the sample rows", err)
+	}
+
+	return buf.Len(), nil
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// sizeEstimator is implemented by the outputs estimating the size of the written result
+type sizeEstimator interface {
+	interfaces.Output
+	EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error)
+}
+
+// sizeFrameRecords returns the records of the rows whose values vary in length randomly across the rows.
+// The values are not periodic, so the evenly spaced sample rows are representative.
+func sizeFrameRecords(rows int) [][]string {
+	r := rand.New(rand.NewPCG(889, 1))
+	records := [][]string{{"id", "name", "amount"}}
+	for i := range rows {
+		records = append(records, []string{fmt.Sprint(i), strings.Repeat("x", 1+r.IntN(20)), fmt.Sprint(float64(r.IntN(100_000)) / 8)})
+	}
+
+	return records
+}
+
+func TestEstimateSize(t *testing.T) {
+	outputs := []struct {
+		name    string
+		format  string
+		output  func() sizeEstimator
+		options map[string]interface{}
+	}{
+		{name: "csv", format: "csv", output: func() sizeEstimator { return NewCSVOutput() }},
+		{name: "csv with quotes and totals", format: "csv", output: func() sizeEstimator { return NewCSVOutput() }, options: map[string]interface{}{"quoteAll": true, "showTotals": true}},
+		{name: "json", format: "json", output: func() sizeEstimator { return NewJSONOutput() }},
+		{name: "compact json", format: "json", output: func() sizeEstimator { return NewJSONOutput() }, options: map[string]interface{}{"indent": ""}},
+	}
+	sizes := []struct {
+		rows      int
+		tolerance float64 // relative error of the estimate from the written size
+	}{
+		{rows: 0, tolerance: 0},
+		{rows: sizeSampleRows, tolerance: 0},
+		{rows: 20_000, tolerance: 0.05},
+	}
+
+	for _, o := range outputs {
+		for _, size := range sizes {
+			t.Run(fmt.Sprintf("%s/rows=%d", o.name, size.rows), func(t *testing.T) {
+				df := loadFrame(sizeFrameRecords(size.rows)...)
+				config := interfaces.OutputConfig{Format: o.format, Options: o.options}
+
+				estimate, err := o.output().EstimateSize(entities.NewProcessing(df, "size"), config)
+				if err != nil {
+					t.Fatalf("EstimateSize() error = %v", err)
+				}
+				actual := len(writeOutputFile(t, o.output(), df, o.format, o.options))
+
+				if diff := math.Abs(float64(estimate-int64(actual))) / float64(actual); diff > size.tolerance {
+					t.Errorf("EstimateSize() = %d, written %d bytes (off by %.2f%%), want within %.0f%%", estimate, actual, diff*100, size.tolerance*100)
+				}
+			})
+		}
+	}
+}
+
+func TestEstimateSizeNoData(t *testing.T) {
+	for _, output := range []sizeEstimator{NewCSVOutput(), NewJSONOutput()} {
+		format := output.SupportedFormats()[0]
+		_, err := output.EstimateSize(entities.NewProcessing(nil, "size"), interfaces.OutputConfig{Format: format})
+		if !domainerrors.IsDataProcessError(err) {
+			t.Errorf("%s EstimateSize() error = %v, want a DataProcessError", format, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strconv"
+)
+
+// totalsOptions holds the parsed options of the totals footer row
+// The footer row is always computed over all rows, so it is shown even if the preview is truncated by maxRows.
+type totalsOptions struct {
+	show   bool
+	label  string
+	method string
+}
+
+// totalsFormatOptions describes the options of the totals footer row shared by the text-based outputs
+var totalsFormatOptions = map[string]string{
+	"showTotals":   "Append a totals footer row computed over numeric columns (default: false)",
+	"totalsLabel":  "Label placed in the first column of the totals row (default: \"Total\")",
+	"totalsMethod": "Method computing the totals; sum or avg (default: sum)",
+}
+
+// parseTotalsOptions reads and validates the totals footer options of the config.
+// Missing options are treated as their defaults.
+func parseTotalsOptions(config interfaces.OutputConfig) (totalsOptions, error) {
+	options := totalsOptions{
+		show:   false,
+		label:  "Total",
+		method: "sum",
+	}
+
+	show, err := config.OptionBoolStrict("showTotals", options.show)
+	if err != nil {
+		return options, err
+	}
+	options.show = show
+
+	label, err := config.OptionStringStrict("totalsLabel", options.label)
+	if err != nil {
+		return options, err
+	}
+	options.label = label
+
+	if value, ok := config.Options["totalsMethod"]; ok {
+		method, _ := value.(string)
+		if method != "sum" && method != "avg" {
+			return options, domainerrors.NewConfigurationError("options.totalsMethod", fmt.Sprintf("totalsMethod must be one of [sum avg], got %v", value), nil)
+		}
+		options.method = method
+	}
+
+	return options, nil
+}
+
+// totalsRow computes the totals footer row over all rows of the DataFrame.
+// Numeric columns get the sum or average of the non-null values, and the other columns are blank.
+// The label overwrites the first column. Returns nil if the totals row is disabled.
+func totalsRow(df *dataframe.DataFrame, options totalsOptions) []string {
+	if !options.show || df.Ncol() == 0 {
+		return nil
+	}
+
+	row := make([]string, df.Ncol())
+	for j, t := range df.Types() {
+		if t != series.Int && t != series.Float {
+			continue
+		}
+
+		column := df.Col(df.Names()[j])
+		sum := 0.0
+		count := 0
+		for i := 0; i < column.Len(); i++ {
+			element := column.Elem(i)
+			if element.IsNA() {
+				continue
+			}
+			sum += element.Float()
+			count++
+		}
+
+		value := sum
+		if options.method == "avg" {
+			if count == 0 {
+				continue
+			}
+			value = sum / float64(count)
+		}
+
+		if t == series.Int && options.method == "sum" {
+			row[j] = strconv.FormatInt(int64(value), 10)
+		} else {
+			row[j] = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+	row[0] = options.label
+
+	return row
+}
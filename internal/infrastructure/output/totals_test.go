@@ -0,0 +1,85 @@
+package output
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTotalsRow(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "count", "amount"},
+		[]string{"east", "2", "10.5"},
+		[]string{"west", "3", ""},
+		[]string{"north", "7", "4.25"},
+	)
+
+	tests := []struct {
+		name    string
+		options totalsOptions
+		want    []string
+	}{
+		{name: "disabled", options: totalsOptions{show: false, label: "Total", method: "sum"}, want: nil},
+		{name: "sum", options: totalsOptions{show: true, label: "Total", method: "sum"}, want: []string{"Total", "12", "14.75"}},
+		{name: "avg", options: totalsOptions{show: true, label: "Average", method: "avg"}, want: []string{"Average", "4", "7.375"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := totalsRow(df, tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("totalsRow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalsRowNonNumericBlank(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "name", "amount"},
+		[]string{"1", "Alice", "10"},
+		[]string{"2", "Bob", "20"},
+	)
+
+	// The label overwrites the first column even if it is numeric, and the text columns are blank
+	want := []string{"Total", "", "30"}
+	if got := totalsRow(df, totalsOptions{show: true, label: "Total", method: "sum"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("totalsRow() = %q, want %q", got, want)
+	}
+}
+
+func TestConsolePreviewTotalsOverAllRows(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount"},
+		[]string{"Alice", "10"},
+		[]string{"Bob", "20"},
+		[]string{"Carol", "30"},
+	)
+	config := interfaces.OutputConfig{Format: "console", Options: map[string]interface{}{"border": "none", "showTotals": true}}
+
+	preview, err := NewConsoleOutput().Preview(entities.NewProcessing(df, "totals"), config, 1)
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+
+	want := "name   amount\nAlice      10\nTotal      60\n(showing 1 of 3 rows)\n"
+	if preview != want {
+		t.Errorf("Preview() = %q, want %q", preview, want)
+	}
+	if strings.Contains(preview, "Bob") {
+		t.Errorf("Preview() = %q, want the rows truncated by maxRows", preview)
+	}
+}
+
+func TestParseTotalsOptionsInvalid(t *testing.T) {
+	for _, options := range []map[string]interface{}{
+		{"showTotals": "yes"},
+		{"totalsMethod": "median"},
+		{"totalsLabel": 1},
+	} {
+		if _, err := parseTotalsOptions(interfaces.OutputConfig{Options: options}); err == nil {
+			t.Errorf("parseTotalsOptions() error = nil, want an error for the options %v", options)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"io"
+	"maps"
+)
+
+// Ensure WriterOutput implements the Output interface
+var _ interfaces.Output = (*WriterOutput)(nil)
+
+// WriterOutput renders the result in the configured format directly to an io.Writer such as http.ResponseWriter.
+// The Destination of the config is ignored, and the options are the same as the file output of each format
+// except that the sidecar metadata is not supported because there is no destination.
+type WriterOutput struct {
+	writer io.Writer
+}
+
+// NewWriterOutput creates a new WriterOutput writing to the given writer.
+func NewWriterOutput(writer io.Writer) *WriterOutput {
+	return &WriterOutput{
+		writer: writer,
+	}
+}
+
+// Write renders the DataFrame in the format of the config and writes it to the writer.
+func (o *WriterOutput) Write(ctx context.Context, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := o.Validate(config); err != nil {
+		return err
+	}
+
+	if config.Format == "json" {
+		options, _ := parseJSONOptions(config)
+		if _, err := requireMetadata(config, options.metadata); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	if df == nil {
+		return domainerrors.NewDataProcessError("output", "no data to write", nil)
+	}
+
+	if err := renderFormat(o.writer, df, config); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to write %s", config.Format), err)
+	}
+	if err := flushWriter(o.writer); err != nil {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("failed to flush %s", config.Format), err)
+	}
+
+	return nil
+}
+
+// WriteStream writes the streamed rows to the writer. Only the csv format supports streaming,
+// and the rows already written remain in the writer on error or cancellation.
+func (o *WriterOutput) WriteStream(ctx context.Context, rows <-chan []string, header []string, config interfaces.OutputConfig) error {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := o.Validate(config); err != nil {
+		return err
+	}
+	if config.Format != "csv" {
+		return domainerrors.NewDataProcessError("output", fmt.Sprintf("streaming is not supported for %s format", config.Format), nil)
+	}
+
+	options, _ := parseCSVOptions(config)
+	if options.totals.show {
+		return domainerrors.NewConfigurationError("options.showTotals", "showTotals is not supported for streaming output", nil)
+	}
+	if options.numbers.enabled() {
+		return domainerrors.NewConfigurationError("options", "number formatting is not supported for streaming output because the column types are unknown", nil)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domainerrors.NewDataProcessError("output", "output is canceled", err)
+	}
+
+	if err := writeCSVStream(ctx, o.writer, rows, header, options); err != nil {
+		return err
+	}
+	if err := flushWriter(o.writer); err != nil {
+		return domainerrors.NewDataProcessError("output", "failed to flush csv", err)
+	}
+
+	return nil
+}
+
+// Close flushes the writer if it is buffered. The writer is not closed because WriterOutput doesn't own it.
+func (o *WriterOutput) Close() error {
+	if err := flushWriter(o.writer); err != nil {
+		return domainerrors.NewDataProcessError("output", "failed to flush the writer", err)
+	}
+
+	return nil
+}
+
+// Validate checks the config has a supported format and valid options of the format.
+func (o *WriterOutput) Validate(config interfaces.OutputConfig) error {
+	switch config.Format {
+	case "csv":
+		options, err := parseCSVOptions(config)
+		if err == nil && options.metadata != metadataNone {
+			err = domainerrors.NewConfigurationError("options.includeMetadata", "metadata is not supported for csv writer output", nil)
+		}
+		if err == nil && options.partitionBy != "" {
+			err = domainerrors.NewConfigurationError("options.partitionBy", "partitionBy is not supported for writer output", nil)
+		}
+		return err
+	case "json":
+		options, err := parseJSONOptions(config)
+		if err == nil && options.metadata == metadataSidecar {
+			err = domainerrors.NewConfigurationError("options.includeMetadata", "sidecar metadata is not supported for writer output, use embed instead", nil)
+		}
+		if err == nil && options.partitionBy != "" {
+			err = domainerrors.NewConfigurationError("options.partitionBy", "partitionBy is not supported for writer output", nil)
+		}
+		return err
+	default:
+		return domainerrors.NewConfigurationError("format", fmt.Sprintf("unsupported format '%s' for writer output, supported: %v", config.Format, o.SupportedFormats()), nil)
+	}
+}
+
+// SupportedFormats returns the output formats supported by WriterOutput.
+func (o *WriterOutput) SupportedFormats() []string {
+	return []string{"csv", "json"}
+}
+
+// GetFormatOptions returns the available options of the format and their descriptions.
+func (o *WriterOutput) GetFormatOptions(format string) map[string]string {
+	switch format {
+	case "csv":
+		return NewCSVOutput().GetFormatOptions(format)
+	case "json":
+		return maps.Clone(jsonFormatOptions)
+	default:
+		return map[string]string{}
+	}
+}
+
+// Preview renders the result data up to maxRows rows (0 for all) in the format of the config.
+func (o *WriterOutput) Preview(result *entities.Processing, config interfaces.OutputConfig, maxRows int) (string, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := o.Validate(config); err != nil {
+		return "", err
+	}
+
+	if result == nil || result.Data == nil {
+		return "", domainerrors.NewDataProcessError("preview", "no data to preview", nil)
+	}
+
+	if config.Format == "csv" {
+		return NewCSVOutput().Preview(result, config, maxRows)
+	}
+
+	// The metadata of the previewed result is embedded unless the caller provides other metadata
+	if config.Metadata == nil {
+		config.Metadata = &result.Metadata
+	}
+
+	df := result.Data
+	if maxRows > 0 && df.Nrow() > maxRows {
+		indexes := make([]int, maxRows)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		head := df.Subset(indexes)
+		df = &head
+	}
+
+	var buf bytes.Buffer
+	if err := renderFormat(&buf, df, config); err != nil {
+		return "", domainerrors.NewDataProcessError("preview", fmt.Sprintf("failed to render %s", config.Format), err)
+	}
+
+	return buf.String(), nil
+}
+
+// EstimateSize estimates the byte size of the result data rendered in the format of the config.
+func (o *WriterOutput) EstimateSize(result *entities.Processing, config interfaces.OutputConfig) (int64, error) {
+	interfaces.ApplyOutputDefaults(&config)
+	if err := o.Validate(config); err != nil {
+		return 0, err
+	}
+
+	// The metadata of the estimated result is embedded unless the caller provides other metadata
+	if result != nil && config.Metadata == nil {
+		config.Metadata = &result.Metadata
+	}
+
+	return estimateSize(result, func(w io.Writer, df *dataframe.DataFrame) error {
+		return renderFormat(w, df, config)
+	})
+}
+
+// PreviewStructured returns the structured preview of the result data up to maxRows rows (0 for all).
+func (o *WriterOutput) PreviewStructured(result *entities.Processing, _ interfaces.OutputConfig, maxRows int) (*interfaces.PreviewResult, error) {
+	return buildPreviewResult(result, maxRows)
+}
+
+// renderFormat renders the whole DataFrame to the writer in the format of the validated config.
+func renderFormat(w io.Writer, df *dataframe.DataFrame, config interfaces.OutputConfig) error {
+	switch config.Format {
+	case "csv":
+		options, err := parseCSVOptions(config)
+		if err != nil {
+			return err
+		}
+
+		return writeCSV(w, csvRecords(df, -1, options), options)
+	case "json":
+		options, err := parseJSONOptions(config)
+		if err != nil {
+			return err
+		}
+
+		if options.metadata == metadataEmbed {
+			metadata, err := requireMetadata(config, options.metadata)
+			if err != nil {
+				return err
+			}
+			return writeJSONWithMetadata(w, df, metadata, options)
+		}
+
+		return writeJSON(w, df, options)
+	default:
+		return fmt.Errorf("unsupported format '%s'", config.Format)
+	}
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"testing"
+)
+
+func TestWriterOutputMatchesFileOutput(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "amount", "note"},
+		[]string{"Alice", "10.5", "a;b"},
+		[]string{"Bob", "", `say "hi"`},
+	)
+
+	tests := []struct {
+		name    string
+		file    interfaces.Output
+		format  string
+		options map[string]interface{}
+	}{
+		{name: "csv", file: NewCSVOutput(), format: "csv"},
+		{name: "csv options", file: NewCSVOutput(), format: "csv", options: map[string]interface{}{"delimiter": ";", "quoteAll": true, "showTotals": true}},
+		{name: "json", file: NewJSONOutput(), format: "json"},
+		{name: "json options", file: NewJSONOutput(), format: "json", options: map[string]interface{}{"indent": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := writeOutputFile(t, tt.file, df, tt.format, tt.options)
+
+			var buffer bytes.Buffer
+			output := NewWriterOutput(&buffer)
+			config := interfaces.OutputConfig{Format: tt.format, Options: tt.options}
+			if err := output.Write(context.Background(), df, config); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := output.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if got := buffer.String(); got != want {
+				t.Errorf("written %s = %q, want the file output %q", tt.format, got, want)
+			}
+		})
+	}
+}
+
+func TestWriterOutputInvalid(t *testing.T) {
+	df := loadFrame([]string{"name"}, []string{"Alice"})
+
+	tests := []struct {
+		name   string
+		config interfaces.OutputConfig
+	}{
+		{name: "unknown format", config: interfaces.OutputConfig{Format: "xml"}},
+		{name: "sidecar metadata", config: interfaces.OutputConfig{Format: "json", Options: map[string]interface{}{"includeMetadata": "sidecar"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := NewWriterOutput(&buffer).Write(context.Background(), df, tt.config); err == nil {
+				t.Error("Write() error = nil, want an error")
+			}
+			if buffer.Len() != 0 {
+				t.Errorf("Write() wrote %q, want nothing", buffer.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Arithmetic expression grammar of the computed columns:
+//
+//	expression := product { ("+" | "-") product }
+//	product    := unary { ("*" | "/") unary }
+//	unary      := "-" unary | primary
+//	primary    := number | column | "(" expression ")"
+//	number     := digit+ ["." digit+]
+//	column     := identifier | quoted
+//	identifier := (letter | "_") (letter | digit | "_" | ".")*
+//	quoted     := '"' chars '"' | "'" chars "'" (backslash escapes the next character)
+//
+// e.g. `(revenue - cost) / revenue * 100`
+
+// DivideByZeroFunc decides the result of a division by zero: the value, false for null, or an error aborting the evaluation.
+type DivideByZeroFunc func() (float64, bool, error)
+
+// ArithmeticNode represents a node of the parsed arithmetic expression.
+type ArithmeticNode interface {
+	// Evaluate computes the value of the node with the column values provided by lookup.
+	// Returns false if the result is null; a null column value makes the whole result null.
+	// The result of a division by zero is decided by divideByZero (null if nil), and its error aborts the evaluation.
+	Evaluate(lookup func(column string) (float64, bool), divideByZero DivideByZeroFunc) (float64, bool, error)
+
+	// Columns returns the column names referenced by the node in order of appearance.
+	Columns() []ColumnReference
+}
+
+// ColumnReference represents a column referenced by the expression with its 1-based character position.
+type ColumnReference struct {
+	Name     string
+	Position int
+}
+
+// numberNode represents a numeric literal
+type numberNode struct {
+	value float64
+}
+
+// columnNode represents a column reference
+type columnNode struct {
+	reference ColumnReference
+}
+
+// negateNode represents the unary minus
+type negateNode struct {
+	operand ArithmeticNode
+}
+
+// binaryNode represents a binary arithmetic operation
+type binaryNode struct {
+	operator    rune
+	left, right ArithmeticNode
+}
+
+// Evaluate implements ArithmeticNode
+func (n *numberNode) Evaluate(_ func(string) (float64, bool), _ DivideByZeroFunc) (float64, bool, error) {
+	return n.value, true, nil
+}
+
+// Columns implements ArithmeticNode
+func (n *numberNode) Columns() []ColumnReference {
+	return nil
+}
+
+// Evaluate implements ArithmeticNode
+func (n *columnNode) Evaluate(lookup func(string) (float64, bool), _ DivideByZeroFunc) (float64, bool, error) {
+	value, ok := lookup(n.reference.Name)
+	return value, ok, nil
+}
+
+// Columns implements ArithmeticNode
+func (n *columnNode) Columns() []ColumnReference {
+	return []ColumnReference{n.reference}
+}
+
+// Evaluate implements ArithmeticNode
+func (n *negateNode) Evaluate(lookup func(string) (float64, bool), divideByZero DivideByZeroFunc) (float64, bool, error) {
+	value, ok, err := n.operand.Evaluate(lookup, divideByZero)
+	return -value, ok, err
+}
+
+// Columns implements ArithmeticNode
+func (n *negateNode) Columns() []ColumnReference {
+	return n.operand.Columns()
+}
+
+// Evaluate implements ArithmeticNode
+func (n *binaryNode) Evaluate(lookup func(string) (float64, bool), divideByZero DivideByZeroFunc) (float64, bool, error) {
+	left, ok, err := n.left.Evaluate(lookup, divideByZero)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+	right, ok, err := n.right.Evaluate(lookup, divideByZero)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+
+	switch n.operator {
+	case '+':
+		return left + right, true, nil
+	case '-':
+		return left - right, true, nil
+	case '*':
+		return left * right, true, nil
+	case '/':
+		if right == 0 {
+			if divideByZero == nil {
+				return 0, false, nil
+			}
+			return divideByZero()
+		}
+		return left / right, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// Columns implements ArithmeticNode
+func (n *binaryNode) Columns() []ColumnReference {
+	return append(n.left.Columns(), n.right.Columns()...)
+}
+
+// arithmeticParser is a recursive descent parser of the arithmetic expression
+type arithmeticParser struct {
+	tokens []token
+	index  int
+}
+
+// ParseArithmeticExpression parses the arithmetic expression of a computed column.
+// Returns a SyntaxError with the position of the invalid part if the expression is malformed.
+func ParseArithmeticExpression(expression string) (ArithmeticNode, error) {
+	tokens, err := tokenizeArithmetic(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &arithmeticParser{tokens: tokens}
+	node, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, unexpected(t, "operator")
+	}
+
+	return node, nil
+}
+
+// peek returns the current token without consuming it.
+func (p *arithmeticParser) peek() token {
+	return p.tokens[p.index]
+}
+
+// next consumes and returns the current token. The EOF token is never consumed.
+func (p *arithmeticParser) next() token {
+	t := p.tokens[p.index]
+	if t.kind != tokenEOF {
+		p.index++
+	}
+
+	return t
+}
+
+// parseExpression parses the additive expression.
+func (p *arithmeticParser) parseExpression() (ArithmeticNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == tokenSymbol && (t.text == "+" || t.text == "-"); t = p.peek() {
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{operator: rune(t.text[0]), left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseProduct parses the multiplicative expression.
+func (p *arithmeticParser) parseProduct() (ArithmeticNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == tokenSymbol && (t.text == "*" || t.text == "/"); t = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{operator: rune(t.text[0]), left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseUnary parses the unary minus and the primary expression.
+func (p *arithmeticParser) parseUnary() (ArithmeticNode, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenSymbol && t.text == "-":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateNode{operand: operand}, nil
+	case t.kind == tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, &SyntaxError{Position: t.position, Message: fmt.Sprintf("malformed number '%s'", t.text)}
+		}
+		return &numberNode{value: value}, nil
+	case t.kind == tokenIdentifier || t.kind == tokenQuoted:
+		return &columnNode{reference: ColumnReference{Name: t.text, Position: t.position}}, nil
+	case t.kind == tokenSymbol && t.text == "(":
+		node, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenSymbol || closing.text != ")" {
+			return nil, unexpected(closing, "')'")
+		}
+		return node, nil
+	default:
+		return nil, unexpected(t, "number, column name, or '('")
+	}
+}
+
+// tokenizeArithmetic splits the arithmetic expression into the tokens terminated by the EOF token.
+func tokenizeArithmetic(expression string) ([]token, error) {
+	runes := []rune(expression)
+	tokens := make([]token, 0)
+
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		position := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			text, length, err := readQuoted(runes[i:], position)
+			if err != nil {
+				return nil, err
+			}
+			i += length
+			tokens = append(tokens, token{kind: tokenQuoted, text: text, position: position})
+		case strings.ContainsRune("+-*/()", r):
+			i++
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(r), position: position})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			if strings.Count(text, ".") > 1 || strings.HasSuffix(text, ".") {
+				return nil, &SyntaxError{Position: position, Message: fmt.Sprintf("malformed number '%s'", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, position: position})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && isIdentifierRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: string(runes[start:i]), position: position})
+		default:
+			return nil, &SyntaxError{Position: position, Message: fmt.Sprintf("unexpected character '%c'", r)}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, position: len(runes) + 1})
+
+	return tokens, nil
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseArithmeticExpression(t *testing.T) {
+	values := map[string]float64{"revenue": 200, "cost": 150, "unit count": 4}
+	lookup := func(column string) (float64, bool) {
+		value, ok := values[column]
+		return value, ok
+	}
+
+	tests := []struct {
+		expression string
+		want       float64
+		columns    []ColumnReference
+	}{
+		{expression: "1 + 2 * 3", want: 7},
+		{expression: "(1 + 2) * 3", want: 9},
+		{expression: "10 - 4 - 3", want: 3},
+		{expression: "12 / 3 / 2", want: 2},
+		{expression: "-2 * -3", want: 6},
+		{
+			expression: "revenue - cost",
+			want:       50,
+			columns:    []ColumnReference{{Name: "revenue", Position: 1}, {Name: "cost", Position: 11}},
+		},
+		{
+			expression: `(revenue - cost) / revenue * 100`,
+			want:       25,
+			columns:    []ColumnReference{{Name: "revenue", Position: 2}, {Name: "cost", Position: 12}, {Name: "revenue", Position: 20}},
+		},
+		{
+			expression: `cost / "unit count"`,
+			want:       37.5,
+			columns:    []ColumnReference{{Name: "cost", Position: 1}, {Name: "unit count", Position: 8}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			node, err := ParseArithmeticExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseArithmeticExpression() error = %v", err)
+			}
+
+			got, ok, err := node.Evaluate(lookup, nil)
+			if err != nil || !ok || got != tt.want {
+				t.Errorf("Evaluate() = (%v, %v, %v), want (%v, true, nil)", got, ok, err, tt.want)
+			}
+			if columns := node.Columns(); !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("Columns() = %v, want %v", columns, tt.columns)
+			}
+		})
+	}
+}
+
+func TestArithmeticNullAndDivideByZero(t *testing.T) {
+	lookup := func(column string) (float64, bool) {
+		return 0, column == "zero"
+	}
+
+	node, err := ParseArithmeticExpression("1 / zero")
+	if err != nil {
+		t.Fatalf("ParseArithmeticExpression() error = %v", err)
+	}
+	if _, ok, err := node.Evaluate(lookup, nil); ok || err != nil {
+		t.Errorf("Evaluate() without divideByZero = (%v, %v), want null", ok, err)
+	}
+	if value, ok, err := node.Evaluate(lookup, func() (float64, bool, error) { return 0, true, nil }); !ok || err != nil || value != 0 {
+		t.Errorf("Evaluate() with zero = (%v, %v, %v), want (0, true, nil)", value, ok, err)
+	}
+	failure := errors.New("division by zero")
+	if _, _, err := node.Evaluate(lookup, func() (float64, bool, error) { return 0, false, failure }); !errors.Is(err, failure) {
+		t.Errorf("Evaluate() error = %v, want %v", err, failure)
+	}
+
+	// A null operand makes the whole result null
+	node, err = ParseArithmeticExpression("missing + 1")
+	if err != nil {
+		t.Fatalf("ParseArithmeticExpression() error = %v", err)
+	}
+	if _, ok, _ := node.Evaluate(lookup, nil); ok {
+		t.Error("Evaluate() with a null operand ok = true, want null")
+	}
+}
+
+func TestParseArithmeticExpressionSyntaxError(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+	}{
+		{expression: "", position: 1},
+		{expression: "1 +", position: 4},
+		{expression: "(revenue - cost", position: 16},
+		{expression: "revenue cost", position: 9},
+		{expression: "revenue % 2", position: 9},
+		{expression: "1.2.3 + x", position: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := ParseArithmeticExpression(tt.expression)
+
+			var syntaxError *SyntaxError
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("ParseArithmeticExpression() error = %v, want a SyntaxError", err)
+			}
+			if syntaxError.Position != tt.position {
+				t.Errorf("SyntaxError.Position = %d, want %d (%v)", syntaxError.Position, tt.position, err)
+			}
+		})
+	}
+}
@@ -1 +1,249 @@
 package parser
+
+import (
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"strings"
+	"unicode"
+)
+
+// Filter expression grammar:
+//
+//	expression := term { logical term }
+//	term       := column operator value
+//	logical    := "and" | "or" (case-insensitive)
+//	column     := identifier | quoted
+//	operator   := "eq" | "neq" | "gt" | "gte" | "lt" | "lte" | "==" | "!=" | ">" | ">=" | "<" | "<="
+//	value      := identifier | number | quoted
+//	identifier := (letter | digit | "_" | ".")+
+//	number     := ["-"] digit+ ["." digit+]
+//	quoted     := '"' chars '"' | "'" chars "'" (backslash escapes the next character)
+//
+// e.g. `price >= 100 and "customer name" eq 'ACME Inc.' or status == paid`
+// "and" takes precedence over "or" in the same way as the filter configurations.
+
+// symbolOperators maps the symbolic operators to the named operators used by FilterConfig
+var symbolOperators = map[string]string{
+	"==": "eq",
+	"!=": "neq",
+	">":  "gt",
+	">=": "gte",
+	"<":  "lt",
+	"<=": "lte",
+}
+
+// SyntaxError represents an error in the filter expression at the 1-based character position.
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Position, e.Message)
+}
+
+// Term represents a single comparison in the filter expression with the positions of its parts.
+type Term struct {
+	Column           string
+	Operator         string
+	Value            string
+	LogicalOperator  string // LogicalOperator represents the way how to combine the next term (empty for the last term)
+	ColumnPosition   int
+	OperatorPosition int
+	ValuePosition    int
+}
+
+// tokenKind represents the kind of the token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenQuoted
+	tokenSymbol
+)
+
+// token represents a lexical token with its 1-based character position
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// ParseFilterExpression parses the filter expression into the terms.
+// Symbolic operators are converted to the named ones, but the operators are not validated here.
+func ParseFilterExpression(expression string) ([]Term, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make([]Term, 0)
+	i := 0
+	next := func() token {
+		t := tokens[i]
+		if t.kind != tokenEOF {
+			i++
+		}
+		return t
+	}
+
+	for {
+		column := next()
+		if column.kind != tokenIdentifier && column.kind != tokenQuoted {
+			return nil, unexpected(column, "column name")
+		}
+
+		operator := next()
+		if operator.kind != tokenIdentifier && operator.kind != tokenSymbol {
+			return nil, unexpected(operator, "operator")
+		}
+		operatorName := operator.text
+		if named, ok := symbolOperators[operatorName]; ok {
+			operatorName = named
+		}
+
+		value := next()
+		if value.kind != tokenIdentifier && value.kind != tokenNumber && value.kind != tokenQuoted {
+			return nil, unexpected(value, "value")
+		}
+
+		term := Term{
+			Column:           column.text,
+			Operator:         operatorName,
+			Value:            value.text,
+			ColumnPosition:   column.position,
+			OperatorPosition: operator.position,
+			ValuePosition:    value.position,
+		}
+
+		logical := next()
+		if logical.kind == tokenEOF {
+			terms = append(terms, term)
+			return terms, nil
+		}
+
+		lower := strings.ToLower(logical.text)
+		if logical.kind != tokenIdentifier || (lower != "and" && lower != "or") {
+			return nil, unexpected(logical, "'and' or 'or'")
+		}
+		term.LogicalOperator = lower
+		terms = append(terms, term)
+	}
+}
+
+// ToFilterConfigs converts the terms into the filter configurations.
+// The last term is combined with "and", which is ignored by the filter evaluation.
+func ToFilterConfigs(terms []Term) []entities.FilterConfig {
+	filters := make([]entities.FilterConfig, len(terms))
+	for i, term := range terms {
+		logicalOperator := term.LogicalOperator
+		if logicalOperator == "" {
+			logicalOperator = "and"
+		}
+
+		filters[i] = entities.FilterConfig{
+			Column:          term.Column,
+			Value:           term.Value,
+			Operator:        term.Operator,
+			LogicalOperator: logicalOperator,
+		}
+	}
+
+	return filters
+}
+
+// unexpected returns the SyntaxError for the unexpected token.
+func unexpected(t token, expected string) *SyntaxError {
+	if t.kind == tokenEOF {
+		return &SyntaxError{Position: t.position, Message: fmt.Sprintf("unexpected end of expression, expected %s", expected)}
+	}
+
+	return &SyntaxError{Position: t.position, Message: fmt.Sprintf("unexpected '%s', expected %s", t.text, expected)}
+}
+
+// tokenize splits the expression into the tokens terminated by the EOF token.
+func tokenize(expression string) ([]token, error) {
+	runes := []rune(expression)
+	tokens := make([]token, 0)
+
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		position := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			text, length, err := readQuoted(runes[i:], position)
+			if err != nil {
+				return nil, err
+			}
+			i += length
+			tokens = append(tokens, token{kind: tokenQuoted, text: text, position: position})
+		case strings.ContainsRune("=!<>", r):
+			text := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				text += "="
+			}
+			if _, ok := symbolOperators[text]; !ok {
+				return nil, &SyntaxError{Position: position, Message: fmt.Sprintf("unknown operator '%s'", text)}
+			}
+			i += len([]rune(text))
+			tokens = append(tokens, token{kind: tokenSymbol, text: text, position: position})
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "-" || strings.Count(text, ".") > 1 || strings.HasSuffix(text, ".") {
+				return nil, &SyntaxError{Position: position, Message: fmt.Sprintf("malformed number '%s'", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, position: position})
+		case isIdentifierRune(r):
+			start := i
+			for i < len(runes) && isIdentifierRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: string(runes[start:i]), position: position})
+		default:
+			return nil, &SyntaxError{Position: position, Message: fmt.Sprintf("unexpected character '%c'", r)}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, position: len(runes) + 1})
+
+	return tokens, nil
+}
+
+// readQuoted reads the quoted string at the head of the runes and returns the unquoted text and the consumed length.
+// A backslash escapes the next character.
+func readQuoted(runes []rune, position int) (string, int, error) {
+	quote := runes[0]
+	var builder strings.Builder
+	for i := 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				builder.WriteRune(runes[i])
+			}
+		case quote:
+			return builder.String(), i + 1, nil
+		default:
+			builder.WriteRune(runes[i])
+		}
+	}
+
+	return "", 0, &SyntaxError{Position: position, Message: "unterminated quoted string"}
+}
+
+// isIdentifierRune checks if the rune can be a part of the identifier.
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
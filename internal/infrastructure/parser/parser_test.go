@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []Term
+	}{
+		{
+			name:       "single",
+			expression: "price >= 100",
+			want: []Term{
+				{Column: "price", Operator: "gte", Value: "100", ColumnPosition: 1, OperatorPosition: 7, ValuePosition: 10},
+			},
+		},
+		{
+			name:       "quoted and logical",
+			expression: `"customer name" eq 'ACME Inc.' OR status == paid`,
+			want: []Term{
+				{Column: "customer name", Operator: "eq", Value: "ACME Inc.", LogicalOperator: "or", ColumnPosition: 1, OperatorPosition: 17, ValuePosition: 20},
+				{Column: "status", Operator: "eq", Value: "paid", ColumnPosition: 35, OperatorPosition: 42, ValuePosition: 45},
+			},
+		},
+		{
+			name:       "negative number and escape",
+			expression: `delta < -1.5 and note != 'it\'s'`,
+			want: []Term{
+				{Column: "delta", Operator: "lt", Value: "-1.5", LogicalOperator: "and", ColumnPosition: 1, OperatorPosition: 7, ValuePosition: 9},
+				{Column: "note", Operator: "neq", Value: "it's", ColumnPosition: 18, OperatorPosition: 23, ValuePosition: 26},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilterExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseFilterExpression() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilterExpression() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterExpressionSyntaxError(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		position   int
+	}{
+		{name: "empty", expression: "", position: 1},
+		{name: "missing value", expression: "price >=", position: 9},
+		{name: "missing logical", expression: "price > 1 status eq paid", position: 11},
+		{name: "unknown symbol", expression: "price => 1", position: 7},
+		{name: "unterminated quote", expression: "name eq 'ACME", position: 9},
+		{name: "malformed number", expression: "price gt 1.2.3", position: 10},
+		{name: "unexpected character", expression: "price gt 1 & x", position: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilterExpression(tt.expression)
+
+			var syntaxError *SyntaxError
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("ParseFilterExpression() error = %v, want a SyntaxError", err)
+			}
+			if syntaxError.Position != tt.position {
+				t.Errorf("SyntaxError.Position = %d, want %d (%v)", syntaxError.Position, tt.position, err)
+			}
+		})
+	}
+}
+
+func TestToFilterConfigs(t *testing.T) {
+	terms, err := ParseFilterExpression("a eq 1 or b neq x")
+	if err != nil {
+		t.Fatalf("ParseFilterExpression() error = %v", err)
+	}
+
+	filters := ToFilterConfigs(terms)
+	if got, want := []string{filters[0].LogicalOperator, filters[1].LogicalOperator}, []string{"or", "and"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LogicalOperators = %v, want %v", got, want)
+	}
+	for i, filter := range filters {
+		if err := filter.Validate(); err != nil {
+			t.Errorf("filters[%d].Validate() error = %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package processor
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// groupIndex holds the rows of each group in the order of the first occurrence of the group (see sortGroups).
+type groupIndex struct {
+	firstRows []int
+	rows      [][]int
+}
+
+// Aggregate applies the aggregation configurations in order, and each configuration aggregates the result of the previous one.
+// The result of each configuration has the grouping columns followed by the passthrough columns and the aggregation result columns.
+// The groups are sorted by the values of the grouping columns in order (see sortGroups), so the row order of the result
+// is deterministic regardless of the row order of the input and the workers.
+// Null values are ignored by every method, and a group without any non-null values (or without any rows matching
+// the Condition) results in null (0 for count), as the selection is empty rather than divided by zero.
+// The weightedAvg of a group with the total weight of zero and the sharePercent of the groups whose sum of all groups
+// is zero divide by zero, so they follow the DivideByZeroPolicy of the processor instead, as do the NaN and Inf results
+// of any method.
+// The configurations using only sum, avg, count, min, and max are aggregated in a single pass keeping an accumulator
+// per group (see streamAggregateGroups), so the memory is proportional to the groups instead of the rows,
+// unless the processor disables it (see GotaProcessorOptions.StreamAggregate).
+// The other configurations buffer the rows of each group, and when there are enough groups, the groups are partitioned
+// across the workers of the processor. Each group writes its result to its own position, so the result is identical
+// to the serial aggregation.
+// The context is checked every checkInterval rows in grouping and aggregating, so the cancellation stops it promptly.
+// The progress is reported to the ProgressFunc of the context in the rows scanned by grouping and by each aggregation.
+// The total is estimated from the input rows, so it jumps to the total at the end when a later configuration
+// aggregates the fewer rows of the previous result.
+func (p *GotaProcessor) Aggregate(ctx context.Context, data *dataframe.DataFrame, config []entities.AggregationConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("aggregate", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("aggregate", "aggregation is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("aggregate", "no data to aggregate", nil)
+	}
+
+	passes := 0
+	for _, aggregation := range config {
+		passes += 1 + len(aggregation.Aggregations)
+	}
+	progress := newProgressReporter(ctx, data.Nrow()*passes, p.checkInterval)
+
+	result := data
+	for i := range config {
+		// Validate a copy not to modify the aggregations and the conditions of the caller (see copyAggregationConfig)
+		aggregation := copyAggregationConfig(config[i])
+		if err := aggregation.Validate(); err != nil {
+			return nil, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("aggregation[%d] is invalid", i), err)
+		}
+
+		aggregated, err := p.aggregateGroups(ctx, result, aggregation, progress)
+		if err != nil {
+			return nil, err
+		}
+		result = aggregated
+	}
+	progress.finish()
+
+	return result, nil
+}
+
+// aggregateGroups groups the rows by the grouping columns and computes the aggregations of each group.
+// The configurations of the streamable methods only are aggregated in a single pass (see streamAggregateGroups)
+// when the processor streams them, otherwise the rows of each group are buffered.
+func (p *GotaProcessor) aggregateGroups(ctx context.Context, df *dataframe.DataFrame, config entities.AggregationConfig, progress *progressReporter) (*dataframe.DataFrame, error) {
+	if err := requireColumns("aggregate", df, slices.Concat(config.GroupingColumns, config.PassthroughColumns)...); err != nil {
+		return nil, err
+	}
+	for _, aggregation := range config.Aggregations {
+		if slices.Contains(config.GroupingColumns, aggregation.ResultName) {
+			return nil, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("result name '%s' collides with a grouping column", aggregation.ResultName), nil)
+		}
+		if slices.Contains(config.PassthroughColumns, aggregation.ResultName) {
+			return nil, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("result name '%s' collides with a passthrough column", aggregation.ResultName), nil)
+		}
+	}
+
+	if p.stream && isStreamable(config) {
+		return p.streamAggregateGroups(ctx, df, config, progress)
+	}
+
+	return p.bufferAggregateGroups(ctx, df, config, progress)
+}
+
+// bufferAggregateGroups groups the row indexes by the grouping columns and computes the aggregations over the rows of each group.
+func (p *GotaProcessor) bufferAggregateGroups(ctx context.Context, df *dataframe.DataFrame, config entities.AggregationConfig, progress *progressReporter) (*dataframe.DataFrame, error) {
+	groups, err := buildGroups(ctx, df, config.GroupingColumns, p.checkInterval, progress)
+	if err != nil {
+		return nil, err
+	}
+	sortGroups(df, &groups, config.GroupingColumns)
+
+	columns := groupKeyColumns(df, config, groups.firstRows)
+	for _, aggregation := range config.Aggregations {
+		column, err := p.aggregateColumn(ctx, df, groups, aggregation, progress)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	return buildAggregatedDataFrame(columns)
+}
+
+// groupKeyColumns returns the grouping and the passthrough columns of the groups starting at the firstRows.
+// The passthrough columns take the value of the first row of each group as the grouping columns do.
+func groupKeyColumns(df *dataframe.DataFrame, config entities.AggregationConfig, firstRows []int) []series.Series {
+	columns := make([]series.Series, 0, len(config.GroupingColumns)+len(config.PassthroughColumns)+len(config.Aggregations))
+	for _, name := range slices.Concat(config.GroupingColumns, config.PassthroughColumns) {
+		column := df.Col(name)
+		values := make([]interface{}, len(firstRows))
+		for g, row := range firstRows {
+			if element := column.Elem(row); !isNull(element) {
+				values[g] = element.Val()
+			}
+		}
+		columns = append(columns, series.New(values, column.Type(), name))
+	}
+
+	return columns
+}
+
+// buildAggregatedDataFrame builds the result DataFrame of the aggregation from its columns.
+func buildAggregatedDataFrame(columns []series.Series) (*dataframe.DataFrame, error) {
+	result := dataframe.New(columns...)
+	if result.Err != nil {
+		return nil, domainerrors.NewDataProcessError("aggregate", "failed to build aggregated DataFrame", result.Err)
+	}
+
+	return &result, nil
+}
+
+// buildGroups groups the row indexes by the values of the grouping columns checking the context every checkInterval rows.
+func buildGroups(ctx context.Context, df *dataframe.DataFrame, groupingColumns []string, checkInterval int, progress *progressReporter) (groupIndex, error) {
+	columns := columnsOf(df, groupingColumns)
+	positions := make(map[string]int)
+	groups := groupIndex{}
+
+	checker := newCancellationChecker(ctx, checkInterval)
+	for row := 0; row < df.Nrow(); row++ {
+		if err := checker.tick(1); err != nil {
+			return groupIndex{}, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("aggregation is canceled at row %d", row), err)
+		}
+		progress.add(1)
+
+		key := rowKey(columns, row)
+		position, ok := positions[key]
+		if !ok {
+			position = len(groups.firstRows)
+			positions[key] = position
+			groups.firstRows = append(groups.firstRows, row)
+			groups.rows = append(groups.rows, nil)
+		}
+		groups.rows[position] = append(groups.rows[position], row)
+	}
+
+	return groups, nil
+}
+
+// sortGroups sorts the groups by the values of the grouping columns in order, comparing the first row of each group.
+// Numeric columns are compared as numbers, bool columns with false before true, and the other columns as strings.
+// The null values follow the non-null values.
+func sortGroups(df *dataframe.DataFrame, groups *groupIndex, groupingColumns []string) {
+	order := groupOrder(df, groups.firstRows, groupingColumns)
+
+	firstRows := make([]int, len(order))
+	rows := make([][]int, len(order))
+	for i, g := range order {
+		firstRows[i] = groups.firstRows[g]
+		rows[i] = groups.rows[g]
+	}
+	groups.firstRows, groups.rows = firstRows, rows
+}
+
+// groupOrder returns the positions of the groups starting at the firstRows in the sorted order of sortGroups.
+func groupOrder(df *dataframe.DataFrame, firstRows []int, groupingColumns []string) []int {
+	columns := columnsOf(df, groupingColumns)
+	order := make([]int, len(firstRows))
+	for g := range order {
+		order[g] = g
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		for _, column := range columns {
+			if c := compareGroupKey(column.Elem(firstRows[a]), column.Elem(firstRows[b])); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	return order
+}
+
+// compareGroupKey compares the values of a grouping column like cmp.Compare placing the null values last.
+func compareGroupKey(a, b series.Element) int {
+	aNull, bNull := isNull(a), isNull(b)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull:
+		return 1
+	case bNull:
+		return -1
+	}
+
+	switch a.Type() {
+	case series.Int, series.Float:
+		return cmp.Compare(a.Float(), b.Float())
+	case series.Bool:
+		aBool, _ := a.Bool()
+		bBool, _ := b.Bool()
+		return compareBool(aBool, bBool)
+	default:
+		return strings.Compare(a.String(), b.String())
+	}
+}
+
+// aggregateColumn computes the aggregation of each group and returns the result series.
+func (p *GotaProcessor) aggregateColumn(ctx context.Context, df *dataframe.DataFrame, groups groupIndex, aggregation entities.Aggregation, progress *progressReporter) (series.Series, error) {
+	column, err := aggregatedColumn(df, aggregation)
+	if err != nil {
+		return series.Series{}, err
+	}
+	method := aggregation.AggregateMethod
+
+	var weights series.Series
+	if method == "weightedAvg" {
+		if err := requireColumns("aggregate", df, aggregation.WeightColumn); err != nil {
+			return series.Series{}, err
+		}
+		weights = df.Col(aggregation.WeightColumn)
+		if !isNumeric(weights.Type()) {
+			return series.Series{}, domainerrors.NewDataProcessError(
+				"aggregate",
+				fmt.Sprintf("weightedAvg requires a numeric weight column, but '%s' is %s", aggregation.WeightColumn, weights.Type()),
+				nil,
+			)
+		}
+	}
+
+	var condition []bool
+	if aggregation.Condition != nil {
+		resolved, err := resolveRelativeDates(df, []entities.FilterConfig{*aggregation.Condition}, p.now())
+		if err != nil {
+			return series.Series{}, err
+		}
+		mask, err := buildFilterMask(ctx, df, resolved, p.checkInterval, false)
+		if err != nil {
+			return series.Series{}, err
+		}
+		if condition, err = mask.Bool(); err != nil {
+			return series.Series{}, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("condition of '%s' is invalid", aggregation.ResultName), err)
+		}
+	}
+
+	values := make([]interface{}, len(groups.rows))
+	dividedByZero := make([]bool, len(groups.rows))
+	err = forEachGroup(ctx, len(groups.rows), p.workers, p.checkInterval, func(g int) int {
+		rows := groups.rows[g]
+		if condition != nil {
+			rows = slices.DeleteFunc(slices.Clone(rows), func(row int) bool { return !condition[row] })
+		}
+		if aggregation.Approximate {
+			values[g] = approximateMedian(column, rows)
+		} else {
+			values[g], dividedByZero[g] = aggregateRows(method, column, weights, rows)
+		}
+		progress.add(len(groups.rows[g]))
+
+		return len(groups.rows[g])
+	})
+	if err != nil {
+		return series.Series{}, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("aggregation of '%s' is canceled", aggregation.ResultName), err)
+	}
+	if method == "sharePercent" {
+		sharePercents(values, dividedByZero)
+	}
+
+	return p.aggregationResult(aggregation, values, dividedByZero, groups.firstRows)
+}
+
+// sharePercents converts the sums of the groups to the percentages of the sum of all groups in place.
+// The null sums stay null, and the other groups divide by zero if the sum of all groups is zero.
+func sharePercents(values []interface{}, dividedByZero []bool) {
+	total := 0.0
+	for _, value := range values {
+		if value != nil {
+			total += value.(float64)
+		}
+	}
+
+	for g, value := range values {
+		if value == nil {
+			continue
+		}
+		if total == 0 {
+			values[g], dividedByZero[g] = nil, true
+			continue
+		}
+		values[g] = value.(float64) / total * 100
+	}
+}
+
+// copyAggregationConfig returns a copy of the config sharing nothing Validate modifies with the original,
+// i.e. the Aggregations and their Conditions, which Validate fills with the defaults and the parsed values.
+func copyAggregationConfig(config entities.AggregationConfig) entities.AggregationConfig {
+	config.Aggregations = slices.Clone(config.Aggregations)
+	for i, aggregation := range config.Aggregations {
+		if aggregation.Condition != nil {
+			condition := *aggregation.Condition
+			config.Aggregations[i].Condition = &condition
+		}
+	}
+
+	return config
+}
+
+// aggregatedColumn returns the column of the aggregation, checking it exists and is numeric unless the method is count.
+func aggregatedColumn(df *dataframe.DataFrame, aggregation entities.Aggregation) (series.Series, error) {
+	if err := requireColumns("aggregate", df, aggregation.Column); err != nil {
+		return series.Series{}, err
+	}
+
+	column := df.Col(aggregation.Column)
+	if aggregation.AggregateMethod != "count" && !isNumeric(column.Type()) {
+		return series.Series{}, domainerrors.NewDataProcessError(
+			"aggregate",
+			fmt.Sprintf("%s requires a numeric column, but '%s' is %s", aggregation.AggregateMethod, aggregation.Column, column.Type()),
+			nil,
+		)
+	}
+
+	return column, nil
+}
+
+// aggregationResult builds the result series of the aggregation from the values of the groups starting at the firstRows.
+// The values of the groups dividing by zero and the NaN and Inf values follow the DivideByZeroPolicy of the processor.
+func (p *GotaProcessor) aggregationResult(aggregation entities.Aggregation, values []interface{}, dividedByZero []bool, firstRows []int) (series.Series, error) {
+	method := aggregation.AggregateMethod
+	nonFinite := make([]bool, len(values))
+	for g := range values {
+		nonFinite[g] = isNonFinite(values[g])
+		if dividedByZero[g] || nonFinite[g] {
+			values[g] = p.divideByZero.divideByZeroValue()
+		}
+	}
+
+	if p.divideByZero == DivideByZeroError {
+		// The first group is reported to keep the error independent of the workers
+		for g := range values {
+			if dividedByZero[g] {
+				return series.Series{}, domainerrors.NewDataProcessError(
+					"aggregate",
+					fmt.Sprintf("%s of column '%s' divides by zero in the group starting at row %d", method, aggregation.Column, firstRows[g]+1),
+					nil,
+				)
+			}
+			if nonFinite[g] {
+				return series.Series{}, domainerrors.NewDataProcessError(
+					"aggregate",
+					fmt.Sprintf("%s of column '%s' results in NaN or Inf in the group starting at row %d", method, aggregation.Column, firstRows[g]+1),
+					nil,
+				)
+			}
+		}
+	}
+
+	resultType := series.Float
+	if method == "count" {
+		resultType = series.Int
+	}
+
+	return series.New(values, resultType, aggregation.ResultName), nil
+}
+
+// parallelGroupThreshold is the minimum number of groups aggregated in parallel; fewer groups don't pay off the goroutines
+const parallelGroupThreshold = 256
+
+// forEachGroup calls fn for each group index. The groups are split into contiguous partitions processed by the workers
+// if there are at least parallelGroupThreshold groups. fn must write only to the position of its group and return
+// the number of rows it processed, which drives the context checks every checkInterval rows in each worker.
+// Returns the context error if the context is canceled.
+func forEachGroup(ctx context.Context, groupCount, workers, checkInterval int, fn func(g int) int) error {
+	if workers <= 1 || groupCount < parallelGroupThreshold {
+		checker := newCancellationChecker(ctx, checkInterval)
+		for g := 0; g < groupCount; g++ {
+			if err := checker.tick(fn(g)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	partitionSize := (groupCount + workers - 1) / workers
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for start := 0; start < groupCount; start += partitionSize {
+		end := min(start+partitionSize, groupCount)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checker := newCancellationChecker(ctx, checkInterval)
+			for g := start; g < end; g++ {
+				if err := checker.tick(fn(g)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// aggregateRows computes the aggregation over the rows of the column. Returns nil for the null result,
+// and whether the result divides by zero. The sharePercent method returns the sum of the group (see sharePercents).
+func aggregateRows(method string, column, weights series.Series, rows []int) (interface{}, bool) {
+	if method == "weightedAvg" {
+		weightedSum := 0.0
+		totalWeight := 0.0
+		weighted := false
+		for _, row := range rows {
+			value, weight := column.Elem(row), weights.Elem(row)
+			if isNull(value) || isNull(weight) {
+				continue
+			}
+			weightedSum += value.Float() * weight.Float()
+			totalWeight += weight.Float()
+			weighted = true
+		}
+		if !weighted {
+			return nil, false
+		}
+		if totalWeight == 0 {
+			return nil, true
+		}
+
+		return weightedSum / totalWeight, false
+	}
+
+	if method == "count" {
+		count := 0
+		for _, row := range rows {
+			if !isNull(column.Elem(row)) {
+				count++
+			}
+		}
+
+		return count, false
+	}
+
+	numbers := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		element := column.Elem(row)
+		if isNull(element) {
+			continue
+		}
+		numbers = append(numbers, element.Float())
+	}
+	if len(numbers) == 0 {
+		return nil, false
+	}
+
+	switch method {
+	case "sum", "sharePercent":
+		return sumOf(numbers), false
+	case "avg":
+		return sumOf(numbers) / float64(len(numbers)), false
+	case "min":
+		return slices.Min(numbers), false
+	case "max":
+		return slices.Max(numbers), false
+	case "median":
+		return medianOf(numbers), false
+	default:
+		return nil, false
+	}
+}
+
+// sumOf returns the sum of the numbers.
+func sumOf(numbers []float64) float64 {
+	sum := 0.0
+	for _, number := range numbers {
+		sum += number
+	}
+
+	return sum
+}
+
+// medianOf returns the median of the non-empty numbers; the middle value for an odd count
+// and the average of the two middle values for an even count.
+func medianOf(numbers []float64) float64 {
+	sorted := slices.Clone(numbers)
+	slices.Sort(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
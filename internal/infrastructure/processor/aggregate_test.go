@@ -0,0 +1,407 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// groupBy returns the aggregation config grouping by the column with the aggregations.
+func groupBy(column string, aggregations ...entities.Aggregation) []entities.AggregationConfig {
+	return []entities.AggregationConfig{{GroupingColumns: []string{column}, Aggregations: aggregations}}
+}
+
+func TestAggregateMethods(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "amount", "weight"},
+		[]string{"east", "10", "1"},
+		[]string{"east", "20", "1"},
+		[]string{"east", "60", "2"},
+		[]string{"east", "NaN", "1"},
+		[]string{"west", "4", "1"},
+		[]string{"west", "6", "3"},
+	)
+
+	tests := []struct {
+		aggregation entities.Aggregation
+		east, west  string
+	}{
+		{aggregation: entities.Aggregation{AggregateMethod: "sum"}, east: "90.000000", west: "10.000000"},
+		{aggregation: entities.Aggregation{AggregateMethod: "avg"}, east: "30.000000", west: "5.000000"},
+		{aggregation: entities.Aggregation{AggregateMethod: "min"}, east: "10.000000", west: "4.000000"},
+		{aggregation: entities.Aggregation{AggregateMethod: "max"}, east: "60.000000", west: "6.000000"},
+		{aggregation: entities.Aggregation{AggregateMethod: "count"}, east: "3", west: "2"},
+		{aggregation: entities.Aggregation{AggregateMethod: "median"}, east: "20.000000", west: "5.000000"},
+		{aggregation: entities.Aggregation{AggregateMethod: "weightedAvg", WeightColumn: "weight"}, east: "37.500000", west: "5.500000"},
+		{aggregation: entities.Aggregation{AggregateMethod: "sharePercent"}, east: "90.000000", west: "10.000000"},
+	}
+
+	tested := make(map[string]bool, len(tests))
+	for _, tt := range tests {
+		tested[tt.aggregation.AggregateMethod] = true
+		t.Run(tt.aggregation.AggregateMethod, func(t *testing.T) {
+			tt.aggregation.Column = "amount"
+			tt.aggregation.ResultName = "result"
+			for _, stream := range []bool{true, false} {
+				aggregated, err := NewGotaProcessorWithOptions(GotaProcessorOptions{StreamAggregate: &stream}).Aggregate(context.Background(), df, groupBy("region", tt.aggregation))
+				if err != nil {
+					t.Fatalf("Aggregate(stream=%v) error = %v", stream, err)
+				}
+				assertRecords(t, aggregated, [][]string{
+					{"region", "result"},
+					{"east", tt.east},
+					{"west", tt.west},
+				})
+			}
+		})
+	}
+
+	// Every supported method is covered
+	for _, method := range entities.SupportedAggregateMethods() {
+		if !tested[method] {
+			t.Errorf("method %q is not tested", method)
+		}
+	}
+}
+
+func TestAggregateWeightedAvg(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "price", "quantity"},
+		[]string{"east", "10", "1"},
+		[]string{"east", "20", "3"},
+		[]string{"west", "5", "2"},
+		[]string{"west", "7", "NaN"},
+		[]string{"zero", "4", "0"},
+		[]string{"zero", "8", "0"},
+	)
+	config := groupBy("region", entities.Aggregation{Column: "price", AggregateMethod: "weightedAvg", WeightColumn: "quantity", ResultName: "avgPrice"})
+
+	tests := []struct {
+		policy DivideByZeroPolicy
+		zero   string
+	}{
+		{policy: DivideByZeroNull, zero: "NaN"},
+		{policy: DivideByZeroZero, zero: "0.000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			// (10*1 + 20*3) / 4 for east, and the row without the weight is ignored for west
+			aggregated, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: tt.policy}).Aggregate(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("Aggregate() error = %v", err)
+			}
+			assertRecords(t, aggregated, [][]string{
+				{"region", "avgPrice"},
+				{"east", "17.500000"},
+				{"west", "5.000000"},
+				{"zero", tt.zero},
+			})
+		})
+	}
+
+	t.Run(DivideByZeroError.String(), func(t *testing.T) {
+		_, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError}).Aggregate(context.Background(), df, config)
+		if err == nil {
+			t.Fatal("Aggregate() error = nil, want the error of the zero total weight")
+		}
+	})
+}
+
+func TestAggregateWeightedAvgRequiresNumericWeight(t *testing.T) {
+	df := loadFrame([]string{"region", "price", "quantity"}, []string{"east", "10", "many"})
+	config := groupBy("region", entities.Aggregation{Column: "price", AggregateMethod: "weightedAvg", WeightColumn: "quantity"})
+
+	if _, err := NewGotaProcessor().Aggregate(context.Background(), df, config); err == nil {
+		t.Error("Aggregate() error = nil, want the error of the string weight column")
+	}
+}
+
+func TestAggregateCondition(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "status", "amount"},
+		[]string{"east", "paid", "10"},
+		[]string{"east", "open", "20"},
+		[]string{"east", "paid", "5"},
+		[]string{"west", "open", "7"},
+	)
+	paid := &entities.FilterConfig{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: "and"}
+	config := groupBy("region",
+		entities.Aggregation{Column: "amount", AggregateMethod: "count", ResultName: "paidCount", Condition: paid},
+		entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "paidSum", Condition: paid},
+		entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+	)
+
+	for _, stream := range []bool{true, false} {
+		aggregated, err := NewGotaProcessorWithOptions(GotaProcessorOptions{StreamAggregate: &stream}).Aggregate(context.Background(), df, config)
+		if err != nil {
+			t.Fatalf("Aggregate() error = %v", err)
+		}
+		// No rows of west match, so the count is 0 and the sum is null
+		assertRecords(t, aggregated, [][]string{
+			{"region", "paidCount", "paidSum", "total"},
+			{"east", "2", "15.000000", "35.000000"},
+			{"west", "0", "NaN", "7.000000"},
+		})
+	}
+}
+
+func TestAggregateParallelMatchesSerial(t *testing.T) {
+	// More groups than parallelGroupThreshold to partition them across the workers
+	df := benchmarkData(20_000, 4*parallelGroupThreshold)
+	config := groupBy("key",
+		entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+		entities.Aggregation{Column: "amount", AggregateMethod: "avg", ResultName: "average"},
+		entities.Aggregation{Column: "amount", AggregateMethod: "median", ResultName: "middle"},
+	)
+
+	serial, err := NewGotaProcessorWithWorkers(1).Aggregate(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("serial Aggregate() error = %v", err)
+	}
+	if serial.Nrow() != 4*parallelGroupThreshold {
+		t.Fatalf("serial Aggregate() rows = %d, want %d", serial.Nrow(), 4*parallelGroupThreshold)
+	}
+
+	for _, workers := range []int{2, 3, 8} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			parallel, err := NewGotaProcessorWithWorkers(workers).Aggregate(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("parallel Aggregate() error = %v", err)
+			}
+			assertRecords(t, parallel, serial.Records())
+		})
+	}
+}
+
+func TestForEachGroupVisitsEveryGroupOnce(t *testing.T) {
+	for _, groupCount := range []int{0, 1, parallelGroupThreshold - 1, parallelGroupThreshold, 1000} {
+		visits := make([]int, groupCount)
+		err := forEachGroup(context.Background(), groupCount, 4, 0, func(g int) int {
+			// Each group writes only to its own position, so the workers never race
+			visits[g]++
+			return 1
+		})
+		if err != nil {
+			t.Fatalf("forEachGroup(%d) error = %v", groupCount, err)
+		}
+		for g, count := range visits {
+			if count != 1 {
+				t.Errorf("forEachGroup(%d) visited group %d %d times, want once", groupCount, g, count)
+			}
+		}
+	}
+}
+
+func BenchmarkAggregateWorkers(b *testing.B) {
+	df := benchmarkData(1_000_000, 10_000)
+	config := groupBy("key",
+		entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+		entities.Aggregation{Column: "amount", AggregateMethod: "median", ResultName: "middle"},
+	)
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			processor := NewGotaProcessorWithWorkers(workers)
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := processor.Aggregate(context.Background(), df, config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateMedian(t *testing.T) {
+	df := loadFrame(
+		[]string{"group", "amount"},
+		[]string{"odd", "3"},
+		[]string{"odd", "1"},
+		[]string{"odd", "2"},
+		[]string{"even", "4"},
+		[]string{"even", "1"},
+		[]string{"even", "NaN"},
+		[]string{"even", "3"},
+		[]string{"even", "2"},
+		[]string{"single", "7"},
+		[]string{"null", "NaN"},
+		[]string{"null", "NaN"},
+	)
+
+	for _, approximate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("approximate=%v", approximate), func(t *testing.T) {
+			config := groupBy("group", entities.Aggregation{Column: "amount", AggregateMethod: "median", ResultName: "median", Approximate: approximate})
+			aggregated, err := NewGotaProcessor().Aggregate(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("Aggregate() error = %v", err)
+			}
+
+			// The approximation is exact up to five values, and the null value of even is ignored
+			assertRecords(t, aggregated, [][]string{
+				{"group", "median"},
+				{"even", "2.500000"},
+				{"null", "NaN"},
+				{"odd", "2.000000"},
+				{"single", "7.000000"},
+			})
+		})
+	}
+}
+
+func TestMedianOf(t *testing.T) {
+	tests := []struct {
+		numbers []float64
+		want    float64
+	}{
+		{numbers: []float64{5}, want: 5},
+		{numbers: []float64{9, 1, 5}, want: 5},
+		{numbers: []float64{4, 1, 3, 2}, want: 2.5},
+		{numbers: []float64{-1, -1, 10, 10}, want: 4.5},
+	}
+
+	for _, tt := range tests {
+		numbers := slices.Clone(tt.numbers)
+		if got := medianOf(numbers); got != tt.want {
+			t.Errorf("medianOf(%v) = %v, want %v", tt.numbers, got, tt.want)
+		}
+		if !slices.Equal(numbers, tt.numbers) {
+			t.Errorf("medianOf(%v) modified the numbers to %v", tt.numbers, numbers)
+		}
+	}
+}
+
+func TestAggregateGroupOrder(t *testing.T) {
+	// The numeric keys sort as numbers (2 before 10), the null keys last, and the ties on the first key by the second
+	records := [][]string{
+		{"10", "b", "1"},
+		{"", "a", "2"},
+		{"2", "b", "3"},
+		{"10", "a", "4"},
+		{"2", "", "5"},
+		{"2", "a", "6"},
+		{"10", "b", "7"},
+	}
+	config := []entities.AggregationConfig{{
+		GroupingColumns: []string{"store", "region"},
+		Aggregations:    []entities.Aggregation{{Column: "amount", AggregateMethod: "count", ResultName: "rows"}},
+	}}
+	want := [][]string{
+		{"store", "region", "rows"},
+		{"2", "a", "1"},
+		{"2", "b", "1"},
+		{"2", "NaN", "1"},
+		{"10", "a", "1"},
+		{"10", "b", "2"},
+		{"NaN", "a", "1"},
+	}
+
+	random := rand.New(rand.NewPCG(897, 1))
+	for run := 0; run < 20; run++ {
+		// Every run shuffles the input rows, so the order of the first occurrences of the groups differs
+		shuffled := slices.Clone(records)
+		random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		df := loadFrame(append([][]string{{"store", "region", "amount"}}, shuffled...)...)
+
+		for _, stream := range []bool{true, false} {
+			aggregated, err := NewGotaProcessorWithOptions(GotaProcessorOptions{StreamAggregate: &stream}).Aggregate(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("Aggregate(stream=%v) error = %v", stream, err)
+			}
+			assertRecords(t, aggregated, want)
+		}
+	}
+}
+
+func TestAggregateGroupOrderRepeated(t *testing.T) {
+	// More groups than parallelGroupThreshold to partition them across the workers
+	df := benchmarkData(20_000, 4*parallelGroupThreshold)
+	config := groupBy("key", entities.Aggregation{Column: "amount", AggregateMethod: "median", ResultName: "middle"})
+
+	first, err := NewGotaProcessorWithWorkers(4).Aggregate(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	keys := first.Col("key").Records()
+	if !slices.IsSorted(keys) {
+		t.Errorf("Aggregate() keys are not sorted: %v", keys[:10])
+	}
+
+	for run := 0; run < 10; run++ {
+		again, err := NewGotaProcessorWithWorkers(4).Aggregate(context.Background(), df, config)
+		if err != nil {
+			t.Fatalf("Aggregate() error = %v", err)
+		}
+		assertRecords(t, again, first.Records())
+	}
+}
+
+func TestAggregatePassthroughColumns(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "label", "amount"},
+		[]string{"2", "West", "5"},
+		[]string{"1", "East", "10"},
+		[]string{"1", "East (old)", "20"},
+		[]string{"2", "West", "7"},
+	)
+	config := []entities.AggregationConfig{{
+		GroupingColumns:    []string{"region"},
+		PassthroughColumns: []string{"label"},
+		Aggregations:       []entities.Aggregation{{Column: "amount", AggregateMethod: "sum", ResultName: "total"}},
+	}}
+	// The label of each group is the one of its first row
+	want := [][]string{{"region", "label", "total"}, {"1", "East", "30.000000"}, {"2", "West", "12.000000"}}
+
+	buffered := false
+	for name, options := range map[string]GotaProcessorOptions{
+		"buffered": {Workers: 2, StreamAggregate: &buffered},
+		"stream":   {Workers: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewGotaProcessorWithOptions(options).Aggregate(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("Aggregate() error = %v", err)
+			}
+			assertRecords(t, result, want)
+		})
+	}
+}
+
+func TestAggregatePassthroughColumnsInvalid(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "label", "amount"},
+		[]string{"1", "East", "10"},
+	)
+
+	tests := []struct {
+		name   string
+		config entities.AggregationConfig
+		want   string
+	}{
+		{
+			name:   "missing column",
+			config: entities.AggregationConfig{GroupingColumns: []string{"region"}, PassthroughColumns: []string{"name"}, Aggregations: []entities.Aggregation{{Column: "amount", AggregateMethod: "sum"}}},
+			want:   "name",
+		},
+		{
+			name:   "result name collision",
+			config: entities.AggregationConfig{GroupingColumns: []string{"region"}, PassthroughColumns: []string{"label"}, Aggregations: []entities.Aggregation{{Column: "amount", AggregateMethod: "sum", ResultName: "label"}}},
+			want:   "result name 'label' collides with a passthrough column",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGotaProcessor().Aggregate(context.Background(), df, []entities.AggregationConfig{tt.config})
+			var processErr *domainerrors.DataProcessError
+			if !errors.As(err, &processErr) || !strings.Contains(processErr.Error(), tt.want) {
+				t.Errorf("Aggregate() error = %v, want a DataProcessError of %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+)
+
+// CheckRowBudget checks the fetched data doesn't exceed the row budget (Config.MaxRows) before any processing.
+// Returns a recoverable DataProcessError suggesting to narrow the source if the data has more rows than maxRows.
+// maxRows of 0 means unlimited.
+func CheckRowBudget(data *dataframe.DataFrame, maxRows int) error {
+	if data == nil {
+		return domainerrors.NewDataProcessError("fetch", "no data to check", nil)
+	}
+
+	if maxRows <= 0 || data.Nrow() <= maxRows {
+		return nil
+	}
+
+	return rowBudgetError(fmt.Sprintf("the source has %d rows, exceeding maxRows %d", data.Nrow(), maxRows))
+}
+
+// CheckEstimatedRowBudget checks the row count estimated by the data source doesn't exceed the row budget,
+// so the source over the budget is rejected before it is fetched. The estimate of -1 (unknown) is never rejected.
+// Returns the same error as CheckRowBudget.
+func CheckEstimatedRowBudget(estimate, maxRows int) error {
+	if maxRows <= 0 || estimate <= maxRows {
+		return nil
+	}
+
+	return rowBudgetError(fmt.Sprintf("the source has an estimated %d rows, exceeding maxRows %d", estimate, maxRows))
+}
+
+// rowBudgetError returns the recoverable DataProcessError of the fetch exceeding the row budget.
+func rowBudgetError(message string) error {
+	return domainerrors.NewRecoverableDataProcessError(
+		"fetch",
+		message,
+		nil,
+		"narrow the source with a range or a pre-filtered file, or raise maxRows",
+	)
+}
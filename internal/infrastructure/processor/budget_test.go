@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"errors"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"testing"
+)
+
+func TestCheckRowBudget(t *testing.T) {
+	df := loadFrame(
+		[]string{"id"},
+		[]string{"1"},
+		[]string{"2"},
+		[]string{"3"},
+	)
+
+	tests := []struct {
+		name    string
+		maxRows int
+		wantErr bool
+	}{
+		{name: "unlimited", maxRows: 0, wantErr: false},
+		{name: "under budget", maxRows: 10, wantErr: false},
+		{name: "exactly at budget", maxRows: 3, wantErr: false},
+		{name: "over budget", maxRows: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRowBudget(df, tt.maxRows)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("CheckRowBudget() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var dataProcessError *domainerrors.DataProcessError
+			if !errors.As(err, &dataProcessError) {
+				t.Fatalf("CheckRowBudget() error = %v, want a DataProcessError", err)
+			}
+			if !dataProcessError.IsRecoverable() {
+				t.Error("IsRecoverable() = false, want true")
+			}
+			if dataProcessError.GetRecoveryAction() == "" {
+				t.Error("GetRecoveryAction() is empty, want the suggestion to narrow the source")
+			}
+			if dataProcessError.Step != "fetch" {
+				t.Errorf("Step = %q, want %q", dataProcessError.Step, "fetch")
+			}
+		})
+	}
+}
+
+func TestCheckRowBudgetNoData(t *testing.T) {
+	if err := CheckRowBudget(nil, 10); err == nil {
+		t.Error("CheckRowBudget(nil) error = nil, want an error")
+	}
+}
+
+func TestCheckEstimatedRowBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		estimate int
+		maxRows  int
+		wantErr  bool
+	}{
+		{name: "unlimited", estimate: 100, maxRows: 0, wantErr: false},
+		{name: "unknown estimate", estimate: -1, maxRows: 10, wantErr: false},
+		{name: "exactly at budget", estimate: 10, maxRows: 10, wantErr: false},
+		{name: "over budget", estimate: 11, maxRows: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckEstimatedRowBudget(tt.estimate, tt.maxRows)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("CheckEstimatedRowBudget() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var dataProcessError *domainerrors.DataProcessError
+			if !errors.As(err, &dataProcessError) || dataProcessError.Step != "fetch" {
+				t.Fatalf("CheckEstimatedRowBudget() error = %v, want a DataProcessError of the fetch step", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package processor
+
+import "context"
+
+// cancellationChecker polls the context every interval rows to stop the long-running loops promptly
+// without paying for the context check on every row. It is not safe for concurrent use.
+type cancellationChecker struct {
+	ctx      context.Context
+	interval int
+	rows     int
+}
+
+// newCancellationChecker creates a new cancellationChecker. The interval of 0 or less disables the checks.
+func newCancellationChecker(ctx context.Context, interval int) *cancellationChecker {
+	return &cancellationChecker{
+		ctx:      ctx,
+		interval: interval,
+	}
+}
+
+// tick counts the processed rows and returns the context error once the interval is reached after the cancellation.
+func (c *cancellationChecker) tick(rows int) error {
+	if c.interval <= 0 {
+		return nil
+	}
+
+	c.rows += rows
+	if c.rows < c.interval {
+		return nil
+	}
+	c.rows = 0
+
+	return c.ctx.Err()
+}
@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// cancelAfterContext is a context canceled partway: its Err returns context.Canceled from the checks-th call onwards.
+type cancelAfterContext struct {
+	context.Context
+	checks int64
+	calls  atomic.Int64
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.calls.Add(1) >= c.checks {
+		return context.Canceled
+	}
+	return nil
+}
+
+// rowsFrame returns a DataFrame of the rows with the id and the group key cycling through ten groups.
+func rowsFrame(rows int) *dataframe.DataFrame {
+	records := make([][]string, 0, rows+1)
+	records = append(records, []string{"id", "group"})
+	for i := 0; i < rows; i++ {
+		records = append(records, []string{strconv.Itoa(i), "g" + strconv.Itoa(i%10)})
+	}
+
+	return loadFrame(records...)
+}
+
+func TestCancellationPartway(t *testing.T) {
+	const rows, interval = 10_000, 100
+	filter := []entities.FilterConfig{{Column: "id", Operator: "gte", Value: "0", LogicalOperator: "and"}}
+	aggregation := groupBy("group", entities.Aggregation{Column: "id", AggregateMethod: "median"})
+
+	tests := []struct {
+		name string
+		run  func(p *GotaProcessor, ctx context.Context, df *dataframe.DataFrame) (*dataframe.DataFrame, error)
+	}{
+		{
+			name: "filter",
+			run: func(p *GotaProcessor, ctx context.Context, df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+				return p.Filter(ctx, df, filter)
+			},
+		},
+		{
+			name: "aggregate",
+			run: func(p *GotaProcessor, ctx context.Context, df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+				return p.Aggregate(ctx, df, aggregation)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := rowsFrame(rows)
+			before := df.Records()
+			// Canceled at the fifth check, i.e. within the first few hundred rows of the pass
+			ctx := &cancelAfterContext{Context: context.Background(), checks: 5}
+			processor := NewGotaProcessorWithOptions(GotaProcessorOptions{Workers: 1, CancellationCheckInterval: interval})
+
+			result, err := tt.run(processor, ctx, df)
+			if !domainerrors.IsDataProcessError(err) || !errors.Is(err, context.Canceled) {
+				t.Fatalf("error = %v, want a DataProcessError wrapping context.Canceled", err)
+			}
+			if result != nil {
+				t.Errorf("result = %v, want nil on cancellation", result)
+			}
+			// The pass stops at the first canceled check instead of running to the end
+			if calls := ctx.calls.Load(); calls != ctx.checks {
+				t.Errorf("context checked %d times, want to stop at the canceled check %d", calls, ctx.checks)
+			}
+			assertRecords(t, df, before)
+		})
+	}
+}
+
+func TestCancellationCheckInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	checker := newCancellationChecker(ctx, 3)
+	for i := 1; i <= 2; i++ {
+		if err := checker.tick(1); err != nil {
+			t.Fatalf("tick() at row %d error = %v, want nil before the interval", i, err)
+		}
+	}
+	if err := checker.tick(1); !errors.Is(err, context.Canceled) {
+		t.Errorf("tick() at the interval error = %v, want context.Canceled", err)
+	}
+
+	disabled := newCancellationChecker(ctx, 0)
+	for i := 0; i < 10; i++ {
+		if err := disabled.tick(1); err != nil {
+			t.Fatalf("tick() with the checks disabled error = %v, want nil", err)
+		}
+	}
+}
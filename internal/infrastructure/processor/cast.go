@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the layouts accepted by the date cast; the date-only layouts are normalized to "2006-01-02"
+var dateLayouts = []struct {
+	layout   string
+	dateOnly bool
+}{
+	{time.DateOnly, true},
+	{"2006/01/02", true},
+	{"2006.01.02", true},
+	{time.RFC3339, false},
+	{time.DateTime, false},
+	{"2006/01/02 15:04:05", false},
+}
+
+// Cast forces the columns to the target types in the order of the cast configurations.
+// A value that cannot be cast fails the whole cast with a recoverable DataProcessError naming
+// the column, the 1-based data row, and the value. When the processor collects the bad rows, the rows having
+// such a value are rejected instead (see GotaProcessorOptions.CollectBadRows).
+func (p *GotaProcessor) Cast(ctx context.Context, data *dataframe.DataFrame, config []entities.CastConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("cast", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("cast", "cast is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("cast", "no data to cast", nil)
+	}
+
+	var failures rowFailures
+	if p.collectBadRows {
+		failures = make(rowFailures)
+	}
+
+	result := data.Copy()
+	for i := range config {
+		cast := config[i]
+		if err := cast.Validate(); err != nil {
+			return nil, domainerrors.NewDataProcessError("cast", fmt.Sprintf("casts[%d] is invalid", i), err)
+		}
+		if err := requireColumns("cast", &result, cast.Column); err != nil {
+			return nil, err
+		}
+
+		column, err := castColumn(result.Col(cast.Column), cast, failures)
+		if err != nil {
+			return nil, err
+		}
+
+		result = result.Mutate(column)
+		if result.Err != nil {
+			return nil, domainerrors.NewDataProcessError("cast", fmt.Sprintf("failed to cast column '%s'", cast.Column), result.Err)
+		}
+	}
+
+	return rejectRows(ctx, "cast", data, &result, failures)
+}
+
+// castColumn returns a new series of the column cast to the target type.
+// If failures is not nil, the values that cannot be cast are recorded in it and left null instead of failing.
+func castColumn(column series.Series, cast entities.CastConfig, failures rowFailures) (series.Series, error) {
+	resultType := series.String
+	switch cast.To {
+	case "int":
+		resultType = series.Int
+	case "float":
+		resultType = series.Float
+	}
+
+	values := make([]interface{}, column.Len())
+	for i := range values {
+		element := column.Elem(i)
+		if isNull(element) {
+			continue
+		}
+
+		text := formatValue(element.Val())
+		value, err := castValue(text, cast.To)
+		if err != nil {
+			message := fmt.Sprintf("cannot cast '%s' of column '%s' at row %d to %s", text, cast.Column, i+1, cast.To)
+			if failures != nil {
+				failures.add(i, message)
+				continue
+			}
+			return series.Series{}, domainerrors.NewRecoverableDataProcessError(
+				"cast",
+				message,
+				err,
+				fmt.Sprintf("fix the value in the source, fill or filter the row out, or cast column '%s' to string", cast.Column),
+			)
+		}
+		values[i] = value
+	}
+
+	return series.New(values, resultType, cast.Column), nil
+}
+
+// castValue converts the text of a value to the Go value of the target type.
+func castValue(text, to string) (interface{}, error) {
+	trimmed := strings.TrimSpace(text)
+	switch to {
+	case "int":
+		if value, err := strconv.Atoi(trimmed); err == nil {
+			return value, nil
+		}
+		number, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil || number != math.Trunc(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("'%s' is not an integer", text)
+		}
+		return int(number), nil
+	case "float":
+		number, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", text)
+		}
+		return number, nil
+	case "date":
+		for _, candidate := range dateLayouts {
+			parsed, err := time.Parse(candidate.layout, trimmed)
+			if err != nil {
+				continue
+			}
+			if candidate.dateOnly {
+				return parsed.Format(time.DateOnly), nil
+			}
+			return parsed.Format(time.RFC3339), nil
+		}
+		return nil, fmt.Errorf("'%s' is not a date", text)
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/series"
+	"strings"
+	"testing"
+)
+
+func TestCast(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "amount", "day"},
+		[]string{"1", "3.0", "2024/01/05"},
+		[]string{"2", "", "2024-02-10T08:30:00Z"},
+	)
+	config := []entities.CastConfig{
+		{Column: "id", To: "string"},
+		{Column: "amount", To: "int"},
+		{Column: "day", To: "date"},
+	}
+
+	cast, err := NewGotaProcessor().Cast(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("Cast() error = %v", err)
+	}
+
+	want := []series.Type{series.String, series.Int, series.String}
+	for i, name := range cast.Names() {
+		if got := cast.Col(name).Type(); got != want[i] {
+			t.Errorf("type of %s = %s, want %s", name, got, want[i])
+		}
+	}
+	assertRecords(t, cast, [][]string{
+		{"id", "amount", "day"},
+		{"1", "3", "2024-01-05"},
+		{"2", "NaN", "2024-02-10T08:30:00Z"},
+	})
+}
+
+func TestCastFailure(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "amount"},
+		[]string{"1", "10"},
+		[]string{"2", "abc"},
+	)
+
+	_, err := NewGotaProcessor().Cast(context.Background(), df, []entities.CastConfig{{Column: "amount", To: "int"}})
+
+	var dataProcessError *domainerrors.DataProcessError
+	if !errors.As(err, &dataProcessError) {
+		t.Fatalf("Cast() error = %v, want a DataProcessError", err)
+	}
+	if !dataProcessError.IsRecoverable() || dataProcessError.GetRecoveryAction() == "" {
+		t.Errorf("Cast() error = %+v, want a recoverable error with the recovery action", dataProcessError)
+	}
+	for _, part := range []string{"'amount'", "row 2", "'abc'"} {
+		if !strings.Contains(dataProcessError.Message, part) {
+			t.Errorf("Message = %q, want it to contain %s", dataProcessError.Message, part)
+		}
+	}
+}
+
+func TestCastInvalid(t *testing.T) {
+	df := loadFrame([]string{"id"}, []string{"1"})
+
+	tests := []struct {
+		name string
+		cast entities.CastConfig
+	}{
+		{name: "unknown type", cast: entities.CastConfig{Column: "id", To: "decimal"}},
+		{name: "missing column", cast: entities.CastConfig{Column: "amount", To: "int"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGotaProcessor().Cast(context.Background(), df, []entities.CastConfig{tt.cast}); err == nil {
+				t.Error("Cast() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/parser"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"slices"
+)
+
+// Compute adds the float column of each computed column in order. See parser.ParseArithmeticExpression for the syntax.
+// All expressions are parsed and their columns are checked before computing, so an invalid configuration adds no columns.
+// A null operand makes the result of the row null, and a division by zero or a NaN or Inf result follows
+// the DivideByZeroPolicy of the processor.
+// When the processor collects the bad rows, the rows failing under DivideByZeroError are rejected
+// instead of failing (see GotaProcessorOptions.CollectBadRows).
+func (p *GotaProcessor) Compute(ctx context.Context, data *dataframe.DataFrame, config []entities.ComputedColumn) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("compute", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("compute", "computation is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("compute", "no data to compute", nil)
+	}
+
+	expressions, err := parseComputedColumns(data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures rowFailures
+	if p.collectBadRows {
+		failures = make(rowFailures)
+	}
+
+	result := data.Copy()
+	for i, computed := range config {
+		column, err := evaluateExpression(&result, expressions[i], computed.Name, p.divideByZero, failures)
+		if err != nil {
+			return nil, err
+		}
+
+		result = result.Mutate(column)
+		if result.Err != nil {
+			return nil, domainerrors.NewDataProcessError("compute", fmt.Sprintf("failed to add column '%s'", computed.Name), result.Err)
+		}
+	}
+
+	return rejectRows(ctx, "compute", data, &result, failures)
+}
+
+// parseComputedColumns validates the computed columns and parses their expressions.
+// The referenced columns must be numeric columns of the DataFrame or preceding computed columns.
+func parseComputedColumns(df *dataframe.DataFrame, config []entities.ComputedColumn) ([]parser.ArithmeticNode, error) {
+	names := df.Names()
+	types := df.Types()
+	numeric := make(map[string]bool, len(names))
+	for i, name := range names {
+		numeric[name] = isNumeric(types[i])
+	}
+
+	expressions := make([]parser.ArithmeticNode, len(config))
+	for i := range config {
+		computed := config[i]
+		if err := computed.Validate(); err != nil {
+			return nil, domainerrors.NewDataProcessError("compute", fmt.Sprintf("computed[%d] is invalid", i), err)
+		}
+		if _, exists := numeric[computed.Name]; exists {
+			return nil, domainerrors.NewDataProcessError("compute", fmt.Sprintf("result column '%s' already exists", computed.Name), nil)
+		}
+
+		expression, err := parser.ParseArithmeticExpression(computed.Expression)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("compute", fmt.Sprintf("computed[%d] has an invalid expression: %v", i, err), err)
+		}
+
+		for _, reference := range expression.Columns() {
+			isNumber, exists := numeric[reference.Name]
+			if !exists {
+				return nil, domainerrors.NewDataProcessError("compute", fmt.Sprintf("computed[%d] refers to unknown column '%s' at position %d", i, reference.Name, reference.Position), nil)
+			}
+			if !isNumber {
+				return nil, domainerrors.NewDataProcessError("compute", fmt.Sprintf("computed[%d] refers to non-numeric column '%s' at position %d", i, reference.Name, reference.Position), nil)
+			}
+		}
+
+		expressions[i] = expression
+		numeric[computed.Name] = true
+	}
+
+	return expressions, nil
+}
+
+// errDivideByZero aborts the evaluation of the expression under DivideByZeroError
+var errDivideByZero = errors.New("division by zero")
+
+// evaluateExpression evaluates the expression for each row of the DataFrame and returns the float series.
+// If failures is not nil, the rows failing the evaluation are recorded in it and left null instead of failing.
+func evaluateExpression(df *dataframe.DataFrame, expression parser.ArithmeticNode, name string, policy DivideByZeroPolicy, failures rowFailures) (series.Series, error) {
+	var divideByZero parser.DivideByZeroFunc
+	switch policy {
+	case DivideByZeroZero:
+		divideByZero = func() (float64, bool, error) { return 0, true, nil }
+	case DivideByZeroError:
+		divideByZero = func() (float64, bool, error) { return 0, false, errDivideByZero }
+	}
+
+	columns := make(map[string]series.Series)
+	for _, reference := range expression.Columns() {
+		if _, ok := columns[reference.Name]; !ok && slices.Contains(df.Names(), reference.Name) {
+			columns[reference.Name] = df.Col(reference.Name)
+		}
+	}
+
+	values := make([]interface{}, df.Nrow())
+	for row := range values {
+		value, ok, err := expression.Evaluate(func(column string) (float64, bool) {
+			element := columns[column].Elem(row)
+			if isNull(element) {
+				return 0, false
+			}
+			return element.Float(), true
+		}, divideByZero)
+		if err != nil {
+			message := fmt.Sprintf("computed column '%s' divides by zero at row %d", name, row+1)
+			if failures != nil {
+				failures.add(row, message)
+				continue
+			}
+			return series.Series{}, domainerrors.NewDataProcessError("compute", message, err)
+		}
+		if !ok {
+			continue
+		}
+		if isNonFinite(value) {
+			if policy == DivideByZeroError {
+				message := fmt.Sprintf("computed column '%s' results in NaN or Inf at row %d", name, row+1)
+				if failures != nil {
+					failures.add(row, message)
+					continue
+				}
+				return series.Series{}, domainerrors.NewDataProcessError("compute", message, nil)
+			}
+			values[row] = policy.divideByZeroValue()
+			continue
+		}
+		values[row] = value
+	}
+
+	return series.New(values, series.Float, name), nil
+}
@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	df := loadFrame(
+		[]string{"item", "revenue", "cost"},
+		[]string{"a", "200", "150"},
+		[]string{"b", "0", "10"},
+		[]string{"c", "NaN", "5"},
+	)
+	config := []entities.ComputedColumn{
+		{Name: "profit", Expression: "revenue - cost"},
+		{Name: "margin", Expression: "profit / revenue * 100"},
+		{Name: "double", Expression: "cost * 2 + 0.5"},
+	}
+
+	tests := []struct {
+		policy DivideByZeroPolicy
+		zero   string
+	}{
+		{policy: DivideByZeroNull, zero: "NaN"},
+		{policy: DivideByZeroZero, zero: "0.000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			computed, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: tt.policy}).Compute(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+
+			// The null revenue makes the row null, and margin refers to the preceding computed column
+			assertRecords(t, computed, [][]string{
+				{"item", "revenue", "cost", "profit", "margin", "double"},
+				{"a", "200", "150", "50.000000", "25.000000", "300.500000"},
+				{"b", "0", "10", "-10.000000", tt.zero, "20.500000"},
+				{"c", "NaN", "5", "NaN", "NaN", "10.500000"},
+			})
+		})
+	}
+
+	t.Run(DivideByZeroError.String(), func(t *testing.T) {
+		if _, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError}).Compute(context.Background(), df, config); err == nil {
+			t.Error("Compute() error = nil, want the error of the division by zero")
+		}
+	})
+}
+
+func TestComputeInvalid(t *testing.T) {
+	df := loadFrame(
+		[]string{"item", "revenue"},
+		[]string{"a", "200"},
+	)
+
+	tests := []struct {
+		name     string
+		computed []entities.ComputedColumn
+	}{
+		{name: "parse error", computed: []entities.ComputedColumn{{Name: "x", Expression: "revenue *"}}},
+		{name: "unknown column", computed: []entities.ComputedColumn{{Name: "x", Expression: "revenue - cost"}}},
+		{name: "string column", computed: []entities.ComputedColumn{{Name: "x", Expression: "item + 1"}}},
+		{name: "later column", computed: []entities.ComputedColumn{{Name: "x", Expression: "y + 1"}, {Name: "y", Expression: "revenue"}}},
+		{name: "existing name", computed: []entities.ComputedColumn{{Name: "revenue", Expression: "revenue + 1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGotaProcessor().Compute(context.Background(), df, tt.computed); err == nil {
+				t.Error("Compute() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"slices"
+)
+
+// Dedup removes the duplicate rows keeping the first or last occurrence while preserving the original order.
+func (p *GotaProcessor) Dedup(ctx context.Context, data *dataframe.DataFrame, config entities.DedupConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("dedup", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("dedup", "dedup is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("dedup", "no data to deduplicate", nil)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, domainerrors.NewDataProcessError("dedup", "dedup config is invalid", err)
+	}
+
+	columnNames := config.Columns
+	if len(columnNames) == 0 {
+		columnNames = data.Names()
+	}
+	if err := requireColumns("dedup", data, columnNames...); err != nil {
+		return nil, err
+	}
+
+	columns := columnsOf(data, columnNames)
+	seen := make(map[string]struct{}, data.Nrow())
+	kept := make([]int, 0, data.Nrow())
+
+	visit := func(row int) {
+		key := rowKey(columns, row)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		kept = append(kept, row)
+	}
+
+	if config.Keep == "last" {
+		for row := data.Nrow() - 1; row >= 0; row-- {
+			visit(row)
+		}
+		slices.Sort(kept)
+	} else {
+		for row := 0; row < data.Nrow(); row++ {
+			visit(row)
+		}
+	}
+
+	result := data.Subset(kept)
+	if result.Err != nil {
+		return nil, domainerrors.NewDataProcessError("dedup", "failed to subset rows", result.Err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "region", "amount"},
+		[]string{"1", "east", "10"},
+		[]string{"2", "west", "20"},
+		[]string{"1", "east", "10"},
+		[]string{"1", "east", "30"},
+		[]string{"3", "west", "20"},
+	)
+
+	tests := []struct {
+		name   string
+		config entities.DedupConfig
+		want   [][]string
+	}{
+		{
+			name:   "full row",
+			config: entities.DedupConfig{},
+			want: [][]string{
+				{"id", "region", "amount"},
+				{"1", "east", "10"},
+				{"2", "west", "20"},
+				{"1", "east", "30"},
+				{"3", "west", "20"},
+			},
+		},
+		{
+			name:   "subset keep first",
+			config: entities.DedupConfig{Columns: []string{"id"}, Keep: "first"},
+			want: [][]string{
+				{"id", "region", "amount"},
+				{"1", "east", "10"},
+				{"2", "west", "20"},
+				{"3", "west", "20"},
+			},
+		},
+		{
+			name:   "subset keep last",
+			config: entities.DedupConfig{Columns: []string{"id"}, Keep: "last"},
+			want: [][]string{
+				{"id", "region", "amount"},
+				{"2", "west", "20"},
+				{"1", "east", "30"},
+				{"3", "west", "20"},
+			},
+		},
+		{
+			name:   "subset of several columns",
+			config: entities.DedupConfig{Columns: []string{"region", "amount"}, Keep: "last"},
+			want: [][]string{
+				{"id", "region", "amount"},
+				{"1", "east", "10"},
+				{"1", "east", "30"},
+				{"3", "west", "20"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deduplicated, err := NewGotaProcessor().Dedup(context.Background(), df, tt.config)
+			if err != nil {
+				t.Fatalf("Dedup() error = %v", err)
+			}
+			assertRecords(t, deduplicated, tt.want)
+		})
+	}
+}
+
+func TestDedupInvalid(t *testing.T) {
+	df := loadFrame([]string{"id"}, []string{"1"})
+
+	tests := []struct {
+		name   string
+		config entities.DedupConfig
+	}{
+		{name: "missing column", config: entities.DedupConfig{Columns: []string{"region"}}},
+		{name: "unknown keep", config: entities.DedupConfig{Keep: "middle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGotaProcessor().Dedup(context.Background(), df, tt.config); err == nil {
+				t.Error("Dedup() error = nil, want an error")
+			}
+		})
+	}
+}
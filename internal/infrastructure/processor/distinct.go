@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"slices"
+)
+
+// Distinct returns the unique combinations of the values of the columns (all columns if empty) in the order of
+// their first occurrence. Unlike Dedup, the result has only the given columns in the given order.
+// Null values are equal to each other and differ from any non-null value.
+func (p *GotaProcessor) Distinct(ctx context.Context, data *dataframe.DataFrame, columns []string) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("distinct", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("distinct", "distinct is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("distinct", "no data to distinct", nil)
+	}
+
+	columnNames := columns
+	if len(columnNames) == 0 {
+		columnNames = data.Names()
+	}
+	if err := requireColumns("distinct", data, columnNames...); err != nil {
+		return nil, err
+	}
+	for i, name := range columnNames {
+		if slices.Index(columnNames, name) != i {
+			return nil, domainerrors.NewDataProcessError("distinct", fmt.Sprintf("column '%s' is listed more than once", name), nil)
+		}
+	}
+
+	selected := columnsOf(data, columnNames)
+	seen := make(map[string]struct{}, data.Nrow())
+	kept := make([]int, 0, data.Nrow())
+	checker := newCancellationChecker(ctx, p.checkInterval)
+	for row := 0; row < data.Nrow(); row++ {
+		if err := checker.tick(1); err != nil {
+			return nil, domainerrors.NewDataProcessError("distinct", fmt.Sprintf("distinct is canceled at row %d", row), err)
+		}
+
+		key := rowKey(selected, row)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		kept = append(kept, row)
+	}
+
+	result := data.Select(columnNames).Subset(kept)
+	if result.Err != nil {
+		return nil, domainerrors.NewDataProcessError("distinct", "failed to subset rows", result.Err)
+	}
+
+	return &result, nil
+}
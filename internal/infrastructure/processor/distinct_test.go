@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"context"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"testing"
+)
+
+func TestDistinct(t *testing.T) {
+	df := loadFrame(
+		[]string{"category", "region", "amount"},
+		[]string{"fruit", "east", "10"},
+		[]string{"fruit", "west", "20"},
+		[]string{"fruit", "east", "10"},
+		[]string{"", "east", "NaN"},
+		[]string{"veg", "", "30"},
+		[]string{"NaN", "east", ""},
+		[]string{"veg", "NaN", "40"},
+		[]string{"fruit", "east", "50"},
+	)
+
+	// Blank and "NaN" are both null, so they are equal to each other and the first occurrence is kept
+	tests := []struct {
+		name    string
+		columns []string
+		want    [][]string
+	}{
+		{
+			name:    "whole row",
+			columns: nil,
+			want: [][]string{
+				{"category", "region", "amount"},
+				{"fruit", "east", "10"},
+				{"fruit", "west", "20"},
+				{"", "east", "NaN"},
+				{"veg", "", "30"},
+				{"veg", "NaN", "40"},
+				{"fruit", "east", "50"},
+			},
+		},
+		{
+			name:    "subset columns",
+			columns: []string{"category", "region"},
+			want: [][]string{
+				{"category", "region"},
+				{"fruit", "east"},
+				{"fruit", "west"},
+				{"", "east"},
+				{"veg", ""},
+			},
+		},
+		{
+			name:    "subset columns in the given order",
+			columns: []string{"region", "category"},
+			want: [][]string{
+				{"region", "category"},
+				{"east", "fruit"},
+				{"west", "fruit"},
+				{"east", ""},
+				{"", "veg"},
+			},
+		},
+		{
+			name:    "single column with nulls",
+			columns: []string{"region"},
+			want: [][]string{
+				{"region"},
+				{"east"},
+				{"west"},
+				{""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distinct, err := NewGotaProcessor().Distinct(context.Background(), df, tt.columns)
+			if err != nil {
+				t.Fatalf("Distinct() error = %v", err)
+			}
+			assertRecords(t, distinct, tt.want)
+		})
+	}
+
+	// The input is not modified
+	if df.Nrow() != 8 || df.Ncol() != 3 {
+		t.Errorf("input is %dx%d after Distinct(), want 8x3", df.Nrow(), df.Ncol())
+	}
+}
+
+func TestDistinctInvalid(t *testing.T) {
+	df := loadFrame([]string{"category", "region"}, []string{"fruit", "east"})
+
+	tests := []struct {
+		name    string
+		columns []string
+	}{
+		{name: "missing column", columns: []string{"country"}},
+		{name: "duplicated column", columns: []string{"region", "region"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGotaProcessor().Distinct(context.Background(), df, tt.columns)
+			if !domainerrors.IsDataProcessError(err) {
+				t.Errorf("Distinct() error = %v, want a DataProcessError", err)
+			}
+		})
+	}
+
+	if _, err := NewGotaProcessor().Distinct(context.Background(), nil, nil); !domainerrors.IsDataProcessError(err) {
+		t.Errorf("Distinct(nil) error = %v, want a DataProcessError", err)
+	}
+}
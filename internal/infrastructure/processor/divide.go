@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+)
+
+// DivideByZeroPolicy decides the result of the operations dividing by zero: the weighted average of a group
+// with the total weight of zero, the share percent of the groups whose sum of all groups is zero, the division
+// of the computed columns, and the divide and percentage merges with the second value of zero.
+// The average of a group without any non-null values is null regardless of the policy, as the selection is empty.
+// The policy decides the non-finite results (NaN and Inf) of these operations and the sum merge as well,
+// e.g. the sum of a group overflowing or containing "Inf", because no output can represent them faithfully.
+type DivideByZeroPolicy int
+
+const (
+	// DivideByZeroNull makes the result null (default)
+	DivideByZeroNull DivideByZeroPolicy = iota
+	// DivideByZeroZero makes the result 0
+	DivideByZeroZero
+	// DivideByZeroError fails the operation with a DataProcessError naming the column and the operation (or the group)
+	DivideByZeroError
+)
+
+// String returns the name of the policy.
+func (p DivideByZeroPolicy) String() string {
+	switch p {
+	case DivideByZeroNull:
+		return "null"
+	case DivideByZeroZero:
+		return "zero"
+	case DivideByZeroError:
+		return "error"
+	default:
+		return fmt.Sprintf("DivideByZeroPolicy(%d)", int(p))
+	}
+}
+
+// divideByZeroValue returns the result value of a division by zero under the policy (nil for null).
+// The error policy is handled by the callers to describe the operation.
+func (p DivideByZeroPolicy) divideByZeroValue() interface{} {
+	if p == DivideByZeroZero {
+		return 0.0
+	}
+
+	return nil
+}
+
+// isNonFinite reports whether the value is a float NaN or infinity.
+func isNonFinite(value interface{}) bool {
+	f, ok := value.(float64)
+
+	return ok && (math.IsNaN(f) || math.IsInf(f, 0))
+}
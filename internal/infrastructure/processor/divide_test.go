@@ -0,0 +1,264 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"math"
+	"strings"
+	"testing"
+)
+
+// assertDivideByZeroError checks the error is a DataProcessError of the step containing the column and the operation.
+func assertDivideByZeroError(t *testing.T, err error, step string, contains ...string) {
+	t.Helper()
+
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) {
+		t.Fatalf("error = %v, want a DataProcessError", err)
+	}
+	if processErr.Step != step {
+		t.Errorf("step = %q, want %q", processErr.Step, step)
+	}
+	for _, s := range contains {
+		if !strings.Contains(processErr.Error(), s) {
+			t.Errorf("error = %v, want it to contain %q", err, s)
+		}
+	}
+}
+
+func TestDivideByZeroPolicies(t *testing.T) {
+	tests := []struct {
+		name string
+		// run applies the operation dividing by zero under the policy
+		run func(processor *GotaProcessor) (*dataframe.DataFrame, error)
+		// column is the result column whose first row divides by zero
+		column string
+		// contains are the parts of the error message under DivideByZeroError
+		contains []string
+		step     string
+	}{
+		{
+			name: "divide merge by zero denominator",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"revenue", "units"}, []string{"10", "0"}, []string{"9", "3"})
+				return processor.Merge(context.Background(), df, []entities.MergeConfig{
+					{FirstColumn: "revenue", SecondColumn: "units", Strategy: "divide", ResultColumnName: "unitPrice"},
+				})
+			},
+			column:   "unitPrice",
+			contains: []string{"divide", "'unitPrice'", "'units' is 0", "row 1"},
+			step:     "merge",
+		},
+		{
+			name: "sharePercent of zero total",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"region", "amount"}, []string{"east", "5"}, []string{"west", "-5"})
+				return processor.Aggregate(context.Background(), df, groupBy("region",
+					entities.Aggregation{Column: "amount", AggregateMethod: "sharePercent", ResultName: "share"},
+				))
+			},
+			column:   "share",
+			contains: []string{"sharePercent", "'amount'", "divides by zero"},
+			step:     "aggregate",
+		},
+		{
+			name: "weightedAvg of zero total weight",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"region", "price", "weight"}, []string{"east", "5", "0"})
+				return processor.Aggregate(context.Background(), df, groupBy("region",
+					entities.Aggregation{Column: "price", AggregateMethod: "weightedAvg", WeightColumn: "weight", ResultName: "price"},
+				))
+			},
+			column:   "price",
+			contains: []string{"weightedAvg", "'price'", "divides by zero"},
+			step:     "aggregate",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, policy := range []DivideByZeroPolicy{DivideByZeroNull, DivideByZeroZero} {
+			t.Run(tt.name+"/"+policy.String(), func(t *testing.T) {
+				result, err := tt.run(NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: policy}))
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+
+				element := result.Col(tt.column).Elem(0)
+				switch policy {
+				case DivideByZeroNull:
+					if !element.IsNA() {
+						t.Errorf("%s = %v, want null", tt.column, element)
+					}
+				case DivideByZeroZero:
+					if value, err := element.Int(); element.IsNA() || err != nil || value != 0 {
+						t.Errorf("%s = %v, want 0", tt.column, element)
+					}
+				}
+			})
+		}
+
+		t.Run(tt.name+"/"+DivideByZeroError.String(), func(t *testing.T) {
+			_, err := tt.run(NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError}))
+			assertDivideByZeroError(t, err, tt.step, tt.contains...)
+		})
+	}
+}
+
+func TestDivideByZeroPoliciesKeepEmptyGroupAvgNull(t *testing.T) {
+	df := loadFrame(
+		[]string{"region", "amount"},
+		[]string{"east", "10"},
+		[]string{"west", "NaN"},
+	)
+	config := groupBy("region",
+		entities.Aggregation{Column: "amount", AggregateMethod: "avg", ResultName: "average"},
+		entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total",
+			Condition: &entities.FilterConfig{Column: "amount", Operator: "gt", Value: "100", LogicalOperator: "and"}},
+	)
+	stream, buffer := true, false
+
+	// The empty selection is not a division by zero, so every policy keeps the result null without an error
+	for _, policy := range []DivideByZeroPolicy{DivideByZeroNull, DivideByZeroZero, DivideByZeroError} {
+		for _, streamAggregate := range []*bool{&stream, &buffer} {
+			processor := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: policy, StreamAggregate: streamAggregate})
+			aggregated, err := processor.Aggregate(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("%s (stream %v): Aggregate() error = %v", policy, *streamAggregate, err)
+			}
+			assertRecords(t, aggregated, [][]string{
+				{"region", "average", "total"},
+				{"east", "10.000000", "NaN"},
+				{"west", "NaN", "NaN"},
+			})
+		}
+	}
+}
+
+func TestNonFiniteResultPolicies(t *testing.T) {
+	stream, buffer := true, false
+
+	tests := []struct {
+		name string
+		// run applies the operation resulting in NaN or Inf in its first row or group under the options
+		run func(processor *GotaProcessor) (*dataframe.DataFrame, error)
+		// column is the result column whose first value is not finite
+		column string
+		// contains are the parts of the error message under DivideByZeroError
+		contains []string
+		step     string
+		// streamed runs the operation on both the stream and the buffered aggregate paths
+		streamed bool
+	}{
+		{
+			name: "sum of Inf",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"region", "amount"}, []string{"east", "Inf"}, []string{"east", "1"}, []string{"west", "2"})
+				return processor.Aggregate(context.Background(), df, groupBy("region",
+					entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+				))
+			},
+			column:   "total",
+			contains: []string{"sum", "'amount'", "NaN or Inf", "group starting at row 1"},
+			step:     "aggregate",
+			streamed: true,
+		},
+		{
+			name: "avg of Inf and -Inf",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"region", "amount"}, []string{"east", "Inf"}, []string{"east", "-Inf"}, []string{"west", "2"})
+				return processor.Aggregate(context.Background(), df, groupBy("region",
+					entities.Aggregation{Column: "amount", AggregateMethod: "avg", ResultName: "average"},
+				))
+			},
+			column:   "average",
+			contains: []string{"avg", "'amount'", "NaN or Inf"},
+			step:     "aggregate",
+			streamed: true,
+		},
+		{
+			name: "computed overflow",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"revenue", "cost"}, []string{"1e308", "-1e308"}, []string{"10", "4"})
+				return processor.Compute(context.Background(), df, []entities.ComputedColumn{{Name: "margin", Expression: "revenue - cost"}})
+			},
+			column:   "margin",
+			contains: []string{"'margin'", "NaN or Inf", "row 1"},
+			step:     "compute",
+		},
+		{
+			name: "sum merge overflow",
+			run: func(processor *GotaProcessor) (*dataframe.DataFrame, error) {
+				df := loadFrame([]string{"domestic", "overseas"}, []string{"1e308", "1e308"}, []string{"1.5", "2"})
+				return processor.Merge(context.Background(), df, []entities.MergeConfig{
+					{FirstColumn: "domestic", SecondColumn: "overseas", Strategy: "sum", ResultColumnName: "total"},
+				})
+			},
+			column:   "total",
+			contains: []string{"sum", "'total'", "NaN or Inf", "row 1"},
+			step:     "merge",
+		},
+	}
+
+	for _, tt := range tests {
+		paths := []*bool{nil}
+		if tt.streamed {
+			paths = []*bool{&stream, &buffer}
+		}
+
+		for _, streamAggregate := range paths {
+			for _, policy := range []DivideByZeroPolicy{DivideByZeroNull, DivideByZeroZero} {
+				t.Run(tt.name+"/"+policy.String(), func(t *testing.T) {
+					result, err := tt.run(NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: policy, StreamAggregate: streamAggregate}))
+					if err != nil {
+						t.Fatalf("error = %v", err)
+					}
+
+					element := result.Col(tt.column).Elem(0)
+					switch policy {
+					case DivideByZeroNull:
+						if !element.IsNA() {
+							t.Errorf("%s = %v, want null", tt.column, element)
+						}
+					case DivideByZeroZero:
+						if element.IsNA() || element.Float() != 0 {
+							t.Errorf("%s = %v, want 0", tt.column, element)
+						}
+					}
+					// The finite results are kept as they are
+					if second := result.Col(tt.column).Elem(1); second.IsNA() || second.Float() == 0 {
+						t.Errorf("%s of the second row = %v, want the finite result", tt.column, second)
+					}
+				})
+			}
+
+			t.Run(tt.name+"/"+DivideByZeroError.String(), func(t *testing.T) {
+				_, err := tt.run(NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError, StreamAggregate: streamAggregate}))
+				assertDivideByZeroError(t, err, tt.step, tt.contains...)
+			})
+		}
+	}
+}
+
+func TestIsNonFinite(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{value: math.NaN(), want: true},
+		{value: math.Inf(1), want: true},
+		{value: math.Inf(-1), want: true},
+		{value: 1.5, want: false},
+		{value: math.MaxFloat64, want: false},
+		{value: 1, want: false},
+		{value: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isNonFinite(tt.value); got != tt.want {
+			t.Errorf("isNonFinite(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
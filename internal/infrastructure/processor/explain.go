@@ -0,0 +1,199 @@
+package processor
+
+import (
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Explain validates the config and describes the stages the pipeline runs for it in order, one numbered line
+// per stage, without fetching the data. The empty stages are omitted, and the defaults filled by Validate
+// (e.g. the merge strategy or the aggregation result names) are shown, so the config is modified in place as Validate does.
+// The filters are shown as the OR groups of the AND chains they are evaluated as.
+// There are no sort and limit stages; the row order is the source order, or the grouping key order after the aggregations.
+func (p *GotaProcessor) Explain(config *entities.Config) (string, error) {
+	if config == nil {
+		return "", domainerrors.NewConfigurationError("", "no config to explain", nil)
+	}
+	if err := config.Validate(); err != nil {
+		return "", domainerrors.NewConfigurationError("", fmt.Sprintf("config is invalid: %v", err), err)
+	}
+
+	stages := make([]string, 0)
+	add := func(step, format string, args ...interface{}) {
+		stages = append(stages, fmt.Sprintf("%s: %s", step, fmt.Sprintf(format, args...)))
+	}
+
+	fetch := fmt.Sprintf("%s '%s'", config.Type, config.Source)
+	if config.MaxRows > 0 {
+		fetch += fmt.Sprintf(", at most %d rows", config.MaxRows)
+	}
+	if len(config.ColumnMapping) > 0 {
+		renames := make([]string, 0, len(config.ColumnMapping))
+		for _, header := range slices.Sorted(maps.Keys(config.ColumnMapping)) {
+			renames = append(renames, fmt.Sprintf("'%s' -> %s", header, config.ColumnMapping[header]))
+		}
+		fetch += fmt.Sprintf(", renaming %s", strings.Join(renames, ", "))
+	}
+	add("fetch", "%s", fetch)
+
+	if len(config.Normalize) > 0 {
+		descriptions := make([]string, len(config.Normalize))
+		for i, normalize := range config.Normalize {
+			operations := []string{"trim"}
+			if normalize.CollapseSpaces {
+				operations = append(operations, "collapse spaces")
+			}
+			if normalize.Lowercase {
+				operations = append(operations, "lowercase")
+			}
+			descriptions[i] = fmt.Sprintf("%s (%s)", normalize.Column, strings.Join(operations, ", "))
+		}
+		add("normalize", "%s", strings.Join(descriptions, "; "))
+	}
+
+	if len(config.Casts) > 0 {
+		descriptions := make([]string, len(config.Casts))
+		for i, cast := range config.Casts {
+			descriptions[i] = fmt.Sprintf("%s to %s", cast.Column, cast.To)
+		}
+		add("cast", "%s", strings.Join(descriptions, ", "))
+	}
+
+	if config.Watermark != nil {
+		if config.Watermark.Since == "" {
+			add("watermark", "all rows (no since), tracking the maximum of %s", config.Watermark.Column)
+		} else {
+			add("watermark", "%s > '%s'", config.Watermark.Column, config.Watermark.Since)
+		}
+	}
+
+	if config.Dedup != nil {
+		columns := "all columns"
+		if len(config.Dedup.Columns) > 0 {
+			columns = fmt.Sprintf("columns %v", config.Dedup.Columns)
+		}
+		add("dedup", "%s, keep %s", columns, config.Dedup.Keep)
+	}
+
+	if len(config.FillNull) > 0 {
+		descriptions := make([]string, len(config.FillNull))
+		for i, fill := range config.FillNull {
+			descriptions[i] = fmt.Sprintf("%s with %s", fill.Column, fill.Method)
+			if fill.Method == "literal" {
+				descriptions[i] = fmt.Sprintf("%s with '%s'", fill.Column, fill.Value)
+			}
+		}
+		add("fillNull", "%s", strings.Join(descriptions, ", "))
+	}
+
+	if len(config.Filters) > 0 {
+		add("filter", "%s", explainFilters(config.Filters))
+	}
+
+	if len(config.MergeColumns) > 0 {
+		descriptions := make([]string, len(config.MergeColumns))
+		for i, merge := range config.MergeColumns {
+			columns := strings.Join(merge.SourceColumns(), ", ")
+			descriptions[i] = fmt.Sprintf("%s = %s(%s)", merge.ResultColumnName, merge.Strategy, columns)
+			if merge.Separator != "" {
+				descriptions[i] += fmt.Sprintf(" separated by '%s'", merge.Separator)
+			}
+			if merge.Strategy == "coalesce" && len(merge.DefaultValues) > 0 {
+				descriptions[i] += fmt.Sprintf(" defaulting to '%s'", merge.DefaultValues[0])
+			}
+			if merge.Strategy == "percentage" {
+				descriptions[i] += fmt.Sprintf(" rounded to %d decimal places", merge.DecimalPlaces)
+			}
+		}
+		add("merge", "%s", strings.Join(descriptions, ", "))
+	}
+
+	if len(config.Computed) > 0 {
+		descriptions := make([]string, len(config.Computed))
+		for i, computed := range config.Computed {
+			descriptions[i] = fmt.Sprintf("%s = %s", computed.Name, computed.Expression)
+		}
+		add("compute", "%s (division by zero: %s)", strings.Join(descriptions, ", "), p.divideByZero)
+	}
+
+	if len(config.PostFilters) > 0 {
+		add("postFilter", "%s", explainFilters(config.PostFilters))
+	}
+
+	for _, aggregation := range config.Aggregations {
+		descriptions := make([]string, len(aggregation.Aggregations))
+		for i, a := range aggregation.Aggregations {
+			arguments := a.Column
+			if a.WeightColumn != "" {
+				arguments += ", " + a.WeightColumn
+			}
+			descriptions[i] = fmt.Sprintf("%s(%s) as %s", a.AggregateMethod, arguments, a.ResultName)
+			if a.Approximate {
+				descriptions[i] += " (approximate)"
+			}
+			if a.Condition != nil {
+				descriptions[i] += fmt.Sprintf(" where %s", DescribeFilter(*a.Condition))
+			}
+		}
+		if len(aggregation.PassthroughColumns) > 0 {
+			add("aggregate", "group by %v keeping first %v: %s", aggregation.GroupingColumns, aggregation.PassthroughColumns, strings.Join(descriptions, ", "))
+			continue
+		}
+		add("aggregate", "group by %v: %s", aggregation.GroupingColumns, strings.Join(descriptions, ", "))
+	}
+
+	output := config.OutputFormat
+	if config.Destination != "" {
+		output += fmt.Sprintf(" to '%s'", config.Destination)
+	}
+	add("output", "%s", output)
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("plan of '%s':\n", config.Name))
+	for i, stage := range stages {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, stage))
+	}
+
+	return builder.String(), nil
+}
+
+// explainFilters describes the filters as the OR groups of the AND chains, e.g. "(a eq 1 and b gt 2) or c eq 3".
+// The AND chains are parenthesized only when there are several groups.
+func explainFilters(filters []entities.FilterConfig) string {
+	chains := make([][]string, 0)
+	chain := make([]string, 0)
+	for i, filter := range filters {
+		chain = append(chain, DescribeFilter(filter))
+		// The LogicalOperator of the last filter is ignored
+		if filter.LogicalOperator == "or" || i == len(filters)-1 {
+			chains = append(chains, chain)
+			chain = make([]string, 0)
+		}
+	}
+
+	groups := make([]string, len(chains))
+	for i, chain := range chains {
+		groups[i] = strings.Join(chain, " and ")
+		if len(chains) > 1 && len(chain) > 1 {
+			groups[i] = "(" + groups[i] + ")"
+		}
+	}
+
+	return strings.Join(groups, " or ")
+}
+
+// DescribeFilter describes the filter in a line, e.g. "price gte 100", "sku in skus.txt", or "tag containsAny [a b]".
+func DescribeFilter(filter entities.FilterConfig) string {
+	switch {
+	case filter.ValuesFile != "":
+		return fmt.Sprintf("%s %s %s", filter.Column, filter.Operator, filter.ValuesFile)
+	case entities.IsListOperator(filter.Operator):
+		return fmt.Sprintf("%s %s %v", filter.Column, filter.Operator, filter.Values)
+	default:
+		return fmt.Sprintf("%s %s %s", filter.Column, filter.Operator, filter.Value)
+	}
+}
@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	config := &entities.Config{
+		Name:      "sales",
+		Type:      "csv",
+		Source:    "sales.csv",
+		MaxRows:   1000,
+		Normalize: []entities.NormalizeConfig{{Column: "region", Lowercase: true}},
+		Casts:     []entities.CastConfig{{Column: "amount", To: "float"}},
+		FillNull:  []entities.FillConfig{{Column: "amount", Method: "zero"}},
+		Filters: []entities.FilterConfig{
+			{Column: "amount", Operator: "gt", Value: "10", LogicalOperator: "and"},
+			{Column: "region", Operator: "eq", Value: "east", LogicalOperator: "or"},
+			{Column: "vip", Operator: "eq", Value: "true", LogicalOperator: "and"},
+		},
+		MergeColumns: []entities.MergeConfig{{FirstColumn: "first", SecondColumn: "last", Separator: " "}},
+		Computed:     []entities.ComputedColumn{{Name: "net", Expression: "amount * 0.9"}},
+		PostFilters:  []entities.FilterConfig{{Column: "net", Operator: "gte", Value: "5", LogicalOperator: "and"}},
+		Aggregations: []entities.AggregationConfig{{
+			GroupingColumns: []string{"region"},
+			Aggregations:    []entities.Aggregation{{Column: "net", AggregateMethod: "sum"}, {Column: "net", AggregateMethod: "max", ResultName: "top"}},
+		}},
+		Destination: "out.csv",
+	}
+
+	got, err := NewGotaProcessor().Explain(config)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	// The stages are in the pipeline order, and the defaults of the merge, the aggregation, and the output are filled
+	want := "plan of 'sales':\n" +
+		"1. fetch: csv 'sales.csv', at most 1000 rows\n" +
+		"2. normalize: region (trim, lowercase)\n" +
+		"3. cast: amount to float\n" +
+		"4. fillNull: amount with zero\n" +
+		"5. filter: (amount gt 10 and region eq east) or vip eq true\n" +
+		"6. merge: first_last = concat(first, last) separated by ' '\n" +
+		"7. compute: net = amount * 0.9 (division by zero: null)\n" +
+		"8. postFilter: net gte 5\n" +
+		"9. aggregate: group by [region]: sum(net) as net_sum, max(net) as top\n" +
+		"10. output: csv to 'out.csv'\n"
+	if got != want {
+		t.Errorf("Explain() =\n%s\nwant\n%s", got, want)
+	}
+	if config.MergeColumns[0].Strategy != "concat" || config.Aggregations[0].Aggregations[0].ResultName != "net_sum" {
+		t.Errorf("Explain() didn't fill the defaults in the config: %+v", config)
+	}
+
+	// The empty stages are omitted
+	minimal := &entities.Config{Name: "minimal", Type: "csv", Source: "sales.csv"}
+	got, err = NewGotaProcessor().Explain(minimal)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if want := "plan of 'minimal':\n1. fetch: csv 'sales.csv'\n2. output: csv\n"; got != want {
+		t.Errorf("Explain() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExplainInvalid(t *testing.T) {
+	for name, config := range map[string]*entities.Config{
+		"no config":      nil,
+		"invalid config": {Type: "csv"},
+	} {
+		if _, err := NewGotaProcessor().Explain(config); !domainerrors.IsConfigurationError(err) {
+			t.Errorf("Explain(%s) error = %v, want a ConfigurationError", name, err)
+		}
+	}
+}
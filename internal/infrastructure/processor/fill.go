@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strconv"
+)
+
+// FillNull fills the null values of the columns in the order of the fill configurations.
+func (p *GotaProcessor) FillNull(ctx context.Context, data *dataframe.DataFrame, config []entities.FillConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("fillNull", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("fillNull", "fill is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("fillNull", "no data to fill", nil)
+	}
+
+	result := data.Copy()
+	for i := range config {
+		fill := config[i]
+		if err := fill.Validate(); err != nil {
+			return nil, domainerrors.NewDataProcessError("fillNull", fmt.Sprintf("fillNull[%d] is invalid", i), err)
+		}
+		if err := requireColumns("fillNull", &result, fill.Column); err != nil {
+			return nil, err
+		}
+
+		column := result.Col(fill.Column)
+		filled, err := fillColumn(column, fill)
+		if err != nil {
+			return nil, err
+		}
+
+		result = result.Mutate(filled)
+		if result.Err != nil {
+			return nil, domainerrors.NewDataProcessError("fillNull", fmt.Sprintf("failed to fill column '%s'", fill.Column), result.Err)
+		}
+	}
+
+	return &result, nil
+}
+
+// fillColumn returns a new series whose null values are filled by the fill method.
+func fillColumn(column series.Series, fill entities.FillConfig) (series.Series, error) {
+	values := elementValues(column)
+
+	switch fill.Method {
+	case "literal":
+		value, err := parseValue(fill.Value, column.Type())
+		if err != nil {
+			return series.Series{}, domainerrors.NewDataProcessError("fillNull", fmt.Sprintf("cannot fill column '%s' with '%s': %v", fill.Column, fill.Value, err), err)
+		}
+		fillNulls(values, value)
+	case "zero":
+		value, _ := parseValue("0", column.Type())
+		fillNulls(values, value)
+	case "mean":
+		if !isNumeric(column.Type()) {
+			return series.Series{}, domainerrors.NewDataProcessError("fillNull", fmt.Sprintf("mean fill requires a numeric column, but '%s' is %s", fill.Column, column.Type()), nil)
+		}
+
+		sum := 0.0
+		count := 0
+		for _, value := range values {
+			if value == nil {
+				continue
+			}
+			sum += toFloat(value)
+			count++
+		}
+		// Nothing to fill with if all values are null
+		if count > 0 {
+			mean := sum / float64(count)
+			if column.Type() == series.Int {
+				// Int columns cannot hold the fractional mean
+				for i := range values {
+					if values[i] != nil {
+						values[i] = toFloat(values[i])
+					}
+				}
+				fillNulls(values, mean)
+
+				return series.New(values, series.Float, fill.Column), nil
+			}
+			fillNulls(values, mean)
+		}
+	case "forward":
+		var last interface{}
+		for i, value := range values {
+			if value == nil {
+				values[i] = last
+				continue
+			}
+			last = value
+		}
+	default:
+		return series.Series{}, domainerrors.NewDataProcessError("fillNull", fmt.Sprintf("unsupported fill method '%s'", fill.Method), nil)
+	}
+
+	return series.New(values, column.Type(), fill.Column), nil
+}
+
+// fillNulls replaces the nil values with the value in place.
+func fillNulls(values []interface{}, value interface{}) {
+	for i := range values {
+		if values[i] == nil {
+			values[i] = value
+		}
+	}
+}
+
+// parseValue parses the string value as the Go value of the series type.
+func parseValue(value string, t series.Type) (interface{}, error) {
+	switch t {
+	case series.Int:
+		return strconv.Atoi(value)
+	case series.Float:
+		return strconv.ParseFloat(value, 64)
+	case series.Bool:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// toFloat converts the Go value of a numeric element to float64.
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"testing"
+)
+
+func TestFillNull(t *testing.T) {
+	df := loadFrame(
+		[]string{"day", "region", "amount"},
+		[]string{"1", "east", "10.000000"},
+		[]string{"2", "", "NaN"},
+		[]string{"3", "west", "20.000000"},
+		[]string{"4", "", "NaN"},
+	)
+
+	tests := []struct {
+		name   string
+		config []entities.FillConfig
+		want   [][]string
+	}{
+		{
+			name:   "literal",
+			config: []entities.FillConfig{{Column: "region", Value: "unknown"}},
+			want: [][]string{
+				{"day", "region", "amount"},
+				{"1", "east", "10.000000"},
+				{"2", "unknown", "NaN"},
+				{"3", "west", "20.000000"},
+				{"4", "unknown", "NaN"},
+			},
+		},
+		{
+			name:   "mean",
+			config: []entities.FillConfig{{Column: "amount", Method: "mean"}},
+			want: [][]string{
+				{"day", "region", "amount"},
+				{"1", "east", "10.000000"},
+				{"2", "", "15.000000"},
+				{"3", "west", "20.000000"},
+				{"4", "", "15.000000"},
+			},
+		},
+		{
+			name:   "zero",
+			config: []entities.FillConfig{{Column: "amount", Method: "zero"}},
+			want: [][]string{
+				{"day", "region", "amount"},
+				{"1", "east", "10.000000"},
+				{"2", "", "0.000000"},
+				{"3", "west", "20.000000"},
+				{"4", "", "0.000000"},
+			},
+		},
+		{
+			name:   "forward",
+			config: []entities.FillConfig{{Column: "region", Method: "forward"}, {Column: "amount", Method: "forward"}},
+			want: [][]string{
+				{"day", "region", "amount"},
+				{"1", "east", "10.000000"},
+				{"2", "east", "10.000000"},
+				{"3", "west", "20.000000"},
+				{"4", "west", "20.000000"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filled, err := NewGotaProcessor().FillNull(context.Background(), df, tt.config)
+			if err != nil {
+				t.Fatalf("FillNull() error = %v", err)
+			}
+			assertRecords(t, filled, tt.want)
+		})
+	}
+}
+
+func TestFillNullInvalid(t *testing.T) {
+	df := loadFrame([]string{"region", "amount"}, []string{"east", "1.5"}, []string{"west", "NaN"})
+
+	tests := []struct {
+		name   string
+		config entities.FillConfig
+	}{
+		{name: "mean of string column", config: entities.FillConfig{Column: "region", Method: "mean"}},
+		{name: "literal of another type", config: entities.FillConfig{Column: "amount", Value: "many"}},
+		{name: "missing column", config: entities.FillConfig{Column: "price", Method: "zero"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGotaProcessor().FillNull(context.Background(), df, []entities.FillConfig{tt.config}); err == nil {
+				t.Error("FillNull() error = nil, want an error")
+			}
+		})
+	}
+}
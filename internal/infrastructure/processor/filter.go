@@ -0,0 +1,421 @@
+package processor
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildFilterSeries turns the filter configurations into a boolean mask Series over the rows of the DataFrame.
+// The LogicalOperator of each filter combines it with the next filter, and "and" takes precedence over "or",
+// so the filters are evaluated as OR-groups of AND chains (e.g. A and B or C and D means (A and B) or (C and D)).
+// The LogicalOperator of the last filter is ignored. Null (missing or blank) values never match any filter.
+// The relative date tokens are resolved in UTC. Returns the mask with all rows true if no filters are given.
+func BuildFilterSeries(df *dataframe.DataFrame, config []entities.FilterConfig) (series.Series, error) {
+	resolved, err := resolveRelativeDates(df, config, utils.Now().UTC())
+	if err != nil {
+		return series.Series{}, err
+	}
+
+	return buildFilterMask(context.Background(), df, resolved, 0, false)
+}
+
+// resolveRelativeDates returns a copy of the filters whose relative date tokens of the date-typed filters
+// (see entities.ResolveRelativeDate) are replaced with the dates resolved against now, formatted in the date layout
+// of the column (see columnDateLayout), e.g. "now-30d" is the date 30 days ago for a "2006-01-02" column.
+// The filters are returned as they are if none of them has a token.
+func resolveRelativeDates(df *dataframe.DataFrame, filters []entities.FilterConfig, now time.Time) ([]entities.FilterConfig, error) {
+	var resolved []entities.FilterConfig
+	for i, filter := range filters {
+		if filter.ValueType != "date" || !entities.IsRelativeDate(filter.Value) {
+			continue
+		}
+
+		date, err := entities.ResolveRelativeDate(filter.Value, now)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("invalid value for column '%s': %v", filter.Column, err), err)
+		}
+
+		layout := time.DateOnly
+		if df != nil && slices.Contains(df.Names(), filter.Column) {
+			layout = columnDateLayout(df.Col(filter.Column))
+		}
+		if resolved == nil {
+			resolved = slices.Clone(filters)
+		}
+		resolved[i].Value = date.Format(layout)
+	}
+
+	if resolved == nil {
+		return filters, nil
+	}
+
+	return resolved, nil
+}
+
+// selectivitySampleSize is the number of the rows sampled to estimate the selectivity of the filters
+const selectivitySampleSize = 1000
+
+// buildFilterMask builds the mask of BuildFilterSeries checking the context every checkInterval rows (0 for no checks).
+// Each AND chain evaluates its filters sequentially over the shrinking set of the rows matching the previous filters
+// of the chain, and the rows already matched by the previous OR groups are not evaluated again,
+// so a selective filter placed first saves the evaluation of the following filters.
+// If reorder is true, the filters of each AND chain are evaluated from the most selective one estimated on
+// the sampled rows (see orderBySelectivity). The filters are only reordered within the chain, so the mask is the same.
+func buildFilterMask(ctx context.Context, df *dataframe.DataFrame, config []entities.FilterConfig, checkInterval int, reorder bool) (series.Series, error) {
+	if df == nil {
+		return series.Series{}, domainerrors.NewDataProcessError("filter", "no data to filter", nil)
+	}
+
+	nrow := df.Nrow()
+	result := make([]bool, nrow)
+	if len(config) == 0 {
+		for i := range result {
+			result[i] = true
+		}
+
+		return series.Bools(result), nil
+	}
+
+	// The matchers are built before evaluating any rows to report the invalid filters regardless of the data
+	matchers := make([]func(row int) bool, len(config))
+	groups := make([][]func(row int) bool, 0)
+	start := 0
+	for i, filter := range config {
+		match, err := rowMatcher(df, filter)
+		if err != nil {
+			return series.Series{}, err
+		}
+		matchers[i] = match
+
+		// Close the AND chain at the last filter or before the OR
+		if i == len(config)-1 || filter.LogicalOperator == "or" {
+			groups = append(groups, matchers[start:i+1])
+			start = i + 1
+		}
+	}
+
+	checker := newCancellationChecker(ctx, checkInterval)
+	for _, group := range groups {
+		if reorder {
+			group = orderBySelectivity(group, nrow)
+		}
+
+		// The rows matching all filters of the AND chain so far
+		chain := make([]int, 0, nrow)
+		for row, matched := range result {
+			if !matched {
+				chain = append(chain, row)
+			}
+		}
+
+		for _, match := range group {
+			// The kept rows are compacted in place because the chain is only read ahead of the write position
+			kept := chain[:0]
+			for _, row := range chain {
+				if err := checker.tick(1); err != nil {
+					return series.Series{}, domainerrors.NewDataProcessError("filter", fmt.Sprintf("filter is canceled at row %d", row), err)
+				}
+				if match(row) {
+					kept = append(kept, row)
+				}
+			}
+			chain = kept
+		}
+
+		for _, row := range chain {
+			result[row] = true
+		}
+	}
+
+	return series.Bools(result), nil
+}
+
+// orderBySelectivity returns the matchers of an AND chain sorted by the fraction of the sampled rows they match,
+// so the most selective matcher comes first. The rows are sampled at even intervals up to selectivitySampleSize,
+// and the matchers of the same fraction keep their order. The matchers are not modified.
+func orderBySelectivity(matchers []func(row int) bool, nrow int) []func(row int) bool {
+	if len(matchers) < 2 || nrow == 0 {
+		return matchers
+	}
+
+	step := max(nrow/selectivitySampleSize, 1)
+	matched := make([]int, len(matchers))
+	for i, match := range matchers {
+		for row := 0; row < nrow; row += step {
+			if match(row) {
+				matched[i]++
+			}
+		}
+	}
+
+	order := make([]int, len(matchers))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(matched[a], matched[b])
+	})
+
+	ordered := make([]func(row int) bool, len(matchers))
+	for i, index := range order {
+		ordered[i] = matchers[index]
+	}
+
+	return ordered
+}
+
+// rowMatcher returns a function deciding whether the row of the given index matches the filter.
+// Null values (see isNull) never match, even the "notIn" and the "neq" operators.
+func rowMatcher(df *dataframe.DataFrame, filter entities.FilterConfig) (func(row int) bool, error) {
+	if !slices.Contains(df.Names(), filter.Column) {
+		return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("column '%s' not found", filter.Column), nil)
+	}
+
+	column := df.Col(filter.Column)
+	if entities.IsListOperator(filter.Operator) {
+		values, err := filter.ListValues()
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("cannot load the values for column '%s'", filter.Column), err)
+		}
+		if len(values) == 0 {
+			return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("values of operator '%s' for column '%s' cannot be empty", filter.Operator, filter.Column), nil)
+		}
+
+		if entities.IsDelimitedOperator(filter.Operator) {
+			if filter.Delimiter == "" {
+				return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("delimiter is required for operator '%s'", filter.Operator), nil)
+			}
+
+			contains := delimitedMatcher(filter.Delimiter, values, filter.Operator == "containsAll")
+			return func(row int) bool {
+				element := column.Elem(row)
+				if isNull(element) {
+					return false
+				}
+
+				return contains(element.String())
+			}, nil
+		}
+
+		contains, err := elementSetMatcher(column.Type(), values)
+		if err != nil {
+			return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("invalid values for column '%s': %v", filter.Column, err), err)
+		}
+
+		negate := filter.Operator == "notIn"
+		return func(row int) bool {
+			element := column.Elem(row)
+			if isNull(element) {
+				return false
+			}
+
+			return contains(element) != negate
+		}, nil
+	}
+
+	if filter.ValueType == "date" {
+		return dateMatcher(column, filter)
+	}
+
+	compare, err := elementComparator(column.Type(), &filter)
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("invalid value for column '%s': %v", filter.Column, err), err)
+	}
+
+	matches, err := operatorMatcher(filter.Operator)
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError("filter", err.Error(), err)
+	}
+
+	return func(row int) bool {
+		element := column.Elem(row)
+		if isNull(element) {
+			return false
+		}
+
+		return matches(compare(element))
+	}, nil
+}
+
+// dateMatcher returns a function deciding whether the row of the given index matches the date-typed filter.
+// The values of the string column and the Value are parsed by the layouts of the date cast once,
+// so the dates written in the different layouts compare as dates. Null values never match.
+// Returns an error if the column is not a string column or a value is not a date.
+func dateMatcher(column series.Series, filter entities.FilterConfig) (func(row int) bool, error) {
+	if column.Type() != series.String {
+		return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("column '%s' of type %s cannot be compared as dates", filter.Column, column.Type()), nil)
+	}
+
+	value, ok := parseDate(filter.Value)
+	if !ok {
+		return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("invalid value for column '%s': '%s' is not a date", filter.Column, filter.Value), nil)
+	}
+
+	matches, err := operatorMatcher(filter.Operator)
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError("filter", err.Error(), err)
+	}
+
+	dates := make([]time.Time, column.Len())
+	valid := make([]bool, column.Len())
+	for row := range dates {
+		element := column.Elem(row)
+		if isNull(element) {
+			continue
+		}
+
+		date, ok := parseDate(element.String())
+		if !ok {
+			return nil, domainerrors.NewDataProcessError("filter", fmt.Sprintf("value '%s' of column '%s' at row %d is not a date", element.String(), filter.Column, row+1), nil)
+		}
+		dates[row], valid[row] = date, true
+	}
+
+	return func(row int) bool {
+		return valid[row] && matches(dates[row].Compare(value))
+	}, nil
+}
+
+// elementComparator returns a function comparing an element with the value of the filter typed as the column type.
+// The typed values are parsed once by FilterConfig.Validate and reused for every row.
+// The function returns a negative number, zero, or a positive number like cmp.Compare.
+func elementComparator(columnType series.Type, filter *entities.FilterConfig) (func(series.Element) int, error) {
+	switch columnType {
+	case series.Int, series.Float:
+		number, ok := filter.NumberValue()
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a number", filter.Value)
+		}
+
+		return func(element series.Element) int {
+			return cmp.Compare(element.Float(), number)
+		}, nil
+	case series.Bool:
+		boolean, ok := filter.BoolValue()
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a bool", filter.Value)
+		}
+
+		return func(element series.Element) int {
+			b, _ := element.Bool()
+			return compareBool(b, boolean)
+		}, nil
+	default:
+		value := filter.Value
+		return func(element series.Element) int {
+			return strings.Compare(element.String(), value)
+		}, nil
+	}
+}
+
+// elementSetMatcher returns a function deciding whether an element is one of the values typed as the column type.
+// The values are parsed once into a set, so the lookup of each row doesn't depend on the number of the values.
+func elementSetMatcher(columnType series.Type, values []string) (func(series.Element) bool, error) {
+	switch columnType {
+	case series.Int, series.Float:
+		numbers := make(map[float64]struct{}, len(values))
+		for _, value := range values {
+			number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a number", value)
+			}
+			numbers[number] = struct{}{}
+		}
+
+		return func(element series.Element) bool {
+			_, ok := numbers[element.Float()]
+			return ok
+		}, nil
+	case series.Bool:
+		booleans := make(map[bool]struct{}, 2)
+		for _, value := range values {
+			boolean, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a bool", value)
+			}
+			booleans[boolean] = struct{}{}
+		}
+
+		return func(element series.Element) bool {
+			b, _ := element.Bool()
+			_, ok := booleans[b]
+			return ok
+		}, nil
+	default:
+		set := make(map[string]struct{}, len(values))
+		for _, value := range values {
+			set[value] = struct{}{}
+		}
+
+		return func(element series.Element) bool {
+			_, ok := set[element.String()]
+			return ok
+		}, nil
+	}
+}
+
+// delimitedMatcher returns a function deciding whether the cell split by the delimiter contains any of the values,
+// or all of them if all is true. The split values are trimmed and compared as strings regardless of the column type.
+func delimitedMatcher(delimiter string, values []string, all bool) func(string) bool {
+	wanted := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		wanted[strings.TrimSpace(value)] = struct{}{}
+	}
+
+	return func(cell string) bool {
+		found := make(map[string]struct{}, len(wanted))
+		for _, part := range strings.Split(cell, delimiter) {
+			part = strings.TrimSpace(part)
+			if _, ok := wanted[part]; ok {
+				if !all {
+					return true
+				}
+				found[part] = struct{}{}
+			}
+		}
+
+		return all && len(found) == len(wanted)
+	}
+}
+
+// operatorMatcher returns a function deciding whether the comparison result satisfies the operator.
+func operatorMatcher(operator string) (func(int) bool, error) {
+	switch operator {
+	case "eq":
+		return func(c int) bool { return c == 0 }, nil
+	case "neq":
+		return func(c int) bool { return c != 0 }, nil
+	case "gt":
+		return func(c int) bool { return c > 0 }, nil
+	case "gte":
+		return func(c int) bool { return c >= 0 }, nil
+	case "lt":
+		return func(c int) bool { return c < 0 }, nil
+	case "lte":
+		return func(c int) bool { return c <= 0 }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator '%s'", operator)
+	}
+}
+
+// compareBool compares two bools treating false as less than true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
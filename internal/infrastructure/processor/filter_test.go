@@ -0,0 +1,683 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"github.com/go-gota/gota/dataframe"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ordersFrame returns the orders the filter tests select the rows from.
+func ordersFrame() *dataframe.DataFrame {
+	return loadFrame(
+		[]string{"id", "region", "status", "amount"},
+		[]string{"1", "east", "paid", "10"},
+		[]string{"2", "west", "paid", "25"},
+		[]string{"3", "east", "open", "40"},
+		[]string{"4", "north", "paid", "5"},
+		[]string{"5", "west", "open", ""},
+	)
+}
+
+func TestBuildFilterSeries(t *testing.T) {
+	tests := []struct {
+		name   string
+		config []entities.FilterConfig
+		want   []bool
+	}{
+		{
+			name:   "no filters",
+			config: nil,
+			want:   []bool{true, true, true, true, true},
+		},
+		{
+			name: "single",
+			config: []entities.FilterConfig{
+				{Column: "region", Operator: "eq", Value: "east", LogicalOperator: "and"},
+			},
+			want: []bool{true, false, true, false, false},
+		},
+		{
+			name: "and",
+			config: []entities.FilterConfig{
+				{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: "and"},
+				{Column: "amount", Operator: "gte", Value: "10", LogicalOperator: "and"},
+			},
+			want: []bool{true, true, false, false, false},
+		},
+		{
+			name: "or",
+			config: []entities.FilterConfig{
+				{Column: "region", Operator: "eq", Value: "north", LogicalOperator: "or"},
+				{Column: "amount", Operator: "gt", Value: "30", LogicalOperator: "and"},
+			},
+			want: []bool{false, false, true, true, false},
+		},
+		{
+			name: "and takes precedence over or",
+			config: []entities.FilterConfig{
+				{Column: "region", Operator: "eq", Value: "east", LogicalOperator: "and"},
+				{Column: "status", Operator: "eq", Value: "open", LogicalOperator: "or"},
+				{Column: "region", Operator: "eq", Value: "west", LogicalOperator: "and"},
+				{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: "and"},
+			},
+			want: []bool{false, true, true, false, false},
+		},
+		{
+			name: "null never matches",
+			config: []entities.FilterConfig{
+				{Column: "amount", Operator: "lt", Value: "100", LogicalOperator: "and"},
+			},
+			want: []bool{true, true, true, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := ordersFrame()
+			mask, err := BuildFilterSeries(df, tt.config)
+			if err != nil {
+				t.Fatalf("BuildFilterSeries() error = %v", err)
+			}
+
+			got, err := mask.Bool()
+			if err != nil {
+				t.Fatalf("mask.Bool() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildFilterSeries() = %v, want %v", got, tt.want)
+			}
+
+			// The mask selects the same rows as Filter
+			filtered, err := NewGotaProcessor().Filter(context.Background(), df, tt.config)
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+			var rows []int
+			for row, matched := range tt.want {
+				if matched {
+					rows = append(rows, row)
+				}
+			}
+			assertRecords(t, filtered, df.Subset(rows).Records())
+		})
+	}
+}
+
+func TestBuildFilterSeriesInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config []entities.FilterConfig
+	}{
+		{name: "unknown column", config: []entities.FilterConfig{{Column: "missing", Operator: "eq", Value: "x", LogicalOperator: "and"}}},
+		{name: "unknown operator", config: []entities.FilterConfig{{Column: "region", Operator: "like", Value: "x", LogicalOperator: "and"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildFilterSeries(ordersFrame(), tt.config); err == nil {
+				t.Error("BuildFilterSeries() error = nil, want an error")
+			}
+		})
+	}
+
+	if _, err := BuildFilterSeries(nil, nil); err == nil {
+		t.Error("BuildFilterSeries(nil) error = nil, want an error")
+	}
+}
+
+func TestFilterOperators(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "amount", "tags"},
+		[]string{"1", "10", "red;blue"},
+		[]string{"2", "20", "blue"},
+		[]string{"3", "30", "green;red;blue"},
+		[]string{"4", "", ""},
+	)
+
+	tests := []struct {
+		filter entities.FilterConfig
+		want   []string
+	}{
+		{filter: entities.FilterConfig{Column: "amount", Operator: "eq", Value: "20"}, want: []string{"2"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "neq", Value: "20"}, want: []string{"1", "3"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "gt", Value: "20"}, want: []string{"3"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "gte", Value: "20"}, want: []string{"2", "3"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "lt", Value: "20"}, want: []string{"1"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "lte", Value: "20"}, want: []string{"1", "2"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "in", Values: []string{"10", "30"}}, want: []string{"1", "3"}},
+		{filter: entities.FilterConfig{Column: "amount", Operator: "notIn", Values: []string{"10", "30"}}, want: []string{"2"}},
+		{filter: entities.FilterConfig{Column: "tags", Operator: "containsAny", Values: []string{"red", "green"}, Delimiter: ";"}, want: []string{"1", "3"}},
+		{filter: entities.FilterConfig{Column: "tags", Operator: "containsAll", Values: []string{"red", "blue"}, Delimiter: ";"}, want: []string{"1", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter.Operator, func(t *testing.T) {
+			tt.filter.LogicalOperator = "and"
+			filtered, err := NewGotaProcessor().Filter(context.Background(), df, []entities.FilterConfig{tt.filter})
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+
+			if got := filtered.Col("id").Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Every supported operator is covered
+	tested := make(map[string]bool, len(tests))
+	for _, tt := range tests {
+		tested[tt.filter.Operator] = true
+	}
+	for _, operator := range entities.SupportedFilterOperators() {
+		if !tested[operator] {
+			t.Errorf("operator %q is not tested", operator)
+		}
+	}
+}
+
+func TestFilterDelimitedMembership(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "tags"},
+		[]string{"1", "vip; new ;sale"},
+		[]string{"2", "sale"},
+		[]string{"3", "vipish;newcomer"},
+		[]string{"4", "new;vip"},
+		[]string{"5", ""},
+	)
+
+	tests := []struct {
+		name   string
+		filter entities.FilterConfig
+		want   []string
+	}{
+		{
+			name:   "containsAny matches one of several tags",
+			filter: entities.FilterConfig{Operator: "containsAny", Values: []string{"vip", "clearance"}},
+			want:   []string{"1", "4"},
+		},
+		{
+			name:   "containsAny matches whole tags only",
+			filter: entities.FilterConfig{Operator: "containsAny", Values: []string{"newcomer"}},
+			want:   []string{"3"},
+		},
+		{
+			name:   "containsAll requires every tag",
+			filter: entities.FilterConfig{Operator: "containsAll", Values: []string{"vip", "new"}},
+			want:   []string{"1", "4"},
+		},
+		{
+			name:   "containsAll with a missing tag",
+			filter: entities.FilterConfig{Operator: "containsAll", Values: []string{"vip", "new", "sale"}},
+			want:   []string{"1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Column = "tags"
+			tt.filter.Delimiter = ";"
+			tt.filter.LogicalOperator = "and"
+			filtered, err := NewGotaProcessor().Filter(context.Background(), df, []entities.FilterConfig{tt.filter})
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+
+			if got := filtered.Col("id").Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterValuesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.txt")
+	if err := os.WriteFile(path, []byte("east\nnorth\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+
+	tests := []struct {
+		operator string
+		want     []string
+	}{
+		{operator: "in", want: []string{"1", "3", "4"}},
+		{operator: "notIn", want: []string{"2", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			filter := entities.FilterConfig{Column: "region", Operator: tt.operator, ValuesFile: path, LogicalOperator: "and"}
+			filtered, err := NewGotaProcessor().Filter(context.Background(), ordersFrame(), []entities.FilterConfig{filter})
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+
+			if got := filtered.Col("id").Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		filter := entities.FilterConfig{Column: "region", Operator: "in", ValuesFile: filepath.Join(t.TempDir(), "missing.txt"), LogicalOperator: "and"}
+		if _, err := NewGotaProcessor().Filter(context.Background(), ordersFrame(), []entities.FilterConfig{filter}); err == nil {
+			t.Error("Filter() error = nil, want the error of the missing values file")
+		}
+	})
+}
+
+func TestFilterReusesValidatedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.txt")
+	if err := os.WriteFile(path, []byte("east\nnorth\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+
+	filters := []entities.FilterConfig{
+		{Column: "region", Operator: "in", ValuesFile: path, LogicalOperator: "and"},
+		{Column: "amount", Operator: "gte", Value: "10", LogicalOperator: "and"},
+	}
+	for i := range filters {
+		if err := filters[i].Validate(); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+	}
+
+	// The validated configs filter with the loaded values, so the ValuesFile is never read again
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove %s: %v", path, err)
+	}
+	processor := NewGotaProcessor()
+	for i := 0; i < 3; i++ {
+		filtered, err := processor.Filter(context.Background(), ordersFrame(), filters)
+		if err != nil {
+			t.Fatalf("Filter() call %d error = %v, want the ValuesFile loaded by Validate reused", i+1, err)
+		}
+		if got, want := filtered.Col("id").Records(), []string{"1", "3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Filter() call %d ids = %v, want %v", i+1, got, want)
+		}
+		if _, err := processor.FilterMask(context.Background(), ordersFrame(), filters); err != nil {
+			t.Fatalf("FilterMask() call %d error = %v, want the ValuesFile loaded by Validate reused", i+1, err)
+		}
+	}
+
+	// The changed Value is validated again on a copy, leaving the caller's config as it is
+	filters[1].Value = "15"
+	filtered, err := processor.Filter(context.Background(), ordersFrame(), filters)
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if got, want := filtered.Col("id").Records(), []string{"3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter() ids = %v, want %v", got, want)
+	}
+	if filters[1].IsValidated() {
+		t.Error("IsValidated() = true for the caller's changed config, want it left unvalidated")
+	}
+}
+
+func TestFilterEmptyResult(t *testing.T) {
+	noMatch := []entities.FilterConfig{{Column: "region", Operator: "eq", Value: "south", LogicalOperator: "and"}}
+	disallow := false
+
+	// The empty result is allowed by default
+	filtered, err := NewGotaProcessor().Filter(context.Background(), ordersFrame(), noMatch)
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	assertRecords(t, filtered, [][]string{{"id", "region", "status", "amount"}})
+
+	_, err = NewGotaProcessorWithOptions(GotaProcessorOptions{AllowEmptyResult: &disallow}).Filter(context.Background(), ordersFrame(), noMatch)
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) || !processErr.IsRecoverable() || !errors.Is(err, ErrEmptyResult) {
+		t.Fatalf("Filter() error = %v, want the recoverable DataProcessError of ErrEmptyResult", err)
+	}
+	if got, want := processErr.GetRecoveryAction(), "loosen the filters or check their values against the data"; got != want {
+		t.Errorf("GetRecoveryAction() = %q, want %q", got, want)
+	}
+
+	// The input without rows has nothing to match, so the empty result is not the filters' fault
+	empty := ordersFrame().Subset([]int{})
+	if _, err := NewGotaProcessorWithOptions(GotaProcessorOptions{AllowEmptyResult: &disallow}).Filter(context.Background(), &empty, noMatch); err != nil {
+		t.Errorf("Filter() of no rows error = %v, want nil", err)
+	}
+}
+
+// intersectedMask builds the mask of the filters the way before the AND chains were evaluated over the shrinking
+// row set: each filter is evaluated over all rows, and the masks are intersected within the AND chains and united
+// across them.
+func intersectedMask(df *dataframe.DataFrame, config []entities.FilterConfig) ([]bool, error) {
+	result := make([]bool, df.Nrow())
+	var chain []bool
+	for i, filter := range config {
+		filterSeries, err := buildFilterMask(context.Background(), df, []entities.FilterConfig{filter}, 0, false)
+		if err != nil {
+			return nil, err
+		}
+		mask, err := filterSeries.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if chain == nil {
+			chain = mask
+		} else {
+			for row := range chain {
+				chain[row] = chain[row] && mask[row]
+			}
+		}
+
+		if i == len(config)-1 || filter.LogicalOperator == "or" {
+			for row := range result {
+				result[row] = result[row] || chain[row]
+			}
+			chain = nil
+		}
+	}
+
+	return result, nil
+}
+
+// selectiveChain returns the AND chain whose first filter keeps one group of benchmarkData.
+func selectiveChain() []entities.FilterConfig {
+	return []entities.FilterConfig{
+		{Column: "key", Operator: "eq", Value: "g7", LogicalOperator: "and"},
+		{Column: "amount", Operator: "gte", Value: "10", LogicalOperator: "and"},
+		{Column: "key", Operator: "in", Values: []string{"g1", "g7", "g9"}, LogicalOperator: "and"},
+		{Column: "amount", Operator: "lt", Value: "90", LogicalOperator: "and"},
+	}
+}
+
+func TestFilterMaskMatchesIntersection(t *testing.T) {
+	df := benchmarkData(10_000, 100)
+	tests := []struct {
+		name   string
+		config []entities.FilterConfig
+	}{
+		{name: "selective first filter", config: selectiveChain()},
+		{
+			name: "broad first filter",
+			config: []entities.FilterConfig{
+				{Column: "amount", Operator: "gte", Value: "1", LogicalOperator: "and"},
+				{Column: "key", Operator: "neq", Value: "g3", LogicalOperator: "and"},
+				{Column: "amount", Operator: "lt", Value: "2", LogicalOperator: "and"},
+			},
+		},
+		{
+			name: "or of and chains",
+			config: []entities.FilterConfig{
+				{Column: "key", Operator: "eq", Value: "g1", LogicalOperator: "and"},
+				{Column: "amount", Operator: "gt", Value: "50", LogicalOperator: "or"},
+				{Column: "amount", Operator: "lt", Value: "5", LogicalOperator: "and"},
+				{Column: "key", Operator: "notIn", Values: []string{"g2", "g4"}, LogicalOperator: "or"},
+				{Column: "key", Operator: "eq", Value: "g1", LogicalOperator: "and"},
+			},
+		},
+		{
+			name: "empty chain",
+			config: []entities.FilterConfig{
+				{Column: "key", Operator: "eq", Value: "missing", LogicalOperator: "and"},
+				{Column: "amount", Operator: "gt", Value: "0", LogicalOperator: "or"},
+				{Column: "key", Operator: "eq", Value: "g5", LogicalOperator: "and"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := intersectedMask(df, tt.config)
+			if err != nil {
+				t.Fatalf("intersectedMask() error = %v", err)
+			}
+
+			for _, reorder := range []bool{false, true} {
+				mask, err := buildFilterMask(context.Background(), df, tt.config, 0, reorder)
+				if err != nil {
+					t.Fatalf("buildFilterMask(reorder: %v) error = %v", reorder, err)
+				}
+				got, err := mask.Bool()
+				if err != nil {
+					t.Fatalf("mask.Bool() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("buildFilterMask(reorder: %v) differs from the intersected masks", reorder)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFilterSelectiveChain(b *testing.B) {
+	df := benchmarkData(1_000_000, 1000)
+	config := selectiveChain()
+
+	b.Run("shrinking", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := buildFilterMask(context.Background(), df, config, 0, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("intersection", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := intersectedMask(df, config); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestOrderBySelectivity(t *testing.T) {
+	const nrow = 100
+	matchers := map[string]func(row int) bool{
+		"broad":        func(row int) bool { return row < 80 },
+		"narrow":       func(row int) bool { return row < 10 },
+		"middle":       func(row int) bool { return row >= 50 },
+		"narrow again": func(row int) bool { return row >= 90 },
+	}
+	names := []string{"broad", "narrow", "middle", "narrow again"}
+	chain := make([]func(row int) bool, len(names))
+	for i, name := range names {
+		chain[i] = matchers[name]
+	}
+
+	// The matchers are told apart by the rows they match
+	nameOf := func(match func(row int) bool) string {
+		for name, candidate := range matchers {
+			same := true
+			for row := range nrow {
+				if match(row) != candidate(row) {
+					same = false
+					break
+				}
+			}
+			if same {
+				return name
+			}
+		}
+		return "unknown"
+	}
+
+	ordered := orderBySelectivity(chain, nrow)
+	got := make([]string, len(ordered))
+	for i, match := range ordered {
+		got[i] = nameOf(match)
+	}
+	// The matchers of the same selectivity keep the config order
+	want := []string{"narrow", "narrow again", "middle", "broad"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("orderBySelectivity() = %v, want %v", got, want)
+	}
+
+	for i, match := range chain {
+		if got := nameOf(match); got != names[i] {
+			t.Errorf("chain[%d] after orderBySelectivity() = %s, want %s unmodified", i, got, names[i])
+		}
+	}
+}
+
+func TestFilterReorderMatchesConfigOrder(t *testing.T) {
+	df := benchmarkData(20_000, 100)
+	// The broad filters come first in each AND chain, and the OR splits the chains
+	config := []entities.FilterConfig{
+		{Column: "amount", Operator: "gte", Value: "1", LogicalOperator: "and"},
+		{Column: "key", Operator: "neq", Value: "g3", LogicalOperator: "and"},
+		{Column: "key", Operator: "eq", Value: "g42", LogicalOperator: "or"},
+		{Column: "amount", Operator: "lt", Value: "90", LogicalOperator: "and"},
+		{Column: "key", Operator: "in", Values: []string{"g1", "g2"}, LogicalOperator: "and"},
+	}
+
+	want, err := NewGotaProcessor().Filter(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	got, err := NewGotaProcessorWithOptions(GotaProcessorOptions{ReorderFilters: true}).Filter(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("Filter(ReorderFilters) error = %v", err)
+	}
+	if got.Nrow() == 0 || !reflect.DeepEqual(got.Records(), want.Records()) {
+		t.Errorf("Filter(ReorderFilters) = %d rows, want the same %d rows as the config order", got.Nrow(), want.Nrow())
+	}
+}
+
+func BenchmarkFilterReorder(b *testing.B) {
+	df := benchmarkData(1_000_000, 1000)
+	// The most selective filter comes last in the config order
+	config := slices.Clone(selectiveChain())
+	slices.Reverse(config)
+
+	for _, reorder := range []bool{false, true} {
+		b.Run(fmt.Sprintf("reorder=%v", reorder), func(b *testing.B) {
+			processor := NewGotaProcessorWithOptions(GotaProcessorOptions{ReorderFilters: reorder})
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := processor.Filter(context.Background(), df, config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// fixedClock is a utils.Clock always returning the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestFilterRelativeDate(t *testing.T) {
+	// 20:00 in UTC is already the next day in Tokyo
+	t.Cleanup(utils.SetClock(fixedClock(time.Date(2024, time.March, 31, 20, 0, 0, 0, time.UTC))))
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	df := loadFrame(
+		[]string{"id", "ordered"},
+		[]string{"1", "2024-02-15"},
+		[]string{"2", "2024-03-01"},
+		[]string{"3", "2024-03-20"},
+		[]string{"4", "2024-03-31"},
+		[]string{"5", "2024-04-01"},
+	)
+
+	tests := []struct {
+		name     string
+		filter   entities.FilterConfig
+		location *time.Location
+		want     []string
+	}{
+		{name: "now-30d", filter: entities.FilterConfig{Column: "ordered", Operator: "gte", Value: "now-30d", ValueType: "date"}, want: []string{"2", "3", "4", "5"}},
+		{name: "startOfMonth", filter: entities.FilterConfig{Column: "ordered", Operator: "lt", Value: "startOfMonth", ValueType: "date"}, want: []string{"1"}},
+		{name: "today in UTC", filter: entities.FilterConfig{Column: "ordered", Operator: "eq", Value: "today", ValueType: "date"}, want: []string{"4"}},
+		{name: "today in Tokyo", filter: entities.FilterConfig{Column: "ordered", Operator: "eq", Value: "today", ValueType: "date"}, location: tokyo, want: []string{"5"}},
+		// The value of the filters other than the date-typed ones is compared as it is
+		{name: "not date-typed", filter: entities.FilterConfig{Column: "ordered", Operator: "eq", Value: "today"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.LogicalOperator = "and"
+			processor := NewGotaProcessorWithOptions(GotaProcessorOptions{Location: tt.location})
+			result, err := processor.Filter(context.Background(), df, []entities.FilterConfig{tt.filter})
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+			if got := result.Col("id").Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter(%s) ids = %v, want %v", tt.filter.Value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMask(t *testing.T) {
+	df := ordersFrame()
+	paid := entities.FilterConfig{Column: "status", Operator: "eq", Value: "paid"}
+	large := entities.FilterConfig{Column: "amount", Operator: "gte", Value: "25"}
+	east := entities.FilterConfig{Column: "region", Operator: "eq", Value: "east"}
+
+	// with returns the filter combined by the logical operator
+	with := func(filter entities.FilterConfig, logicalOperator string) entities.FilterConfig {
+		filter.LogicalOperator = logicalOperator
+		return filter
+	}
+
+	tests := []struct {
+		name   string
+		config []entities.FilterConfig
+		want   []int
+	}{
+		{name: "single", config: []entities.FilterConfig{with(paid, "and")}, want: []int{0, 1, 3}},
+		{name: "and", config: []entities.FilterConfig{with(paid, "and"), with(large, "and")}, want: []int{1}},
+		{name: "or", config: []entities.FilterConfig{with(paid, "or"), with(large, "or")}, want: []int{0, 1, 2, 3}},
+		// The operators combine the filters from left to right: (east and paid) or large
+		{name: "and with or", config: []entities.FilterConfig{with(east, "and"), with(paid, "or"), with(large, "or")}, want: []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewGotaProcessor()
+			indexes, err := processor.FilterMask(context.Background(), df, tt.config)
+			if err != nil {
+				t.Fatalf("FilterMask() error = %v", err)
+			}
+			if !reflect.DeepEqual(indexes, tt.want) {
+				t.Errorf("FilterMask() = %v, want %v", indexes, tt.want)
+			}
+
+			// The indexes are exactly the rows Filter keeps
+			filtered, err := processor.Filter(context.Background(), df, tt.config)
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+			selected := df.Subset(indexes)
+			assertRecords(t, &selected, filtered.Records())
+		})
+	}
+}
+
+func TestFilterMaskEmptyAndInvalid(t *testing.T) {
+	df := ordersFrame()
+	none := []entities.FilterConfig{{Column: "amount", Operator: "gt", Value: "1000", LogicalOperator: "and"}}
+
+	// The mask of no rows is a valid answer even if the processor disallows the empty result of Filter
+	disallowEmpty := false
+	indexes, err := NewGotaProcessorWithOptions(GotaProcessorOptions{AllowEmptyResult: &disallowEmpty}).FilterMask(context.Background(), df, none)
+	if err != nil || indexes == nil || len(indexes) != 0 {
+		t.Errorf("FilterMask() = %v, %v, want an empty slice", indexes, err)
+	}
+
+	invalid := []entities.FilterConfig{{Column: "amount", Operator: "like", Value: "1", LogicalOperator: "and"}}
+	var processErr *domainerrors.DataProcessError
+	if _, err := NewGotaProcessor().FilterMask(context.Background(), df, invalid); !errors.As(err, &processErr) || processErr.Step != "filter" {
+		t.Errorf("FilterMask() error = %v, want the DataProcessError of the filter step", err)
+	}
+}
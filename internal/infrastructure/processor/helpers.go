@@ -0,0 +1,92 @@
+package processor
+
+import (
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// isNull checks if the element is null, the missing value or the blank string (see entities.IsNull).
+func isNull(element series.Element) bool {
+	return entities.IsNull(element)
+}
+
+// isNumeric checks if the series type is int or float.
+func isNumeric(t series.Type) bool {
+	return t == series.Int || t == series.Float
+}
+
+// requireColumns returns a DataProcessError of the step if any of the columns doesn't exist in the DataFrame.
+func requireColumns(step string, df *dataframe.DataFrame, columns ...string) error {
+	names := df.Names()
+	for _, column := range columns {
+		if !slices.Contains(names, column) {
+			return domainerrors.NewDataProcessError(step, fmt.Sprintf("column '%s' not found", column), nil)
+		}
+	}
+
+	return nil
+}
+
+// elementValues returns the values of the series as a slice of Go values with nil for the null values.
+func elementValues(column series.Series) []interface{} {
+	values := make([]interface{}, column.Len())
+	for i := range values {
+		element := column.Elem(i)
+		if isNull(element) {
+			continue
+		}
+		values[i] = element.Val()
+	}
+
+	return values
+}
+
+// rowKey builds the key of the row identifying the values of the columns.
+// Null values are distinguished from any string values.
+func rowKey(columns []series.Series, row int) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		element := column.Elem(row)
+		if isNull(element) {
+			parts[i] = "\x01"
+			continue
+		}
+		parts[i] = element.String()
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// columnsOf returns the series of the named columns.
+func columnsOf(df *dataframe.DataFrame, names []string) []series.Series {
+	columns := make([]series.Series, len(names))
+	for i, name := range names {
+		columns[i] = df.Col(name)
+	}
+
+	return columns
+}
+
+// formatValue formats the Go value of an element as a string without the fixed precision of gota's float format.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
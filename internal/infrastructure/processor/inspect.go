@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strconv"
+	"strings"
+)
+
+const (
+	// numericConformThreshold is the minimum ratio of the numeric values to report a string column as mistyped
+	numericConformThreshold = 0.8
+	// maxSampleValues is the maximum number of the offending values reported by a TypeWarning
+	maxSampleValues = 5
+)
+
+// InspectTypes reports the string columns whose non-null values are mostly (80% or more) numeric,
+// with the sample values preventing the column from being inferred as numeric.
+func (p *GotaProcessor) InspectTypes(data *dataframe.DataFrame) []entities.TypeWarning {
+	warnings := make([]entities.TypeWarning, 0)
+	if data == nil {
+		return warnings
+	}
+
+	for _, name := range data.Names() {
+		column := data.Col(name)
+		if column.Type() != series.String {
+			continue
+		}
+
+		total, numbers := 0, 0
+		allInts := true
+		samples := make([]string, 0, maxSampleValues)
+		for i := 0; i < column.Len(); i++ {
+			element := column.Elem(i)
+			if isNull(element) {
+				continue
+			}
+			total++
+
+			value := strings.TrimSpace(element.String())
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				if len(samples) < maxSampleValues {
+					samples = append(samples, element.String())
+				}
+				continue
+			}
+			numbers++
+			if _, err := strconv.Atoi(value); err != nil {
+				allInts = false
+			}
+		}
+
+		if total == 0 || numbers == total {
+			continue
+		}
+
+		ratio := float64(numbers) / float64(total)
+		if ratio < numericConformThreshold {
+			continue
+		}
+
+		suggestedType := "float"
+		if allInts {
+			suggestedType = "int"
+		}
+
+		warnings = append(warnings, entities.TypeWarning{
+			Column:        name,
+			DetectedType:  string(series.String),
+			SuggestedType: suggestedType,
+			ConformRatio:  ratio,
+			SampleValues:  samples,
+		})
+	}
+
+	return warnings
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInspectTypes(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "amount", "price", "name", "clean"},
+		[]string{"1", "10", "1.5", "Alice", "3"},
+		[]string{"2", "20", "2.5", "Bob", "4"},
+		[]string{"3", "N/A", "3.5", "Carol", "5"},
+		[]string{"4", "40", "4.5", "Dave", "6"},
+		[]string{"5", "50", "?", "Eve", ""},
+	)
+
+	warnings := NewGotaProcessor().InspectTypes(df)
+
+	// name is not numeric at all and clean is inferred as int, so only amount and price are reported
+	want := []entities.TypeWarning{
+		{Column: "amount", DetectedType: "string", SuggestedType: "int", ConformRatio: 0.8, SampleValues: []string{"N/A"}},
+		{Column: "price", DetectedType: "string", SuggestedType: "float", ConformRatio: 0.8, SampleValues: []string{"?"}},
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("InspectTypes() = %+v, want %+v", warnings, want)
+	}
+
+	if message := warnings[0].String(); !strings.Contains(message, "'amount'") || !strings.Contains(message, `"N/A"`) {
+		t.Errorf("String() = %q, want the column and the sample value", message)
+	}
+}
+
+func TestInspectTypesBelowThreshold(t *testing.T) {
+	df := loadFrame(
+		[]string{"code"},
+		[]string{"1"},
+		[]string{"2"},
+		[]string{"A3"},
+		[]string{"B4"},
+	)
+
+	if warnings := NewGotaProcessor().InspectTypes(df); len(warnings) != 0 {
+		t.Errorf("InspectTypes() = %+v, want no warnings for the half numeric column", warnings)
+	}
+	if warnings := NewGotaProcessor().InspectTypes(nil); warnings == nil || len(warnings) != 0 {
+		t.Errorf("InspectTypes(nil) = %#v, want an empty slice", warnings)
+	}
+}
@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"slices"
+)
+
+// rowPair represents a pair of the row indexes joined from the left and right DataFrames (-1 for no row).
+type rowPair struct {
+	left  int
+	right int
+}
+
+// Join combines the two DataFrames by the key columns.
+// The result has the key column (named after the left key), the left non-key columns, and the right non-key columns.
+// Non-key columns existing in both DataFrames are suffixed with "_left" and "_right" respectively.
+// Keys are compared by their string representation, and null keys never match.
+// The row order follows the left DataFrame (the right DataFrame for the right join),
+// and the unmatched right rows of the outer join are appended at the end.
+func (p *GotaProcessor) Join(ctx context.Context, left, right *dataframe.DataFrame, config entities.JoinConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("join", left)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("join", "join is canceled", err)
+	}
+
+	if left == nil || right == nil {
+		return nil, domainerrors.NewDataProcessError("join", "no data to join", nil)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, domainerrors.NewDataProcessError("join", "join config is invalid", err)
+	}
+	if err := requireColumns("join", left, config.LeftKey); err != nil {
+		return nil, err
+	}
+	if err := requireColumns("join", right, config.RightKey); err != nil {
+		return nil, err
+	}
+
+	leftKey := left.Col(config.LeftKey)
+	rightKey := right.Col(config.RightKey)
+
+	pairs := joinPairs(leftKey, rightKey, config.Type)
+
+	// Key column takes the left value, or the right value if there is no left row
+	keyType := leftKey.Type()
+	if keyType != rightKey.Type() {
+		keyType = series.String
+	}
+	keyValues := make([]interface{}, len(pairs))
+	leftKeyValues := elementValues(leftKey)
+	rightKeyValues := elementValues(rightKey)
+	for i, pair := range pairs {
+		var value interface{}
+		if pair.left >= 0 {
+			value = leftKeyValues[pair.left]
+		} else {
+			value = rightKeyValues[pair.right]
+		}
+		if value != nil && keyType == series.String {
+			value = formatValue(value)
+		}
+		keyValues[i] = value
+	}
+	columns := []series.Series{series.New(keyValues, keyType, config.LeftKey)}
+
+	leftNames := slices.DeleteFunc(left.Names(), func(name string) bool { return name == config.LeftKey })
+	rightNames := slices.DeleteFunc(right.Names(), func(name string) bool { return name == config.RightKey })
+
+	for _, name := range leftNames {
+		resultName := name
+		if slices.Contains(rightNames, name) {
+			resultName = name + "_left"
+		}
+		columns = append(columns, pickRows(left.Col(name), pairs, true, resultName))
+	}
+	for _, name := range rightNames {
+		resultName := name
+		if slices.Contains(leftNames, name) || name == config.LeftKey {
+			resultName = name + "_right"
+		}
+		columns = append(columns, pickRows(right.Col(name), pairs, false, resultName))
+	}
+
+	result := dataframe.New(columns...)
+	if result.Err != nil {
+		return nil, domainerrors.NewDataProcessError("join", "failed to build joined DataFrame", result.Err)
+	}
+
+	return &result, nil
+}
+
+// joinPairs matches the rows of the left and right key columns according to the join type.
+func joinPairs(leftKey, rightKey series.Series, joinType string) []rowPair {
+	index := func(key series.Series) map[string][]int {
+		rows := make(map[string][]int)
+		for i := 0; i < key.Len(); i++ {
+			element := key.Elem(i)
+			if isNull(element) {
+				continue
+			}
+			k := formatValue(element.Val())
+			rows[k] = append(rows[k], i)
+		}
+
+		return rows
+	}
+
+	pairs := make([]rowPair, 0, max(leftKey.Len(), rightKey.Len()))
+
+	if joinType == "right" {
+		leftIndex := index(leftKey)
+		for r := 0; r < rightKey.Len(); r++ {
+			element := rightKey.Elem(r)
+			matches := leftIndex[formatValue(element.Val())]
+			if isNull(element) || len(matches) == 0 {
+				pairs = append(pairs, rowPair{left: -1, right: r})
+				continue
+			}
+			for _, l := range matches {
+				pairs = append(pairs, rowPair{left: l, right: r})
+			}
+		}
+
+		return pairs
+	}
+
+	rightIndex := index(rightKey)
+	matchedRight := make([]bool, rightKey.Len())
+	for l := 0; l < leftKey.Len(); l++ {
+		element := leftKey.Elem(l)
+		matches := rightIndex[formatValue(element.Val())]
+		if isNull(element) || len(matches) == 0 {
+			if joinType == "left" || joinType == "outer" {
+				pairs = append(pairs, rowPair{left: l, right: -1})
+			}
+			continue
+		}
+		for _, r := range matches {
+			pairs = append(pairs, rowPair{left: l, right: r})
+			matchedRight[r] = true
+		}
+	}
+
+	if joinType == "outer" {
+		for r, matched := range matchedRight {
+			if !matched {
+				pairs = append(pairs, rowPair{left: -1, right: r})
+			}
+		}
+	}
+
+	return pairs
+}
+
+// pickRows builds a new series taking the rows of the left or right side of the pairs (null for no row).
+func pickRows(column series.Series, pairs []rowPair, fromLeft bool, name string) series.Series {
+	source := elementValues(column)
+	values := make([]interface{}, len(pairs))
+	for i, pair := range pairs {
+		row := pair.right
+		if fromLeft {
+			row = pair.left
+		}
+		if row >= 0 {
+			values[i] = source[row]
+		}
+	}
+
+	return series.New(values, column.Type(), name)
+}
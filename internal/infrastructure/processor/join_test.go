@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"testing"
+)
+
+func TestJoin(t *testing.T) {
+	orders := loadFrame(
+		[]string{"customer", "amount"},
+		[]string{"c1", "10"},
+		[]string{"c2", "20"},
+		[]string{"c9", "30"},
+		[]string{"c1", "40"},
+	)
+	customers := loadFrame(
+		[]string{"id", "name", "amount"},
+		[]string{"c1", "Alice", "1"},
+		[]string{"c2", "Bob", "2"},
+		[]string{"c3", "Carol", "3"},
+	)
+
+	tests := []struct {
+		joinType string
+		want     [][]string
+	}{
+		{
+			joinType: "inner",
+			want: [][]string{
+				{"customer", "amount_left", "name", "amount_right"},
+				{"c1", "10", "Alice", "1"},
+				{"c2", "20", "Bob", "2"},
+				{"c1", "40", "Alice", "1"},
+			},
+		},
+		{
+			joinType: "left",
+			want: [][]string{
+				{"customer", "amount_left", "name", "amount_right"},
+				{"c1", "10", "Alice", "1"},
+				{"c2", "20", "Bob", "2"},
+				{"c9", "30", "NaN", "NaN"},
+				{"c1", "40", "Alice", "1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.joinType, func(t *testing.T) {
+			config := entities.JoinConfig{LeftKey: "customer", RightKey: "id", Type: tt.joinType}
+			joined, err := NewGotaProcessor().Join(context.Background(), orders, customers, config)
+			if err != nil {
+				t.Fatalf("Join() error = %v", err)
+			}
+			assertRecords(t, joined, tt.want)
+		})
+	}
+}
+
+func TestJoinMissingKey(t *testing.T) {
+	left := loadFrame([]string{"id"}, []string{"1"})
+	right := loadFrame([]string{"key"}, []string{"1"})
+
+	config := entities.JoinConfig{LeftKey: "id", RightKey: "id", Type: "inner"}
+	if _, err := NewGotaProcessor().Join(context.Background(), left, right, config); err == nil {
+		t.Error("Join() error = nil, want the error of the missing right key")
+	}
+}
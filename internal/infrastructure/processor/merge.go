@@ -0,0 +1,298 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"math"
+	"strings"
+)
+
+// Merge adds the result column of each merge configuration in order. The source columns are kept as they are.
+//
+// Null handling of each strategy (null values are replaced by DefaultValues first when given):
+// - concat: Null values are treated as empty strings, and the result is null only if both values are null
+// - sum: Null values are ignored, and the result is null only if both values are null
+// - first/second: The prior value if not null, otherwise the other value
+// - divide/percentage: The result is null if either value is null, and the second value of zero follows the DivideByZeroPolicy
+// - coalesce: The first value of the Columns that is not null, where the empty strings are null as well but
+// the whitespace-only strings are values; the DefaultValues[0] if all are null, otherwise null
+//
+// The NaN and Inf results of sum, divide, and percentage follow the DivideByZeroPolicy as well.
+//
+// With a Separator, concat quotes the values containing the separator, quotes, or newlines as in RFC 4180.
+func (p *GotaProcessor) Merge(ctx context.Context, data *dataframe.DataFrame, config []entities.MergeConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("merge", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("merge", "merge is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("merge", "no data to merge", nil)
+	}
+
+	result := data.Copy()
+	for i := range config {
+		merge := config[i]
+		if err := merge.Validate(); err != nil {
+			return nil, domainerrors.NewDataProcessError("merge", fmt.Sprintf("mergeColumn[%d] is invalid", i), err)
+		}
+		if err := requireColumns("merge", &result, merge.SourceColumns()...); err != nil {
+			return nil, err
+		}
+		if err := requireNewColumn("merge", &result, merge.ResultColumnName); err != nil {
+			return nil, err
+		}
+
+		var merged series.Series
+		var err error
+		if merge.Strategy == "coalesce" {
+			merged, err = coalesceColumns(&result, merge)
+		} else {
+			merged, err = mergeColumns(result.Col(merge.FirstColumn), result.Col(merge.SecondColumn), merge, p.divideByZero)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = result.Mutate(merged)
+		if result.Err != nil {
+			return nil, domainerrors.NewDataProcessError("merge", fmt.Sprintf("failed to add column '%s'", merge.ResultColumnName), result.Err)
+		}
+	}
+
+	return &result, nil
+}
+
+// mergeColumns merges two series according to the merge strategy. The policy decides the divisions by zero.
+func mergeColumns(first, second series.Series, merge entities.MergeConfig, policy DivideByZeroPolicy) (series.Series, error) {
+	firstValues, err := applyDefault(elementValues(first), first.Type(), merge, 0)
+	if err != nil {
+		return series.Series{}, err
+	}
+	secondValues, err := applyDefault(elementValues(second), second.Type(), merge, 1)
+	if err != nil {
+		return series.Series{}, err
+	}
+
+	values := make([]interface{}, len(firstValues))
+	resultType := series.String
+
+	switch merge.Strategy {
+	case "concat":
+		for i := range values {
+			if firstValues[i] == nil && secondValues[i] == nil {
+				continue
+			}
+			values[i] = concatValues(formatValue(firstValues[i]), formatValue(secondValues[i]), merge.Separator)
+		}
+	case "sum", "divide", "percentage":
+		if !isNumeric(first.Type()) || !isNumeric(second.Type()) {
+			return series.Series{}, domainerrors.NewDataProcessError(
+				"merge",
+				fmt.Sprintf("%s strategy requires numeric columns, but '%s' is %s and '%s' is %s", merge.Strategy, merge.FirstColumn, first.Type(), merge.SecondColumn, second.Type()),
+				nil,
+			)
+		}
+		if merge.Strategy == "divide" || merge.Strategy == "percentage" {
+			return divideColumn(firstValues, secondValues, merge, policy)
+		}
+
+		resultType = series.Float
+		if first.Type() == series.Int && second.Type() == series.Int {
+			resultType = series.Int
+		}
+
+		for i := range values {
+			if firstValues[i] == nil && secondValues[i] == nil {
+				continue
+			}
+
+			sum := 0.0
+			if firstValues[i] != nil {
+				sum += toFloat(firstValues[i])
+			}
+			if secondValues[i] != nil {
+				sum += toFloat(secondValues[i])
+			}
+
+			if resultType == series.Int {
+				values[i] = int(sum)
+				continue
+			}
+			if isNonFinite(sum) {
+				if policy == DivideByZeroError {
+					return series.Series{}, domainerrors.NewDataProcessError(
+						"merge",
+						fmt.Sprintf("sum of '%s' results in NaN or Inf at row %d", merge.ResultColumnName, i+1),
+						nil,
+					)
+				}
+				values[i] = policy.divideByZeroValue()
+				continue
+			}
+			values[i] = sum
+		}
+	case "first", "second":
+		prior, other := firstValues, secondValues
+		if merge.Strategy == "second" {
+			prior, other = secondValues, firstValues
+		}
+
+		if first.Type() == second.Type() {
+			resultType = first.Type()
+		}
+
+		for i := range values {
+			value := prior[i]
+			if value == nil {
+				value = other[i]
+			}
+			if value != nil && resultType == series.String {
+				value = formatValue(value)
+			}
+			values[i] = value
+		}
+	default:
+		return series.Series{}, domainerrors.NewDataProcessError("merge", fmt.Sprintf("unsupported strategy '%s'", merge.Strategy), nil)
+	}
+
+	return series.New(values, resultType, merge.ResultColumnName), nil
+}
+
+// coalesceColumns takes the first non-null value of the Columns of the merge for each row, or the DefaultValues[0]
+// if all are null. The result has the type of the columns if they have the same type, otherwise it is a string column.
+func coalesceColumns(df *dataframe.DataFrame, merge entities.MergeConfig) (series.Series, error) {
+	columns := make([][]interface{}, len(merge.Columns))
+	resultType := df.Col(merge.Columns[0]).Type()
+	for i, name := range merge.Columns {
+		column := df.Col(name)
+		if column.Type() != resultType {
+			resultType = series.String
+		}
+		columns[i] = elementValues(column)
+	}
+
+	var defaultValue interface{}
+	if len(merge.DefaultValues) > 0 {
+		value, err := parseValue(merge.DefaultValues[0], resultType)
+		if err != nil {
+			return series.Series{}, domainerrors.NewDataProcessError(
+				"merge",
+				fmt.Sprintf("defaultValues[0] '%s' doesn't match the result type %s", merge.DefaultValues[0], resultType),
+				err,
+			)
+		}
+		defaultValue = value
+	}
+
+	values := make([]interface{}, df.Nrow())
+	for row := range values {
+		values[row] = defaultValue
+		for _, column := range columns {
+			if column[row] != nil {
+				values[row] = column[row]
+				break
+			}
+		}
+		if values[row] != nil && resultType == series.String {
+			values[row] = formatValue(values[row])
+		}
+	}
+
+	return series.New(values, resultType, merge.ResultColumnName), nil
+}
+
+// concatValues joins the values by the separator. With a separator, the values containing the separator, quotes,
+// or newlines are quoted and the quotes in them are escaped by doubling, as the CSV fields of RFC 4180.
+func concatValues(first, second, separator string) string {
+	if separator == "" {
+		return first + second
+	}
+
+	quote := func(value string) string {
+		if !strings.Contains(value, separator) && !strings.ContainsAny(value, "\"\r\n") {
+			return value
+		}
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+
+	return quote(first) + separator + quote(second)
+}
+
+// divideColumn computes first / second of the divide strategy, or first / second * 100 rounded to the DecimalPlaces
+// of the percentage strategy, as a float series.
+func divideColumn(firstValues, secondValues []interface{}, merge entities.MergeConfig, policy DivideByZeroPolicy) (series.Series, error) {
+	percentage := merge.Strategy == "percentage"
+	scale := math.Pow10(merge.DecimalPlaces)
+	values := make([]interface{}, len(firstValues))
+	for i := range values {
+		if firstValues[i] == nil || secondValues[i] == nil {
+			continue
+		}
+
+		denominator := toFloat(secondValues[i])
+		if denominator == 0 {
+			if policy == DivideByZeroError {
+				return series.Series{}, domainerrors.NewDataProcessError(
+					"merge",
+					fmt.Sprintf("%s of '%s' divides by zero at row %d because '%s' is 0", merge.Strategy, merge.ResultColumnName, i+1, merge.SecondColumn),
+					nil,
+				)
+			}
+			values[i] = policy.divideByZeroValue()
+			continue
+		}
+
+		quotient := toFloat(firstValues[i]) / denominator
+		if percentage {
+			quotient = math.Round(quotient*100*scale) / scale
+		}
+		if isNonFinite(quotient) {
+			if policy == DivideByZeroError {
+				return series.Series{}, domainerrors.NewDataProcessError(
+					"merge",
+					fmt.Sprintf("%s of '%s' results in NaN or Inf at row %d", merge.Strategy, merge.ResultColumnName, i+1),
+					nil,
+				)
+			}
+			values[i] = policy.divideByZeroValue()
+			continue
+		}
+		values[i] = quotient
+	}
+
+	return series.New(values, series.Float, merge.ResultColumnName), nil
+}
+
+// applyDefault replaces the nil values with the DefaultValues[index] parsed as the column type if it is given.
+func applyDefault(values []interface{}, t series.Type, merge entities.MergeConfig, index int) ([]interface{}, error) {
+	if index >= len(merge.DefaultValues) {
+		return values, nil
+	}
+
+	defaultValue, err := parseValue(merge.DefaultValues[index], t)
+	if err != nil {
+		return nil, domainerrors.NewDataProcessError(
+			"merge",
+			fmt.Sprintf("defaultValues[%d] '%s' doesn't match the column type %s", index, merge.DefaultValues[index], t),
+			err,
+		)
+	}
+	fillNulls(values, defaultValue)
+
+	return values, nil
+}
+
+// requireNewColumn returns a DataProcessError of the step if the column already exists in the DataFrame.
+func requireNewColumn(step string, df *dataframe.DataFrame, column string) error {
+	if err := requireColumns(step, df, column); err == nil {
+		return domainerrors.NewDataProcessError(step, fmt.Sprintf("result column '%s' already exists", column), nil)
+	}
+
+	return nil
+}
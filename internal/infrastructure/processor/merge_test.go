@@ -0,0 +1,276 @@
+package processor
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeStrategies(t *testing.T) {
+	df := loadFrame(
+		[]string{"first", "second", "label", "note"},
+		[]string{"6", "3", "a", "x"},
+		[]string{"NaN", "4", "", "y"},
+		[]string{"5", "0", "c", ""},
+	)
+
+	tests := []struct {
+		merge entities.MergeConfig
+		want  []string
+	}{
+		{merge: entities.MergeConfig{FirstColumn: "label", SecondColumn: "note", Strategy: "concat"}, want: []string{"ax", "y", "c"}},
+		{merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "sum"}, want: []string{"9", "4", "5"}},
+		{merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "first"}, want: []string{"6", "4", "5"}},
+		{merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "second"}, want: []string{"3", "4", "0"}},
+		{merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "divide"}, want: []string{"2.000000", "NaN", "NaN"}},
+		{merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "percentage"}, want: []string{"200.000000", "NaN", "NaN"}},
+		{merge: entities.MergeConfig{Columns: []string{"label", "note"}, Strategy: "coalesce"}, want: []string{"a", "y", "c"}},
+	}
+
+	tested := make(map[string]bool, len(tests))
+	for _, tt := range tests {
+		tested[tt.merge.Strategy] = true
+		t.Run(tt.merge.Strategy, func(t *testing.T) {
+			tt.merge.ResultColumnName = "result"
+			merged, err := NewGotaProcessor().Merge(context.Background(), df, []entities.MergeConfig{tt.merge})
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			if got := merged.Col("result").Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() result = %v, want %v", got, tt.want)
+			}
+			if got, want := merged.Names(), append(df.Names(), "result"); !reflect.DeepEqual(got, want) {
+				t.Errorf("Merge() columns = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// Every supported strategy is covered
+	for _, strategy := range entities.SupportedMergeStrategies() {
+		if !tested[strategy] {
+			t.Errorf("strategy %q is not tested", strategy)
+		}
+	}
+}
+
+func TestMergeDivideByZeroError(t *testing.T) {
+	df := loadFrame([]string{"first", "second"}, []string{"5", "0"})
+	config := []entities.MergeConfig{{FirstColumn: "first", SecondColumn: "second", Strategy: "divide", ResultColumnName: "ratio"}}
+
+	if _, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError}).Merge(context.Background(), df, config); err == nil {
+		t.Error("Merge() error = nil, want an error dividing by zero")
+	}
+}
+
+func TestMergeInvalid(t *testing.T) {
+	df := loadFrame([]string{"first", "second"}, []string{"5", "1"})
+
+	tests := []struct {
+		name  string
+		merge entities.MergeConfig
+	}{
+		{name: "unknown strategy", merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "product"}},
+		{name: "missing column", merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "missing", Strategy: "sum"}},
+		{name: "existing result column", merge: entities.MergeConfig{FirstColumn: "first", SecondColumn: "second", Strategy: "sum", ResultColumnName: "first"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGotaProcessor().Merge(context.Background(), df, []entities.MergeConfig{tt.merge}); err == nil {
+				t.Error("Merge() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestMergePercentage(t *testing.T) {
+	df := loadFrame(
+		[]string{"conversions", "visits"},
+		[]string{"1", "3"},
+		[]string{"2", "3"},
+		[]string{"1", "8"},
+		[]string{"5", "0"},
+	)
+
+	tests := []struct {
+		name          string
+		decimalPlaces int
+		policy        DivideByZeroPolicy
+		want          []float64
+	}{
+		{name: "whole percent", decimalPlaces: 0, want: []float64{33, 67, 13, math.NaN()}},
+		{name: "one decimal place", decimalPlaces: 1, want: []float64{33.3, 66.7, 12.5, math.NaN()}},
+		{name: "two decimal places", decimalPlaces: 2, want: []float64{33.33, 66.67, 12.5, math.NaN()}},
+		{name: "zero denominator as zero", decimalPlaces: 2, policy: DivideByZeroZero, want: []float64{33.33, 66.67, 12.5, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := []entities.MergeConfig{{FirstColumn: "conversions", SecondColumn: "visits", Strategy: "percentage", DecimalPlaces: tt.decimalPlaces, ResultColumnName: "rate"}}
+			merged, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: tt.policy}).Merge(context.Background(), df, config)
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			got := merged.Col("rate").Float()
+			for i := range tt.want {
+				if got[i] != tt.want[i] && !(math.IsNaN(got[i]) && math.IsNaN(tt.want[i])) {
+					t.Errorf("Merge() rate = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	t.Run("zero denominator as error", func(t *testing.T) {
+		config := []entities.MergeConfig{{FirstColumn: "conversions", SecondColumn: "visits", Strategy: "percentage", ResultColumnName: "rate"}}
+		_, err := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError}).Merge(context.Background(), df, config)
+		if err == nil || !strings.Contains(err.Error(), "percentage of 'rate' divides by zero at row 4") {
+			t.Errorf("Merge() error = %v, want the division by zero at row 4", err)
+		}
+	})
+}
+
+func TestMergePercentageInvalidDecimalPlaces(t *testing.T) {
+	df := loadFrame([]string{"first", "second"}, []string{"1", "2"})
+
+	for _, merge := range []entities.MergeConfig{
+		{FirstColumn: "first", SecondColumn: "second", Strategy: "percentage", DecimalPlaces: -1},
+		{FirstColumn: "first", SecondColumn: "second", Strategy: "divide", DecimalPlaces: 2},
+	} {
+		if _, err := NewGotaProcessor().Merge(context.Background(), df, []entities.MergeConfig{merge}); err == nil {
+			t.Errorf("Merge(%s, decimalPlaces %d) error = nil, want an error", merge.Strategy, merge.DecimalPlaces)
+		}
+	}
+}
+
+func TestMergeConcatQuoting(t *testing.T) {
+	df := loadFrame(
+		[]string{"name", "note"},
+		[]string{"Smith, Alice", `said "hi"`},
+		[]string{"Bob", "line one\nline two"},
+		[]string{"Carol", "plain"},
+	)
+	config := []entities.MergeConfig{{FirstColumn: "name", SecondColumn: "note", Strategy: "concat", Separator: ",", ResultColumnName: "merged"}}
+
+	merged, err := NewGotaProcessor().Merge(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	want := []string{`"Smith, Alice","said ""hi"""`, "Bob,\"line one\nline two\"", "Carol,plain"}
+	got := merged.Col("merged").Records()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() merged = %q, want %q", got, want)
+	}
+
+	// Each merged value reads back as the CSV record of the source values
+	for i, value := range got {
+		record, err := csv.NewReader(strings.NewReader(value)).Read()
+		if err != nil {
+			t.Fatalf("read back %q: %v", value, err)
+		}
+		if source := []string{df.Elem(i, 0).String(), df.Elem(i, 1).String()}; !reflect.DeepEqual(record, source) {
+			t.Errorf("read back %q = %q, want %q", value, record, source)
+		}
+	}
+}
+
+func TestMergeCoalesce(t *testing.T) {
+	// The rows cover each null pattern of the three columns; "" is missing like null, but " " is a value
+	df := dataframe.New(
+		series.New([]interface{}{"w1", nil, "", nil, "w5", nil, " ", nil}, series.String, "work"),
+		series.New([]interface{}{"m1", "m2", nil, "", nil, nil, "m7", nil}, series.String, "mobile"),
+		series.New([]interface{}{"h1", "h3", "h3", "h4", "h5", "h6", nil, ""}, series.String, "home"),
+	)
+	columns := []string{"work", "mobile", "home"}
+
+	tests := []struct {
+		name          string
+		defaultValues []string
+		want          []string
+	}{
+		{name: "without default", want: []string{"w1", "m2", "h3", "h4", "w5", "h6", " ", "NaN"}},
+		{name: "with default", defaultValues: []string{"none"}, want: []string{"w1", "m2", "h3", "h4", "w5", "h6", " ", "none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := []entities.MergeConfig{{Columns: columns, Strategy: "coalesce", DefaultValues: tt.defaultValues, ResultColumnName: "phone"}}
+			merged, err := NewGotaProcessor().Merge(context.Background(), &df, config)
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			if got := merged.Col("phone").Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() phone = %q, want %q", got, tt.want)
+			}
+			// The all-null row without a default is a true null rather than the "NaN" string
+			if last := merged.Col("phone").Elem(df.Nrow() - 1); last.IsNA() != (tt.defaultValues == nil) {
+				t.Errorf("Merge() phone of the all-null row IsNA() = %v, want %v", last.IsNA(), tt.defaultValues == nil)
+			}
+		})
+	}
+}
+
+func TestMergeCoalesceResultType(t *testing.T) {
+	tests := []struct {
+		name     string
+		df       *dataframe.DataFrame
+		defaults []string
+		wantType series.Type
+		want     []string
+	}{
+		{
+			name:     "same type",
+			df:       loadFrame([]string{"a", "b", "c"}, []string{"NaN", "2", "3"}, []string{"NaN", "NaN", "NaN"}, []string{"1", "NaN", "3"}),
+			defaults: []string{"0"},
+			wantType: series.Int,
+			want:     []string{"2", "0", "1"},
+		},
+		{
+			name:     "mixed types",
+			df:       loadFrame([]string{"a", "b", "c"}, []string{"NaN", "x", "3"}, []string{"NaN", "NaN", "1.5"}, []string{"1", "NaN", "3"}),
+			wantType: series.String,
+			want:     []string{"x", "1.5", "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := []entities.MergeConfig{{Columns: []string{"a", "b", "c"}, Strategy: "coalesce", DefaultValues: tt.defaults, ResultColumnName: "result"}}
+			merged, err := NewGotaProcessor().Merge(context.Background(), tt.df, config)
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			result := merged.Col("result")
+			if result.Type() != tt.wantType {
+				t.Errorf("Merge() result type = %s, want %s", result.Type(), tt.wantType)
+			}
+			if got := result.Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() result = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeCoalesceDefaultMismatch(t *testing.T) {
+	df := loadFrame([]string{"a", "b"}, []string{"1", "NaN"}, []string{"NaN", "2"})
+	config := []entities.MergeConfig{{Columns: []string{"a", "b"}, Strategy: "coalesce", DefaultValues: []string{"none"}, ResultColumnName: "result"}}
+
+	_, err := NewGotaProcessor().Merge(context.Background(), df, config)
+	var processErr *domainerrors.DataProcessError
+	if !errors.As(err, &processErr) || !strings.Contains(processErr.Error(), "defaultValues[0] 'none'") {
+		t.Errorf("Merge() error = %v, want the default value not matching the int columns", err)
+	}
+}
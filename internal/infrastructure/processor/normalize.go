@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strings"
+)
+
+// Normalize trims the whitespace of the string columns in the order of the normalize configurations,
+// and optionally collapses the internal whitespace and lowercases the values. The null values stay null.
+func (p *GotaProcessor) Normalize(ctx context.Context, data *dataframe.DataFrame, config []entities.NormalizeConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("normalize", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, domainerrors.NewDataProcessError("normalize", "normalize is canceled", err)
+	}
+
+	if data == nil {
+		return nil, domainerrors.NewDataProcessError("normalize", "no data to normalize", nil)
+	}
+
+	result := data.Copy()
+	for i := range config {
+		normalize := config[i]
+		if err := normalize.Validate(); err != nil {
+			return nil, domainerrors.NewDataProcessError("normalize", fmt.Sprintf("normalize[%d] is invalid", i), err)
+		}
+		if err := requireColumns("normalize", &result, normalize.Column); err != nil {
+			return nil, err
+		}
+
+		column := result.Col(normalize.Column)
+		if column.Type() != series.String {
+			return nil, domainerrors.NewDataProcessError("normalize", fmt.Sprintf("column '%s' is %s, only string columns can be normalized", normalize.Column, column.Type()), nil)
+		}
+
+		values := make([]interface{}, column.Len())
+		for row := range values {
+			element := column.Elem(row)
+			if isNull(element) {
+				continue
+			}
+			values[row] = normalizeString(element.String(), normalize)
+		}
+
+		result = result.Mutate(series.New(values, series.String, normalize.Column))
+		if result.Err != nil {
+			return nil, domainerrors.NewDataProcessError("normalize", fmt.Sprintf("failed to normalize column '%s'", normalize.Column), result.Err)
+		}
+	}
+
+	return &result, nil
+}
+
+// normalizeString trims the value and applies the optional normalizations of the config.
+func normalizeString(value string, config entities.NormalizeConfig) string {
+	if config.CollapseSpaces {
+		value = strings.Join(strings.Fields(value), " ")
+	} else {
+		value = strings.TrimSpace(value)
+	}
+	if config.Lowercase {
+		value = strings.ToLower(value)
+	}
+
+	return value
+}
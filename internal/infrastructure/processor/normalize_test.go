@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "region", "name"},
+		[]string{"1", "east ", "  Alice   Smith "},
+		[]string{"2", " West", "BOB\tJones"},
+		[]string{"3", "", "carol"},
+	)
+
+	tests := []struct {
+		name   string
+		config entities.NormalizeConfig
+		want   [][]string
+	}{
+		{
+			name:   "trim",
+			config: entities.NormalizeConfig{Column: "name"},
+			want:   [][]string{{"name"}, {"Alice   Smith"}, {"BOB\tJones"}, {"carol"}},
+		},
+		{
+			name:   "collapse spaces",
+			config: entities.NormalizeConfig{Column: "name", CollapseSpaces: true},
+			want:   [][]string{{"name"}, {"Alice Smith"}, {"BOB Jones"}, {"carol"}},
+		},
+		{
+			name:   "lowercase",
+			config: entities.NormalizeConfig{Column: "name", CollapseSpaces: true, Lowercase: true},
+			want:   [][]string{{"name"}, {"alice smith"}, {"bob jones"}, {"carol"}},
+		},
+		{
+			name:   "null stays null",
+			config: entities.NormalizeConfig{Column: "region"},
+			want:   [][]string{{"region"}, {"east"}, {"West"}, {"NaN"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NewGotaProcessor().Normalize(context.Background(), df, []entities.NormalizeConfig{tt.config})
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+
+			column := normalized.Select([]string{tt.config.Column})
+			assertRecords(t, &column, tt.want)
+		})
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	df := loadFrame([]string{"id", "region"}, []string{"1", "east"})
+
+	tests := []struct {
+		name   string
+		config entities.NormalizeConfig
+	}{
+		{name: "no column", config: entities.NormalizeConfig{}},
+		{name: "missing column", config: entities.NormalizeConfig{Column: "name"}},
+		{name: "int column", config: entities.NormalizeConfig{Column: "id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGotaProcessor().Normalize(context.Background(), df, []entities.NormalizeConfig{tt.config}); err == nil {
+				t.Error("Normalize() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"github.com/go-gota/gota/dataframe"
+	"time"
+)
+
+// Ensure GotaProcessor implements the ObservableProcessor interface
+var _ interfaces.ObservableProcessor = (*GotaProcessor)(nil)
+
+// Observer returns the Observer of the processor (see GotaProcessorOptions.Observer), which never is nil.
+func (p *GotaProcessor) Observer() interfaces.Observer {
+	return p.observer
+}
+
+// observe notifies the Observer of the start of the step over the data, and returns the function notifying the end
+// of the step with its result and error, to be deferred by the step with the pointers to its named results.
+func (p *GotaProcessor) observe(step string, data *dataframe.DataFrame) func(result **dataframe.DataFrame, err *error) {
+	inputRows := 0
+	if data != nil {
+		inputRows = data.Nrow()
+	}
+	p.observer.OnStepStart(step, inputRows)
+	start := utils.Now()
+
+	return func(result **dataframe.DataFrame, err *error) {
+		outputRows := inputRows
+		if *err == nil && *result != nil {
+			outputRows = (*result).Nrow()
+		}
+		p.observer.OnStepEnd(step, outputRows, utils.Now().Sub(start), *err)
+	}
+}
+
+// nopObserver observes nothing
+type nopObserver struct{}
+
+// Ensure nopObserver implements the Observer interface
+var _ interfaces.Observer = nopObserver{}
+
+func (nopObserver) OnStepStart(string, int)                     {}
+func (nopObserver) OnStepEnd(string, int, time.Duration, error) {}
@@ -0,0 +1,112 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingObserver records the callbacks as "start <name> <inputRows>" and "end <name> <outputRows> <d> <err>"
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) OnStepStart(name string, inputRows int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf("start %s %d", name, inputRows))
+}
+
+func (o *recordingObserver) OnStepEnd(name string, outputRows int, d time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf("end %s %d %v %v", name, outputRows, d, err))
+}
+
+// steppingClock advances by a second on every reading, so the duration of a step is the number of its readings
+type steppingClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func TestObserverMultiStageRun(t *testing.T) {
+	t.Cleanup(utils.SetClock(&steppingClock{now: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)}))
+	observer := &recordingObserver{}
+	processor := NewGotaProcessorWithOptions(GotaProcessorOptions{Observer: observer})
+	ctx := context.Background()
+
+	df := loadFrame(
+		[]string{"region", "price", "units"},
+		[]string{"east", "10", "2"},
+		[]string{"west", "5", "1"},
+		[]string{"east", "3", "4"},
+		[]string{"west", "1", "0"},
+	)
+	filtered, err := processor.Filter(ctx, df, []entities.FilterConfig{{Column: "units", Operator: "gt", Value: "0", LogicalOperator: "and"}})
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	merged, err := processor.Merge(ctx, filtered, []entities.MergeConfig{{FirstColumn: "price", SecondColumn: "units", Strategy: "sum", ResultColumnName: "total"}})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if _, err := processor.Aggregate(ctx, merged, groupBy("region", entities.Aggregation{Column: "total", AggregateMethod: "sum", ResultName: "total"})); err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	// Each step reads the clock at its start and its end, and the filter once more to resolve the relative dates
+	want := []string{
+		"start filter 4",
+		"end filter 3 2s <nil>",
+		"start merge 3",
+		"end merge 3 1s <nil>",
+		"start aggregate 3",
+		"end aggregate 2 1s <nil>",
+	}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("events = %q, want %q", observer.events, want)
+	}
+}
+
+func TestObserverFailedStep(t *testing.T) {
+	t.Cleanup(utils.SetClock(&steppingClock{}))
+	observer := &recordingObserver{}
+	processor := NewGotaProcessorWithOptions(GotaProcessorOptions{Observer: observer})
+
+	df := loadFrame([]string{"region", "amount"}, []string{"east", "10"}, []string{"west", "5"})
+	_, err := processor.Aggregate(context.Background(), df, groupBy("country", entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"}))
+	if err == nil {
+		t.Fatalf("Aggregate() error = nil, want the missing grouping column")
+	}
+
+	// The failed step ends with its error and the input rows
+	want := []string{"start aggregate 2", fmt.Sprintf("end aggregate 2 1s %v", err)}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("events = %q, want %q", observer.events, want)
+	}
+}
+
+func TestObserverNil(t *testing.T) {
+	processor := NewGotaProcessorWithOptions(GotaProcessorOptions{})
+	if _, ok := processor.Observer().(nopObserver); !ok {
+		t.Errorf("Observer() = %T, want the nopObserver of the nil observer", processor.Observer())
+	}
+
+	df := loadFrame([]string{"amount"}, []string{"10"})
+	if _, err := processor.Filter(context.Background(), df, nil); err != nil {
+		t.Errorf("Filter() error = %v, want no error without an observer", err)
+	}
+}
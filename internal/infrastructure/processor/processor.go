@@ -0,0 +1,257 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/SHIMA0111/kanjo/internal/domain/utils"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/parser"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// Ensure GotaProcessor implements the Processor interface
+var _ interfaces.Processor = (*GotaProcessor)(nil)
+
+// defaultCancellationCheckInterval is the default number of rows processed between the context checks
+const defaultCancellationCheckInterval = 10000
+
+// GotaProcessorOptions holds the tuning options of GotaProcessor.
+// Workers represents the number of goroutines aggregating the groups in parallel (less than 1 for serial).
+// CancellationCheckInterval represents the number of rows processed between the context checks in the long-running
+// loops of Filter and Aggregate (0 for the default 10000).
+// DivideByZero represents the policy of the divisions by zero in Aggregate, Compute, and Merge (DivideByZeroNull by default).
+// CollectBadRows diverts the rows failing Cast (a value that cannot be cast) or Compute (a division by zero
+// under DivideByZeroError) to the RejectFunc of the context instead of failing the step,
+// and the step continues on the good rows.
+// AllowEmptyResult represents whether Filter returns the empty DataFrame when no rows match (nil for the default true).
+// When false, Filter fails with a recoverable DataProcessError wrapping ErrEmptyResult instead.
+// ReorderFilters evaluates the filters of each AND chain of Filter from the most selective one estimated on
+// the sampled rows instead of the config order. The result is the same either way, and false keeps the evaluation
+// in the config order, e.g. to compare the step performance between runs (false by default).
+// Location represents the time zone the relative date tokens of the filters are resolved in (nil for UTC),
+// e.g. "today" is the date of the location when the filter is applied (see entities.ResolveRelativeDate).
+// Observer receives the start and the end of every stage method call, e.g. Filter, Merge, and Aggregate,
+// named after the method in lower camel case (nil to observe nothing). See interfaces.Observer.
+// StreamAggregate represents whether Aggregate computes the configurations using only sum, avg, count, min, and max
+// in a single pass with an accumulator per group (nil for the default true). When false, they buffer the rows of
+// each group across the Workers as the other configurations do, e.g. to spread many groups over many CPUs.
+// The results are identical either way.
+type GotaProcessorOptions struct {
+	Workers                   int
+	CancellationCheckInterval int
+	DivideByZero              DivideByZeroPolicy
+	CollectBadRows            bool
+	AllowEmptyResult          *bool
+	ReorderFilters            bool
+	Location                  *time.Location
+	Observer                  interfaces.Observer
+	StreamAggregate           *bool
+}
+
+// ErrEmptyResult is the cause of the error of Filter matching no rows when the empty result is not allowed
+var ErrEmptyResult = errors.New("no rows match the filters")
+
+// GotaProcessor implements the Processor interface against gota DataFrames.
+// All operations return a new DataFrame and never modify the input DataFrame.
+type GotaProcessor struct {
+	workers        int // workers represents the number of goroutines aggregating the groups in parallel (1 for serial)
+	checkInterval  int // checkInterval represents the number of rows processed between the context checks
+	divideByZero   DivideByZeroPolicy
+	collectBadRows bool           // collectBadRows diverts the rows failing Cast or Compute instead of failing the step
+	allowEmpty     bool           // allowEmpty returns the empty DataFrame from Filter instead of failing
+	reorderFilters bool           // reorderFilters evaluates the most selective filters of each AND chain first
+	location       *time.Location // location represents the time zone of the relative date tokens of the filters
+	observer       interfaces.Observer
+	stream         bool // stream aggregates the streamable configurations in a single pass instead of buffering the groups
+}
+
+// NewGotaProcessor creates a new GotaProcessor instance aggregating with runtime.NumCPU() workers.
+func NewGotaProcessor() *GotaProcessor {
+	return NewGotaProcessorWithWorkers(runtime.NumCPU())
+}
+
+// NewGotaProcessorWithWorkers creates a new GotaProcessor instance aggregating with the given number of workers.
+// Workers less than 1 are treated as 1, which aggregates serially.
+func NewGotaProcessorWithWorkers(workers int) *GotaProcessor {
+	return NewGotaProcessorWithOptions(GotaProcessorOptions{Workers: workers})
+}
+
+// NewGotaProcessorWithOptions creates a new GotaProcessor instance with the options.
+// Unknown DivideByZero policies are treated as DivideByZeroNull.
+func NewGotaProcessorWithOptions(options GotaProcessorOptions) *GotaProcessor {
+	checkInterval := options.CancellationCheckInterval
+	if checkInterval <= 0 {
+		checkInterval = defaultCancellationCheckInterval
+	}
+
+	divideByZero := options.DivideByZero
+	if divideByZero < DivideByZeroNull || divideByZero > DivideByZeroError {
+		divideByZero = DivideByZeroNull
+	}
+
+	location := options.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	observer := options.Observer
+	if observer == nil {
+		observer = nopObserver{}
+	}
+
+	return &GotaProcessor{
+		workers:        max(options.Workers, 1),
+		checkInterval:  checkInterval,
+		divideByZero:   divideByZero,
+		collectBadRows: options.CollectBadRows,
+		allowEmpty:     options.AllowEmptyResult == nil || *options.AllowEmptyResult,
+		reorderFilters: options.ReorderFilters,
+		location:       location,
+		observer:       observer,
+		stream:         options.StreamAggregate == nil || *options.StreamAggregate,
+	}
+}
+
+// now returns the current time in the location of the processor, against which the relative dates are resolved.
+func (p *GotaProcessor) now() time.Time {
+	return utils.Now().In(p.location)
+}
+
+// Filter keeps the rows matching the filter configurations. See BuildFilterSeries for the combination rules.
+// The context is checked every checkInterval rows, so the cancellation stops the filter promptly.
+// Returns the DataFrame of the columns without rows if no rows match, unless the processor disallows the empty result
+// (see GotaProcessorOptions.AllowEmptyResult).
+func (p *GotaProcessor) Filter(ctx context.Context, data *dataframe.DataFrame, config []entities.FilterConfig) (observed *dataframe.DataFrame, err error) {
+	defer p.observe("filter", data)(&observed, &err)
+
+	mask, err := p.matchFilters(ctx, data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := data.Subset(mask)
+	if filtered.Err != nil {
+		return nil, domainerrors.NewDataProcessError("filter", "failed to subset rows", filtered.Err)
+	}
+	if filtered.Nrow() == 0 && data.Nrow() > 0 && !p.allowEmpty {
+		return nil, domainerrors.NewRecoverableDataProcessError(
+			"filter",
+			fmt.Sprintf("filters matched none of the %d rows", data.Nrow()),
+			ErrEmptyResult,
+			"loosen the filters or check their values against the data",
+		)
+	}
+
+	return &filtered, nil
+}
+
+// FilterMask returns the 0-based indexes of the rows matching the filter configurations in the row order,
+// the rows Filter keeps. The empty result is returned as an empty slice regardless of the AllowEmptyResult option.
+func (p *GotaProcessor) FilterMask(ctx context.Context, data *dataframe.DataFrame, config []entities.FilterConfig) ([]int, error) {
+	mask, err := p.matchFilters(ctx, data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, mask.Len())
+	for row := 0; row < mask.Len(); row++ {
+		if matched, _ := mask.Elem(row).Bool(); matched {
+			indexes = append(indexes, row)
+		}
+	}
+
+	return indexes, nil
+}
+
+// matchFilters validates the filter configurations and returns the mask of the rows matching them,
+// resolving the relative dates against the current time of the processor.
+// The configurations already validated (e.g. by the pipeline) keep their parsed values and loaded ValuesFile,
+// and only the others are validated. They are validated as copies to cache the parsed values, so the caller's
+// configurations are never modified and the same configurations can be filtered concurrently.
+func (p *GotaProcessor) matchFilters(ctx context.Context, data *dataframe.DataFrame, config []entities.FilterConfig) (series.Series, error) {
+	if err := ctx.Err(); err != nil {
+		return series.Series{}, domainerrors.NewDataProcessError("filter", "filter is canceled", err)
+	}
+
+	cloned := false
+	for i := range config {
+		if config[i].IsValidated() {
+			continue
+		}
+		if !cloned {
+			config = slices.Clone(config)
+			cloned = true
+		}
+		if err := config[i].Validate(); err != nil {
+			return series.Series{}, domainerrors.NewDataProcessError("filter", fmt.Sprintf("filter[%d] is invalid", i), err)
+		}
+	}
+
+	resolved, err := resolveRelativeDates(data, config, p.now())
+	if err != nil {
+		return series.Series{}, err
+	}
+
+	return buildFilterMask(ctx, data, resolved, p.checkInterval, p.reorderFilters)
+}
+
+// ValidateExpression checks if a filter expression is syntactically valid and refers to the available columns
+// with the supported operators. See parser.ParseFilterExpression for the syntax.
+// The returned ConfigurationError wraps a parser.SyntaxError holding the position of the invalid part.
+func (p *GotaProcessor) ValidateExpression(expression string, columnNames []string) error {
+	terms, err := parser.ParseFilterExpression(expression)
+	if err != nil {
+		return expressionError(err)
+	}
+
+	operators := p.GetSupportedOperators()
+	for _, term := range terms {
+		if !slices.Contains(columnNames, term.Column) {
+			return expressionError(&parser.SyntaxError{
+				Position: term.ColumnPosition,
+				Message:  fmt.Sprintf("column '%s' not found", term.Column),
+			})
+		}
+
+		if !slices.Contains(operators, term.Operator) {
+			return expressionError(&parser.SyntaxError{
+				Position: term.OperatorPosition,
+				Message:  fmt.Sprintf("unsupported operator '%s', supported: %v", term.Operator, operators),
+			})
+		}
+	}
+
+	return nil
+}
+
+// expressionError wraps the SyntaxError of the filter expression into a ConfigurationError.
+func expressionError(err error) error {
+	return domainerrors.NewConfigurationError("expression", fmt.Sprintf("invalid filter expression: %v", err), err)
+}
+
+// GetSupportedOperators returns the filter operators accepted by FilterConfig.Validate.
+func (p *GotaProcessor) GetSupportedOperators() []string {
+	return entities.SupportedFilterOperators()
+}
+
+// GetSupportedLogicalOperators returns the logical operators accepted by FilterConfig.Validate.
+func (p *GotaProcessor) GetSupportedLogicalOperators() []string {
+	return entities.SupportedLogicalOperators()
+}
+
+// GetSupportedMergeStrategies returns the merge strategies accepted by MergeConfig.Validate.
+func (p *GotaProcessor) GetSupportedMergeStrategies() []string {
+	return entities.SupportedMergeStrategies()
+}
+
+// GetSupportedAggregations returns the aggregate methods accepted by Aggregation.Validate.
+func (p *GotaProcessor) GetSupportedAggregations() []string {
+	return entities.SupportedAggregateMethods()
+}
@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/parser"
+	"github.com/go-gota/gota/dataframe"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// loadFrame loads the records (the first one is the header) into a DataFrame detecting the column types as CSV.
+func loadFrame(records ...[]string) *dataframe.DataFrame {
+	df := dataframe.LoadRecords(records)
+
+	return &df
+}
+
+// assertRecords fails the test unless the records of the DataFrame (the first one is the header) are the wanted ones.
+func assertRecords(t *testing.T, df *dataframe.DataFrame, want [][]string) {
+	t.Helper()
+
+	if df == nil {
+		t.Fatalf("DataFrame = nil, want %v", want)
+	}
+	if got := df.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
+
+func TestValidateExpression(t *testing.T) {
+	columns := []string{"price", "status", "customer name"}
+
+	tests := []struct {
+		name       string
+		expression string
+		position   int // 0 for the valid expressions
+	}{
+		{name: "valid", expression: `price >= 100 and "customer name" eq 'ACME' or status == paid`},
+		{name: "valid named operators", expression: "price lt 10"},
+		{name: "unknown column", expression: "price > 1 and amount < 5", position: 15},
+		{name: "malformed syntax", expression: "price > ", position: 9},
+		{name: "unknown symbol operator", expression: "price =< 1", position: 7},
+		{name: "unknown named operator", expression: "status like paid", position: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewGotaProcessor().ValidateExpression(tt.expression, columns)
+			if tt.position == 0 {
+				if err != nil {
+					t.Errorf("ValidateExpression() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !domainerrors.IsConfigurationError(err) {
+				t.Fatalf("ValidateExpression() error = %v, want a ConfigurationError", err)
+			}
+			var syntaxError *parser.SyntaxError
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("ValidateExpression() error = %v, want a wrapped SyntaxError", err)
+			}
+			if syntaxError.Position != tt.position {
+				t.Errorf("SyntaxError.Position = %d, want %d (%v)", syntaxError.Position, tt.position, err)
+			}
+		})
+	}
+}
+
+func TestGetSupportedLogicalOperatorsMatchesValidate(t *testing.T) {
+	supported := NewGotaProcessor().GetSupportedLogicalOperators()
+	if len(supported) == 0 {
+		t.Fatal("GetSupportedLogicalOperators() is empty")
+	}
+
+	for _, logicalOperator := range supported {
+		filter := entities.FilterConfig{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: logicalOperator}
+		if err := filter.Validate(); err != nil {
+			t.Errorf("Validate() with the supported %q error = %v, want nil", logicalOperator, err)
+		}
+	}
+
+	for _, logicalOperator := range []string{"", "xor", "not", "AND", "&&"} {
+		filter := entities.FilterConfig{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: logicalOperator}
+		if err := filter.Validate(); err == nil {
+			t.Errorf("Validate() with the unsupported %q error = nil, want an error", logicalOperator)
+		}
+	}
+
+	// The returned slice is a copy, so the caller cannot change the supported set
+	supported[0] = "xor"
+	if got := NewGotaProcessor().GetSupportedLogicalOperators(); slices.Contains(got, "xor") {
+		t.Errorf("GetSupportedLogicalOperators() = %v after modifying the returned slice", got)
+	}
+}
@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"sync"
+)
+
+// progressReporter reports the progress to the ProgressFunc of the context every interval units.
+// It is safe for concurrent use, and the reported done values never decrease.
+// It does nothing if the context doesn't carry a ProgressFunc.
+type progressReporter struct {
+	mu       sync.Mutex
+	report   interfaces.ProgressFunc
+	total    int
+	interval int
+	done     int
+	reported int
+}
+
+// newProgressReporter creates a new progressReporter of the step with the estimated total units (-1 if unknown).
+// The interval of 0 or less reports every defaultCancellationCheckInterval units.
+func newProgressReporter(ctx context.Context, total, interval int) *progressReporter {
+	if interval <= 0 {
+		interval = defaultCancellationCheckInterval
+	}
+
+	return &progressReporter{
+		report:   interfaces.ProgressFromContext(ctx),
+		total:    total,
+		interval: interval,
+	}
+}
+
+// add counts the processed units and reports them once the interval is reached.
+func (r *progressReporter) add(units int) {
+	if r.report == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done += units
+	if r.total >= 0 && r.done > r.total {
+		// The estimate was too small
+		r.total = -1
+	}
+	if r.done-r.reported >= r.interval {
+		r.reported = r.done
+		r.report(r.done, r.total)
+	}
+}
+
+// finish reports the completion with done equal to total.
+func (r *progressReporter) finish() {
+	if r.report == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := max(r.total, r.done)
+	r.done, r.reported = total, total
+	r.report(total, total)
+}
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"testing"
+)
+
+// progressCall is a call of the ProgressFunc
+type progressCall struct {
+	done, total int
+}
+
+// recordProgress returns the context carrying the ProgressFunc recording its calls.
+func recordProgress(calls *[]progressCall) context.Context {
+	return interfaces.WithProgress(context.Background(), func(done, total int) {
+		*calls = append(*calls, progressCall{done: done, total: total})
+	})
+}
+
+// assertProgress checks the done values never decrease and the final call reports done equal to the total.
+func assertProgress(t *testing.T, calls []progressCall, total int) {
+	t.Helper()
+
+	if len(calls) == 0 {
+		t.Fatal("ProgressFunc is never invoked")
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i].done < calls[i-1].done {
+			t.Errorf("done decreases from %d to %d at call %d", calls[i-1].done, calls[i].done, i)
+		}
+	}
+	if last := calls[len(calls)-1]; last != (progressCall{done: total, total: total}) {
+		t.Errorf("final call = %+v, want done and total %d", last, total)
+	}
+}
+
+func TestAggregateReportsProgress(t *testing.T) {
+	const rows = 1000
+	df := benchmarkData(rows, 40)
+	streamable := groupBy("key",
+		entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+		entities.Aggregation{Column: "amount", AggregateMethod: "max", ResultName: "maximum"},
+	)
+	buffered := groupBy("key",
+		entities.Aggregation{Column: "amount", AggregateMethod: "median", ResultName: "middle"},
+		entities.Aggregation{Column: "amount", AggregateMethod: "count", ResultName: "rows"},
+	)
+	stream, buffer := true, false
+
+	tests := []struct {
+		name    string
+		options GotaProcessorOptions
+		config  []entities.AggregationConfig
+	}{
+		{name: "stream", options: GotaProcessorOptions{StreamAggregate: &stream}, config: streamable},
+		{name: "buffered", options: GotaProcessorOptions{StreamAggregate: &buffer}, config: streamable},
+		{name: "median", options: GotaProcessorOptions{}, config: buffered},
+		{name: "parallel", options: GotaProcessorOptions{Workers: 4}, config: buffered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []progressCall
+			tt.options.CancellationCheckInterval = 100
+			processor := NewGotaProcessorWithOptions(tt.options)
+			if _, err := processor.Aggregate(recordProgress(&calls), df, tt.config); err != nil {
+				t.Fatalf("Aggregate() error = %v", err)
+			}
+
+			// Grouping and each of the two aggregations scan every row
+			total := rows * 3
+			assertProgress(t, calls, total)
+			if len(calls) < 2 {
+				t.Errorf("calls = %v, want the periodic calls before the final one", calls)
+			}
+			for _, call := range calls {
+				if call.total != total {
+					t.Errorf("call = %+v, want the total %d", call, total)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateReportsProgressOfChainedConfigurations(t *testing.T) {
+	df := benchmarkData(500, 50)
+	config := append(
+		groupBy("key", entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"}),
+		groupBy("total", entities.Aggregation{Column: "key", AggregateMethod: "count", ResultName: "keys"})...,
+	)
+
+	var calls []progressCall
+	if _, err := NewGotaProcessorWithOptions(GotaProcessorOptions{CancellationCheckInterval: 10}).Aggregate(recordProgress(&calls), df, config); err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	// The second configuration scans only the 50 groups, so done jumps to the total estimated from the input at the end
+	total := 500 * 4
+	assertProgress(t, calls, total)
+	if scanned := calls[len(calls)-2].done; scanned != 500*2+50*2 {
+		t.Errorf("done before the final call = %d, want the scanned rows %d", scanned, 500*2+50*2)
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		units []int
+		want  []progressCall
+	}{
+		{
+			name:  "estimated",
+			total: 10,
+			units: []int{3, 3, 3, 1},
+			want:  []progressCall{{6, 10}, {10, 10}, {10, 10}},
+		},
+		{
+			name:  "unknown",
+			total: -1,
+			units: []int{5, 2},
+			want:  []progressCall{{5, -1}, {7, 7}},
+		},
+		{
+			name:  "estimate too small",
+			total: 4,
+			units: []int{5, 1},
+			want:  []progressCall{{5, -1}, {6, 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []progressCall
+			reporter := newProgressReporter(recordProgress(&calls), tt.total, 4)
+			for _, units := range tt.units {
+				reporter.add(units)
+			}
+			reporter.finish()
+
+			if fmt.Sprint(calls) != fmt.Sprint(tt.want) {
+				t.Errorf("calls = %v, want %v", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressReporterWithoutProgressFunc(t *testing.T) {
+	// Nothing to report to, so neither call panics
+	reporter := newProgressReporter(context.Background(), 10, 1)
+	reporter.add(5)
+	reporter.finish()
+}
@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"github.com/go-gota/gota/series"
+	"math"
+	"slices"
+)
+
+// p2Markers is the number of the markers tracked by the P² estimator
+const p2Markers = 5
+
+// p2Quantile estimates a quantile of a stream of numbers in constant memory with the P² algorithm
+// (Jain and Chlamtac, 1985). It keeps five markers whose heights are adjusted by a piecewise-parabolic
+// interpolation as the numbers arrive, and the middle marker estimates the quantile.
+//
+// The estimate is exact up to five numbers. Beyond that, P² has no worst-case guarantee because the markers
+// depend on the arrival order, but for the continuous distributions (uniform, normal, exponential, and heavy-tailed
+// products of normals) the rank of the estimated median and 95th percentile is observed within 2.5% of the numbers
+// from the exact rank for 1,000 numbers, within 1.5% for 10,000, and within 0.2% for 1,000,000 (e.g. the estimated
+// median of 1,000,000 numbers lies between the 49.8th and the 50.2nd percentiles). Sorted or heavily clustered input
+// may drift further, so such data should be aggregated exactly.
+type p2Quantile struct {
+	quantile  float64
+	count     int
+	heights   [p2Markers]float64
+	positions [p2Markers]float64
+	desired   [p2Markers]float64
+	increment [p2Markers]float64
+}
+
+// newP2Quantile creates a new p2Quantile estimating the quantile between 0 and 1 (0.5 for the median).
+func newP2Quantile(quantile float64) *p2Quantile {
+	return &p2Quantile{
+		quantile:  quantile,
+		desired:   [p2Markers]float64{0, 2 * quantile, 4 * quantile, 2 + 2*quantile, 4},
+		increment: [p2Markers]float64{0, quantile / 2, quantile, (1 + quantile) / 2, 1},
+	}
+}
+
+// add observes the number.
+func (q *p2Quantile) add(x float64) {
+	if q.count < p2Markers {
+		q.heights[q.count] = x
+		q.count++
+		if q.count == p2Markers {
+			slices.Sort(q.heights[:])
+			for i := range q.positions {
+				q.positions[i] = float64(i)
+			}
+		}
+		return
+	}
+	q.count++
+
+	// Find the cell of the number extending the extreme markers if necessary
+	var cell int
+	switch {
+	case x < q.heights[0]:
+		q.heights[0] = x
+		cell = 0
+	case x >= q.heights[p2Markers-1]:
+		q.heights[p2Markers-1] = x
+		cell = p2Markers - 2
+	default:
+		for cell = 0; cell < p2Markers-2 && x >= q.heights[cell+1]; cell++ {
+		}
+	}
+
+	for i := cell + 1; i < p2Markers; i++ {
+		q.positions[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.increment[i]
+	}
+
+	// Move the middle markers toward their desired positions
+	for i := 1; i < p2Markers-1; i++ {
+		d := q.desired[i] - q.positions[i]
+		if (d >= 1 && q.positions[i+1]-q.positions[i] > 1) || (d <= -1 && q.positions[i-1]-q.positions[i] < -1) {
+			step := math.Copysign(1, d)
+			height := q.parabolic(i, step)
+			if height <= q.heights[i-1] || height >= q.heights[i+1] {
+				height = q.linear(i, step)
+			}
+			q.heights[i] = height
+			q.positions[i] += step
+		}
+	}
+}
+
+// parabolic returns the height of the marker i moved by the step with the piecewise-parabolic formula.
+func (q *p2Quantile) parabolic(i int, step float64) float64 {
+	n, h := q.positions, q.heights
+	return h[i] + step/(n[i+1]-n[i-1])*((n[i]-n[i-1]+step)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+		(n[i+1]-n[i]-step)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+// linear returns the height of the marker i moved by the step with the linear formula.
+func (q *p2Quantile) linear(i int, step float64) float64 {
+	j := i + int(step)
+	return q.heights[i] + step*(q.heights[j]-q.heights[i])/(q.positions[j]-q.positions[i])
+}
+
+// value returns the estimated quantile, or false if no numbers are observed.
+// Up to five numbers, the exact quantile interpolating the two closest numbers is returned.
+func (q *p2Quantile) value() (float64, bool) {
+	if q.count == 0 {
+		return 0, false
+	}
+	if q.count <= p2Markers {
+		sorted := slices.Clone(q.heights[:q.count])
+		slices.Sort(sorted)
+		rank := q.quantile * float64(len(sorted)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+
+		return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower)), true
+	}
+
+	return q.heights[p2Markers/2], true
+}
+
+// approximateMedian estimates the median of the non-null values over the rows of the column with the P² algorithm.
+// Returns nil if all values are null.
+func approximateMedian(column series.Series, rows []int) interface{} {
+	estimator := newP2Quantile(0.5)
+	for _, row := range rows {
+		element := column.Elem(row)
+		if isNull(element) {
+			continue
+		}
+		estimator.add(element.Float())
+	}
+
+	median, ok := estimator.value()
+	if !ok {
+		return nil
+	}
+
+	return median
+}
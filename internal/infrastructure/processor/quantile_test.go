@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// quantileDistributions generate the continuous distributions the error bound of p2Quantile is documented for
+var quantileDistributions = map[string]func(r *rand.Rand) float64{
+	"uniform":     func(r *rand.Rand) float64 { return r.Float64() * 1000 },
+	"normal":      func(r *rand.Rand) float64 { return r.NormFloat64()*15 + 100 },
+	"exponential": func(r *rand.Rand) float64 { return r.ExpFloat64() * 50 },
+	"product":     func(r *rand.Rand) float64 { return r.NormFloat64() * r.NormFloat64() },
+}
+
+// rankOf returns the fraction of the sorted numbers less than the value.
+func rankOf(sorted []float64, value float64) float64 {
+	index, _ := slices.BinarySearch(sorted, value)
+
+	return float64(index) / float64(len(sorted))
+}
+
+func TestP2QuantileWithinDocumentedBound(t *testing.T) {
+	// The sizes and the tolerances of the rank documented on p2Quantile
+	bounds := []struct {
+		n         int
+		tolerance float64
+	}{
+		{n: 1_000, tolerance: 0.025},
+		{n: 10_000, tolerance: 0.015},
+		{n: 1_000_000, tolerance: 0.002},
+	}
+	if testing.Short() {
+		bounds = bounds[:2]
+	}
+
+	for name, generate := range quantileDistributions {
+		for _, bound := range bounds {
+			t.Run(fmt.Sprintf("%s/n=%d", name, bound.n), func(t *testing.T) {
+				r := rand.New(rand.NewPCG(uint64(bound.n), 887))
+				numbers := make([]float64, bound.n)
+				for i := range numbers {
+					numbers[i] = generate(r)
+				}
+				sorted := slices.Clone(numbers)
+				slices.Sort(sorted)
+
+				for _, quantile := range []float64{0.5, 0.95} {
+					estimator := newP2Quantile(quantile)
+					for _, x := range numbers {
+						estimator.add(x)
+					}
+					estimate, ok := estimator.value()
+					if !ok {
+						t.Fatalf("p%v value() = not ok", quantile*100)
+					}
+
+					if rank := rankOf(sorted, estimate); math.Abs(rank-quantile) > bound.tolerance {
+						t.Errorf("p%v = %v at the rank %.4f, want within %v of %v", quantile*100, estimate, rank, bound.tolerance, quantile)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestP2QuantileExactUpToFiveNumbers(t *testing.T) {
+	numbers := []float64{40, 10, 50, 20, 30}
+
+	for n := 1; n <= len(numbers); n++ {
+		sorted := slices.Clone(numbers[:n])
+		slices.Sort(sorted)
+
+		for _, quantile := range []float64{0.5, 0.95} {
+			estimator := newP2Quantile(quantile)
+			for _, x := range numbers[:n] {
+				estimator.add(x)
+			}
+
+			rank := quantile * float64(n-1)
+			lower, upper := sorted[int(math.Floor(rank))], sorted[int(math.Ceil(rank))]
+			want := lower + (upper-lower)*(rank-math.Floor(rank))
+			if got, ok := estimator.value(); !ok || got != want {
+				t.Errorf("p%v of %v = %v, want %v", quantile*100, numbers[:n], got, want)
+			}
+		}
+	}
+
+	if _, ok := newP2Quantile(0.5).value(); ok {
+		t.Error("value() of no numbers = ok, want not ok")
+	}
+}
+
+func TestAggregateApproximateMedianOfLargeGroups(t *testing.T) {
+	const rows = 200_000
+	r := rand.New(rand.NewPCG(1, 887))
+	groups := []string{"east", "west"}
+	records := [][]string{{"group", "amount"}}
+	values := make(map[string][]float64)
+	for i := range rows {
+		group := groups[i%len(groups)]
+		value := r.ExpFloat64() * 50
+		values[group] = append(values[group], value)
+		records = append(records, []string{group, fmt.Sprint(value)})
+	}
+	df := loadFrame(records...)
+
+	config := groupBy("group", entities.Aggregation{Column: "amount", AggregateMethod: "median", ResultName: "median", Approximate: true})
+	aggregated, err := NewGotaProcessor().Aggregate(context.Background(), df, config)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	// 100,000 numbers per group lie between the documented bounds of 10,000 and 1,000,000 numbers
+	for i, group := range groups {
+		sorted := slices.Clone(values[group])
+		slices.Sort(sorted)
+		estimate := aggregated.Col("median").Elem(i).Float()
+		if rank := rankOf(sorted, estimate); math.Abs(rank-0.5) > 0.015 {
+			t.Errorf("approximate median of %s = %v at the rank %.4f, want within 0.015 of 0.5", group, estimate, rank)
+		}
+	}
+}
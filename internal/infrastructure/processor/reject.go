@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/go-gota/gota/dataframe"
+	"slices"
+)
+
+// rowFailures maps the index of the rows failing a step to the reason of the first failure of each row
+type rowFailures map[int]string
+
+// add records the failure of the row unless the row has already failed.
+func (f rowFailures) add(row int, reason string) {
+	if _, ok := f[row]; !ok {
+		f[row] = reason
+	}
+}
+
+// rejectRows removes the failed rows from the result of the step and reports them with their values in the input
+// to the RejectFunc of the context, if any. The input and the result must have the same rows.
+func rejectRows(ctx context.Context, step string, input, result *dataframe.DataFrame, failures rowFailures) (*dataframe.DataFrame, error) {
+	if len(failures) == 0 {
+		return result, nil
+	}
+
+	failed := make([]int, 0, len(failures))
+	for row := range failures {
+		failed = append(failed, row)
+	}
+	slices.Sort(failed)
+
+	if reject := interfaces.RejectFuncFromContext(ctx); reject != nil {
+		for _, row := range failed {
+			values := make([]interface{}, input.Ncol())
+			for j := range values {
+				if element := input.Elem(row, j); !isNull(element) {
+					values[j] = element.Val()
+				}
+			}
+			reject(step, values, failures[row])
+		}
+	}
+
+	kept := make([]int, 0, result.Nrow()-len(failed))
+	for row := 0; row < result.Nrow(); row++ {
+		if _, ok := failures[row]; !ok {
+			kept = append(kept, row)
+		}
+	}
+
+	filtered := result.Subset(kept)
+	if filtered.Err != nil {
+		return nil, domainerrors.NewDataProcessError(step, fmt.Sprintf("failed to remove %d rejected rows", len(failed)), filtered.Err)
+	}
+
+	return &filtered, nil
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"reflect"
+	"testing"
+)
+
+// rejectedRow is a call of the RejectFunc
+type rejectedRow struct {
+	step   string
+	row    []interface{}
+	reason string
+}
+
+// recordRejects returns the context carrying the RejectFunc recording its calls.
+func recordRejects(rejected *[]rejectedRow) context.Context {
+	return interfaces.WithRejectFunc(context.Background(), func(step string, row []interface{}, reason string) {
+		*rejected = append(*rejected, rejectedRow{step: step, row: row, reason: reason})
+	})
+}
+
+func TestCastCollectsBadRows(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "amount"},
+		[]string{"1", "10"},
+		[]string{"2", "abc"},
+		[]string{"3", "30"},
+		[]string{"4", "12x"},
+		[]string{"5", ""},
+	)
+
+	var rejected []rejectedRow
+	p := NewGotaProcessorWithOptions(GotaProcessorOptions{CollectBadRows: true})
+	cast, err := p.Cast(recordRejects(&rejected), df, []entities.CastConfig{{Column: "amount", To: "int"}})
+	if err != nil {
+		t.Fatalf("Cast() error = %v", err)
+	}
+
+	// The null value is not a bad value
+	assertRecords(t, cast, [][]string{
+		{"id", "amount"},
+		{"1", "10"},
+		{"3", "30"},
+		{"5", "NaN"},
+	})
+	want := []rejectedRow{
+		{step: "cast", row: []interface{}{2, "abc"}, reason: "cannot cast 'abc' of column 'amount' at row 2 to int"},
+		{step: "cast", row: []interface{}{4, "12x"}, reason: "cannot cast '12x' of column 'amount' at row 4 to int"},
+	}
+	if !reflect.DeepEqual(rejected, want) {
+		t.Errorf("rejected = %v, want %v", rejected, want)
+	}
+
+	// Without CollectBadRows the first bad value fails the step
+	if _, err := NewGotaProcessor().Cast(context.Background(), df, []entities.CastConfig{{Column: "amount", To: "int"}}); err == nil {
+		t.Error("Cast() error = nil, want the error of the bad value")
+	}
+}
+
+func TestComputeCollectsBadRows(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "revenue", "quantity"},
+		[]string{"1", "100", "4"},
+		[]string{"2", "50", "0"},
+		[]string{"3", "90", "3"},
+	)
+	computed := []entities.ComputedColumn{{Name: "unitPrice", Expression: "revenue / quantity"}}
+
+	var rejected []rejectedRow
+	p := NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroError, CollectBadRows: true})
+	result, err := p.Compute(recordRejects(&rejected), df, computed)
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+
+	assertRecords(t, result, [][]string{
+		{"id", "revenue", "quantity", "unitPrice"},
+		{"1", "100", "4", "25.000000"},
+		{"3", "90", "3", "30.000000"},
+	})
+	want := []rejectedRow{
+		{step: "compute", row: []interface{}{2, 50, 0}, reason: "computed column 'unitPrice' divides by zero at row 2"},
+	}
+	if !reflect.DeepEqual(rejected, want) {
+		t.Errorf("rejected = %v, want %v", rejected, want)
+	}
+
+	// The other policies don't fail the row, so nothing is rejected
+	rejected = nil
+	p = NewGotaProcessorWithOptions(GotaProcessorOptions{DivideByZero: DivideByZeroNull, CollectBadRows: true})
+	result, err = p.Compute(recordRejects(&rejected), df, computed)
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if result.Nrow() != 3 || len(rejected) != 0 {
+		t.Errorf("Compute() = %d rows and %d rejected, want 3 rows and none rejected", result.Nrow(), len(rejected))
+	}
+}
@@ -0,0 +1,160 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"slices"
+)
+
+// streamableMethods are the aggregate methods computed by the running accumulators of streamAggregateGroups
+var streamableMethods = []string{"sum", "avg", "count", "min", "max"}
+
+// isStreamable reports whether all aggregations of the config use the streamable methods,
+// so the config can be aggregated without buffering the rows of each group.
+func isStreamable(config entities.AggregationConfig) bool {
+	for _, aggregation := range config.Aggregations {
+		if !slices.Contains(streamableMethods, aggregation.AggregateMethod) || aggregation.Approximate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// accumulator holds the running aggregation of the non-null values of a group
+type accumulator struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+}
+
+// add accumulates the value.
+func (a *accumulator) add(value float64) {
+	if a.count == 0 {
+		a.min, a.max = value, value
+	} else {
+		a.min, a.max = min(a.min, value), max(a.max, value)
+	}
+	a.sum += value
+	a.count++
+}
+
+// value returns the result of the method over the accumulated values like aggregateRows.
+// None of the streamable methods divides by zero, as the avg of no values is null.
+func (a *accumulator) value(method string) interface{} {
+	if method == "count" {
+		return a.count
+	}
+	if a.count == 0 {
+		return nil
+	}
+
+	switch method {
+	case "sum":
+		return a.sum
+	case "avg":
+		return a.sum / float64(a.count)
+	case "min":
+		return a.min
+	case "max":
+		return a.max
+	default:
+		return nil
+	}
+}
+
+// streamAggregateGroups computes the aggregations of the streamable methods (see isStreamable) in a single pass
+// over the rows, updating an accumulator per group and aggregation. The memory is proportional to the number of the groups
+// instead of the rows, and the values are accumulated in the row order as the buffered path does, so the results are identical.
+// The pass runs on a single goroutine, checking the context every checkInterval rows.
+func (p *GotaProcessor) streamAggregateGroups(ctx context.Context, df *dataframe.DataFrame, config entities.AggregationConfig, progress *progressReporter) (*dataframe.DataFrame, error) {
+	columns := make([]series.Series, len(config.Aggregations))
+	conditions := make([]func(row int) bool, len(config.Aggregations))
+	for i, aggregation := range config.Aggregations {
+		column, err := aggregatedColumn(df, aggregation)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = column
+
+		if aggregation.Condition != nil {
+			resolved, err := resolveRelativeDates(df, []entities.FilterConfig{*aggregation.Condition}, p.now())
+			if err != nil {
+				return nil, err
+			}
+			match, err := rowMatcher(df, resolved[0])
+			if err != nil {
+				return nil, err
+			}
+			conditions[i] = match
+		}
+	}
+
+	keyColumns := columnsOf(df, config.GroupingColumns)
+	positions := make(map[string]int)
+	var firstRows []int
+	accumulators := make([][]accumulator, len(config.Aggregations))
+
+	checker := newCancellationChecker(ctx, p.checkInterval)
+	for row := 0; row < df.Nrow(); row++ {
+		if err := checker.tick(1); err != nil {
+			return nil, domainerrors.NewDataProcessError("aggregate", fmt.Sprintf("aggregation is canceled at row %d", row), err)
+		}
+		// Counted as the grouping and each aggregation scanning the row, as the buffered path does
+		progress.add(1 + len(config.Aggregations))
+
+		key := rowKey(keyColumns, row)
+		g, ok := positions[key]
+		if !ok {
+			g = len(firstRows)
+			positions[key] = g
+			firstRows = append(firstRows, row)
+			for i := range accumulators {
+				accumulators[i] = append(accumulators[i], accumulator{})
+			}
+		}
+
+		for i, column := range columns {
+			if conditions[i] != nil && !conditions[i](row) {
+				continue
+			}
+			element := column.Elem(row)
+			if isNull(element) {
+				continue
+			}
+			if config.Aggregations[i].AggregateMethod == "count" {
+				accumulators[i][g].count++
+				continue
+			}
+			accumulators[i][g].add(element.Float())
+		}
+	}
+
+	order := groupOrder(df, firstRows, config.GroupingColumns)
+	sortedFirstRows := make([]int, len(order))
+	for i, g := range order {
+		sortedFirstRows[i] = firstRows[g]
+	}
+
+	result := groupKeyColumns(df, config, sortedFirstRows)
+	for i, aggregation := range config.Aggregations {
+		values := make([]interface{}, len(order))
+		dividedByZero := make([]bool, len(order))
+		for j, g := range order {
+			values[j] = accumulators[i][g].value(aggregation.AggregateMethod)
+		}
+
+		column, err := p.aggregationResult(aggregation, values, dividedByZero, sortedFirstRows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, column)
+	}
+
+	return buildAggregatedDataFrame(result)
+}
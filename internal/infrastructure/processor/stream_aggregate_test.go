@@ -0,0 +1,197 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"reflect"
+	"testing"
+)
+
+func TestStreamAggregateMatchesBuffered(t *testing.T) {
+	df := dataframe.LoadRecords([][]string{
+		{"region", "status", "amount"},
+		{"east", "paid", "10.5"},
+		{"west", "paid", "3"},
+		{"east", "open", "NaN"},
+		{"", "paid", "7"},
+		{"west", "open", "-2.25"},
+		{"east", "paid", "4"},
+		{"north", "open", "NaN"},
+	})
+	config := []entities.AggregationConfig{{
+		GroupingColumns: []string{"region"},
+		Aggregations: []entities.Aggregation{
+			{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+			{Column: "amount", AggregateMethod: "avg", ResultName: "average"},
+			{Column: "amount", AggregateMethod: "count", ResultName: "rows"},
+			{Column: "amount", AggregateMethod: "min", ResultName: "lowest"},
+			{Column: "amount", AggregateMethod: "max", ResultName: "highest"},
+			{
+				Column: "amount", AggregateMethod: "sum", ResultName: "paid",
+				Condition: &entities.FilterConfig{Column: "status", Operator: "eq", Value: "paid", LogicalOperator: "and"},
+			},
+		},
+	}}
+
+	buffered := false
+	streamed, err := NewGotaProcessorWithOptions(GotaProcessorOptions{}).Aggregate(context.Background(), &df, config)
+	if err != nil {
+		t.Fatalf("stream aggregate: %v", err)
+	}
+	bufferedResult, err := NewGotaProcessorWithOptions(GotaProcessorOptions{StreamAggregate: &buffered}).Aggregate(context.Background(), &df, config)
+	if err != nil {
+		t.Fatalf("buffered aggregate: %v", err)
+	}
+
+	if got, want := streamed.Records(), bufferedResult.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stream result = %v, want the buffered result %v", got, want)
+	}
+}
+
+func TestStreamAggregateMatchesBufferedShapes(t *testing.T) {
+	sum := entities.Aggregation{Column: "amount", AggregateMethod: "sum", ResultName: "total"}
+	count := entities.Aggregation{Column: "amount", AggregateMethod: "count", ResultName: "rows"}
+	minimum := entities.Aggregation{Column: "amount", AggregateMethod: "min", ResultName: "lowest"}
+	average := entities.Aggregation{Column: "amount", AggregateMethod: "avg", ResultName: "average"}
+
+	tests := []struct {
+		name   string
+		df     *dataframe.DataFrame
+		config []entities.AggregationConfig
+	}{
+		{
+			name:   "int column",
+			df:     loadFrame([]string{"region", "amount"}, []string{"east", "1"}, []string{"west", "2"}, []string{"east", "3"}),
+			config: []entities.AggregationConfig{{GroupingColumns: []string{"region"}, Aggregations: []entities.Aggregation{sum, average, minimum, count}}},
+		},
+		{
+			name: "multiple grouping columns",
+			df: loadFrame(
+				[]string{"region", "status", "amount"},
+				[]string{"east", "paid", "1.5"},
+				[]string{"east", "open", "2"},
+				[]string{"west", "paid", "3"},
+				[]string{"east", "paid", "4"},
+			),
+			config: []entities.AggregationConfig{{GroupingColumns: []string{"region", "status"}, Aggregations: []entities.Aggregation{sum, count}}},
+		},
+		{
+			name:   "all-null group",
+			df:     loadFrame([]string{"region", "amount"}, []string{"east", "NaN"}, []string{"west", "2"}, []string{"east", "NaN"}),
+			config: []entities.AggregationConfig{{GroupingColumns: []string{"region"}, Aggregations: []entities.Aggregation{sum, average, minimum, count}}},
+		},
+		{
+			name: "passthrough column",
+			df:   loadFrame([]string{"region", "manager", "amount"}, []string{"east", "Alice", "1"}, []string{"west", "Bob", "2"}, []string{"east", "Alice", "3"}),
+			config: []entities.AggregationConfig{{
+				GroupingColumns: []string{"region"}, PassthroughColumns: []string{"manager"}, Aggregations: []entities.Aggregation{sum},
+			}},
+		},
+		{
+			// The buffered path aggregates the groups above parallelGroupThreshold in parallel
+			name:   "parallel groups",
+			df:     benchmarkData(10*parallelGroupThreshold, 2*parallelGroupThreshold),
+			config: []entities.AggregationConfig{{GroupingColumns: []string{"key"}, Aggregations: []entities.Aggregation{sum, average, minimum, count}}},
+		},
+		{
+			name: "chained configurations",
+			df:   loadFrame([]string{"region", "status", "amount"}, []string{"east", "paid", "1"}, []string{"east", "open", "2"}, []string{"west", "paid", "3"}),
+			config: []entities.AggregationConfig{
+				{GroupingColumns: []string{"region", "status"}, Aggregations: []entities.Aggregation{sum}},
+				{GroupingColumns: []string{"region"}, Aggregations: []entities.Aggregation{{Column: "total", AggregateMethod: "max", ResultName: "largest"}}},
+			},
+		},
+	}
+
+	buffered := false
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamed, err := NewGotaProcessorWithOptions(GotaProcessorOptions{Workers: 4}).Aggregate(context.Background(), tt.df, tt.config)
+			if err != nil {
+				t.Fatalf("stream aggregate: %v", err)
+			}
+			bufferedResult, err := NewGotaProcessorWithOptions(GotaProcessorOptions{Workers: 4, StreamAggregate: &buffered}).Aggregate(context.Background(), tt.df, tt.config)
+			if err != nil {
+				t.Fatalf("buffered aggregate: %v", err)
+			}
+
+			if got, want := streamed.Records(), bufferedResult.Records(); !reflect.DeepEqual(got, want) {
+				t.Errorf("stream result = %v, want the buffered result %v", got, want)
+			}
+			for _, name := range streamed.Names() {
+				if got, want := streamed.Col(name).Type(), bufferedResult.Col(name).Type(); got != want {
+					t.Errorf("stream %s type = %s, want the buffered type %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIsStreamable(t *testing.T) {
+	tests := []struct {
+		name         string
+		aggregations []entities.Aggregation
+		want         bool
+	}{
+		{name: "streamable methods", aggregations: []entities.Aggregation{{AggregateMethod: "sum"}, {AggregateMethod: "avg"}, {AggregateMethod: "count"}, {AggregateMethod: "min"}, {AggregateMethod: "max"}}, want: true},
+		{name: "median", aggregations: []entities.Aggregation{{AggregateMethod: "sum"}, {AggregateMethod: "median"}}, want: false},
+		{name: "approximate", aggregations: []entities.Aggregation{{AggregateMethod: "sum", Approximate: true}}, want: false},
+		{name: "weightedAvg", aggregations: []entities.Aggregation{{AggregateMethod: "weightedAvg"}}, want: false},
+		{name: "sharePercent", aggregations: []entities.Aggregation{{AggregateMethod: "sharePercent"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStreamable(entities.AggregationConfig{Aggregations: tt.aggregations}); got != tt.want {
+				t.Errorf("isStreamable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func benchmarkData(rows, groups int) *dataframe.DataFrame {
+	keys := make([]string, rows)
+	amounts := make([]float64, rows)
+	for i := range rows {
+		keys[i] = fmt.Sprintf("g%d", i%groups)
+		amounts[i] = float64(i%1000) / 10
+	}
+	df := dataframe.New(series.New(keys, series.String, "key"), series.New(amounts, series.Float, "amount"))
+	return &df
+}
+
+func BenchmarkAggregatePaths(b *testing.B) {
+	config := []entities.AggregationConfig{{
+		GroupingColumns: []string{"key"},
+		Aggregations: []entities.Aggregation{
+			{Column: "amount", AggregateMethod: "sum", ResultName: "total"},
+			{Column: "amount", AggregateMethod: "avg", ResultName: "average"},
+			{Column: "amount", AggregateMethod: "max", ResultName: "maximum"},
+		},
+	}}
+	buffered := false
+	for _, groups := range []int{10, 10000} {
+		data := benchmarkData(1_000_000, groups)
+		for _, tc := range []struct {
+			name    string
+			options GotaProcessorOptions
+		}{
+			{"buffered/workers=1", GotaProcessorOptions{Workers: 1, StreamAggregate: &buffered}},
+			{"buffered/workers=8", GotaProcessorOptions{Workers: 8, StreamAggregate: &buffered}},
+			{"stream", GotaProcessorOptions{Workers: 8}},
+		} {
+			b.Run(fmt.Sprintf("groups=%d/%s", groups, tc.name), func(b *testing.B) {
+				p := NewGotaProcessorWithOptions(tc.options)
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, err := p.Aggregate(context.Background(), data, config); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
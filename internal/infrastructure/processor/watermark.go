@@ -0,0 +1,166 @@
+package processor
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Watermark keeps the rows whose value of the watermark column is strictly greater than the Since of the config,
+// and returns them with the new watermark, the maximum value of the kept rows formatted for the Since of the next run.
+// The empty Since keeps all rows, and the new watermark stays the Since when no rows are kept.
+// The null values are never kept. The int and float columns are compared as numbers, and the string columns
+// are compared as dates if all their values and Since are dates (see the layouts of the date cast), otherwise as strings.
+func (p *GotaProcessor) Watermark(ctx context.Context, data *dataframe.DataFrame, config entities.WatermarkConfig) (observed *dataframe.DataFrame, watermark string, err error) {
+	defer p.observe("watermark", data)(&observed, &err)
+
+	if err := ctx.Err(); err != nil {
+		return nil, "", domainerrors.NewDataProcessError("watermark", "watermark is canceled", err)
+	}
+
+	if data == nil {
+		return nil, "", domainerrors.NewDataProcessError("watermark", "no data to filter by the watermark", nil)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, "", domainerrors.NewDataProcessError("watermark", "watermark config is invalid", err)
+	}
+	if err := requireColumns("watermark", data, config.Column); err != nil {
+		return nil, "", err
+	}
+
+	column := data.Col(config.Column)
+	key, err := watermarkKey(column, config.Since)
+	if err != nil {
+		return nil, "", domainerrors.NewDataProcessError("watermark", fmt.Sprintf("column '%s' is not comparable with the watermark: %v", config.Column, err), err)
+	}
+
+	var since interface{}
+	if config.Since != "" {
+		if since, err = key(config.Since); err != nil {
+			return nil, "", domainerrors.NewDataProcessError("watermark", fmt.Sprintf("invalid since '%s' for column '%s': %v", config.Since, config.Column, err), err)
+		}
+	}
+
+	kept := make([]int, 0, data.Nrow())
+	watermark = config.Since
+	var maximum interface{}
+	for row := 0; row < column.Len(); row++ {
+		element := column.Elem(row)
+		if isNull(element) {
+			continue
+		}
+
+		// The keys of the values are already checked by watermarkKey
+		text := formatValue(element.Val())
+		value, _ := key(text)
+		if since != nil && compareWatermarkKeys(value, since) <= 0 {
+			continue
+		}
+
+		kept = append(kept, row)
+		if maximum == nil || compareWatermarkKeys(value, maximum) > 0 {
+			maximum = value
+			watermark = text
+		}
+	}
+
+	result := data.Subset(kept)
+	if result.Err != nil {
+		return nil, "", domainerrors.NewDataProcessError("watermark", "failed to subset rows", result.Err)
+	}
+
+	return &result, watermark, nil
+}
+
+// watermarkKey returns the function converting the text of a value to the key compared with the watermark.
+// The string columns are compared as dates if since (when set) and all values of the column are dates.
+func watermarkKey(column series.Series, since string) (func(string) (interface{}, error), error) {
+	switch column.Type() {
+	case series.Int, series.Float:
+		return func(text string) (interface{}, error) {
+			number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a number", text)
+			}
+			return number, nil
+		}, nil
+	case series.String:
+		isDates := since == "" || isDate(since)
+		for row := 0; isDates && row < column.Len(); row++ {
+			if element := column.Elem(row); !isNull(element) {
+				isDates = isDate(element.String())
+			}
+		}
+		if !isDates {
+			return func(text string) (interface{}, error) { return text, nil }, nil
+		}
+
+		return func(text string) (interface{}, error) {
+			parsed, ok := parseDate(text)
+			if !ok {
+				return nil, fmt.Errorf("'%s' is not a date", text)
+			}
+			return parsed, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%s column cannot be compared", column.Type())
+	}
+}
+
+// compareWatermarkKeys compares the keys of the same kind made by watermarkKey.
+func compareWatermarkKeys(a, b interface{}) int {
+	switch x := a.(type) {
+	case float64:
+		return cmp.Compare(x, b.(float64))
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// parseDate parses the text by the layouts of the date cast.
+func parseDate(text string) (time.Time, bool) {
+	trimmed := strings.TrimSpace(text)
+	for _, candidate := range dateLayouts {
+		if parsed, err := time.Parse(candidate.layout, trimmed); err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// columnDateLayout returns the layout of the date cast the first date of the column is written in,
+// or "2006-01-02" if the column has no dates.
+func columnDateLayout(column series.Series) string {
+	for row := 0; row < column.Len(); row++ {
+		element := column.Elem(row)
+		if isNull(element) {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(element.String())
+		for _, candidate := range dateLayouts {
+			if _, err := time.Parse(candidate.layout, trimmed); err == nil {
+				return candidate.layout
+			}
+		}
+	}
+
+	return time.DateOnly
+}
+
+// isDate reports whether the text is a date of the layouts of the date cast.
+func isDate(text string) bool {
+	_, ok := parseDate(text)
+	return ok
+}
@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"context"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"reflect"
+	"testing"
+)
+
+func TestWatermark(t *testing.T) {
+	df := loadFrame(
+		[]string{"id", "seq", "updated", "code"},
+		[]string{"1", "10", "2024-01-05", "b"},
+		[]string{"2", "30", "2024-03-01", "d"},
+		[]string{"3", "", "", ""},
+		[]string{"4", "20", "2024-02-10", "c"},
+		[]string{"5", "5", "2023-12-31", "a"},
+	)
+
+	tests := []struct {
+		name          string
+		config        entities.WatermarkConfig
+		wantIDs       []string
+		wantWatermark string
+	}{
+		{
+			name:          "numbers above the watermark",
+			config:        entities.WatermarkConfig{Column: "seq", Since: "10"},
+			wantIDs:       []string{"2", "4"},
+			wantWatermark: "30",
+		},
+		{
+			name:          "dates above the watermark",
+			config:        entities.WatermarkConfig{Column: "updated", Since: "2024-01-05"},
+			wantIDs:       []string{"2", "4"},
+			wantWatermark: "2024-03-01",
+		},
+		{
+			name:          "strings above the watermark",
+			config:        entities.WatermarkConfig{Column: "code", Since: "b"},
+			wantIDs:       []string{"2", "4"},
+			wantWatermark: "d",
+		},
+		{
+			name:          "first run keeps every non-null row",
+			config:        entities.WatermarkConfig{Column: "seq"},
+			wantIDs:       []string{"1", "2", "4", "5"},
+			wantWatermark: "30",
+		},
+		{
+			name:          "no newer rows keep the watermark",
+			config:        entities.WatermarkConfig{Column: "seq", Since: "30"},
+			wantIDs:       []string{},
+			wantWatermark: "30",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, watermark, err := NewGotaProcessor().Watermark(context.Background(), df, tt.config)
+			if err != nil {
+				t.Fatalf("Watermark() error = %v", err)
+			}
+
+			ids := make([]string, 0)
+			if result.Nrow() > 0 {
+				ids = result.Col("id").Records()
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Watermark() ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if watermark != tt.wantWatermark {
+				t.Errorf("Watermark() watermark = %q, want %q", watermark, tt.wantWatermark)
+			}
+		})
+	}
+}
+
+func TestWatermarkInvalid(t *testing.T) {
+	df := loadFrame(
+		[]string{"seq", "active"},
+		[]string{"1", "true"},
+		[]string{"2", "false"},
+	)
+
+	tests := []struct {
+		name   string
+		config entities.WatermarkConfig
+	}{
+		{name: "missing column", config: entities.WatermarkConfig{Column: "updated", Since: "1"}},
+		{name: "bool column", config: entities.WatermarkConfig{Column: "active", Since: "false"}},
+		{name: "since not a number", config: entities.WatermarkConfig{Column: "seq", Since: "yesterday"}},
+		{name: "no column", config: entities.WatermarkConfig{Since: "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := NewGotaProcessor().Watermark(context.Background(), df, tt.config); err == nil {
+				t.Error("Watermark() error = nil, want an error")
+			}
+		})
+	}
+}
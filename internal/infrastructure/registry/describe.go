@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+)
+
+// aggregateMethodDescriptions describes each aggregate method accepted by Aggregation.Validate
+var aggregateMethodDescriptions = map[string]string{
+	"sum":          "Total of the non-null values in each group",
+	"avg":          "Average of the non-null values in each group",
+	"min":          "Minimum of the non-null values in each group",
+	"max":          "Maximum of the non-null values in each group",
+	"count":        "Number of the non-null values in each group",
+	"median":       "Median of the non-null values in each group (the average of the two middle values for an even count)",
+	"weightedAvg":  "Average weighted by the weightColumn in each group (null if the total weight is zero)",
+	"sharePercent": "Sum of each group as a percentage of the sum of all groups (null if the sum of all groups is zero)",
+}
+
+// DescribeOutputs returns the options and their descriptions of each output format registered in the default registry.
+func DescribeOutputs() map[string]map[string]string {
+	descriptions := make(map[string]map[string]string)
+	for _, format := range Default().OutputFormats() {
+		out, err := Default().GetOutput(format)
+		if err != nil {
+			continue
+		}
+		descriptions[format] = out.GetFormatOptions(format)
+	}
+
+	return descriptions
+}
+
+// DescribeDataSources returns the capabilities of each data source type registered in the default registry.
+func DescribeDataSources() map[string]interfaces.Capabilities {
+	descriptions := make(map[string]interfaces.Capabilities)
+	for _, sourceType := range Default().DataSourceTypes() {
+		source, err := Default().GetDataSource(sourceType)
+		if err != nil {
+			continue
+		}
+		descriptions[sourceType] = source.GetCapabilities(interfaces.DataSourceConfig{Type: sourceType})
+	}
+
+	return descriptions
+}
+
+// DescribeAggregations returns the description of each supported aggregate method.
+func DescribeAggregations() map[string]string {
+	descriptions := make(map[string]string)
+	for _, method := range entities.SupportedAggregateMethods() {
+		descriptions[method] = aggregateMethodDescriptions[method]
+	}
+
+	return descriptions
+}
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	"testing"
+)
+
+func TestDescribeOutputs(t *testing.T) {
+	descriptions := DescribeOutputs()
+
+	tests := []struct {
+		format  string
+		options []string
+	}{
+		{format: "csv", options: []string{"delimiter", "header", "quoteAll", "lineEnding", "showTotals", "nullValue"}},
+		{format: "console", options: []string{"border", "maxColWidth", "showTotals", "nullValue"}},
+		{format: "json", options: []string{"indent", "includeMetadata"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			options, ok := descriptions[tt.format]
+			if !ok {
+				t.Fatalf("DescribeOutputs() has no %q format: %v", tt.format, descriptions)
+			}
+			for _, option := range tt.options {
+				if options[option] == "" {
+					t.Errorf("DescribeOutputs()[%q] has no description of %q", tt.format, option)
+				}
+			}
+		})
+	}
+
+	for _, format := range Default().OutputFormats() {
+		if _, ok := descriptions[format]; !ok {
+			t.Errorf("DescribeOutputs() has no registered format %q", format)
+		}
+	}
+}
+
+func TestDescribeDataSources(t *testing.T) {
+	descriptions := DescribeDataSources()
+
+	if csv, ok := descriptions["csv"]; !ok || !csv.SupportsRowCountEstimation || csv.RequiredAuth != "none" {
+		t.Errorf("DescribeDataSources()[\"csv\"] = %+v, want the row count estimation without auth", csv)
+	}
+	if sheets, ok := descriptions["googlesheets"]; !ok || !sheets.SupportsRange || sheets.RequiredAuth != "oauth2" {
+		t.Errorf("DescribeDataSources()[\"googlesheets\"] = %+v, want the range with oauth2", sheets)
+	}
+}
+
+func TestDescribeAggregations(t *testing.T) {
+	descriptions := DescribeAggregations()
+
+	for _, method := range entities.SupportedAggregateMethods() {
+		if descriptions[method] == "" {
+			t.Errorf("DescribeAggregations() has no description of %q", method)
+		}
+	}
+	if len(descriptions) != len(entities.SupportedAggregateMethods()) {
+		t.Errorf("DescribeAggregations() has %d methods, want %d", len(descriptions), len(entities.SupportedAggregateMethods()))
+	}
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/datasource"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/output"
+	"slices"
+	"sync"
+)
+
+// Registry maps the source types (Config.Type) and the output formats (Config.OutputFormat)
+// to the factories of their implementations. It is safe for concurrent use.
+type Registry struct {
+	mu          sync.RWMutex
+	dataSources map[string]func() interfaces.DataSource
+	outputs     map[string]func() interfaces.Output
+}
+
+// defaultRegistry is the registry pre-registered with the built-in implementations
+var defaultRegistry = NewDefaultRegistry()
+
+// Default returns the process-wide registry pre-registered with the built-in implementations.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// NewRegistry creates a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		dataSources: make(map[string]func() interfaces.DataSource),
+		outputs:     make(map[string]func() interfaces.Output),
+	}
+}
+
+// NewDefaultRegistry creates a new Registry pre-registered with the built-in implementations:
+// - Data sources: csv, googlesheets
+// - Outputs: csv, console, json, report
+//
+// The built-in googlesheets source has no token provider, so register a factory with the provider to fetch the sheets.
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+
+	registry.RegisterDataSource("csv", func() interfaces.DataSource { return datasource.NewCSVDataSource() })
+	registry.RegisterDataSource("googlesheets", func() interfaces.DataSource { return datasource.NewGoogleSheetsDataSource(nil) })
+
+	registry.RegisterOutput("csv", func() interfaces.Output { return output.NewCSVOutput() })
+	registry.RegisterOutput("console", func() interfaces.Output { return output.NewConsoleOutput() })
+	registry.RegisterOutput("json", func() interfaces.Output { return output.NewJSONOutput() })
+	registry.RegisterOutput("report", func() interfaces.Output { return output.NewReportOutput() })
+
+	return registry
+}
+
+// RegisterDataSource registers the factory of the data source for the source type, replacing the existing one.
+// The source type is also added to the types accepted by Config.Validate (see entities.RegisterSourceType).
+func (r *Registry) RegisterDataSource(sourceType string, factory func() interfaces.DataSource) {
+	entities.RegisterSourceType(sourceType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dataSources[sourceType] = factory
+}
+
+// RegisterOutput registers the factory of the output for the format, replacing the existing one.
+// The format is also added to the formats accepted by Config.Validate (see entities.RegisterOutputFormat).
+func (r *Registry) RegisterOutput(format string, factory func() interfaces.Output) {
+	entities.RegisterOutputFormat(format)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.outputs[format] = factory
+}
+
+// GetDataSource creates the data source registered for the source type.
+// Returns a ConfigurationError listing the registered types if the type is unknown.
+func (r *Registry) GetDataSource(sourceType string) (interfaces.DataSource, error) {
+	r.mu.RLock()
+	factory, ok := r.dataSources[sourceType]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, domainerrors.NewConfigurationError("type", fmt.Sprintf("unknown data source type '%s', registered: %v", sourceType, r.DataSourceTypes()), nil)
+	}
+
+	return factory(), nil
+}
+
+// GetOutput creates the output registered for the format.
+// Returns a ConfigurationError listing the registered formats if the format is unknown.
+func (r *Registry) GetOutput(format string) (interfaces.Output, error) {
+	r.mu.RLock()
+	factory, ok := r.outputs[format]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, domainerrors.NewConfigurationError("outputFormat", fmt.Sprintf("unknown output format '%s', registered: %v", format, r.OutputFormats()), nil)
+	}
+
+	return factory(), nil
+}
+
+// DataSourceTypes returns the registered source types in sorted order.
+func (r *Registry) DataSourceTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.dataSources))
+	for sourceType := range r.dataSources {
+		types = append(types, sourceType)
+	}
+	slices.Sort(types)
+
+	return types
+}
+
+// OutputFormats returns the registered output formats in sorted order.
+func (r *Registry) OutputFormats() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	formats := make([]string, 0, len(r.outputs))
+	for format := range r.outputs {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+
+	return formats
+}
+
+// ValidateConfig validates the Config and checks its source type and output format are registered.
+func (r *Registry) ValidateConfig(config *entities.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.ValidateType(r.DataSourceTypes()); err != nil {
+		return err
+	}
+
+	return config.ValidateOutputFormat(r.OutputFormats())
+}
@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"github.com/SHIMA0111/kanjo/internal/domain/entities"
+	domainerrors "github.com/SHIMA0111/kanjo/internal/domain/errors"
+	"github.com/SHIMA0111/kanjo/internal/domain/interfaces"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/datasource"
+	"github.com/SHIMA0111/kanjo/internal/infrastructure/output"
+	"github.com/go-gota/gota/dataframe"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegistryCustomDataSource(t *testing.T) {
+	registry := NewRegistry()
+	df := dataframe.LoadRecords([][]string{{"id"}, {"1"}})
+	created := 0
+	registry.RegisterDataSource("memory", func() interfaces.DataSource {
+		created++
+		return datasource.NewInMemoryDataSource(&df)
+	})
+
+	source, err := registry.GetDataSource("memory")
+	if err != nil {
+		t.Fatalf("GetDataSource() error = %v", err)
+	}
+	if _, ok := source.(*datasource.InMemoryDataSource); !ok {
+		t.Errorf("GetDataSource() = %T, want *datasource.InMemoryDataSource", source)
+	}
+	if _, err := registry.GetDataSource("memory"); err != nil || created != 2 {
+		t.Errorf("GetDataSource() created %d sources, want a new source per call", created)
+	}
+
+	if got, want := registry.DataSourceTypes(), []string{"memory"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DataSourceTypes() = %v, want %v", got, want)
+	}
+	// The registered type is accepted by Config.Validate
+	if !slices.Contains(entities.SupportedSourceTypes(), "memory") {
+		t.Errorf("SupportedSourceTypes() = %v, want the registered type", entities.SupportedSourceTypes())
+	}
+}
+
+func TestRegistryCustomOutput(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterOutput("stdout", func() interfaces.Output { return output.NewConsoleOutput() })
+
+	out, err := registry.GetOutput("stdout")
+	if err != nil {
+		t.Fatalf("GetOutput() error = %v", err)
+	}
+	if _, ok := out.(*output.ConsoleOutput); !ok {
+		t.Errorf("GetOutput() = %T, want *output.ConsoleOutput", out)
+	}
+}
+
+func TestRegistryUnknownKey(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	_, err := registry.GetDataSource("gsheets")
+	if !domainerrors.IsConfigurationError(err) {
+		t.Fatalf("GetDataSource() error = %v, want a ConfigurationError", err)
+	}
+	if message := err.Error(); !strings.Contains(message, "gsheets") || !strings.Contains(message, "[csv googlesheets]") {
+		t.Errorf("GetDataSource() error = %q, want the unknown type and the registered types", message)
+	}
+
+	_, err = registry.GetOutput("cvs")
+	if !domainerrors.IsConfigurationError(err) {
+		t.Fatalf("GetOutput() error = %v, want a ConfigurationError", err)
+	}
+	if message := err.Error(); !strings.Contains(message, "cvs") || !strings.Contains(message, "[console csv json report]") {
+		t.Errorf("GetOutput() error = %q, want the unknown format and the registered formats", message)
+	}
+}
+
+func TestDefaultRegistryBuiltins(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	if got, want := registry.DataSourceTypes(), []string{"csv", "googlesheets"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DataSourceTypes() = %v, want %v", got, want)
+	}
+	if got, want := registry.OutputFormats(), []string{"console", "csv", "json", "report"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OutputFormats() = %v, want %v", got, want)
+	}
+	for _, format := range registry.OutputFormats() {
+		out, err := registry.GetOutput(format)
+		if err != nil {
+			t.Fatalf("GetOutput(%q) error = %v", format, err)
+		}
+		if !slices.Contains(out.SupportedFormats(), format) {
+			t.Errorf("GetOutput(%q).SupportedFormats() = %v, want the format", format, out.SupportedFormats())
+		}
+	}
+}